@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"crypto"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/reseed"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+// NewBlocklistCommand creates a new CLI command for publishing the reseed server's
+// --blacklist file as a signed I2P blocklist SU3, so other operators can fetch and
+// apply the same list.
+func NewBlocklistCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "blocklist",
+		Usage:  "Build a signed blocklist su3 from a blacklist file",
+		Action: blocklistAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "signer",
+				Value: getDefaultSigner(),
+				Usage: "Your su3 signing ID (ex. something@mail.i2p)",
+			},
+			&cli.StringFlag{
+				Name:  "key",
+				Usage: "Path to your su3 signing private key (defaults to <signer>.pem)",
+			},
+			&cli.StringFlag{
+				Name:  "blacklist",
+				Usage: "Path to the blacklist file, one IP address or CIDR range per line",
+			},
+			&cli.StringFlag{
+				Name:  "out",
+				Value: "blocklist.su3",
+				Usage: "Path to write the signed blocklist su3",
+			},
+			base64Flag("Write the su3 as base64 text instead of binary"),
+		},
+	}
+}
+
+func blocklistAction(c *cli.Context) error {
+	signerID := c.String("signer")
+	if signerID == "" {
+		return fmt.Errorf("--signer is required")
+	}
+	if c.String("blacklist") == "" {
+		return fmt.Errorf("--blacklist is required")
+	}
+
+	keyPath := c.String("key")
+	if keyPath == "" {
+		keyPath = signerFile(signerID) + ".pem"
+	}
+	privKey, err := loadPrivateKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	blacklist := reseed.NewBlacklist()
+	if err := blacklist.LoadFile(c.String("blacklist")); err != nil {
+		return err
+	}
+	list, count, err := formatBlocklist(blacklist.Entries(), signerID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	data, err := buildBlocklistSU3(list, signerID, privKey)
+	if err != nil {
+		return err
+	}
+	if c.Bool("base64") {
+		data = encodeSU3Base64(data)
+	}
+
+	if err := os.WriteFile(c.String("out"), data, 0o644); err != nil {
+		lgr.WithError(err).WithField("out", c.String("out")).Error("Failed to write blocklist su3")
+		return err
+	}
+
+	fmt.Printf("Blocklist su3 with %d entries saved to: %s\n", count, c.String("out"))
+	return nil
+}
+
+// formatBlocklist renders blacklist entries in the router blocklist format: a
+// # header naming the signer, then one address or CIDR range per line. Entries
+// that are neither are rejected rather than published for routers to skip; #
+// comment lines are dropped. It also returns the number of entries written.
+func formatBlocklist(entries []string, signerID string, now time.Time) ([]byte, int, error) {
+	var b strings.Builder
+	count := 0
+	fmt.Fprintf(&b, "# Blocklist published by %s on %s\n", signerID, now.UTC().Format(time.RFC3339))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return nil, 0, fmt.Errorf("blacklist entry %q is not an IP address or CIDR range", entry)
+			}
+		}
+		b.WriteString(entry + "\n")
+		count++
+	}
+	if count == 0 {
+		return nil, 0, fmt.Errorf("blacklist has no entries")
+	}
+	return []byte(b.String()), count, nil
+}
+
+// buildBlocklistSU3 wraps the list in a blocklist SU3, signs it and returns the encoded file.
+func buildBlocklistSU3(list []byte, signerID string, privKey crypto.Signer) ([]byte, error) {
+	su3File, err := su3.NewBlocklistFile(list)
+	if err != nil {
+		return nil, err
+	}
+	su3File.SignerID = []byte(signerID)
+
+	if err := su3File.SignWith(privKey); err != nil {
+		lgr.WithError(err).WithField("signer_id", signerID).Error("Failed to sign blocklist su3")
+		return nil, err
+	}
+
+	return su3File.MarshalBinary()
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+func TestFormatBlocklist(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	list, count, err := formatBlocklist([]string{"# local note", "192.0.2.1", "198.51.100.0/24", "2001:db8::1"}, "you@mail.i2p", now)
+	if err != nil {
+		t.Fatalf("formatBlocklist() error: %v", err)
+	}
+	want := "# Blocklist published by you@mail.i2p on 2026-01-02T03:04:05Z\n192.0.2.1\n198.51.100.0/24\n2001:db8::1\n"
+	if string(list) != want || count != 3 {
+		t.Errorf("formatBlocklist() = %q (%d entries), want %q (3 entries)", list, count, want)
+	}
+
+	if _, _, err := formatBlocklist([]string{"192.0.2.1", "not-an-ip"}, "you@mail.i2p", now); err == nil || !strings.Contains(err.Error(), "not-an-ip") {
+		t.Errorf("Expected an error naming the invalid entry, got: %v", err)
+	}
+	if _, _, err := formatBlocklist([]string{"# only a comment"}, "you@mail.i2p", now); err == nil {
+		t.Error("Expected an error for a blacklist without entries")
+	}
+}
+
+func TestBuildBlocklistSU3_Verifies(t *testing.T) {
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	certDer, err := su3.NewSigningCertificate("you@mail.i2p", privKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(certDer)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	data, err := buildBlocklistSU3([]byte("192.0.2.1\n"), "you@mail.i2p", privKey)
+	if err != nil {
+		t.Fatalf("buildBlocklistSU3() error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "blocklist.su3")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	su3File, err := loadAndParseSU3File(path, false, nil)
+	if err != nil {
+		t.Fatalf("loadAndParseSU3File() error: %v", err)
+	}
+	if su3File.ContentType != su3.ContentTypeBlocklist {
+		t.Errorf("Expected blocklist content type, got %d", su3File.ContentType)
+	}
+	if err := verifySignature(su3File, cert); err != nil {
+		t.Errorf("verifySignature() error: %v", err)
+	}
+}
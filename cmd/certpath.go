@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// certificateDirCandidates lists the directories Java I2P and i2pd keep their
+// bundled signer certificates in on goos, in the order they are tried: the $I2P
+// override first, then per-user installs before system-wide ones, and Java I2P
+// before i2pd. Each entry holds one subdirectory per purpose, such as reseed.
+// home is the user's home directory and getenv looks up environment variables.
+func certificateDirCandidates(goos, home string, getenv func(string) string) []string {
+	var dirs []string
+	add := func(parts ...string) {
+		if parts[0] != "" {
+			dirs = append(dirs, filepath.Join(append(parts, "certificates")...))
+		}
+	}
+	add(getenv("I2P"))
+	switch goos {
+	case "windows":
+		add(getenv("LOCALAPPDATA"), "I2P")
+		add(getenv("APPDATA"), "I2P")
+		add(getenv("ProgramFiles"), "i2p")
+		add(getenv("ProgramFiles(x86)"), "i2p")
+		add(getenv("APPDATA"), "i2pd")
+		add(getenv("LOCALAPPDATA"), "i2pd")
+		add(getenv("ProgramData"), "i2pd")
+	case "darwin":
+		add(home, "Library", "Application Support", "i2p")
+		add(home, "i2p")
+		add("/Applications/i2p")
+		add(home, "Library", "Application Support", "i2pd")
+		add(home, ".i2pd")
+	default:
+		add(home, "i2p-config")
+		add(home, "i2p")
+		add(home, ".i2p")
+		add("/var/lib/i2p/i2p-config")
+		add("/usr/share/i2p")
+		add(home, ".i2pd")
+		add("/var/lib/i2pd")
+		add("/usr/share/i2pd")
+		add("/var/db/i2pd")
+		add("/usr/local/share/i2pd")
+	}
+	return dirs
+}
+
+// findCertificatesDir returns the first purpose subdirectory, such as reseed,
+// of the certificateDirCandidates that exists on this system. If there is none
+// it falls back to the one under I2PHome.
+func findCertificatesDir(purpose string) string {
+	home, _ := os.UserHomeDir()
+	for _, dir := range certificateDirCandidates(runtime.GOOS, home, os.Getenv) {
+		path := filepath.Join(dir, purpose)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+	}
+	return filepath.Join(I2PHome(), "certificates", purpose)
+}
+
+// signatureVerifier is an SU3 file whose signature can be checked, whether it
+// was read whole or streamed.
+type signatureVerifier interface {
+	VerifySignature(cert *x509.Certificate) error
+}
+
+// findCertificateInDir tries every certificate in dir against su3File and
+// returns the first one that verifies it, with its path. It is used when the
+// signer's own certificate is missing or does not verify, so a bundle can be
+// checked against the certificates an I2P install ships.
+func findCertificateInDir(dir string, su3File signatureVerifier) (*x509.Certificate, string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	tried := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".crt") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		cert, err := loadCertificate(path)
+		if err != nil {
+			continue
+		}
+		tried++
+		if su3File.VerifySignature(cert) == nil {
+			return cert, path, nil
+		}
+	}
+	return nil, "", fmt.Errorf("none of the %d certificates in %s verifies the file", tried, dir)
+}
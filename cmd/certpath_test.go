@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+func TestCertificateDirCandidates(t *testing.T) {
+	env := map[string]string{"I2P": "/opt/i2p", "APPDATA": `C:\Users\u\AppData\Roaming`}
+	getenv := func(key string) string { return env[key] }
+
+	linux := certificateDirCandidates("linux", "/home/u", getenv)
+	if linux[0] != filepath.Join("/opt/i2p", "certificates") {
+		t.Errorf("Expected $I2P to be tried first, got %v", linux)
+	}
+	for _, want := range []string{"/home/u/.i2pd/certificates", "/usr/share/i2pd/certificates", "/var/lib/i2pd/certificates", "/usr/share/i2p/certificates"} {
+		found := false
+		for _, dir := range linux {
+			found = found || dir == filepath.FromSlash(want)
+		}
+		if !found {
+			t.Errorf("Expected %s among the Linux candidates, got %v", want, linux)
+		}
+	}
+
+	env["I2P"] = ""
+	windows := certificateDirCandidates("windows", "", getenv)
+	if len(windows) != 2 || !strings.Contains(windows[0], "I2P") || !strings.Contains(windows[1], "i2pd") {
+		t.Errorf("Expected only the APPDATA candidates when the rest is unset, got %v", windows)
+	}
+}
+
+// writeTestSignerCert writes a certificate for key named after signerID into dir.
+func writeTestSignerCert(t *testing.T, dir, signerID string, key *rsa.PrivateKey) {
+	t.Helper()
+	der, err := su3.NewSigningCertificate(signerID, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, signerFile(signerID)+".crt")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestVerify_TriesEveryKeystoreCertificate verifies that a file whose signer
+// has no certificate of its own name is verified by whichever certificate in
+// the keystore matches, and that --signer turns the search off.
+func TestVerify_TriesEveryKeystoreCertificate(t *testing.T) {
+	keystore := t.TempDir()
+	signerKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestSignerCert(t, keystore, "other@mail.i2p", otherKey)
+	writeTestSignerCert(t, keystore, "renamed@mail.i2p", signerKey)
+
+	file := su3.New()
+	file.SignerID = []byte("signer@mail.i2p")
+	file.Content = []byte("content")
+	if err := file.Sign(signerKey); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := file.MarshalBinary()
+	su3Path := filepath.Join(t.TempDir(), "file.su3")
+	if err := os.WriteFile(su3Path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, path, err := findCertificateInDir(keystore, file)
+	if err != nil || filepath.Base(path) != "renamed_at_mail.i2p.crt" || cert.Subject.CommonName != "renamed@mail.i2p" {
+		t.Errorf("Expected the renamed certificate to verify, got %s (%v)", path, err)
+	}
+
+	run := func(args ...string) error {
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = NewSu3VerifyCommand().Flags
+		app.Action = su3VerifyAction
+		return app.Run(append([]string{"test", "--keystore=" + keystore, "--deep=false"}, append(args, su3Path)...))
+	}
+	if err := run(); err != nil {
+		t.Errorf("Expected the keystore search to verify the file, got %v", err)
+	}
+	if err := run("--signer=signer@mail.i2p"); err == nil {
+		t.Error("Expected --signer to check only that signer's certificate")
+	}
+
+	os.Remove(filepath.Join(keystore, "renamed_at_mail.i2p.crt"))
+	if _, _, err := findCertificateInDir(keystore, file); err == nil || !strings.Contains(err.Error(), "none of the 1 certificates") {
+		t.Errorf("Expected no certificate to verify, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/reseed"
+)
+
+// NewCheckNetDbCommand creates a new CLI command that checks whether a netDb
+// would yield a servable reseed bundle, exiting non-zero when it would not so
+// it can gate CI jobs and cron scripts.
+func NewCheckNetDbCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "check-netdb",
+		Usage: "Check that a netDb has enough usable RouterInfos to build a reseed bundle",
+		Description: `Run the netDb through the same filters the reseed server applies when it
+rebuilds (age, parsing, reachability, congestion and version, then keeping
+the freshest --min-routerinfo-fraction) and compare the result against --numRi. Exits with status 0 when a
+bundle could be built and 1 otherwise.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "netdb",
+				Aliases: []string{"n"},
+				Usage:   "Path to the netDb directory containing RouterInfo files",
+				Value:   findDefaultNetDbPath(),
+			},
+			&cli.DurationFlag{
+				Name:  "routerInfoAge",
+				Value: 72 * time.Hour,
+				Usage: "Maximum age of router infos to include in reseed files (ex. 72h, 8d)",
+			},
+			&cli.IntFlag{
+				Name:  "numRi",
+				Value: 61,
+				Usage: "Number of routerInfos to include in each su3 file",
+			},
+			&cli.StringFlag{
+				Name:  "min-router-version",
+				Usage: "Exclude routerInfos older than this router version (ex. 0.9.62), on top of the built-in version check",
+			},
+			routerInfoFractionFlag(),
+		},
+		Action: checkNetDbAction,
+	}
+}
+
+// checkNetDbAction runs the check and turns a failing verdict into an error, so
+// the process exits with a non-zero status.
+func checkNetDbAction(c *cli.Context) error {
+	netdbPath := c.String("netdb")
+	if netdbPath == "" {
+		return fmt.Errorf("netDb path is required. Use --netdb flag or ensure I2P is installed in a standard location")
+	}
+	if err := validateNetDbPath(netdbPath); err != nil {
+		return err
+	}
+	if c.Int("numRi") < 1 {
+		return fmt.Errorf("--numRi must be at least 1")
+	}
+	if err := validateRouterInfoFraction(c.Float64("min-routerinfo-fraction")); err != nil {
+		return err
+	}
+
+	netdb := reseed.NewLocalNetDb(netdbPath, c.Duration("routerInfoAge"))
+	if version := c.String("min-router-version"); version != "" {
+		if err := reseed.ValidateRouterVersion(version); err != nil {
+			return fmt.Errorf("--min-router-version: %w", err)
+		}
+		netdb.MinRouterVersion = version
+	}
+	// Only counts are needed, so do not hold every file in memory
+	netdb.LazyData = true
+
+	check, err := netdb.Check(c.Int("numRi"), c.Float64("min-routerinfo-fraction"))
+	if err != nil {
+		return err
+	}
+	return printNetDbCheck(os.Stdout, netdbPath, check)
+}
+
+// printNetDbCheck writes the counts and verdict for check, returning an error
+// when the netDb could not produce a bundle.
+func printNetDbCheck(w io.Writer, netdbPath string, check reseed.NetDbCheck) error {
+	fmt.Fprintf(w, "netDb:              %s\n", netdbPath)
+	fmt.Fprintf(w, "RouterInfo files:   %d\n", check.Scanned)
+	fmt.Fprintf(w, "Passing filters:    %d\n", check.Valid)
+	fmt.Fprintf(w, "Usable (freshest):  %d\n", check.Usable)
+	fmt.Fprintf(w, "Needed per bundle:  %d\n", check.Required)
+	if !check.OK() {
+		fmt.Fprintln(w, "FAIL: not enough usable RouterInfos to build a reseed bundle")
+		return fmt.Errorf("netDb %s has %d usable routerInfos, need %d", netdbPath, check.Usable, check.Required)
+	}
+	fmt.Fprintln(w, "OK: a reseed bundle can be built")
+	return nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/reseed"
+)
+
+func TestPrintNetDbCheck(t *testing.T) {
+	var out bytes.Buffer
+	if err := printNetDbCheck(&out, "/netDb", reseed.NetDbCheck{Scanned: 120, Valid: 100, Usable: 75, Required: 61}); err != nil {
+		t.Errorf("Expected a passing check, got %v", err)
+	}
+	if !strings.Contains(out.String(), "OK:") || !strings.Contains(out.String(), "75") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := printNetDbCheck(&out, "/netDb", reseed.NetDbCheck{Scanned: 80, Valid: 60, Usable: 45, Required: 61}); err == nil {
+		t.Error("Expected a failing check to return an error")
+	}
+	if !strings.Contains(out.String(), "FAIL:") {
+		t.Errorf("Expected a FAIL verdict, got:\n%s", out.String())
+	}
+}
+
+func TestCheckNetDbAction_FailsOnUnusableNetDb(t *testing.T) {
+	netdb := t.TempDir()
+	if err := os.WriteFile(filepath.Join(netdb, "routerInfo-corrupt.dat"), []byte("not a router info"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := cli.NewApp()
+	app.Name = "test"
+	app.Flags = NewCheckNetDbCommand().Flags
+	app.Action = checkNetDbAction
+
+	if err := app.Run([]string{"test", "--netdb=" + netdb, "--numRi=1"}); err == nil {
+		t.Error("Expected a netDb without valid RouterInfos to fail the check")
+	}
+	if err := app.Run([]string{"test", "--netdb=" + filepath.Join(netdb, "missing")}); err == nil {
+		t.Error("Expected a missing netDb to fail the check")
+	}
+	if err := app.Run([]string{"test", "--netdb=" + netdb, "--min-router-version=latest"}); err == nil {
+		t.Error("Expected an invalid --min-router-version to be rejected")
+	}
+	for _, fraction := range []string{"0", "1.5"} {
+		if err := app.Run([]string{"test", "--netdb=" + netdb, "--min-routerinfo-fraction=" + fraction}); err == nil || !strings.Contains(err.Error(), "min-routerinfo-fraction") {
+			t.Errorf("Expected --min-routerinfo-fraction=%s to be rejected, got %v", fraction, err)
+		}
+	}
+}
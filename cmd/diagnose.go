@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-i2p/common/router_info"
@@ -42,6 +44,7 @@ to prevent "mapping format violation" errors during reseed operations.`,
 				Usage:   "Remove files that fail parsing (use with caution)",
 				Value:   false,
 			},
+			netDbReadOnlyFlag(),
 			&cli.BoolFlag{
 				Name:    "verbose",
 				Aliases: []string{"v"},
@@ -72,22 +75,31 @@ func diagnoseRouterInfoFiles(ctx *cli.Context) error {
 
 	printDiagnosisHeader(config)
 
-	routerInfoPattern, err := compileRouterInfoPattern()
+	stats, err := scanNetDb(config)
 	if err != nil {
 		return err
 	}
 
+	printDiagnosisSummary(stats, config.removeBad)
+	return nil
+}
+
+// scanNetDb walks the netDb once, analyzing every RouterInfo file.
+func scanNetDb(config *diagnosisConfig) (*diagnosisStats, error) {
+	routerInfoPattern, err := compileRouterInfoPattern()
+	if err != nil {
+		return nil, err
+	}
+
 	stats := &diagnosisStats{}
 
 	err = filepath.WalkDir(config.netdbPath, func(path string, d fs.DirEntry, err error) error {
 		return processRouterInfoFile(path, d, err, routerInfoPattern, config, stats)
 	})
 	if err != nil {
-		return fmt.Errorf("error walking netDb directory: %v", err)
+		return nil, fmt.Errorf("error walking netDb directory: %v", err)
 	}
-
-	printDiagnosisSummary(stats, config.removeBad)
-	return nil
+	return stats, nil
 }
 
 // diagnosisConfig holds all configuration parameters for diagnosis
@@ -95,10 +107,25 @@ type diagnosisConfig struct {
 	netdbPath string
 	maxAge    time.Duration
 	removeBad bool
+	readOnly  bool
 	verbose   bool
 	debug     bool
+	// quiet suppresses the per-file report, for scans whose results are
+	// only read from the stats
+	quiet bool
 }
 
+// printf prints a line of the per-file report unless the scan is quiet.
+func (config *diagnosisConfig) printf(format string, args ...any) {
+	if !config.quiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// diagnosisAgeBuckets are the upper bounds of the file age histogram; files
+// older than the last bound are counted in one more, open-ended bucket.
+var diagnosisAgeBuckets = [...]time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour, 72 * time.Hour}
+
 // diagnosisStats tracks file processing statistics
 type diagnosisStats struct {
 	totalFiles     int
@@ -106,6 +133,20 @@ type diagnosisStats struct {
 	corruptedFiles int
 	validFiles     int
 	removedFiles   int
+	// ageCounts counts every RouterInfo file by age, one entry per
+	// diagnosisAgeBuckets bound plus one for older files
+	ageCounts [len(diagnosisAgeBuckets) + 1]int
+}
+
+// recordAge counts a file of the given age in the age histogram.
+func (stats *diagnosisStats) recordAge(age time.Duration) {
+	for i, bound := range diagnosisAgeBuckets {
+		if age < bound {
+			stats.ageCounts[i]++
+			return
+		}
+	}
+	stats.ageCounts[len(diagnosisAgeBuckets)]++
 }
 
 // extractDiagnosisConfig extracts and validates configuration from CLI context
@@ -114,6 +155,7 @@ func extractDiagnosisConfig(ctx *cli.Context) (*diagnosisConfig, error) {
 		netdbPath: ctx.String("netdb"),
 		maxAge:    ctx.Duration("max-age"),
 		removeBad: ctx.Bool("remove-bad"),
+		readOnly:  ctx.Bool("netdb-readonly"),
 		verbose:   ctx.Bool("verbose"),
 		debug:     ctx.Bool("debug"),
 	}
@@ -128,6 +170,12 @@ func extractDiagnosisConfig(ctx *cli.Context) (*diagnosisConfig, error) {
 		return nil, fmt.Errorf("netDb path is required. Use --netdb flag or ensure I2P is installed in a standard location")
 	}
 
+	if config.removeBad {
+		if err := checkNetDbWritable(config.readOnly, config.netdbPath, "--remove-bad"); err != nil {
+			return nil, err
+		}
+	}
+
 	return config, nil
 }
 
@@ -197,6 +245,7 @@ func shouldSkipOldFile(path string, d fs.DirEntry, config *diagnosisConfig, stat
 	}
 
 	age := time.Since(info.ModTime())
+	stats.recordAge(age)
 	if age > config.maxAge {
 		stats.tooOldFiles++
 		if config.verbose {
@@ -212,7 +261,7 @@ func shouldSkipOldFile(path string, d fs.DirEntry, config *diagnosisConfig, stat
 func analyzeRouterInfoFile(path string, config *diagnosisConfig, stats *diagnosisStats) error {
 	routerBytes, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Printf("ERROR reading %s: %v\n", path, err)
+		config.printf("ERROR reading %s: %v\n", path, err)
 		stats.corruptedFiles++
 		return nil
 	}
@@ -228,9 +277,9 @@ func analyzeRouterInfoFile(path string, config *diagnosisConfig, stats *diagnosi
 
 // handleCorruptedFile processes files that fail parsing
 func handleCorruptedFile(path string, parseErr error, remainder []byte, config *diagnosisConfig, stats *diagnosisStats) error {
-	fmt.Printf("CORRUPTED: %s - %v\n", path, parseErr)
+	config.printf("CORRUPTED: %s - %v\n", path, parseErr)
 	if len(remainder) > 0 {
-		fmt.Printf("  Leftover data: %d bytes\n", len(remainder))
+		config.printf("  Leftover data: %d bytes\n", len(remainder))
 		if config.verbose {
 			maxBytes := len(remainder)
 			if maxBytes > 50 {
@@ -258,7 +307,7 @@ func handleCorruptedFile(path string, parseErr error, remainder []byte, config *
 func validateRouterInfo(path string, riStruct router_info.RouterInfo, config *diagnosisConfig, stats *diagnosisStats) error {
 	gv, err := riStruct.GoodVersion()
 	if err != nil {
-		fmt.Printf("Version check error %s", err)
+		config.printf("Version check error %s", err)
 	}
 
 	stats.validFiles++
@@ -266,14 +315,78 @@ func validateRouterInfo(path string, riStruct router_info.RouterInfo, config *di
 		if riStruct.Reachable() && riStruct.UnCongested() && gv {
 			fmt.Printf("OK: %s (reachable, uncongested, good version)\n", path)
 		} else {
+			caps, version := riStruct.RouterCapabilities(), riStruct.RouterVersion()
 			fmt.Printf("OK: %s (but would be skipped by reseed: reachable=%v uncongested=%v goodversion=%v)\n",
 				path, riStruct.Reachable(), riStruct.UnCongested(), gv)
+			fmt.Printf("  caps=%q version=%q\n", caps, version)
+			for _, reason := range skipReasons(caps, version, gv, err) {
+				fmt.Printf("  - %s\n", reason)
+			}
 		}
 	}
 
 	return nil
 }
 
+// congestionCaps explains the capability letters that make UnCongested false.
+var congestionCaps = []struct{ letter, meaning string }{
+	{"D", "medium congestion, the router asks for fewer tunnels"},
+	{"E", "high congestion, the router asks to be avoided"},
+	{"G", "the router is rejecting all tunnel build requests"},
+}
+
+// skipReasons explains, one reason per line, why a RouterInfo with the given
+// capabilities and version fails the Reachable, UnCongested or GoodVersion
+// checks that decide whether reseed puts it in bundles. goodVersion and
+// versionErr are the results of GoodVersion.
+func skipReasons(caps, version string, goodVersion bool, versionErr error) []string {
+	var reasons []string
+	switch {
+	case strings.Contains(caps, "U"):
+		reasons = append(reasons, "capability U: the router reports itself unreachable (firewalled or behind NAT), so new routers cannot connect to it")
+	case !strings.Contains(caps, "R"):
+		reasons = append(reasons, "no capability R: the router does not report itself reachable, so new routers may not be able to connect to it")
+	}
+	for _, c := range congestionCaps {
+		if strings.Contains(caps, c.letter) {
+			reasons = append(reasons, fmt.Sprintf("capability %s: %s", c.letter, c.meaning))
+		}
+	}
+	if !goodVersion {
+		reasons = append(reasons, versionReason(version, versionErr))
+	}
+	return reasons
+}
+
+// versionReason explains why GoodVersion refused version, which must be a
+// 0.9.x release from router_info.MIN_GOOD_VERSION to MAX_GOOD_VERSION.
+func versionReason(version string, versionErr error) string {
+	if version == "" {
+		return "version: the router does not publish a router.version"
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return fmt.Sprintf("version %q is not in major.minor.patch form", version)
+	}
+	major, errMajor := strconv.Atoi(strings.TrimSpace(parts[0]))
+	minor, errMinor := strconv.Atoi(strings.TrimSpace(parts[1]))
+	patch, errPatch := strconv.Atoi(strings.TrimSpace(parts[2]))
+	switch {
+	case errMajor != nil || errMinor != nil || errPatch != nil:
+		return fmt.Sprintf("version %q has a non-numeric component", version)
+	case major != 0 || minor != 9:
+		return fmt.Sprintf("version %q is not a 0.9.x release, the only series the version check accepts", version)
+	case patch < router_info.MIN_GOOD_VERSION:
+		return fmt.Sprintf("version %q is older than 0.9.%d, the oldest release reseeds hand out", version, router_info.MIN_GOOD_VERSION)
+	case patch > router_info.MAX_GOOD_VERSION:
+		return fmt.Sprintf("version %q is newer than 0.9.%d, the newest release the version check accepts", version, router_info.MAX_GOOD_VERSION)
+	case versionErr != nil:
+		return fmt.Sprintf("version %q: %v", version, versionErr)
+	default:
+		return fmt.Sprintf("version %q was refused by the version check", version)
+	}
+}
+
 // printDiagnosisSummary prints the final diagnosis results
 func printDiagnosisSummary(stats *diagnosisStats, removeBad bool) {
 	fmt.Println("\n=== DIAGNOSIS SUMMARY ===")
@@ -295,22 +408,3 @@ func printDiagnosisSummary(stats *diagnosisStats, removeBad bool) {
 		fmt.Println("\nNo corrupted RouterInfo files found. The parsing errors may be transient.")
 	}
 }
-
-// findDefaultNetDbPath attempts to find the default netDb path for the current system
-func findDefaultNetDbPath() string {
-	// Common I2P netDb locations
-	possiblePaths := []string{
-		os.ExpandEnv("$HOME/.i2p/netDb"),
-		os.ExpandEnv("$HOME/Library/Application Support/i2p/netDb"),
-		"/var/lib/i2p/i2p-config/netDb",
-		"/usr/share/i2p/netDb",
-	}
-
-	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
-			return path
-		}
-	}
-
-	return "" // Return empty if not found
-}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestDiagnose_NetDbReadOnlyRefusesRemoval(t *testing.T) {
+	netdb := t.TempDir()
+	corrupt := filepath.Join(netdb, "routerInfo-corrupt.dat")
+	if err := os.WriteFile(corrupt, []byte("not a router info"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := cli.NewApp()
+	app.Name = "test"
+	app.Flags = NewDiagnoseCommand().Flags
+	app.Action = diagnoseRouterInfoFiles
+
+	t.Setenv("RESEED_NETDB_READONLY", "true")
+	err := app.Run([]string{"test", "--netdb=" + netdb, "--remove-bad"})
+	if !errors.Is(err, errNetDbReadOnly) {
+		t.Fatalf("Expected errNetDbReadOnly, got: %v", err)
+	}
+	if !fileExists(corrupt) {
+		t.Error("Corrupted file should not be removed from a read-only netDb")
+	}
+}
+
+func TestSkipReasons(t *testing.T) {
+	tests := []struct {
+		name        string
+		caps        string
+		version     string
+		goodVersion bool
+		want        []string
+	}{
+		{"usable", "XfR", "0.9.64", true, nil},
+		{"unreachable", "LU", "0.9.64", true, []string{"capability U"}},
+		{"no reachability claim", "L", "0.9.64", true, []string{"no capability R"}},
+		{"congested", "XRDG", "0.9.64", true, []string{"capability D", "capability G"}},
+		{"old version", "XR", "0.9.50", false, []string{"older than 0.9.58"}},
+		{"new series", "XR", "2.0.0", false, []string{"not a 0.9.x release"}},
+		{"malformed version", "XR", "0.9", false, []string{"major.minor.patch"}},
+		{"missing version", "XR", "", false, []string{"does not publish"}},
+		{"several problems", "KUE", "0.9.1", false, []string{"capability U", "capability E", "older than"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := skipReasons(tt.caps, tt.version, tt.goodVersion, nil)
+			if len(got) != len(tt.want) {
+				t.Fatalf("skipReasons() = %q, want %d reasons", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("reason %d = %q, want it to mention %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
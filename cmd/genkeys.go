@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cretz/bine/torutil"
+	"github.com/cretz/bine/torutil/ed25519"
+	"github.com/go-i2p/go-sam-bridge/lib/destination"
+	"github.com/go-i2p/i2pkeys"
+	"github.com/urfave/cli/v3"
+)
+
+// NewGenkeysCommand creates a new CLI command for generating onion and I2P service
+// keys ahead of time. Keys can be provisioned and backed up on an offline machine
+// before the reseed server first runs, and rotated later with --force.
+func NewGenkeysCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "genkeys",
+		Usage:  "Generate onion and I2P service keys without starting a server",
+		Action: genkeysAction,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "onion",
+				Usage: "Generate an ed25519 onion service key",
+			},
+			&cli.StringFlag{
+				Name:  "onionKey",
+				Value: "onion.key",
+				Usage: "Path to write the ed25519 onion service key",
+			},
+			&cli.BoolFlag{
+				Name:  "i2p",
+				Usage: "Generate I2P destination keys",
+			},
+			&cli.StringFlag{
+				Name:  "i2pKeys",
+				Value: "reseed.i2pkeys",
+				Usage: "Path to write the I2P destination keys",
+			},
+			&cli.BoolFlag{
+				Name:  "offline",
+				Usage: "Generate I2P keys locally (Ed25519/X25519) instead of asking a SAM bridge",
+			},
+			&cli.StringFlag{
+				Name:  "samaddr",
+				Value: "127.0.0.1:7656",
+				Usage: "SAM address used to generate I2P keys when --offline is not set",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Rotate existing keys, keeping the old file as <path>.<timestamp>.bak",
+			},
+		},
+	}
+}
+
+func genkeysAction(c *cli.Context) error {
+	if !c.Bool("onion") && !c.Bool("i2p") {
+		fmt.Println("You must specify --onion, --i2p or both")
+		return fmt.Errorf("you must specify --onion, --i2p or both")
+	}
+
+	if c.Bool("onion") {
+		path := c.String("onionKey")
+		if err := prepareKeyPath(path, c.Bool("force")); err != nil {
+			return err
+		}
+		addr, err := generateOnionKeyFile(path)
+		if err != nil {
+			lgr.WithError(err).WithField("path", path).Error("Failed to generate onion key")
+			return err
+		}
+		fmt.Printf("Onion key saved to: %s\n\tAddress: %s\n", path, addr)
+	}
+
+	if c.Bool("i2p") {
+		path := c.String("i2pKeys")
+		if err := prepareKeyPath(path, c.Bool("force")); err != nil {
+			return err
+		}
+		var keys i2pkeys.I2PKeys
+		var err error
+		if c.Bool("offline") {
+			keys, err = generateOfflineI2PKeys()
+		} else {
+			keys, err = CreateEepServiceKey(c)
+		}
+		if err != nil {
+			lgr.WithError(err).WithField("path", path).Error("Failed to generate I2P keys")
+			return err
+		}
+		if err := persistKeysToFile(keys, path); err != nil {
+			return err
+		}
+		if err := os.Chmod(path, 0o600); err != nil {
+			return err
+		}
+		fmt.Printf("I2P keys saved to: %s\n\tAddress: %s\n", path, keys.Addr().Base32())
+	}
+
+	return nil
+}
+
+// prepareKeyPath refuses to overwrite an existing key unless force is set, in
+// which case the existing key is moved aside so it can still be recovered.
+func prepareKeyPath(path string, force bool) error {
+	if !fileExists(path) {
+		return nil
+	}
+	if !force {
+		return fmt.Errorf("%s already exists, use --force to rotate it", path)
+	}
+	backup := fmt.Sprintf("%s.%d.bak", path, time.Now().Unix())
+	if err := os.Rename(path, backup); err != nil {
+		return err
+	}
+	fmt.Printf("Existing key moved to: %s\n", backup)
+	return nil
+}
+
+// generateOnionKeyFile writes a new ed25519 onion service key to path in the
+// format read by the reseed command and returns the resulting onion address.
+func generateOnionKeyFile(path string) (string, error) {
+	key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(key.PrivateKey()), 0o600); err != nil {
+		return "", err
+	}
+	return torutil.OnionServiceIDFromPrivateKey(key.PrivateKey()) + ".onion", nil
+}
+
+// generateOfflineI2PKeys creates an Ed25519/X25519 destination without a router.
+// The private part uses the SAM private key layout of destination, 32-byte
+// encryption key and 32-byte Ed25519 seed, as accepted by Java I2P and i2pd.
+func generateOfflineI2PKeys() (i2pkeys.I2PKeys, error) {
+	dest, priv, err := destination.NewManager().Generate(destination.SigTypeEd25519)
+	if err != nil {
+		return i2pkeys.I2PKeys{}, err
+	}
+	pub, err := dest.Base64()
+	if err != nil {
+		return i2pkeys.I2PKeys{}, err
+	}
+	destBytes, err := dest.Bytes()
+	if err != nil {
+		return i2pkeys.I2PKeys{}, err
+	}
+
+	// priv is the X25519 key followed by the 64-byte seed||public Ed25519 key
+	const encKeyLen, seedLen = 32, 32
+	if len(priv) < encKeyLen+seedLen {
+		return i2pkeys.I2PKeys{}, fmt.Errorf("unexpected private key length %d", len(priv))
+	}
+	both := append(destBytes, priv[:encKeyLen+seedLen]...)
+
+	return i2pkeys.NewKeys(i2pkeys.I2PAddr(pub), destination.Base64Encode(both)), nil
+}
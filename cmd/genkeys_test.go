@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-i2p/go-sam-bridge/lib/destination"
+)
+
+func TestNewGenkeysCommand(t *testing.T) {
+	cmd := NewGenkeysCommand()
+	if cmd.Name != "genkeys" {
+		t.Errorf("Expected command name 'genkeys', got %s", cmd.Name)
+	}
+	if cmd.Action == nil {
+		t.Error("Command action should not be nil")
+	}
+}
+
+func TestGenerateOnionKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "onion.key")
+
+	addr, err := generateOnionKeyFile(path)
+	if err != nil {
+		t.Fatalf("generateOnionKeyFile() error: %v", err)
+	}
+	if !strings.HasSuffix(addr, ".onion") || len(addr) != 56+len(".onion") {
+		t.Errorf("Unexpected onion address %q", addr)
+	}
+
+	key, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read onion key: %v", err)
+	}
+	if len(key) != 64 {
+		t.Errorf("Expected 64-byte ed25519 key, got %d bytes", len(key))
+	}
+	// The reseed command loads an existing key unchanged
+	loaded, err := loadOrGenerateOnionKey(path)
+	if err != nil || string(loaded) != string(key) {
+		t.Errorf("loadOrGenerateOnionKey() did not return the generated key: %v", err)
+	}
+}
+
+func TestGenerateOfflineI2PKeys(t *testing.T) {
+	keys, err := generateOfflineI2PKeys()
+	if err != nil {
+		t.Fatalf("generateOfflineI2PKeys() error: %v", err)
+	}
+	if !strings.HasSuffix(keys.Addr().Base32(), ".b32.i2p") {
+		t.Errorf("Unexpected base32 address %q", keys.Addr().Base32())
+	}
+
+	pub, err := destination.Base64Decode(string(keys.Addr()))
+	if err != nil {
+		t.Fatalf("Failed to decode public destination: %v", err)
+	}
+	both, err := destination.Base64Decode(keys.Both)
+	if err != nil {
+		t.Fatalf("Failed to decode private keys: %v", err)
+	}
+	if len(both) != len(pub)+64 {
+		t.Errorf("Expected private keys to be destination plus 64 bytes, got %d vs %d", len(both), len(pub))
+	}
+	if string(both[:len(pub)]) != string(pub) {
+		t.Error("Private keys should start with the public destination")
+	}
+
+	// Round trip through the format used by the reseed command
+	path := filepath.Join(t.TempDir(), "reseed.i2pkeys")
+	if err := persistKeysToFile(keys, path); err != nil {
+		t.Fatalf("persistKeysToFile() error: %v", err)
+	}
+	loaded, err := loadExistingKeys(path)
+	if err != nil {
+		t.Fatalf("loadExistingKeys() error: %v", err)
+	}
+	if loaded.Addr().Base32() != keys.Addr().Base32() {
+		t.Error("Reloaded keys have a different address")
+	}
+}
+
+func TestPrepareKeyPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "onion.key")
+
+	if err := prepareKeyPath(path, false); err != nil {
+		t.Fatalf("Expected no error for a new path, got: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := prepareKeyPath(path, false); err == nil {
+		t.Error("Expected error when key exists without --force")
+	}
+
+	if err := prepareKeyPath(path, true); err != nil {
+		t.Fatalf("Expected rotation to succeed, got: %v", err)
+	}
+	if fileExists(path) {
+		t.Error("Expected existing key to be moved aside")
+	}
+	backups, _ := filepath.Glob(path + ".*.bak")
+	if len(backups) != 1 {
+		t.Errorf("Expected one backup file, got %v", backups)
+	}
+}
@@ -18,16 +18,20 @@ func NewKeygenCommand() *cli.Command {
 		Name:   "keygen",
 		Usage:  "Generate keys for reseed su3 signing and TLS serving.",
 		Action: keygenAction,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:  "signer",
 				Usage: "Generate a private key and certificate for the given su3 signing ID (ex. something@mail.i2p)",
 			},
+			&cli.BoolFlag{
+				Name:  "ed25519",
+				Usage: "Generate an Ed25519 su3 signing key instead of a 4096-bit RSA one",
+			},
 			&cli.StringFlag{
 				Name:  "tlsHost",
 				Usage: "Generate a self-signed TLS certificate and private key for the given host",
 			},
-		},
+		}, certSubjectFlags()...),
 	}
 }
 
@@ -44,7 +48,7 @@ func keygenAction(c *cli.Context) error {
 
 	// Generate signing certificate if signer ID is provided
 	if signerID != "" {
-		if err := createSigningCertificate(signerID); nil != err {
+		if err := createSigningCertificate(signerID, c.Bool("ed25519"), certSubject(c)); nil != err {
 			lgr.WithError(err).WithField("signer_id", signerID).Error("Failed to create signing certificate")
 			fmt.Println(err)
 			return err
@@ -53,7 +57,7 @@ func keygenAction(c *cli.Context) error {
 
 	// Generate TLS certificate if host is provided
 	if tlsHost != "" {
-		if err := createTLSCertificate(tlsHost); nil != err {
+		if err := createTLSCertificate(tlsHost, certSubject(c)); nil != err {
 			lgr.WithError(err).WithField("tls_host", tlsHost).Error("Failed to create TLS certificate")
 			fmt.Println(err)
 			return err
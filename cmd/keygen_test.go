@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"crypto/ed25519"
 	"os"
 	"testing"
 
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+
 	"github.com/urfave/cli/v3"
 )
 
@@ -13,6 +16,7 @@ func newKeygenTestApp() *cli.App {
 	app.Name = "test"
 	app.Flags = []cli.Flag{
 		&cli.StringFlag{Name: "signer"},
+		&cli.BoolFlag{Name: "ed25519"},
 		&cli.StringFlag{Name: "tlsHost"},
 	}
 	app.Action = keygenAction
@@ -169,3 +173,57 @@ func TestNewKeygenCommand_NoTrustProxyFlag(t *testing.T) {
 		}
 	}
 }
+
+// TestKeygenAction_Ed25519Signer verifies that --ed25519 writes an Ed25519 key
+// and certificate that sign and verify a reseed su3 end to end.
+func TestKeygenAction_Ed25519Signer(t *testing.T) {
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+	defer os.Chdir(origDir)
+
+	if err := newKeygenTestApp().Run([]string{"test", "--signer=ed@mail.i2p", "--ed25519"}); err != nil {
+		t.Fatalf("keygenAction with --ed25519 failed: %v", err)
+	}
+	key, err := loadPrivateKey("ed_at_mail.i2p.pem")
+	if err != nil {
+		t.Fatalf("Failed to load the generated key: %v", err)
+	}
+	if _, ok := key.(ed25519.PrivateKey); !ok {
+		t.Fatalf("Expected an Ed25519 key, got %T", key)
+	}
+	if _, err := os.Stat("ed_at_mail.i2p.crl"); err != nil {
+		t.Errorf("Expected a CRL to be written: %v", err)
+	}
+	cert, err := loadMatchingSignerCert("ed_at_mail.i2p.crt", key)
+	if err != nil {
+		t.Fatalf("Expected the certificate to match the key: %v", err)
+	}
+
+	bundle := su3.New()
+	bundle.FileType = su3.FileTypeZIP
+	bundle.ContentType = su3.ContentTypeReseed
+	bundle.SignerID = []byte("ed@mail.i2p")
+	bundle.Content = []byte("seeds")
+	if err := bundle.SignWith(key); err != nil {
+		t.Fatalf("Failed to sign with the Ed25519 key: %v", err)
+	}
+	data, err := bundle.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed su3.File
+	if err := parsed.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.SignatureType != su3.SigTypeEdDSASHA512Ed25519ph {
+		t.Errorf("Expected signature type %d, got %d", su3.SigTypeEdDSASHA512Ed25519ph, parsed.SignatureType)
+	}
+	if err := parsed.VerifySignature(cert); err != nil {
+		t.Errorf("Expected the su3 to verify against the generated certificate: %v", err)
+	}
+}
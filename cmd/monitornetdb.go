@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// NewMonitorNetDbCommand creates a new CLI command that repeats the diagnose
+// scan on a schedule and publishes the results for a monitoring agent, without
+// running a reseed server.
+func NewMonitorNetDbCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "monitor-netdb",
+		Usage: "Periodically scan a netDb and export its health for a monitoring agent",
+		Description: `Run the diagnose scan every --interval and publish the counts of valid,
+corrupted and too-old RouterInfo files together with a file age histogram, as
+JSON. The results are served at /netdb on --listen, written to --output, or
+both. Files are never removed.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "netdb",
+				Aliases: []string{"n"},
+				Usage:   "Path to the netDb directory containing RouterInfo files",
+				Value:   findDefaultNetDbPath(),
+			},
+			&cli.DurationFlag{
+				Name:    "max-age",
+				Aliases: []string{"a"},
+				Usage:   "Maximum age for RouterInfo files to count as current (e.g., 72h for 3 days)",
+				Value:   72 * time.Hour,
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "How often to rescan the netDb",
+				Value: 5 * time.Minute,
+			},
+			&cli.StringFlag{
+				Name:  "listen",
+				Usage: "Address to serve the latest results on at /netdb (ex. 127.0.0.1:9780)",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "File to write the latest results to as JSON, replaced atomically after every scan",
+			},
+		},
+		Action: monitorNetDbAction,
+	}
+}
+
+// netDbHealth is one scan's results as published by monitor-netdb.
+type netDbHealth struct {
+	NetDb       string          `json:"netdb"`
+	Scanned     time.Time       `json:"scanned"`
+	ScanSeconds float64         `json:"scan_seconds"`
+	Total       int             `json:"total"`
+	Valid       int             `json:"valid"`
+	Corrupted   int             `json:"corrupted"`
+	TooOld      int             `json:"too_old"`
+	Ages        []netDbAgeCount `json:"age_histogram"`
+	// Error is set when the scan failed; the counts are then zero
+	Error string `json:"error,omitempty"`
+}
+
+// netDbAgeCount is one bucket of the file age histogram.
+type netDbAgeCount struct {
+	// Under is the bucket's exclusive upper bound, empty for the oldest bucket
+	Under string `json:"under,omitempty"`
+	Count int    `json:"count"`
+}
+
+// netDbMonitor holds the most recent scan for the HTTP endpoint.
+type netDbMonitor struct {
+	mu     sync.RWMutex
+	latest netDbHealth
+}
+
+// monitorNetDbAction scans the netDb at startup and then every --interval,
+// publishing each result, until the process is stopped.
+func monitorNetDbAction(c *cli.Context) error {
+	config := &diagnosisConfig{netdbPath: c.String("netdb"), maxAge: c.Duration("max-age"), quiet: true}
+	if config.netdbPath == "" {
+		return fmt.Errorf("netDb path is required. Use --netdb flag or ensure I2P is installed in a standard location")
+	}
+	if err := validateNetDbPath(config.netdbPath); err != nil {
+		return err
+	}
+	if c.String("listen") == "" && c.String("output") == "" {
+		return fmt.Errorf("monitor-netdb needs --listen, --output or both")
+	}
+	if c.Duration("interval") <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	monitor := &netDbMonitor{}
+	monitor.scan(config, c.String("output"))
+	if addr := c.String("listen"); addr != "" {
+		server := &http.Server{Addr: addr, Handler: monitor, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			lgr.WithField("address", addr).Info("netDb monitor listening")
+			if err := server.ListenAndServe(); err != nil {
+				lgr.WithError(err).Fatal("netDb monitor listener failed")
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(c.Duration("interval"))
+	defer ticker.Stop()
+	for range ticker.C {
+		monitor.scan(config, c.String("output"))
+	}
+	return nil
+}
+
+// scan runs the diagnose walk, records the results as the latest and writes
+// them to output, if set.
+func (monitor *netDbMonitor) scan(config *diagnosisConfig, output string) {
+	health := scanNetDbHealth(config, time.Now())
+	monitor.mu.Lock()
+	monitor.latest = health
+	monitor.mu.Unlock()
+	if health.Error != "" {
+		lgr.WithField("netdb", config.netdbPath).WithField("error", health.Error).Warn("netDb scan failed")
+	}
+	if output != "" {
+		if err := writeNetDbHealth(output, health); err != nil {
+			lgr.WithError(err).WithField("output", output).Error("Failed to write netDb health")
+		}
+	}
+}
+
+// scanNetDbHealth runs one diagnose scan and summarizes it.
+func scanNetDbHealth(config *diagnosisConfig, start time.Time) netDbHealth {
+	health := netDbHealth{NetDb: config.netdbPath, Scanned: start}
+	// The walk skips unreadable entries, so a netDb that has gone away
+	// would otherwise look like an empty one
+	if err := validateNetDbPath(config.netdbPath); err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	stats, err := scanNetDb(config)
+	health.ScanSeconds = time.Since(start).Seconds()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.Total = stats.totalFiles
+	health.Valid = stats.validFiles
+	health.Corrupted = stats.corruptedFiles
+	health.TooOld = stats.tooOldFiles
+	for i, count := range stats.ageCounts {
+		bucket := netDbAgeCount{Count: count}
+		if i < len(diagnosisAgeBuckets) {
+			bucket.Under = diagnosisAgeBuckets[i].String()
+		}
+		health.Ages = append(health.Ages, bucket)
+	}
+	return health
+}
+
+// writeNetDbHealth replaces path with health as JSON, atomically so a reader
+// never sees a partial document.
+func writeNetDbHealth(path string, health netDbHealth) error {
+	data, err := json.MarshalIndent(health, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0o644)
+}
+
+// ServeHTTP serves the latest scan at /netdb.
+func (monitor *netDbMonitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/netdb" {
+		http.NotFound(w, r)
+		return
+	}
+	monitor.mu.RLock()
+	health := monitor.latest
+	monitor.mu.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if health.Error != "" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(health)
+}
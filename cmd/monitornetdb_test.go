@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScanNetDbHealth(t *testing.T) {
+	netdb := t.TempDir()
+	write := func(name string, age time.Duration) {
+		path := filepath.Join(netdb, name)
+		if err := os.WriteFile(path, []byte("not a router info"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("routerInfo-fresh.dat", time.Minute)
+	write("routerInfo-day.dat", 12*time.Hour)
+	write("routerInfo-old.dat", 100*time.Hour)
+	write("unrelated.txt", time.Minute)
+
+	health := scanNetDbHealth(&diagnosisConfig{netdbPath: netdb, maxAge: 72 * time.Hour, quiet: true}, time.Now())
+	if health.Error != "" {
+		t.Fatalf("Unexpected scan error: %s", health.Error)
+	}
+	if health.Total != 3 || health.TooOld != 1 || health.Corrupted != 2 || health.Valid != 0 {
+		t.Errorf("Unexpected counts: %+v", health)
+	}
+	want := []netDbAgeCount{{"1h0m0s", 1}, {"6h0m0s", 0}, {"24h0m0s", 1}, {"72h0m0s", 0}, {"", 1}}
+	if len(health.Ages) != len(want) {
+		t.Fatalf("Expected %d age buckets, got %+v", len(want), health.Ages)
+	}
+	for i := range want {
+		if health.Ages[i] != want[i] {
+			t.Errorf("Age bucket %d = %+v, want %+v", i, health.Ages[i], want[i])
+		}
+	}
+
+	missing := scanNetDbHealth(&diagnosisConfig{netdbPath: filepath.Join(netdb, "missing"), quiet: true}, time.Now())
+	if missing.Error == "" {
+		t.Error("Expected a scan of a missing directory to report an error")
+	}
+}
+
+func TestNetDbMonitor_Publish(t *testing.T) {
+	netdb := t.TempDir()
+	output := filepath.Join(t.TempDir(), "netdb-health.json")
+	monitor := &netDbMonitor{}
+	monitor.scan(&diagnosisConfig{netdbPath: netdb, maxAge: 72 * time.Hour, quiet: true}, output)
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Expected the results file to be written: %v", err)
+	}
+	var written netDbHealth
+	if err := json.Unmarshal(data, &written); err != nil || written.NetDb != netdb {
+		t.Errorf("Expected the results for %s, got %+v (%v)", netdb, written, err)
+	}
+
+	w := httptest.NewRecorder()
+	monitor.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/netdb", nil))
+	var served netDbHealth
+	if err := json.NewDecoder(w.Body).Decode(&served); err != nil || w.Code != http.StatusOK || served.NetDb != netdb {
+		t.Errorf("Expected the latest results at /netdb, got %d %+v (%v)", w.Code, served, err)
+	}
+	w = httptest.NewRecorder()
+	monitor.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 outside /netdb, got %d", w.Code)
+	}
+}
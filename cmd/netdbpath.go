@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/go-i2p/checki2cp/getmeanetdb"
+)
+
+// netDbCandidates lists the netDb directories Java I2P and i2pd use on goos, in
+// the order they are tried: per-user installs before system-wide ones, and
+// Java I2P before i2pd. home is the user's home directory and getenv looks up
+// environment variables.
+func netDbCandidates(goos, home string, getenv func(string) string) []string {
+	var dirs []string
+	add := func(parts ...string) {
+		if parts[0] != "" {
+			dirs = append(dirs, filepath.Join(append(parts, "netDb")...))
+		}
+	}
+	switch goos {
+	case "windows":
+		add(getenv("LOCALAPPDATA"), "I2P")
+		add(getenv("APPDATA"), "I2P")
+		add(getenv("APPDATA"), "i2pd")
+		add(getenv("LOCALAPPDATA"), "i2pd")
+		add(getenv("ProgramData"), "i2pd")
+	case "darwin":
+		add(home, "Library", "Application Support", "i2p")
+		add(home, ".i2p")
+		add(home, "Library", "Application Support", "i2pd")
+		add(home, ".i2pd")
+	default:
+		add(home, ".i2p")
+		add("/var/lib/i2p/i2p-config")
+		add("/usr/share/i2p")
+		add(home, ".i2pd")
+		add("/var/lib/i2pd")
+		add("/var/db/i2pd")
+	}
+	return dirs
+}
+
+// findDefaultNetDbPath returns the first netDb from netDbCandidates that exists
+// on this system. If there is none it falls back to the location an I2P router
+// would use here, and to "" if even that cannot be determined.
+func findDefaultNetDbPath() string {
+	home, _ := os.UserHomeDir()
+	for _, path := range netDbCandidates(runtime.GOOS, home, os.Getenv) {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+	}
+	if path, err := getmeanetdb.WhereIstheNetDB(); err == nil {
+		return path
+	}
+	return ""
+}
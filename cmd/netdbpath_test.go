@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestNetDbCandidates(t *testing.T) {
+	env := map[string]string{`APPDATA`: `C:\Users\u\AppData\Roaming`, `LOCALAPPDATA`: `C:\Users\u\AppData\Local`}
+	getenv := func(key string) string { return env[key] }
+
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{"linux", []string{
+			filepath.Join("/home/u", ".i2p", "netDb"),
+			filepath.Join("/var/lib/i2p/i2p-config", "netDb"),
+			filepath.Join("/home/u", ".i2pd", "netDb"),
+			filepath.Join("/var/lib/i2pd", "netDb"),
+		}},
+		{"darwin", []string{
+			filepath.Join("/home/u", "Library", "Application Support", "i2p", "netDb"),
+			filepath.Join("/home/u", "Library", "Application Support", "i2pd", "netDb"),
+		}},
+		{"windows", []string{
+			filepath.Join(env["LOCALAPPDATA"], "I2P", "netDb"),
+			filepath.Join(env["APPDATA"], "I2P", "netDb"),
+			filepath.Join(env["APPDATA"], "i2pd", "netDb"),
+		}},
+	}
+	for _, tt := range tests {
+		got := netDbCandidates(tt.goos, "/home/u", getenv)
+		for _, want := range tt.want {
+			if !slices.Contains(got, want) {
+				t.Errorf("%s: expected %s among %v", tt.goos, want, got)
+			}
+		}
+	}
+
+	// Java I2P comes before i2pd, and unset variables yield no candidates
+	linux := netDbCandidates("linux", "/home/u", getenv)
+	if slices.Index(linux, filepath.Join("/home/u", ".i2p", "netDb")) > slices.Index(linux, filepath.Join("/var/lib/i2pd", "netDb")) {
+		t.Errorf("Expected Java I2P locations first, got %v", linux)
+	}
+	if got := netDbCandidates("windows", "", func(string) string { return "" }); len(got) != 0 {
+		t.Errorf("Expected no Windows candidates without APPDATA/LOCALAPPDATA, got %v", got)
+	}
+}
+
+func TestFindDefaultNetDbPath_PrefersExistingDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("HOME does not control the Windows candidates")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	i2pd := filepath.Join(home, ".i2pd", "netDb")
+	if err := os.MkdirAll(i2pd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	candidates := netDbCandidates(runtime.GOOS, home, os.Getenv)
+	for _, path := range candidates[:slices.Index(candidates, i2pd)] {
+		if _, err := os.Stat(path); err == nil {
+			t.Skipf("%s exists on this system and comes first", path)
+		}
+	}
+	if got := findDefaultNetDbPath(); got != i2pd {
+		t.Errorf("findDefaultNetDbPath() = %q, want %q", got, i2pd)
+	}
+}
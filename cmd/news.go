@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+// NewNewsCommand creates a new CLI command for building signed I2P news SU3 files.
+// The output uses the gzip-compressed XML format, news content type and timestamp
+// version that the router's news subsystem expects when fetching news.su3.
+func NewNewsCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "news",
+		Usage:  "Build a signed news su3 from an XML news feed",
+		Action: newsAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "signer",
+				Value: getDefaultSigner(),
+				Usage: "Your su3 signing ID (ex. something@mail.i2p)",
+			},
+			&cli.StringFlag{
+				Name:  "key",
+				Usage: "Path to your su3 signing private key (defaults to <signer>.pem)",
+			},
+			&cli.StringFlag{
+				Name:  "xml",
+				Usage: "Path to the Atom XML news feed (plain or gzip-compressed)",
+			},
+			&cli.StringFlag{
+				Name:  "out",
+				Value: "news.su3",
+				Usage: "Path to write the signed news su3",
+			},
+			base64Flag("Write the su3 as base64 text instead of binary"),
+		},
+	}
+}
+
+func newsAction(c *cli.Context) error {
+	signerID := c.String("signer")
+	if signerID == "" {
+		return fmt.Errorf("--signer is required")
+	}
+	if c.String("xml") == "" {
+		return fmt.Errorf("--xml is required")
+	}
+
+	keyPath := c.String("key")
+	if keyPath == "" {
+		keyPath = signerFile(signerID) + ".pem"
+	}
+	privKey, err := loadPrivateKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	feed, err := os.ReadFile(c.String("xml"))
+	if err != nil {
+		lgr.WithError(err).WithField("xml", c.String("xml")).Error("Failed to read news feed")
+		return err
+	}
+
+	data, err := buildNewsSU3(feed, signerID, privKey)
+	if err != nil {
+		return err
+	}
+	if c.Bool("base64") {
+		data = encodeSU3Base64(data)
+	}
+
+	if err := os.WriteFile(c.String("out"), data, 0o644); err != nil {
+		lgr.WithError(err).WithField("out", c.String("out")).Error("Failed to write news su3")
+		return err
+	}
+
+	fmt.Printf("News su3 saved to: %s\n", c.String("out"))
+	return nil
+}
+
+// buildNewsSU3 wraps the feed in a news SU3, signs it and returns the encoded file.
+func buildNewsSU3(feed []byte, signerID string, privKey crypto.Signer) ([]byte, error) {
+	su3File, err := su3.NewNewsFile(feed)
+	if err != nil {
+		return nil, err
+	}
+	su3File.SignerID = []byte(signerID)
+
+	if err := su3File.SignWith(privKey); err != nil {
+		lgr.WithError(err).WithField("signer_id", signerID).Error("Failed to sign news su3")
+		return nil, err
+	}
+
+	return su3File.MarshalBinary()
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+func TestNewNewsCommand(t *testing.T) {
+	cmd := NewNewsCommand()
+	if cmd.Name != "news" {
+		t.Errorf("Expected command name 'news', got %s", cmd.Name)
+	}
+	if cmd.Action == nil {
+		t.Error("Command action should not be nil")
+	}
+}
+
+func TestBuildNewsSU3_VerifyAndExtract(t *testing.T) {
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	certDer, err := su3.NewSigningCertificate("news@mail.i2p", privKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(certDer)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	feed := []byte(`<?xml version="1.0" encoding="UTF-8"?><feed xmlns="http://www.w3.org/2005/Atom"></feed>`)
+	data, err := buildNewsSU3(feed, "news@mail.i2p", privKey)
+	if err != nil {
+		t.Fatalf("buildNewsSU3() error: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "news.su3")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write su3: %v", err)
+	}
+
+	keepContent := func(*su3.File) bool { return true }
+	su3File, err := loadAndParseSU3File(path, false, keepContent)
+	if err != nil {
+		t.Fatalf("loadAndParseSU3File() error: %v", err)
+	}
+	if err := verifySignature(su3File, cert); err != nil {
+		t.Fatalf("verifySignature() error: %v", err)
+	}
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+	defer os.Chdir(origDir)
+
+	if err := extractSU3Content(su3File.File); err != nil {
+		t.Fatalf("extractSU3Content() error: %v", err)
+	}
+	extracted, err := os.ReadFile("news.xml")
+	if err != nil {
+		t.Fatalf("failed to read news.xml: %v", err)
+	}
+	if string(extracted) != string(feed) {
+		t.Errorf("extracted feed mismatch:\n  got:  %q\n  want: %q", extracted, feed)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// processAlive reports whether a process with the given PID is running. It is
+// a variable so tests can stand in for the signal-0 check.
+var processAlive = func(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Signal 0 checks for the process without touching it; EPERM means it
+	// exists but belongs to another user
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// writePIDFile records the current PID in path for init scripts, replacing it
+// atomically. It refuses if path names a process that is still running, and
+// overwrites it if the process is gone. The returned function removes the
+// file again, as long as it still holds this process's PID.
+func writePIDFile(path string) (func(), error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && pid > 0 && pid != os.Getpid() && processAlive(pid) {
+			return nil, fmt.Errorf("%s: reseed server already running as PID %d", path, pid)
+		}
+		lgr.WithField("pid_file", path).Warn("Replacing stale PID file")
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	pid := strconv.Itoa(os.Getpid())
+	if err := writeFileAtomic(path, []byte(pid+"\n"), 0o644); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		// Leave the file alone if another instance has since taken it over
+		if data, err := os.ReadFile(path); err != nil || strings.TrimSpace(string(data)) != pid {
+			return
+		}
+		if err := os.Remove(path); err != nil {
+			lgr.WithError(err).WithField("pid_file", path).Warn("Failed to remove PID file")
+		}
+	}, nil
+}
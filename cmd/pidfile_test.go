@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// stubProcessAlive makes processAlive report the given PIDs as running.
+func stubProcessAlive(t *testing.T, alive ...int) {
+	t.Helper()
+	orig := processAlive
+	processAlive = func(pid int) bool {
+		for _, a := range alive {
+			if a == pid {
+				return true
+			}
+		}
+		return false
+	}
+	t.Cleanup(func() { processAlive = orig })
+}
+
+func TestWritePIDFile(t *testing.T) {
+	stubProcessAlive(t)
+	path := filepath.Join(t.TempDir(), "reseed.pid")
+
+	remove, err := writePIDFile(path)
+	if err != nil {
+		t.Fatalf("writePIDFile() error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+		t.Fatalf("Expected the PID file to hold %d, got %q (%v)", os.Getpid(), data, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o644 {
+		t.Errorf("Expected a world-readable PID file, got %o", info.Mode().Perm())
+	}
+
+	remove()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the PID file to be removed, got %v", err)
+	}
+}
+
+func TestWritePIDFile_LiveAndStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reseed.pid")
+	os.WriteFile(path, []byte("4242\n"), 0o644)
+
+	stubProcessAlive(t, 4242)
+	if _, err := writePIDFile(path); err == nil || !strings.Contains(err.Error(), "already running as PID 4242") {
+		t.Fatalf("Expected a live PID to be refused, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "4242\n" {
+		t.Errorf("Expected the live PID file to be left alone, got %q", data)
+	}
+
+	stubProcessAlive(t)
+	remove, err := writePIDFile(path)
+	if err != nil {
+		t.Fatalf("Expected a stale PID file to be replaced, got %v", err)
+	}
+	defer remove()
+	if data, _ := os.ReadFile(path); strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("Expected the stale PID to be replaced, got %q", data)
+	}
+}
+
+func TestWritePIDFile_RemoveKeepsForeignFile(t *testing.T) {
+	stubProcessAlive(t)
+	path := filepath.Join(t.TempDir(), "reseed.pid")
+	remove, err := writePIDFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Another instance took the file over after this one started
+	os.WriteFile(path, []byte("4242\n"), 0o644)
+	remove()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected a PID file naming another process to be kept, got %v", err)
+	}
+}
+
+func TestProcessAlive(t *testing.T) {
+	if !processAlive(os.Getpid()) {
+		t.Error("Expected the test process to be reported as running")
+	}
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-i2p/common/router_info"
+	"github.com/urfave/cli/v3"
+)
+
+// NewPruneCommand creates a new CLI command that removes stale, corrupted and,
+// optionally, duplicate RouterInfo files from the netDb directory, the
+// maintenance counterpart of diagnose.
+func NewPruneCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "prune",
+		Usage: "Remove stale, corrupted and duplicate RouterInfo files from netDb",
+		Description: `Walk the netDb directory and delete RouterInfo files older than --max-age
+and files that fail to parse. With --dedupe, files describing the same router
+are reduced to the most recently modified one. Use --dry-run to see what would
+be removed without deleting anything.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "netdb",
+				Aliases: []string{"n"},
+				Usage:   "Path to the netDb directory containing RouterInfo files",
+				Value:   findDefaultNetDbPath(),
+			},
+			&cli.DurationFlag{
+				Name:    "max-age",
+				Aliases: []string{"a"},
+				Usage:   "Remove RouterInfo files last modified longer ago than this (e.g., 72h for 3 days)",
+				Value:   72 * time.Hour, // Matches reseed server --routerInfoAge default (I2P standard)
+			},
+			&cli.BoolFlag{
+				Name:  "dedupe",
+				Usage: "Also remove all but the newest file for each router hash",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Report what would be removed without deleting anything",
+			},
+			netDbReadOnlyFlag(),
+			&cli.BoolFlag{
+				Name:    "verbose",
+				Aliases: []string{"v"},
+				Usage:   "Print every file that is removed",
+			},
+		},
+		Action: pruneNetDbAction,
+	}
+}
+
+// pruneConfig holds the settings of one prune run.
+type pruneConfig struct {
+	netdbPath string
+	maxAge    time.Duration
+	dedupe    bool
+	dryRun    bool
+	verbose   bool
+}
+
+// pruneStats counts what a prune run found and removed.
+type pruneStats struct {
+	totalFiles     int
+	tooOldFiles    int
+	corruptedFiles int
+	duplicateFiles int
+	removedFiles   int
+}
+
+// pruneEntry is a parseable RouterInfo file, as considered for deduplication.
+type pruneEntry struct {
+	path    string
+	hash    string
+	modTime time.Time
+}
+
+// pruneNetDbAction validates the flags and runs the prune.
+func pruneNetDbAction(c *cli.Context) error {
+	config := &pruneConfig{
+		netdbPath: c.String("netdb"),
+		maxAge:    c.Duration("max-age"),
+		dedupe:    c.Bool("dedupe"),
+		dryRun:    c.Bool("dry-run"),
+		verbose:   c.Bool("verbose"),
+	}
+	if config.netdbPath == "" {
+		return fmt.Errorf("netDb path is required. Use --netdb flag or ensure I2P is installed in a standard location")
+	}
+	if err := validateNetDbPath(config.netdbPath); err != nil {
+		return err
+	}
+	if config.maxAge <= 0 {
+		return fmt.Errorf("--max-age must be positive")
+	}
+	if !config.dryRun {
+		if err := checkNetDbWritable(c.Bool("netdb-readonly"), config.netdbPath, "prune"); err != nil {
+			return err
+		}
+	}
+
+	stats, err := pruneNetDb(os.Stdout, config, time.Now())
+	if err != nil {
+		return err
+	}
+	printPruneSummary(os.Stdout, stats, config.dryRun)
+	return nil
+}
+
+// pruneNetDb walks config.netdbPath once, removing RouterInfo files that are
+// older than config.maxAge at now or fail to parse, then, with config.dedupe,
+// every file but the newest of each router hash. In a dry run nothing is
+// removed but the counts are the same.
+func pruneNetDb(w io.Writer, config *pruneConfig, now time.Time) (*pruneStats, error) {
+	pattern, err := compileRouterInfoPattern()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &pruneStats{}
+	var entries []pruneEntry
+	err = filepath.WalkDir(config.netdbPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !pattern.MatchString(d.Name()) {
+			return nil
+		}
+		stats.totalFiles++
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		if age := now.Sub(info.ModTime()); age > config.maxAge {
+			stats.tooOldFiles++
+			config.remove(w, stats, path, fmt.Sprintf("too old, age %v", age.Round(time.Second)))
+			return nil
+		}
+
+		routerBytes, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(w, "ERROR reading %s: %v\n", path, err)
+			return nil
+		}
+		hash, err := routerInfoHash(routerBytes)
+		if err != nil {
+			stats.corruptedFiles++
+			config.remove(w, stats, path, fmt.Sprintf("corrupted, %v", err))
+			return nil
+		}
+		entries = append(entries, pruneEntry{path: path, hash: hash, modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking netDb directory: %v", err)
+	}
+
+	if config.dedupe {
+		for _, entry := range duplicateRouterInfos(entries) {
+			stats.duplicateFiles++
+			config.remove(w, stats, entry.path, "duplicate of a newer file for the same router")
+		}
+	}
+	return stats, nil
+}
+
+// routerInfoHash parses routerBytes as the reseed server does and returns the
+// identity hash of the router it describes.
+func routerInfoHash(routerBytes []byte) (string, error) {
+	riStruct, _, err := router_info.ReadRouterInfo(routerBytes)
+	if err != nil {
+		return "", err
+	}
+	hash, err := riStruct.IdentHash()
+	if err != nil {
+		return "", err
+	}
+	return string(hash[:]), nil
+}
+
+// duplicateRouterInfos returns every entry that shares its router hash with a
+// more recently modified one. Of files modified at the same time, the one with
+// the first path is kept.
+func duplicateRouterInfos(entries []pruneEntry) []pruneEntry {
+	sorted := slices.Clone(entries)
+	slices.SortFunc(sorted, func(a, b pruneEntry) int {
+		if c := b.modTime.Compare(a.modTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	seen := make(map[string]bool)
+	var duplicates []pruneEntry
+	for _, entry := range sorted {
+		if seen[entry.hash] {
+			duplicates = append(duplicates, entry)
+			continue
+		}
+		seen[entry.hash] = true
+	}
+	return duplicates
+}
+
+// remove deletes path, or only reports it in a dry run, counting it as removed
+// when it is gone or would be.
+func (config *pruneConfig) remove(w io.Writer, stats *pruneStats, path, reason string) {
+	if config.dryRun {
+		if config.verbose {
+			fmt.Fprintf(w, "WOULD REMOVE: %s (%s)\n", path, reason)
+		}
+		stats.removedFiles++
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		fmt.Fprintf(w, "ERROR removing %s: %v\n", path, err)
+		return
+	}
+	if config.verbose {
+		fmt.Fprintf(w, "REMOVED: %s (%s)\n", path, reason)
+	}
+	stats.removedFiles++
+}
+
+// printPruneSummary prints the final prune results.
+func printPruneSummary(w io.Writer, stats *pruneStats, dryRun bool) {
+	fmt.Fprintln(w, "\n=== PRUNE SUMMARY ===")
+	fmt.Fprintf(w, "Total RouterInfo files found: %d\n", stats.totalFiles)
+	fmt.Fprintf(w, "Files too old: %d\n", stats.tooOldFiles)
+	fmt.Fprintf(w, "Corrupted files: %d\n", stats.corruptedFiles)
+	fmt.Fprintf(w, "Duplicate files: %d\n", stats.duplicateFiles)
+	if dryRun {
+		fmt.Fprintf(w, "Files that would be removed: %d\n", stats.removedFiles)
+	} else {
+		fmt.Fprintf(w, "Files removed: %d\n", stats.removedFiles)
+	}
+	fmt.Fprintf(w, "Files kept: %d\n", stats.totalFiles-stats.removedFiles)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestPruneNetDb_StaleAndCorrupted(t *testing.T) {
+	netdb := t.TempDir()
+	stale := filepath.Join(netdb, "routerInfo-stale.dat")
+	corrupt := filepath.Join(netdb, "rA", "routerInfo-corrupt.dat")
+	notes := filepath.Join(netdb, "notes.txt")
+	if err := os.MkdirAll(filepath.Dir(corrupt), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{stale, corrupt, notes} {
+		if err := os.WriteFile(path, []byte("not a router info"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-100 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &pruneConfig{netdbPath: netdb, maxAge: 72 * time.Hour, dryRun: true}
+	stats, err := pruneNetDb(io.Discard, config, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *stats != (pruneStats{totalFiles: 2, tooOldFiles: 1, corruptedFiles: 1, removedFiles: 2}) {
+		t.Errorf("Unexpected dry-run stats %+v", *stats)
+	}
+	if !fileExists(stale) || !fileExists(corrupt) {
+		t.Fatal("Expected a dry run to leave every file in place")
+	}
+
+	config.dryRun = false
+	if _, err := pruneNetDb(io.Discard, config, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(stale) || fileExists(corrupt) {
+		t.Error("Expected the stale and corrupted files to be removed")
+	}
+	if !fileExists(notes) {
+		t.Error("Expected files that are not RouterInfos to be left alone")
+	}
+}
+
+func TestDuplicateRouterInfos(t *testing.T) {
+	now := time.Now()
+	entries := []pruneEntry{
+		{path: "rA/routerInfo-a.dat", hash: "a", modTime: now.Add(-time.Hour)},
+		{path: "rB/routerInfo-a.dat", hash: "a", modTime: now},
+		{path: "rC/routerInfo-a.dat", hash: "a", modTime: now.Add(-2 * time.Hour)},
+		{path: "rA/routerInfo-b.dat", hash: "b", modTime: now},
+		{path: "rB/routerInfo-b.dat", hash: "b", modTime: now},
+		{path: "rA/routerInfo-c.dat", hash: "c", modTime: now},
+	}
+	got := map[string]bool{}
+	for _, entry := range duplicateRouterInfos(entries) {
+		got[entry.path] = true
+	}
+	if len(got) != 3 || !got["rA/routerInfo-a.dat"] || !got["rC/routerInfo-a.dat"] || !got["rB/routerInfo-b.dat"] {
+		t.Errorf("Expected all but the newest file of each router, and the first path of a tie, got %v", got)
+	}
+}
+
+func TestPrune_NetDbReadOnly(t *testing.T) {
+	netdb := t.TempDir()
+	corrupt := filepath.Join(netdb, "routerInfo-corrupt.dat")
+	if err := os.WriteFile(corrupt, []byte("not a router info"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) error {
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = NewPruneCommand().Flags
+		app.Action = pruneNetDbAction
+		return app.Run(append([]string{"test", "--netdb=" + netdb}, args...))
+	}
+
+	t.Setenv("RESEED_NETDB_READONLY", "true")
+	if err := run(); !errors.Is(err, errNetDbReadOnly) {
+		t.Fatalf("Expected errNetDbReadOnly, got: %v", err)
+	}
+	if err := run("--dry-run"); err != nil {
+		t.Errorf("Expected a dry run to be allowed on a read-only netDb, got: %v", err)
+	}
+	if !fileExists(corrupt) {
+		t.Error("Corrupted file should not be removed from a read-only netDb")
+	}
+}
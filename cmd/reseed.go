@@ -2,13 +2,17 @@ package cmd
 
 import (
 	"context"
+	"crypto"
 	"crypto/rsa"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -32,8 +36,7 @@ import (
 	"github.com/rglonek/untar"
 	"github.com/urfave/cli/v3"
 	"i2pgit.org/go-i2p/reseed-tools/reseed"
-
-	"github.com/go-i2p/checki2cp/getmeanetdb"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
 )
 
 var lgr = logger.GetGoI2PLogger()
@@ -58,10 +61,34 @@ func getHostName() string {
 	return strings.Replace(hostname, "\n", "", -1)
 }
 
-func providedReseeds(c *cli.Context) []string {
-	reseedArg := c.StringSlice("friends")
-	reseed.AllReseeds = reseedArg
-	return reseed.AllReseeds
+// providedReseeds sets the reseed servers to ping from --friends-file, if
+// set, or else --friends. It is called again on SIGHUP and by the admin
+// friends reload, so a changed --friends-file takes effect without a restart.
+func providedReseeds(c *cli.Context) error {
+	entries := c.StringSlice("friends")
+	if path := c.String("friends-file"); path != "" {
+		var err error
+		if entries, err = reseed.LoadReseedPeersFile(path); err != nil {
+			return fmt.Errorf("--friends-file: %w", err)
+		}
+	}
+	if err := reseed.SetReseedPeers(entries); err != nil {
+		if c.String("friends-file") != "" {
+			return fmt.Errorf("--friends-file: %w", err)
+		}
+		return fmt.Errorf("--friends: %w", err)
+	}
+	return nil
+}
+
+// reloadReseeds re-reads the reseed servers to ping, keeping the current list
+// if the new one cannot be loaded.
+func reloadReseeds(c *cli.Context) error {
+	if err := providedReseeds(c); err != nil {
+		return err
+	}
+	lgr.WithField("friends", len(reseed.ReseedPeers())).Info("Reloaded the reseed servers to ping")
+	return nil
 }
 
 // NewReseedCommand creates a new CLI command for starting a reseed server.
@@ -69,15 +96,12 @@ func providedReseeds(c *cli.Context) []string {
 // The server supports multiple protocols (HTTP, HTTPS, I2P, Tor) and provides signed SU3 files
 // containing router information for network bootstrapping.
 func NewReseedCommand() *cli.Command {
-	ndb, err := getmeanetdb.WhereIstheNetDB()
-	if err != nil {
-		lgr.WithError(err).Fatal("Failed to locate NetDB")
-	}
+	ndb := findDefaultNetDbPath()
 	return &cli.Command{
 		Name:   "reseed",
 		Usage:  "Start a reseed server",
 		Action: reseedAction,
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:  "signer",
 				Value: getDefaultSigner(),
@@ -90,7 +114,7 @@ func NewReseedCommand() *cli.Command {
 			},
 			&cli.BoolFlag{
 				Name:  "onion",
-				Usage: "Present an onionv3 address",
+				Usage: "Present an onionv3 address, forwarded to 127.0.0.1 on the port one above the clearnet listener's",
 			},
 			&cli.BoolFlag{
 				Name:  "singleOnion",
@@ -108,13 +132,56 @@ func NewReseedCommand() *cli.Command {
 			&cli.StringFlag{
 				Name:  "netdb",
 				Value: ndb,
-				Usage: "Path to NetDB directory containing routerInfos",
+				Usage: "Path to NetDB directory containing routerInfos, or to a tar or tar.gz archive of one (ex. the netDb.tar.gz served by share)",
 			},
 			&cli.DurationFlag{
 				Name:  "routerInfoAge",
 				Value: 72 * time.Hour,
 				Usage: "Maximum age of router infos to include in reseed files (ex. 72h, 8d)",
 			},
+			&cli.StringFlag{
+				Name:  "min-router-version",
+				Usage: "Exclude routerInfos older than this router version (ex. 0.9.62), on top of the built-in version check",
+			},
+			&cli.DurationFlag{
+				Name:  "startup-wait",
+				Value: 0,
+				Usage: "Hold the initial rebuild back until the netDb has enough routerInfos for a bundle or this long has passed (ex. 10m); the server listens meanwhile",
+			},
+			&cli.IntFlag{
+				Name:  "bundle-history",
+				Value: 0,
+				Usage: "Number of earlier bundle sets to keep in memory besides the current one, for rolling back through /admin/bundles/pin on --admin-addr",
+			},
+			&cli.StringFlag{
+				Name:  "netdb-switch",
+				Usage: "Staging netDb directory that POST /admin/netdb/switch rebuilds from and, if the rebuild succeeds, makes active; requires --admin-addr",
+			},
+			&cli.StringFlag{
+				Name:  "admin-addr",
+				Usage: "Serve the unauthenticated /admin/ endpoints on this loopback address (ex. 127.0.0.1:8444); other addresses are refused",
+			},
+			&cli.BoolFlag{
+				Name:  "admin-pprof",
+				Usage: "Also serve net/http/pprof profiles under /debug/pprof/ on --admin-addr",
+			},
+			&cli.BoolFlag{
+				Name:  "metrics",
+				Usage: "Serve Prometheus metrics for requests and the su3 cache at /metrics on --metrics-addr",
+			},
+			&cli.StringFlag{
+				Name:  "metrics-addr",
+				Value: "127.0.0.1:9464",
+				Usage: "Address for the --metrics endpoint",
+			},
+			&cli.BoolFlag{
+				Name:  "lazy-routerinfos",
+				Usage: "Keep only RouterInfo metadata in memory and re-read each file when building bundles, trading extra disk reads for a smaller resident set on large netDbs",
+			},
+			&cli.IntFlag{
+				Name:  "netdb-max-files",
+				Usage: "Read at most this many routerInfo files per rebuild, to cap memory on huge netDbs (0 = no limit)",
+			},
 			&cli.StringFlag{
 				Name:  "tlsCert",
 				Usage: "Path to a TLS certificate",
@@ -133,11 +200,20 @@ func NewReseedCommand() *cli.Command {
 				Value: "8443",
 				Usage: "Port to listen on",
 			},
+			&cli.StringFlag{
+				Name:  "https-addr",
+				Usage: "Address the HTTPS listener binds (ex. 203.0.113.5:443), instead of --ip and --port",
+			},
+			&cli.StringFlag{
+				Name:  "http-addr",
+				Usage: "Address the plain HTTP listener used with --trustProxy binds (ex. 127.0.0.1:8080), instead of --ip and --port",
+			},
 			&cli.IntFlag{
 				Name:  "numRi",
 				Value: 61,
-				Usage: "Number of routerInfos to include in each su3 file",
+				Usage: "Number of routerInfos to include in each su3 file; values below 50 are logged as too small to bootstrap reliably",
 			},
+			routerInfoFractionFlag(),
 			&cli.IntFlag{
 				Name:  "numSu3",
 				Value: 50,
@@ -148,20 +224,45 @@ func NewReseedCommand() *cli.Command {
 				Value: "90h",
 				Usage: "Duration between SU3 cache rebuilds (ex. 12h, 15m)",
 			},
+			&cli.DurationFlag{
+				Name:  "bundle-ttl",
+				Usage: "How long clients are told a bundle stays fresh after it is built, via Cache-Control and Expires (0 = --interval)",
+			},
 			&cli.StringFlag{
 				Name:  "prefix",
 				Value: "",
-				Usage: "Prefix path for the HTTP(S) server. (ex. /netdb)",
+				Usage: "Prefix path for the HTTP(S) server. (ex. /netdb); path segments may only contain letters, digits and -._~",
+			},
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: "Validate the configuration, signing key, certificates and netDb, print what was found and exit, without binding any ports; exits non-zero on any problem",
 			},
 			&cli.BoolFlag{
 				Name:  "trustProxy",
 				Usage: "If provided, we will trust the 'X-Forwarded-For' header in requests (ex. behind cloudflare)",
 			},
+			&cli.StringSliceFlag{
+				Name:  "trusted-proxies",
+				Usage: "IP addresses or CIDR ranges of the proxies allowed to set X-Forwarded-For with --trustProxy; the chain is followed past each of them to the client (default: only the proxy that connects)",
+			},
 			&cli.StringFlag{
 				Name:  "blacklist",
 				Value: "",
 				Usage: "Path to a txt file containing a list of IPs to deny connections from.",
 			},
+			&cli.StringFlag{
+				Name:  "allowlist",
+				Usage: "Path to a txt file of IPs and CIDR ranges exempt from the per-client su3 rate limit, e.g. shared NAT exits",
+			},
+			&cli.StringFlag{
+				Name:  "blacklist-url",
+				Usage: "HTTP(S) URL of a list of IPs and CIDR ranges to deny connections from, fetched at startup and every --blacklist-refresh",
+			},
+			&cli.DurationFlag{
+				Name:  "blacklist-refresh",
+				Value: time.Hour,
+				Usage: "How often to re-fetch --blacklist-url; a failed fetch keeps the previous list (0 = fetch only at startup)",
+			},
 			&cli.DurationFlag{
 				Name:  "stats",
 				Value: 0,
@@ -171,6 +272,15 @@ func NewReseedCommand() *cli.Command {
 				Name:  "i2p",
 				Usage: "Listen for reseed request inside the I2P network",
 			},
+			&cli.StringFlag{
+				Name:  "i2pKeys",
+				Value: "reseed.i2pkeys",
+				Usage: "Path to the I2P destination keys, created through SAM if missing (see genkeys)",
+			},
+			&cli.StringFlag{
+				Name:  "pid-file",
+				Usage: "Write the process ID to this file at startup and remove it on shutdown; refuses to start while the PID in an existing file is still running",
+			},
 			&cli.BoolFlag{
 				Name:  "yes",
 				Usage: "Automatically answer 'yes' to self-signed SSL generation",
@@ -180,10 +290,19 @@ func NewReseedCommand() *cli.Command {
 				Value: "127.0.0.1:7656",
 				Usage: "Use this SAM address to set up I2P connections for in-network reseed",
 			},
+			&cli.StringSliceFlag{
+				Name:  "useragent",
+				Value: cli.NewStringSlice(reseed.I2pUserAgent),
+				Usage: "User-Agent a client must send, exactly, to be served bundles; repeat to accept several, such as a newer router's",
+			},
 			&cli.StringSliceFlag{
 				Name:  "friends",
 				Value: cli.NewStringSlice(reseed.AllReseeds...),
-				Usage: "Ping other reseed servers and display the result on the homepage to provide information about reseed uptime.",
+				Usage: "Ping other reseed servers and display the result on the homepage to provide information about reseed uptime. Append a server's I2P, onion or HTTPS mirrors with '|' to link them too.",
+			},
+			&cli.StringFlag{
+				Name:  "friends-file",
+				Usage: "Read the reseed servers to ping from this file instead of --friends, one per line in the same format; it is re-read on SIGHUP or POST /admin/friends/reload",
 			},
 			&cli.StringFlag{
 				Name:  "share-peer",
@@ -219,7 +338,169 @@ func NewReseedCommand() *cli.Command {
 				Value: 2000,
 				Usage: "Maximum number of total requests per-hour, across all IP addresses.",
 			},
-		},
+			&cli.IntFlag{
+				Name:  "ratelimit-store-size",
+				Value: 65536,
+				Usage: "Maximum number of client addresses tracked by each per-IP rate limiter; least recently seen addresses are evicted first",
+			},
+			&cli.StringFlag{
+				Name:  "ratelimit-redis",
+				Usage: "Keep rate limit counters in this Redis server (redis://[:password@]host[:port][/db]) so that instances sharing it enforce the limits together",
+			},
+			&cli.IntFlag{
+				Name:  "min-key-bits",
+				Value: 2048,
+				Usage: "Refuse to start with a TLS or signing key weaker than this many RSA-equivalent bits (P-256 and Ed25519 count as 3072, P-384 as 7680); TLS keys are held to at least 2048 whatever the value, 0 disables the check for the signing key",
+			},
+			&cli.BoolFlag{
+				Name:  "rsa-pss",
+				Usage: "Sign bundles with RSA-PSS instead of PKCS#1 v1.5; I2P routers cannot verify these, so only use it where policy requires PSS",
+			},
+			&cli.BoolFlag{
+				Name:  "floodfill-first",
+				Usage: "Put floodfill routers first inside each bundle's zip, for clients that read RouterInfos in order; the selection itself stays random",
+			},
+			&cli.BoolFlag{
+				Name:  "verify-on-build",
+				Usage: "Verify each su3 signature against the signer certificate right after signing, dropping bundles that fail",
+			},
+			&cli.StringFlag{
+				Name:  "signer-cert",
+				Usage: "Path to the signer certificate used by --verify-on-build and --serve-signer-cert (defaults to <signer>.crt)",
+			},
+			&cli.DurationFlag{
+				Name:  "signer-cert-expiry-window",
+				Value: 30 * 24 * time.Hour,
+				Usage: "Warn at startup if the signer certificate expires within this long (ex. 720h); 0 checks only that it is currently valid",
+			},
+			&cli.BoolFlag{
+				Name:  "require-valid-signer-cert",
+				Usage: "Refuse to start instead of warning if the signer certificate is missing, not yet valid, expired or inside --signer-cert-expiry-window",
+			},
+			&cli.StringFlag{
+				Name:  "ntp-server",
+				Usage: "NTP server to compare the system clock against at startup and every --ntp-interval (ex. pool.ntp.org); bundles signed with a wrong clock carry bad versions",
+			},
+			&cli.DurationFlag{
+				Name:  "max-clock-skew",
+				Value: 30 * time.Second,
+				Usage: "Largest offset from --ntp-server tolerated before warning, or refusing with --require-clock-sync",
+			},
+			&cli.DurationFlag{
+				Name:  "ntp-interval",
+				Value: time.Hour,
+				Usage: "How often to repeat the --ntp-server check while running",
+			},
+			&cli.BoolFlag{
+				Name:  "require-clock-sync",
+				Usage: "Refuse to start, and skip rebuilds while running, instead of warning when the clock is off by more than --max-clock-skew. Requires --ntp-server",
+			},
+			&cli.IntFlag{
+				Name:  "shed-memory",
+				Usage: "Answer reseed requests with 503 while the heap is above this many MiB, so a rebuild under load cannot run the server out of memory (0 to disable)",
+			},
+			&cli.DurationFlag{
+				Name:  "shed-memory-interval",
+				Value: time.Second,
+				Usage: "How often to sample the heap for --shed-memory",
+			},
+			&cli.BoolFlag{
+				Name:  "serve-signer-cert",
+				Usage: "Serve the signer certificate at <prefix>/reseed-cert.pem so operators can install it in their routers",
+			},
+			&cli.StringFlag{
+				Name:  "bind-source",
+				Usage: "Local IP address or interface name that reseed pings originate from. It applies to pings only: --share-peer downloads and other SAM connections follow the system routing table",
+			},
+			&cli.StringFlag{
+				Name:  "audit-log",
+				Value: "",
+				Usage: "Append the RouterInfo filenames selected for each su3 bundle to this file (JSON lines) after every rebuild",
+			},
+			&cli.BoolFlag{
+				Name:  "deterministic",
+				Usage: "Build the same su3 bundles from the same netDb contents, so they can be cached or served from a CDN",
+			},
+			&cli.StringFlag{
+				Name:  "deterministic-seed",
+				Usage: "Value mixed into the --deterministic bundle selection; change it to reshuffle the bundles",
+			},
+			&cli.StringFlag{
+				Name:  "prefer-transport",
+				Usage: "Weight su3 bundles towards routers advertising this transport (ntcp2 or ssu2)",
+			},
+			&cli.Float64Flag{
+				Name:  "prefer-transport-share",
+				Value: 0.75,
+				Usage: "Fraction of each su3 bundle drawn from routers with --prefer-transport; 1 includes only those routers while enough exist",
+			},
+			netDbReadOnlyFlag(),
+			&cli.StringFlag{
+				Name:  "canonical-url",
+				Usage: "Public base URL of the homepage (ex. https://reseed.example.org); adds canonical and hreflang links for each translation",
+			},
+			&cli.StringSliceFlag{
+				Name:  "languages",
+				Usage: "Only offer these homepage languages (ex. en,ru,de); English is always kept as the fallback. Defaults to every supported language",
+			},
+			&cli.BoolFlag{
+				Name:  "sitemap",
+				Usage: "Serve /sitemap.xml listing every homepage translation (requires --canonical-url)",
+			},
+			&cli.BoolFlag{
+				Name:    "ephemeral",
+				Usage:   "Never write to disk: serve the homepage from the embedded content, keep ping results in memory and refuse to generate keys or certificates, which must all be provided",
+				EnvVars: []string{"RESEED_EPHEMERAL"},
+			},
+			&cli.BoolFlag{
+				Name:  "multi-bundle",
+				Usage: "Also serve every built su3 bundle at <prefix>/i2pseeds-N.su3, listed in <prefix>/i2pseeds-index.txt, for clients that fetch several",
+			},
+			&cli.BoolFlag{
+				Name:  "checksum",
+				Usage: "Also serve <prefix>/i2pseeds.su3.sha256, the SHA-256 of the bundle the requesting client downloads, for verifying transfers",
+			},
+			&cli.IntFlag{
+				Name:  "max-su3-size",
+				Usage: "Refuse with 503, and log an error, rather than serve an su3 bundle larger than this many bytes (0 = unlimited)",
+			},
+			&cli.DurationFlag{
+				Name:  "warmup-grace",
+				Value: 0,
+				Usage: "Keep /readyz reporting not ready for this long after the first listener starts serving, on top of waiting for the first fresh rebuild",
+			},
+			&cli.BoolFlag{
+				Name:  "serve-stale-during-warmup",
+				Usage: "Let /readyz report ready while bundles are available, before the first fresh rebuild has completed",
+			},
+			&cli.StringFlag{
+				Name:  "session-ticket-keys",
+				Usage: "File of hex-encoded 32-byte TLS session ticket keys, one per line, newest first; share it between load-balanced servers",
+			},
+			&cli.DurationFlag{
+				Name:  "session-ticket-rotate",
+				Value: 0,
+				Usage: "Rotate HTTPS session ticket keys at this interval: re-read --session-ticket-keys, or generate a new key if no file is given",
+			},
+			&cli.StringSliceFlag{
+				Name:  "geoip-db",
+				Usage: "MaxMind GeoIP2/GeoLite2 database (Country and/or ASN) used to add the client's country and ASN to access log lines; may be given more than once",
+			},
+			&cli.StringSliceFlag{
+				Name:  "geo-partition",
+				Usage: "A group of '|'-separated country codes (ex. 'DE|FR|NL') whose clients are served only from that group's share of the bundles; may be given more than once, once per group. Requires --geoip-db",
+			},
+			&cli.DurationFlag{
+				Name:  "slow-request-threshold",
+				Value: 0,
+				Usage: "Log a warning with route, transport and duration for requests slower than this (ex. 2s); 0 disables",
+			},
+			&cli.StringFlag{
+				Name:  "download-name",
+				Value: reseed.DefaultDownloadName,
+				Usage: "Filename offered in Content-Disposition for served su3 bundles; numbered bundles insert -N before the extension",
+			},
+		}, certSubjectFlags()...),
 	}
 }
 
@@ -315,6 +596,20 @@ func reseedAction(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	if c.Bool("check") {
+		return reseedCheck(c, os.Stdout, netdbDir, signerID)
+	}
+
+	// Claim the PID file before anything else, so a second instance stops here.
+	// Every later failure is returned rather than exiting, so it is removed again
+	if path := c.String("pid-file"); path != "" {
+		removePIDFile, err := writePIDFile(path)
+		if err != nil {
+			fmt.Println("--pid-file:", err)
+			return fmt.Errorf("--pid-file: %w", err)
+		}
+		defer removePIDFile()
+	}
 
 	// Setup remote NetDB sharing if configured
 	if err := setupRemoteNetDBSharing(c); err != nil {
@@ -344,20 +639,126 @@ func reseedAction(c *cli.Context) error {
 		return err
 	}
 
+	// Make sure clients will accept what gets signed
+	if err := checkSignerCert(c, signerID, privKey, time.Now()); err != nil {
+		return err
+	}
+
 	// Initialize reseeder with configured parameters
 	reseeder, err := initializeReseeder(c, netdbDir, signerID, privKey, reloadIntvl)
 	if err != nil {
 		return err
 	}
 
+	// Keep the shared netDb up to date now that there is a reseeder to rebuild
+	startSupplementalNetDb(c, reseeder)
+
+	if err := setupRateLimitRedis(c); err != nil {
+		return err
+	}
+
+	// Open the GeoIP databases used to annotate the access log
+	geo, err := setupGeoIP(c)
+	if err != nil {
+		return err
+	}
+
 	// Start all configured servers
-	startConfiguredServers(c, tlsConfig, i2pkey, reseeder)
+	return startConfiguredServers(c, tlsConfig, i2pkey, reseeder, geo)
+}
+
+// geoConfig holds the databases opened by --geoip-db and the --geo-partition
+// country groups, shared by every transport's server. Both are nil without
+// --geoip-db.
+type geoConfig struct {
+	ip         *reseed.GeoIP
+	partitions *reseed.GeoPartitions
+}
+
+// rateStores holds the --ratelimit-redis stores shared by every transport's
+// server; its fields stay nil, selecting in-memory stores, without the flag.
+var rateStores reseed.RateStores
+
+// setupRateLimitRedis connects to the --ratelimit-redis server, if one is set.
+func setupRateLimitRedis(c *cli.Context) error {
+	redisURL := c.String("ratelimit-redis")
+	if redisURL == "" {
+		return nil
+	}
+	stores, err := reseed.RedisRateStores(redisURL)
+	if err != nil {
+		fmt.Println("--ratelimit-redis:", err)
+		return fmt.Errorf("--ratelimit-redis: %w", err)
+	}
+	rateStores = stores
 	return nil
 }
 
+// newReseedServer creates a server with the rate limits from the command line,
+// backed by the --ratelimit-redis stores when they are configured, annotating
+// its access log and partitioning its bundles by geo.
+func newReseedServer(c *cli.Context, geo geoConfig) (*reseed.Server, error) {
+	trustedProxies, err := reseed.ParseTrustedProxies(c.StringSlice("trusted-proxies"))
+	if err != nil {
+		return nil, fmt.Errorf("--trusted-proxies: %w", err)
+	}
+	server, err := reseed.NewServerWithConfig(reseed.ServerConfig{
+		Prefix:             c.String("prefix"),
+		TrustProxy:         c.Bool("trustProxy"),
+		SAMAddr:            c.String("samaddr"),
+		RequestRateLimit:   c.Int("ratelimit"),
+		WebRateLimit:       c.Int("ratelimitweb"),
+		GlobalRateLimit:    c.Int("ratelimitglobal"),
+		RequestRateStore:   rateStores.Request,
+		WebRateStore:       rateStores.Web,
+		GlobalRateStore:    rateStores.Global,
+		RateLimitStoreSize: c.Int("ratelimit-store-size"),
+		TrustedProxies:     trustedProxies,
+		GeoIP:              geo.ip,
+		GeoPartitions:      geo.partitions,
+		Ephemeral:          c.Bool("ephemeral"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// setupGeoIP opens the --geoip-db databases, if any were given, and reads the
+// --geo-partition country groups that depend on them.
+func setupGeoIP(c *cli.Context) (geoConfig, error) {
+	var geo geoConfig
+	paths := c.StringSlice("geoip-db")
+	if groups := c.StringSlice("geo-partition"); len(groups) > 0 {
+		if len(paths) == 0 {
+			fmt.Println("--geo-partition requires --geoip-db")
+			return geo, fmt.Errorf("--geo-partition requires --geoip-db")
+		}
+		parts, err := reseed.ParseGeoPartitions(groups)
+		if err != nil {
+			fmt.Println("--geo-partition:", err)
+			return geo, fmt.Errorf("--geo-partition: %w", err)
+		}
+		geo.partitions = parts
+	}
+	if len(paths) == 0 {
+		return geo, nil
+	}
+	db, err := reseed.OpenGeoIP(paths...)
+	if err != nil {
+		fmt.Println("--geoip-db:", err)
+		return geo, fmt.Errorf("--geoip-db: %w", err)
+	}
+	geo.ip = db
+	return geo, nil
+}
+
 // validateRequiredConfig validates and returns the required netdb and signer configuration.
 func validateRequiredConfig(c *cli.Context) (string, string, error) {
-	providedReseeds(c)
+	if err := providedReseeds(c); err != nil {
+		fmt.Println(err)
+		return "", "", err
+	}
 
 	netdbDir := c.String("netdb")
 	if netdbDir == "" {
@@ -384,12 +785,212 @@ func validateRequiredConfig(c *cli.Context) (string, string, error) {
 		signerID = string(bytes)
 	}
 
+	if c.Int("netdb-max-files") < 0 {
+		fmt.Println("--netdb-max-files cannot be negative")
+		return "", "", fmt.Errorf("--netdb-max-files cannot be negative")
+	}
+
+	if err := validateRouterInfoFraction(c.Float64("min-routerinfo-fraction")); err != nil {
+		fmt.Println(err)
+		return "", "", err
+	}
+
+	if info, err := os.Stat(netdbDir); err == nil && info.Mode().IsRegular() && c.String("share-peer") != "" {
+		fmt.Println("--share-peer cannot write into a netDb archive")
+		return "", "", fmt.Errorf("--share-peer requires a netDb directory, %s is an archive", netdbDir)
+	}
+
+	if err := validateListenerAddrs(c); err != nil {
+		fmt.Println(err)
+		return "", "", err
+	}
+
+	if _, err := reseed.ParseTrustedProxies(c.StringSlice("trusted-proxies")); err != nil {
+		fmt.Println("--trusted-proxies:", err)
+		return "", "", fmt.Errorf("--trusted-proxies: %w", err)
+	}
+	if len(c.StringSlice("trusted-proxies")) > 0 && !c.Bool("trustProxy") {
+		fmt.Println("--trusted-proxies requires --trustProxy")
+		return "", "", fmt.Errorf("--trusted-proxies requires --trustProxy")
+	}
+
+	if c.Duration("bundle-ttl") < 0 {
+		fmt.Println("--bundle-ttl cannot be negative")
+		return "", "", fmt.Errorf("--bundle-ttl cannot be negative")
+	}
+
+	if c.Int("max-su3-size") < 0 {
+		fmt.Println("--max-su3-size cannot be negative")
+		return "", "", fmt.Errorf("--max-su3-size cannot be negative")
+	}
+
+	if c.Int("ratelimit-store-size") <= 0 {
+		fmt.Println("--ratelimit-store-size must be greater than zero")
+		return "", "", fmt.Errorf("--ratelimit-store-size must be greater than zero")
+	}
+
+	prefix, err := reseed.NormalizePrefix(c.String("prefix"))
+	if err != nil {
+		fmt.Println("--prefix:", err)
+		return "", "", fmt.Errorf("--prefix: %w", err)
+	}
+	if err := c.Set("prefix", prefix); err != nil {
+		return "", "", err
+	}
+
+	if err := reseed.SetLanguages(c.StringSlice("languages")); err != nil {
+		fmt.Println("--languages:", err)
+		return "", "", fmt.Errorf("--languages: %w", err)
+	}
+
+	if version := c.String("min-router-version"); version != "" {
+		if err := reseed.ValidateRouterVersion(version); err != nil {
+			fmt.Println("--min-router-version:", err)
+			return "", "", fmt.Errorf("--min-router-version: %w", err)
+		}
+	}
+
+	if addr := c.String("admin-addr"); addr != "" {
+		if err := validateAdminAddr(addr); err != nil {
+			fmt.Println(err)
+			return "", "", err
+		}
+	}
+	if c.String("netdb-switch") != "" && c.String("admin-addr") == "" {
+		fmt.Println("--netdb-switch requires --admin-addr")
+		return "", "", fmt.Errorf("--netdb-switch requires --admin-addr")
+	}
+	if c.Bool("admin-pprof") && c.String("admin-addr") == "" {
+		fmt.Println("--admin-pprof requires --admin-addr")
+		return "", "", fmt.Errorf("--admin-pprof requires --admin-addr")
+	}
+	if c.Bool("metrics") && c.String("metrics-addr") == "" {
+		fmt.Println("--metrics requires --metrics-addr")
+		return "", "", fmt.Errorf("--metrics requires --metrics-addr")
+	}
+
+	if path := c.String("session-ticket-keys"); path != "" {
+		if _, err := reseed.LoadSessionTicketKeys(path); err != nil {
+			fmt.Println("--session-ticket-keys:", err)
+			return "", "", fmt.Errorf("--session-ticket-keys: %w", err)
+		}
+	}
+
+	if err := reseed.ValidateDownloadName(c.String("download-name")); err != nil {
+		fmt.Println("--download-name:", err)
+		return "", "", fmt.Errorf("--download-name: %w", err)
+	}
+
+	if err := validateCanonicalURL(c.String("canonical-url"), c.Bool("sitemap")); err != nil {
+		fmt.Println(err)
+		return "", "", err
+	}
+
+	if c.Bool("ephemeral") {
+		if err := validateEphemeral(c, signerID); err != nil {
+			fmt.Println(err)
+			return "", "", err
+		}
+	}
+
+	if err := reseed.SetPingSourceAddr(c.String("bind-source")); err != nil {
+		fmt.Println("--bind-source:", err)
+		return "", "", fmt.Errorf("--bind-source: %w", err)
+	}
+
 	return netdbDir, signerID, nil
 }
 
+// validateCanonicalURL checks that --canonical-url is an absolute http(s) URL,
+// which --sitemap requires since sitemap entries must be absolute.
+func validateCanonicalURL(canonical string, sitemap bool) error {
+	if canonical == "" {
+		if sitemap {
+			return fmt.Errorf("--sitemap requires --canonical-url")
+		}
+		return nil
+	}
+	u, err := url.Parse(canonical)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("--canonical-url must be an absolute http or https URL, got %q", canonical)
+	}
+	return nil
+}
+
+// validateAdminAddr checks that --admin-addr binds a loopback address. The
+// admin endpoints change what is served and expose profiles without any
+// authentication, so they must not be reachable from other hosts.
+func validateAdminAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("--admin-addr: %w", err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("--admin-addr must be a loopback address such as 127.0.0.1:8444, got %q; the admin endpoints have no authentication", addr)
+	}
+	return nil
+}
+
+// validateEphemeral checks that --ephemeral can run without writing to disk.
+// Options that always write are refused, and every key and certificate the
+// enabled listeners would otherwise generate must already exist. That includes
+// the identities onramp loads from its keystore directories for --i2p and --onion.
+func validateEphemeral(c *cli.Context, signerID string) error {
+	switch {
+	case c.Bool("acme"):
+		return fmt.Errorf("--ephemeral cannot be combined with --acme, which writes certificates to disk")
+	case c.String("share-peer") != "":
+		return fmt.Errorf("--ephemeral cannot be combined with --share-peer, which writes to the netDb")
+	case c.String("audit-log") != "":
+		return fmt.Errorf("--ephemeral cannot be combined with --audit-log, which writes to disk")
+	case c.String("pid-file") != "":
+		return fmt.Errorf("--ephemeral cannot be combined with --pid-file, which writes to disk")
+	}
+
+	signerKey := c.String("key")
+	if signerKey == "" {
+		signerKey = signerFile(signerID) + ".pem"
+	}
+	required := [][2]string{{"signing key", signerKey}}
+	if tlsHost := c.String("tlsHost"); tlsHost != "" && !c.Bool("trustProxy") {
+		tlsCert, tlsKey := c.String("tlsCert"), c.String("tlsKey")
+		if tlsCert == "" {
+			tlsCert = tlsHost + ".crt"
+		}
+		if tlsKey == "" {
+			tlsKey = tlsHost + ".pem"
+		}
+		required = append(required, [2]string{"TLS certificate", tlsCert}, [2]string{"TLS key", tlsKey})
+	}
+	if c.Bool("onion") {
+		required = append(required,
+			[2]string{"onion key", c.String("onionKey")},
+			[2]string{"onramp onion identity", filepath.Join(onramp.ONION_KEYSTORE_PATH, "reseed.tor.private")})
+	}
+	if c.Bool("i2p") {
+		required = append(required,
+			[2]string{"I2P keys", c.String("i2pKeys")},
+			[2]string{"onramp I2P identity", filepath.Join(onramp.I2P_KEYSTORE_PATH, "reseed.i2p.private")})
+	}
+
+	for _, req := range required {
+		if !fileExists(req[1]) {
+			return fmt.Errorf("--ephemeral requires an existing %s at %q; it cannot be generated without writing to disk", req[0], req[1])
+		}
+	}
+	return nil
+}
+
 // setupRemoteNetDBSharing configures and starts remote NetDB downloading if share-peer is specified.
 func setupRemoteNetDBSharing(c *cli.Context) error {
 	if c.String("share-peer") != "" {
+		if err := checkNetDbWritable(c.Bool("netdb-readonly"), c.String("netdb"), "--share-peer"); err != nil {
+			fmt.Println(err)
+			return err
+		}
 		count := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
 		for i := range count {
 			err := downloadRemoteNetDB(c.String("share-peer"), c.String("share-password"), c.String("netdb"), c.String("samaddr"))
@@ -400,11 +1001,18 @@ func setupRemoteNetDBSharing(c *cli.Context) error {
 				break
 			}
 		}
-		go getSupplementalNetDb(c.String("share-peer"), c.String("share-password"), c.String("netdb"), c.String("samaddr"))
 	}
 	return nil
 }
 
+// startSupplementalNetDb keeps downloading the --share-peer netDb in the
+// background, asking reseeder to rebuild after each successful download.
+func startSupplementalNetDb(c *cli.Context, reseeder *reseed.ReseederImpl) {
+	if c.String("share-peer") != "" {
+		go getSupplementalNetDb(c.String("share-peer"), c.String("share-password"), c.String("netdb"), c.String("samaddr"), reseeder.RequestRebuild)
+	}
+}
+
 // tlsConfiguration holds TLS certificate configuration for different protocols.
 type tlsConfiguration struct {
 	tlsCert, tlsKey           string
@@ -473,14 +1081,12 @@ func validateAndProvisionCertificates(c *cli.Context, config *tlsConfiguration)
 
 	if acme {
 		acmeserver := c.String("acmeserver")
-		err := checkUseAcmeCert(config.tlsHost, "", acmeserver, &config.tlsCert, &config.tlsKey, auto)
-		if err != nil {
-			lgr.WithError(err).Fatal("Fatal error")
+		if err := checkUseAcmeCert(config.tlsHost, "", acmeserver, &config.tlsCert, &config.tlsKey, auto); err != nil {
+			return fmt.Errorf("ACME certificate for %s: %w", config.tlsHost, err)
 		}
 	} else {
-		err := checkOrNewTLSCert(config.tlsHost, &config.tlsCert, &config.tlsKey, auto)
-		if err != nil {
-			lgr.WithError(err).Fatal("Fatal error")
+		if err := checkOrNewTLSCert(config.tlsHost, &config.tlsCert, &config.tlsKey, auto, certSubject(c)); err != nil {
+			return fmt.Errorf("TLS certificate for %s: %w", config.tlsHost, err)
 		}
 	}
 	return nil
@@ -495,15 +1101,15 @@ func setupI2PKeys(c *cli.Context, tlsConfig *tlsConfiguration) (i2pkeys.I2PKeys,
 	}
 
 	var err error
-	i2pkey, err = LoadKeys("reseed.i2pkeys", c)
+	i2pkey, err = LoadKeys(c.String("i2pKeys"), c)
 	if err != nil {
-		lgr.WithError(err).Fatal("Fatal error")
+		return i2pkey, fmt.Errorf("I2P keys %s: %w", c.String("i2pKeys"), err)
 	}
 
 	configureI2PTLSSettings(tlsConfig, i2pkey)
 
 	if err := setupI2PTLSCertificate(c, tlsConfig); err != nil {
-		lgr.WithError(err).Fatal("Fatal error")
+		return i2pkey, fmt.Errorf("I2P TLS certificate for %s: %w", tlsConfig.i2pTlsHost, err)
 	}
 
 	return i2pkey, nil
@@ -540,7 +1146,7 @@ func setupI2PTLSCertificate(c *cli.Context, tlsConfig *tlsConfiguration) error {
 		return nil
 	}
 
-	return checkOrNewTLSCert(tlsConfig.i2pTlsHost, &tlsConfig.i2pTlsCert, &tlsConfig.i2pTlsKey, auto)
+	return checkOrNewTLSCert(tlsConfig.i2pTlsHost, &tlsConfig.i2pTlsCert, &tlsConfig.i2pTlsKey, auto, certSubject(c))
 }
 
 // loadOrGenerateOnionKey loads an existing onion key from file or generates a new one.
@@ -587,7 +1193,7 @@ func setupOnionTlsCertificate(c *cli.Context, tlsConfig *tlsConfiguration) error
 	auto := c.Bool("yes")
 	ignore := c.Bool("trustProxy")
 	if !ignore {
-		return checkOrNewTLSCert(tlsConfig.onionTlsHost, &tlsConfig.onionTlsCert, &tlsConfig.onionTlsKey, auto)
+		return checkOrNewTLSCert(tlsConfig.onionTlsHost, &tlsConfig.onionTlsCert, &tlsConfig.onionTlsKey, auto, certSubject(c))
 	}
 	return nil
 }
@@ -600,28 +1206,30 @@ func setupOnionKeys(c *cli.Context, tlsConfig *tlsConfiguration) error {
 
 	onionKey, err := loadOrGenerateOnionKey(c.String("onionKey"))
 	if err != nil {
-		lgr.WithError(err).Fatal("Fatal error")
+		return fmt.Errorf("onion key %s: %w", c.String("onionKey"), err)
 	}
 
 	configureOnionTlsHost(tlsConfig, onionKey)
 
-	err = ioutil.WriteFile(c.String("onionKey"), onionKey, 0o644)
-	if err != nil {
-		lgr.WithError(err).Fatal("Fatal error")
+	if !c.Bool("ephemeral") {
+		err = ioutil.WriteFile(c.String("onionKey"), onionKey, 0o644)
+		if err != nil {
+			return fmt.Errorf("saving onion key %s: %w", c.String("onionKey"), err)
+		}
 	}
 
 	configureOnionTlsPaths(tlsConfig)
 
 	err = setupOnionTlsCertificate(c, tlsConfig)
 	if err != nil {
-		lgr.WithError(err).Fatal("Fatal error")
+		return fmt.Errorf("onion TLS certificate for %s: %w", tlsConfig.onionTlsHost, err)
 	}
 
 	return nil
 }
 
 // setupSigningConfiguration parses duration and sets up signing certificates.
-func setupSigningConfiguration(c *cli.Context, signerID string) (time.Duration, *rsa.PrivateKey, error) {
+func setupSigningConfiguration(c *cli.Context, signerID string) (time.Duration, crypto.Signer, error) {
 	reloadIntvl, err := time.ParseDuration(c.String("interval"))
 	if err != nil {
 		fmt.Printf("'%s' is not a valid time interval.\n", reloadIntvl)
@@ -634,43 +1242,236 @@ func setupSigningConfiguration(c *cli.Context, signerID string) (time.Duration,
 	}
 
 	auto := c.Bool("yes")
-	privKey, err := getOrNewSigningCert(&signerKey, signerID, auto)
+	privKey, err := getOrNewSigningCert(&signerKey, signerID, auto, certSubject(c))
 	if err != nil {
-		lgr.WithError(err).Fatal("Fatal error")
+		return 0, nil, fmt.Errorf("signing key %s: %w", signerKey, err)
 	}
 
 	return reloadIntvl, privKey, nil
 }
 
+// newReseedNetDb opens netdbDir with the netDb filters from the command line.
+func newReseedNetDb(c *cli.Context, netdbDir string) *reseed.LocalNetDbImpl {
+	netdb := reseed.NewLocalNetDb(netdbDir, c.Duration("routerInfoAge"))
+	netdb.LazyData = c.Bool("lazy-routerinfos")
+	netdb.MinRouterVersion = c.String("min-router-version")
+	netdb.MaxFiles = c.Int("netdb-max-files")
+	return netdb
+}
+
+// validateSigningKey checks that privKey can sign su3 files with the
+// --rsa-pss and --min-key-bits settings.
+func validateSigningKey(c *cli.Context, privKey crypto.Signer) error {
+	if _, isRSA := privKey.(*rsa.PrivateKey); c.Bool("rsa-pss") && !isRSA {
+		fmt.Println("--rsa-pss requires an RSA signing key")
+		return fmt.Errorf("--rsa-pss requires an RSA signing key, got %T", privKey)
+	}
+	if key, isRSA := privKey.(*rsa.PrivateKey); isRSA && !slices.Contains(su3.RSAKeySizes, key.Size()*8) {
+		fmt.Printf("The %d-bit RSA signing key cannot sign su3 files\n", key.Size()*8)
+		return fmt.Errorf("RSA signing key is %d bits, su3 signatures require one of %v", key.Size()*8, su3.RSAKeySizes)
+	}
+	if err := reseed.CheckKeySize(privKey.Public(), c.Int("min-key-bits")); err != nil {
+		fmt.Println("Signing key is too weak:", err)
+		return fmt.Errorf("--min-key-bits: signing %w", err)
+	}
+	return nil
+}
+
 // initializeReseeder creates and configures a new reseeder instance.
-func initializeReseeder(c *cli.Context, netdbDir, signerID string, privKey *rsa.PrivateKey, reloadIntvl time.Duration) (*reseed.ReseederImpl, error) {
-	routerInfoAge := c.Duration("routerInfoAge")
-	netdb := reseed.NewLocalNetDb(netdbDir, routerInfoAge)
+func initializeReseeder(c *cli.Context, netdbDir, signerID string, privKey crypto.Signer, reloadIntvl time.Duration) (*reseed.ReseederImpl, error) {
+	if err := validateSigningKey(c, privKey); err != nil {
+		return nil, err
+	}
 
-	reseeder := reseed.NewReseeder(netdb)
+	reseeder := reseed.NewReseeder(newReseedNetDb(c, netdbDir))
 	reseeder.SigningKey = privKey
 	reseeder.SignerID = []byte(signerID)
+	reseeder.RSAPSS = c.Bool("rsa-pss")
+	reseeder.FloodfillFirst = c.Bool("floodfill-first")
 	reseeder.NumRi = c.Int("numRi")
+	reseeder.MinRouterInfoFraction = c.Float64("min-routerinfo-fraction")
 	reseeder.NumSu3 = c.Int("numSu3")
 	reseeder.RebuildInterval = reloadIntvl
+	reseeder.BundleTTL = c.Duration("bundle-ttl")
+	reseeder.StartupWait = c.Duration("startup-wait")
+	reseeder.HistorySize = c.Int("bundle-history")
+	reseeder.AuditLog = c.String("audit-log")
+	reseeder.Deterministic = c.Bool("deterministic")
+	reseeder.DeterministicSeed = []byte(c.String("deterministic-seed"))
+	if transport := c.String("prefer-transport"); transport != "" {
+		selector, err := newTransportSelector(transport, c.Float64("prefer-transport-share"))
+		if err != nil {
+			return nil, err
+		}
+		reseeder.Selector = selector
+	}
+	if c.Bool("verify-on-build") {
+		cert, err := loadCertificate(signerCertPath(c, signerID))
+		if err != nil {
+			return nil, fmt.Errorf("--verify-on-build requires the signer certificate: %w", err)
+		}
+		reseeder.VerifyCert = cert
+	}
+	if c.Bool("serve-signer-cert") {
+		cert, err := loadMatchingSignerCert(signerCertPath(c, signerID), privKey)
+		if err != nil {
+			return nil, fmt.Errorf("--serve-signer-cert: %w", err)
+		}
+		reseeder.SignerCert = cert
+	}
+	clock, err := setupClockCheck(c)
+	if err != nil {
+		return nil, err
+	}
+	reseeder.ClockCheck = clock
+	guard, err := setupMemoryGuard(c)
+	if err != nil {
+		return nil, err
+	}
+	reseeder.MemoryGuard = guard
 	reseeder.Start()
 
 	return reseeder, nil
 }
 
+// signerCertPath returns the --signer-cert path, defaulting to <signer>.crt.
+func signerCertPath(c *cli.Context, signerID string) string {
+	if certPath := c.String("signer-cert"); certPath != "" {
+		return certPath
+	}
+	return signerFile(signerID) + ".crt"
+}
+
+// loadMatchingSignerCert loads the signer certificate at path and checks that it
+// belongs to the signing key, so the server never publishes a certificate that
+// cannot verify the bundles it serves.
+func loadMatchingSignerCert(path string, privKey crypto.Signer) (*x509.Certificate, error) {
+	cert, err := loadCertificate(path)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(privKey.Public()) {
+		return nil, fmt.Errorf("certificate %s does not match the signing key", path)
+	}
+	return cert, nil
+}
+
+// checkSignerCert checks at startup that the signer certificate is valid now
+// and for at least --signer-cert-expiry-window, since routers reject bundles
+// signed under an expired certificate. Problems are logged as warnings, or
+// returned when --require-valid-signer-cert is set.
+func checkSignerCert(c *cli.Context, signerID string, privKey crypto.Signer, now time.Time) error {
+	path := signerCertPath(c, signerID)
+	cert, err := loadMatchingSignerCert(path, privKey)
+	if err == nil {
+		err = signerCertValidity(cert, now, c.Duration("signer-cert-expiry-window"))
+	}
+	if err == nil {
+		return nil
+	}
+	if c.Bool("require-valid-signer-cert") {
+		fmt.Println("--require-valid-signer-cert:", err)
+		return fmt.Errorf("--require-valid-signer-cert: %w", err)
+	}
+	lgr.WithError(err).WithField("signer_cert", path).Warn("Signer certificate check failed")
+	return nil
+}
+
+// setupMemoryGuard samples the heap once when --shed-memory is set, for the
+// servers to shed reseed requests above it. The samples taken every
+// --shed-memory-interval after that are started with the servers, by
+// startConfiguredServers.
+func setupMemoryGuard(c *cli.Context) (*reseed.MemoryGuard, error) {
+	limit := c.Int("shed-memory")
+	if limit == 0 {
+		return nil, nil
+	}
+	if limit < 0 || c.Duration("shed-memory-interval") <= 0 {
+		fmt.Println("--shed-memory and --shed-memory-interval must be positive")
+		return nil, fmt.Errorf("--shed-memory and --shed-memory-interval must be positive")
+	}
+	guard := &reseed.MemoryGuard{Limit: uint64(limit) << 20}
+	guard.Check()
+	return guard, nil
+}
+
+// setupClockCheck checks the system clock against --ntp-server, if given. A
+// skewed or unverifiable clock is logged as a warning, or returned when
+// --require-clock-sync is set. The --ntp-interval checks that follow are
+// started with the servers, by startConfiguredServers.
+func setupClockCheck(c *cli.Context) (*reseed.ClockCheck, error) {
+	if c.String("ntp-server") == "" {
+		if c.Bool("require-clock-sync") {
+			fmt.Println("--require-clock-sync requires --ntp-server")
+			return nil, fmt.Errorf("--require-clock-sync requires --ntp-server")
+		}
+		return nil, nil
+	}
+	clock := &reseed.ClockCheck{
+		Server:   c.String("ntp-server"),
+		MaxSkew:  c.Duration("max-clock-skew"),
+		Required: c.Bool("require-clock-sync"),
+	}
+	if err := clock.Check(); err != nil {
+		if clock.Required {
+			fmt.Println("--require-clock-sync:", err)
+			return nil, fmt.Errorf("--require-clock-sync: %w", err)
+		}
+		lgr.WithError(err).Warn("Clock sanity check failed")
+	}
+	return clock, nil
+}
+
+// signerCertValidity reports whether cert is outside its validity period at
+// now, or leaves it within window.
+func signerCertValidity(cert *x509.Certificate, now time.Time, window time.Duration) error {
+	switch {
+	case now.Before(cert.NotBefore):
+		return fmt.Errorf("signer certificate is not valid until %s", cert.NotBefore.Format(time.RFC3339))
+	case now.After(cert.NotAfter):
+		return fmt.Errorf("signer certificate expired on %s", cert.NotAfter.Format(time.RFC3339))
+	case now.Add(window).After(cert.NotAfter):
+		return fmt.Errorf("signer certificate expires on %s, within %s", cert.NotAfter.Format(time.RFC3339), window)
+	}
+	return nil
+}
+
+// newTransportSelector validates the --prefer-transport options and builds the
+// matching bundle selector.
+func newTransportSelector(transport string, share float64) (reseed.TransportSelector, error) {
+	transport = strings.ToLower(transport)
+	if transport != "ntcp2" && transport != "ssu2" {
+		return reseed.TransportSelector{}, fmt.Errorf("--prefer-transport must be ntcp2 or ssu2, got %q", transport)
+	}
+	if share <= 0 || share > 1 {
+		return reseed.TransportSelector{}, fmt.Errorf("--prefer-transport-share must be greater than 0 and at most 1, got %v", share)
+	}
+	return reseed.TransportSelector{Transport: transport, Share: share}, nil
+}
+
 // Context-aware server functions that return errors instead of calling Fatal
-func reseedHTTPSWithContext(ctx context.Context, c *cli.Context, tlsCert, tlsKey string, reseeder *reseed.ReseederImpl) error {
-	server := reseed.NewServer(c.String("prefix"), c.Bool("trustProxy"), c.String("samaddr"), c.Int("ratelimit"), c.Int("ratelimitweb"), c.Int("ratelimitglobal"))
+func reseedHTTPSWithContext(ctx context.Context, c *cli.Context, tlsCert, tlsKey string, reseeder *reseed.ReseederImpl, blacklist *reseed.Blacklist, geo geoConfig) error {
+	server, err := newReseedServer(c, geo)
+	if err != nil {
+		return err
+	}
 	server.Reseeder = reseeder
-	server.Addr = net.JoinHostPort(c.String("ip"), c.String("port"))
+	server.MultiBundle = c.Bool("multi-bundle")
+	server.Checksum = c.Bool("checksum")
+	server.MaxSu3Size = int64(c.Int("max-su3-size"))
+	server.DownloadName = c.String("download-name")
+	server.SlowRequestThreshold = c.Duration("slow-request-threshold")
+	server.AllowedUserAgents = c.StringSlice("useragent")
+	server.CanonicalURL = c.String("canonical-url")
+	server.Sitemap = c.Bool("sitemap")
+	server.Addr = clearnetListenAddr(c).addr
+	server.SessionTicketKeyFile = c.String("session-ticket-keys")
+	server.SessionTicketRotation = c.Duration("session-ticket-rotate")
+	server.MinKeyBits = c.Int("min-key-bits")
 
-	// load a blacklist
-	blacklist := reseed.NewBlacklist()
 	server.Blacklist = blacklist
-	blacklistFile := c.String("blacklist")
-	if "" != blacklistFile {
-		blacklist.LoadFile(blacklistFile)
-	}
+	configureServerAllowlist(server, c)
 
 	// print stats once in a while
 	if c.Duration("stats") != 0 {
@@ -706,18 +1507,24 @@ func reseedHTTPSWithContext(ctx context.Context, c *cli.Context, tlsCert, tlsKey
 	return nil
 }
 
-func reseedHTTPWithContext(ctx context.Context, c *cli.Context, reseeder *reseed.ReseederImpl) error {
-	server := reseed.NewServer(c.String("prefix"), c.Bool("trustProxy"), c.String("samaddr"), c.Int("ratelimit"), c.Int("ratelimitweb"), c.Int("ratelimitglobal"))
+func reseedHTTPWithContext(ctx context.Context, c *cli.Context, reseeder *reseed.ReseederImpl, blacklist *reseed.Blacklist, geo geoConfig) error {
+	server, err := newReseedServer(c, geo)
+	if err != nil {
+		return err
+	}
 	server.Reseeder = reseeder
-	server.Addr = net.JoinHostPort(c.String("ip"), c.String("port"))
+	server.MultiBundle = c.Bool("multi-bundle")
+	server.Checksum = c.Bool("checksum")
+	server.MaxSu3Size = int64(c.Int("max-su3-size"))
+	server.DownloadName = c.String("download-name")
+	server.SlowRequestThreshold = c.Duration("slow-request-threshold")
+	server.AllowedUserAgents = c.StringSlice("useragent")
+	server.CanonicalURL = c.String("canonical-url")
+	server.Sitemap = c.Bool("sitemap")
+	server.Addr = clearnetListenAddr(c).addr
 
-	// load a blacklist
-	blacklist := reseed.NewBlacklist()
 	server.Blacklist = blacklist
-	blacklistFile := c.String("blacklist")
-	if "" != blacklistFile {
-		blacklist.LoadFile(blacklistFile)
-	}
+	configureServerAllowlist(server, c)
 
 	// print stats once in a while
 	if c.Duration("stats") != 0 {
@@ -754,20 +1561,23 @@ func reseedHTTPWithContext(ctx context.Context, c *cli.Context, reseeder *reseed
 }
 
 // setupOnionServer configures a new reseed server instance with blacklist support.
-func setupOnionServer(c *cli.Context, reseeder *reseed.ReseederImpl) *reseed.Server {
-	server := reseed.NewServer(c.String("prefix"), c.Bool("trustProxy"), c.String("samaddr"), c.Int("ratelimit"), c.Int("ratelimitweb"), c.Int("ratelimitglobal"))
+func setupOnionServer(c *cli.Context, reseeder *reseed.ReseederImpl, geo geoConfig) (*reseed.Server, error) {
+	server, err := newReseedServer(c, geo)
+	if err != nil {
+		return nil, err
+	}
 	server.Reseeder = reseeder
+	server.MultiBundle = c.Bool("multi-bundle")
+	server.Checksum = c.Bool("checksum")
+	server.MaxSu3Size = int64(c.Int("max-su3-size"))
+	server.DownloadName = c.String("download-name")
+	server.SlowRequestThreshold = c.Duration("slow-request-threshold")
+	server.AllowedUserAgents = c.StringSlice("useragent")
+	server.CanonicalURL = c.String("canonical-url")
+	server.Sitemap = c.Bool("sitemap")
 	server.Addr = net.JoinHostPort(c.String("ip"), c.String("port"))
 
-	// load a blacklist
-	blacklist := reseed.NewBlacklist()
-	server.Blacklist = blacklist
-	blacklistFile := c.String("blacklist")
-	if "" != blacklistFile {
-		blacklist.LoadFile(blacklistFile)
-	}
-
-	return server
+	return server, nil
 }
 
 // startStatsMonitoring begins memory statistics monitoring in a separate goroutine.
@@ -792,11 +1602,18 @@ func startStatsMonitoring(ctx context.Context, c *cli.Context) {
 	}()
 }
 
-// calculateOnionPort parses the port from context and increments it for onion service.
+// calculateOnionPort returns the loopback port the onion service is forwarded
+// to: one above the port of the clearnet listener, so it follows --https-addr
+// or --http-addr when either replaces --port.
 func calculateOnionPort(c *cli.Context) (int, error) {
-	port, err := strconv.Atoi(c.String("port"))
+	clearnet := clearnetListenAddr(c)
+	_, portStr, err := net.SplitHostPort(clearnet.addr)
 	if err != nil {
-		return 0, fmt.Errorf("invalid port: %w", err)
+		return 0, fmt.Errorf("invalid %s: %w", clearnet.flag, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid port in %s: %w", clearnet.flag, err)
 	}
 	return port + 1, nil
 }
@@ -830,8 +1647,13 @@ func handleOnionKeyBasedService(server *reseed.Server, c *cli.Context, port int,
 	}
 }
 
-func reseedOnionWithContext(ctx context.Context, c *cli.Context, onionTlsCert, onionTlsKey string, reseeder *reseed.ReseederImpl) error {
-	server := setupOnionServer(c, reseeder)
+func reseedOnionWithContext(ctx context.Context, c *cli.Context, onionTlsCert, onionTlsKey string, reseeder *reseed.ReseederImpl, blacklist *reseed.Blacklist, geo geoConfig) error {
+	server, err := setupOnionServer(c, reseeder, geo)
+	if err != nil {
+		return err
+	}
+	server.Blacklist = blacklist
+	configureServerAllowlist(server, c)
 	startStatsMonitoring(ctx, c)
 
 	port, err := calculateOnionPort(c)
@@ -868,10 +1690,14 @@ func reseedOnionWithContext(ctx context.Context, c *cli.Context, onionTlsCert, o
 
 // reseedI2PWithContext starts an I2P reseed server using the SAM interface for network connectivity.
 // It configures the server with rate limiting, blacklist filtering, and optional TLS support.
-func reseedI2PWithContext(ctx context.Context, c *cli.Context, i2pTlsCert, i2pTlsKey string, i2pIdentKey i2pkeys.I2PKeys, reseeder *reseed.ReseederImpl) error {
-	server := configureI2PReseederServer(c, reseeder)
+func reseedI2PWithContext(ctx context.Context, c *cli.Context, i2pTlsCert, i2pTlsKey string, i2pIdentKey i2pkeys.I2PKeys, reseeder *reseed.ReseederImpl, blacklist *reseed.Blacklist, geo geoConfig) error {
+	server, err := configureI2PReseederServer(c, reseeder, geo)
+	if err != nil {
+		return err
+	}
 
-	configureServerBlacklist(server, c)
+	server.Blacklist = blacklist
+	configureServerAllowlist(server, c)
 
 	startI2PStatsMonitoring(ctx, c)
 
@@ -884,7 +1710,7 @@ func reseedI2PWithContext(ctx context.Context, c *cli.Context, i2pTlsCert, i2pTl
 		}
 	}()
 
-	err := startI2PServerListener(server, c, i2pTlsCert, i2pTlsKey, i2pIdentKey)
+	err = startI2PServerListener(server, c, i2pTlsCert, i2pTlsKey, i2pIdentKey)
 	if err != nil && err != http.ErrServerClosed {
 		return err
 	}
@@ -893,22 +1719,54 @@ func reseedI2PWithContext(ctx context.Context, c *cli.Context, i2pTlsCert, i2pTl
 
 // configureI2PReseederServer creates and configures a new reseed server for I2P networking.
 // It sets up rate limiting, network address, and basic server configuration.
-func configureI2PReseederServer(c *cli.Context, reseeder *reseed.ReseederImpl) *reseed.Server {
-	server := reseed.NewServer(c.String("prefix"), c.Bool("trustProxy"), c.String("samaddr"), c.Int("ratelimit"), c.Int("ratelimitweb"), c.Int("ratelimitglobal"))
+func configureI2PReseederServer(c *cli.Context, reseeder *reseed.ReseederImpl, geo geoConfig) (*reseed.Server, error) {
+	server, err := newReseedServer(c, geo)
+	if err != nil {
+		return nil, err
+	}
 	server.Reseeder = reseeder
+	server.MultiBundle = c.Bool("multi-bundle")
+	server.Checksum = c.Bool("checksum")
+	server.MaxSu3Size = int64(c.Int("max-su3-size"))
+	server.DownloadName = c.String("download-name")
+	server.SlowRequestThreshold = c.Duration("slow-request-threshold")
+	server.AllowedUserAgents = c.StringSlice("useragent")
+	server.CanonicalURL = c.String("canonical-url")
+	server.Sitemap = c.Bool("sitemap")
 	server.Addr = net.JoinHostPort(c.String("ip"), c.String("port"))
-	return server
+	return server, nil
+}
+
+// configureServerAllowlist loads the --allowlist file of clients that bypass
+// the per-client bundle download limit.
+func configureServerAllowlist(server *reseed.Server, c *cli.Context) {
+	allowlistFile := c.String("allowlist")
+	if allowlistFile == "" {
+		return
+	}
+	allowlist := reseed.NewAllowlist()
+	allowlist.LoadFile(allowlistFile)
+	server.Allowlist = allowlist
 }
 
-// configureServerBlacklist sets up IP blacklist filtering for the server based on configuration.
-// It loads blacklist entries from a file if specified in the configuration.
-func configureServerBlacklist(server *reseed.Server, c *cli.Context) {
+// newServerBlacklist sets up IP blacklist filtering based on configuration.
+// It loads blacklist entries from a file if specified in the configuration, and from
+// --blacklist-url, which it then re-fetches every --blacklist-refresh until ctx is done.
+func newServerBlacklist(ctx context.Context, c *cli.Context) *reseed.Blacklist {
 	blacklist := reseed.NewBlacklist()
-	server.Blacklist = blacklist
 	blacklistFile := c.String("blacklist")
 	if blacklistFile != "" {
 		blacklist.LoadFile(blacklistFile)
 	}
+	if url := c.String("blacklist-url"); url != "" {
+		if err := blacklist.LoadURL(url); err != nil {
+			lgr.WithError(err).WithField("blacklist_url", url).Error("Failed to load remote blacklist, will retry at the next refresh")
+		}
+		if interval := c.Duration("blacklist-refresh"); interval > 0 {
+			go blacklist.Refresh(ctx, url, interval)
+		}
+	}
+	return blacklist
 }
 
 // startI2PStatsMonitoring launches a background goroutine to periodically log memory statistics for I2P.
@@ -945,7 +1803,7 @@ func startI2PServerListener(server *reseed.Server, c *cli.Context, i2pTlsCert, i
 }
 
 // startOnionServer launches the onion server in a goroutine if enabled.
-func startOnionServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfiguration, reseeder *reseed.ReseederImpl, wg *sync.WaitGroup, errChan chan<- error) {
+func startOnionServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfiguration, reseeder *reseed.ReseederImpl, blacklist *reseed.Blacklist, geo geoConfig, wg *sync.WaitGroup, errChan chan<- error) {
 	if !c.Bool("onion") {
 		return
 	}
@@ -954,7 +1812,7 @@ func startOnionServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfigu
 	go func() {
 		defer wg.Done()
 		lgr.WithField("service", "onion").Debug("Onion server starting")
-		if err := reseedOnionWithContext(ctx, c, tlsConfig.onionTlsCert, tlsConfig.onionTlsKey, reseeder); err != nil {
+		if err := reseedOnionWithContext(ctx, c, tlsConfig.onionTlsCert, tlsConfig.onionTlsKey, reseeder, blacklist, geo); err != nil {
 			select {
 			case errChan <- fmt.Errorf("onion server error: %w", err):
 			default:
@@ -964,7 +1822,7 @@ func startOnionServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfigu
 }
 
 // startI2PServer launches the I2P server in a goroutine if enabled.
-func startI2PServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfiguration, i2pkey i2pkeys.I2PKeys, reseeder *reseed.ReseederImpl, wg *sync.WaitGroup, errChan chan<- error) {
+func startI2PServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfiguration, i2pkey i2pkeys.I2PKeys, reseeder *reseed.ReseederImpl, blacklist *reseed.Blacklist, geo geoConfig, wg *sync.WaitGroup, errChan chan<- error) {
 	if !c.Bool("i2p") {
 		return
 	}
@@ -973,7 +1831,7 @@ func startI2PServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfigura
 	go func() {
 		defer wg.Done()
 		lgr.WithField("service", "i2p").Debug("I2P server starting")
-		if err := reseedI2PWithContext(ctx, c, tlsConfig.i2pTlsCert, tlsConfig.i2pTlsKey, i2pkey, reseeder); err != nil {
+		if err := reseedI2PWithContext(ctx, c, tlsConfig.i2pTlsCert, tlsConfig.i2pTlsKey, i2pkey, reseeder, blacklist, geo); err != nil {
 			select {
 			case errChan <- fmt.Errorf("i2p server error: %w", err):
 			default:
@@ -983,26 +1841,85 @@ func startI2PServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfigura
 }
 
 // startHTTPServer launches the appropriate HTTP/HTTPS server in a goroutine.
-func startHTTPServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfiguration, reseeder *reseed.ReseederImpl, wg *sync.WaitGroup, errChan chan<- error) {
+func startHTTPServer(ctx context.Context, c *cli.Context, tlsConfig *tlsConfiguration, reseeder *reseed.ReseederImpl, blacklist *reseed.Blacklist, geo geoConfig, wg *sync.WaitGroup, errChan chan<- error) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		err := runHTTPServerBasedOnConfig(ctx, c, tlsConfig, reseeder)
+		err := runHTTPServerBasedOnConfig(ctx, c, tlsConfig, reseeder, blacklist, geo)
 		if err != nil {
 			sendErrorToChannel(errChan, err)
 		}
 	}()
 }
 
+// startAdminServer launches the --admin-addr listener in a goroutine, if one is configured.
+func startAdminServer(ctx context.Context, c *cli.Context, reseeder *reseed.ReseederImpl, blacklist *reseed.Blacklist, wg *sync.WaitGroup, errChan chan<- error) {
+	if c.String("admin-addr") == "" {
+		return
+	}
+	admin := reseed.NewAdmin(reseeder)
+	admin.NetDbStaging = c.String("netdb-switch")
+	admin.ReloadFriends = func() error { return reloadReseeds(c) }
+	admin.Blacklist = blacklist
+	if c.Bool("admin-pprof") {
+		admin.EnableProfiling()
+	}
+	server := &http.Server{Addr: c.String("admin-addr"), Handler: admin, ReadHeaderTimeout: 10 * time.Second}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer shutdownCancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				lgr.WithError(err).Warn("Error during admin server shutdown")
+			}
+		}()
+		lgr.WithField("address", server.Addr).Info("Admin server started")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			sendErrorToChannel(errChan, err)
+		}
+	}()
+}
+
+// startMetricsServer launches the /metrics listener on --metrics-addr in a goroutine, if --metrics is set.
+func startMetricsServer(ctx context.Context, c *cli.Context, reseeder *reseed.ReseederImpl, wg *sync.WaitGroup, errChan chan<- error) {
+	if !c.Bool("metrics") {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", reseed.MetricsHandler(reseeder))
+	server := &http.Server{Addr: c.String("metrics-addr"), Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer shutdownCancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				lgr.WithError(err).Warn("Error during metrics server shutdown")
+			}
+		}()
+		lgr.WithField("address", server.Addr).Info("Metrics server started")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			sendErrorToChannel(errChan, err)
+		}
+	}()
+}
+
 // runHTTPServerBasedOnConfig determines whether to run HTTP or HTTPS server based on the trustProxy configuration.
 // It starts the appropriate server type and returns any errors that occur during startup or operation.
-func runHTTPServerBasedOnConfig(ctx context.Context, c *cli.Context, tlsConfig *tlsConfiguration, reseeder *reseed.ReseederImpl) error {
+func runHTTPServerBasedOnConfig(ctx context.Context, c *cli.Context, tlsConfig *tlsConfiguration, reseeder *reseed.ReseederImpl, blacklist *reseed.Blacklist, geo geoConfig) error {
 	if !c.Bool("trustProxy") {
 		lgr.WithField("service", "https").Debug("HTTPS server starting")
-		return reseedHTTPSWithContext(ctx, c, tlsConfig.tlsCert, tlsConfig.tlsKey, reseeder)
+		return reseedHTTPSWithContext(ctx, c, tlsConfig.tlsCert, tlsConfig.tlsKey, reseeder, blacklist, geo)
 	} else {
 		lgr.WithField("service", "http").Debug("HTTP server starting")
-		return reseedHTTPWithContext(ctx, c, reseeder)
+		return reseedHTTPWithContext(ctx, c, reseeder, blacklist, geo)
 	}
 }
 
@@ -1016,31 +1933,135 @@ func sendErrorToChannel(errChan chan<- error, err error) {
 	}
 }
 
+// expectTransports declares every enabled transport to the readiness endpoint so
+// that one failing during startup is reported as down rather than omitted.
+func expectTransports(health *reseed.Health, c *cli.Context) {
+	if c.Bool("trustProxy") {
+		health.Expect(reseed.TransportHTTP)
+	} else {
+		health.Expect(reseed.TransportHTTPS)
+	}
+	if c.Bool("i2p") {
+		health.Expect(reseed.TransportI2P)
+	}
+	if c.Bool("onion") {
+		health.Expect(reseed.TransportOnion)
+	}
+}
+
 // setupServerContext initializes the context and error handling infrastructure for server coordination.
 func setupServerContext() (context.Context, context.CancelFunc, *sync.WaitGroup, chan error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
-	errChan := make(chan error, 3) // Buffer for up to 3 server errors
+	errChan := make(chan error, 4) // Buffer for up to 4 server errors
 	return ctx, cancel, &wg, errChan
 }
 
-// waitForServerCompletion coordinates server completion and error handling.
-func waitForServerCompletion(wg *sync.WaitGroup, errChan chan error) {
+// waitForServerCompletion waits until every server has stopped or one of
+// them has failed, and returns the first failure.
+func waitForServerCompletion(wg *sync.WaitGroup, errChan chan error) error {
 	// Wait for first error or all servers to complete
 	go func() {
 		wg.Wait()
 		close(errChan)
 	}()
 
-	// Handle the first error that occurs
-	if err := <-errChan; err != nil {
-		lgr.WithError(err).Fatal("Fatal server error", err)
+	return <-errChan
+}
+
+// listenAddr is a clearnet address the server will bind, with the flag it came from.
+type listenAddr struct {
+	flag, addr string
+}
+
+// clearnetListenAddr returns the address the HTTPS listener, or the plain HTTP
+// one under --trustProxy, binds: --https-addr or --http-addr when set, and
+// --ip with --port otherwise.
+func clearnetListenAddr(c *cli.Context) listenAddr {
+	name := "https-addr"
+	if c.Bool("trustProxy") {
+		name = "http-addr"
+	}
+	if addr := c.String(name); addr != "" {
+		return listenAddr{"--" + name, addr}
+	}
+	return listenAddr{"--port", net.JoinHostPort(c.String("ip"), c.String("port"))}
+}
+
+// validateListenerAddrs checks that --https-addr and --http-addr are host:port
+// addresses and only given for the listener that runs, since only one of the
+// two is started.
+func validateListenerAddrs(c *cli.Context) error {
+	for _, flag := range []string{"https-addr", "http-addr"} {
+		if addr := c.String(flag); addr != "" {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return fmt.Errorf("--%s: %w", flag, err)
+			}
+		}
+	}
+	if c.String("https-addr") != "" && c.Bool("trustProxy") {
+		return fmt.Errorf("--https-addr has no effect with --trustProxy, which serves plain HTTP; use --http-addr")
+	}
+	if c.String("http-addr") != "" && !c.Bool("trustProxy") {
+		return fmt.Errorf("--http-addr requires --trustProxy")
+	}
+	return nil
+}
+
+// clearnetAddrs lists the TCP addresses startConfiguredServers will listen on,
+// including the loopback port, one above the clearnet listener's, that Tor
+// forwards the onion service to. The I2P transport is reached through its SAM tunnel and binds
+// nothing locally.
+func clearnetAddrs(c *cli.Context) []listenAddr {
+	addrs := []listenAddr{clearnetListenAddr(c)}
+	if c.Bool("onion") {
+		if port, err := calculateOnionPort(c); err == nil {
+			addrs = append(addrs, listenAddr{"--onion", net.JoinHostPort("127.0.0.1", strconv.Itoa(port))})
+		}
+	}
+	if c.String("admin-addr") != "" {
+		addrs = append(addrs, listenAddr{"--admin-addr", c.String("admin-addr")})
+	}
+	if c.Bool("metrics") && c.String("metrics-addr") != "" {
+		addrs = append(addrs, listenAddr{"--metrics-addr", c.String("metrics-addr")})
+	}
+	return addrs
+}
+
+// checkPortsFree binds every address in addrs at once and releases them again,
+// so a port that is taken, or given twice, is reported before any transport
+// or tunnel is started.
+func checkPortsFree(addrs []listenAddr) error {
+	var listeners []net.Listener
+	defer func() {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+	}()
+	for _, a := range addrs {
+		ln, err := net.Listen("tcp", a.addr)
+		if err != nil {
+			if errors.Is(err, syscall.EADDRINUSE) {
+				_, port, _ := net.SplitHostPort(a.addr)
+				return fmt.Errorf("%s: port %s already in use", a.flag, port)
+			}
+			return fmt.Errorf("%s: cannot listen on %s: %w", a.flag, a.addr, err)
+		}
+		listeners = append(listeners, ln)
 	}
+	return nil
 }
 
 // startConfiguredServers starts all enabled server protocols (Onion, I2P, HTTP/HTTPS) with proper coordination.
-// It installs an OS signal handler so that SIGINT or SIGTERM triggers a graceful shutdown of all servers.
-func startConfiguredServers(c *cli.Context, tlsConfig *tlsConfiguration, i2pkey i2pkeys.I2PKeys, reseeder *reseed.ReseederImpl) {
+// It installs an OS signal handler so that SIGINT or SIGTERM triggers a graceful shutdown of all servers,
+// which closes their I2P and Tor tunnels, followed by the reseeder's rebuild loop.
+// The clearnet ports are checked first, so a port in use fails startup before anything is listening.
+func startConfiguredServers(c *cli.Context, tlsConfig *tlsConfiguration, i2pkey i2pkeys.I2PKeys, reseeder *reseed.ReseederImpl, geo geoConfig) error {
+	if err := checkPortsFree(clearnetAddrs(c)); err != nil {
+		fmt.Println(err)
+		return err
+	}
+
 	ctx, cancel, wg, errChan := setupServerContext()
 	defer cancel()
 
@@ -1057,14 +2078,64 @@ func startConfiguredServers(c *cli.Context, tlsConfig *tlsConfiguration, i2pkey
 		}
 	}()
 
-	startOnionServer(ctx, c, tlsConfig, reseeder, wg, errChan)
-	startI2PServer(ctx, c, tlsConfig, i2pkey, reseeder, wg, errChan)
-	startHTTPServer(ctx, c, tlsConfig, reseeder, wg, errChan)
+	// Re-read the friends list on SIGHUP, the usual reload signal
+	go func() {
+		hupChan := make(chan os.Signal, 1)
+		signal.Notify(hupChan, syscall.SIGHUP)
+		defer signal.Stop(hupChan)
+		for {
+			select {
+			case <-hupChan:
+				if err := reloadReseeds(c); err != nil {
+					lgr.WithError(err).Warn("Friends reload failed, keeping the current list")
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	reseed.DefaultHealth.WarmupGrace = c.Duration("warmup-grace")
+	reseed.DefaultHealth.ServeStale = c.Bool("serve-stale-during-warmup")
+	expectTransports(reseed.DefaultHealth, c)
+
+	// Keep checking the clock and sampling the heap until shutdown
+	if interval := c.Duration("ntp-interval"); reseeder.ClockCheck != nil && interval > 0 {
+		go reseeder.ClockCheck.Watch(ctx, interval)
+	}
+	if reseeder.MemoryGuard != nil {
+		go reseeder.MemoryGuard.Watch(ctx, c.Duration("shed-memory-interval"))
+	}
+
+	// One blacklist serves every transport, so that edits made through the
+	// admin endpoints apply everywhere and --blacklist-url is fetched once
+	blacklist := newServerBlacklist(ctx, c)
+	startOnionServer(ctx, c, tlsConfig, reseeder, blacklist, geo, wg, errChan)
+	startI2PServer(ctx, c, tlsConfig, i2pkey, reseeder, blacklist, geo, wg, errChan)
+	startHTTPServer(ctx, c, tlsConfig, reseeder, blacklist, geo, wg, errChan)
+	startAdminServer(ctx, c, reseeder, blacklist, wg, errChan)
+	startMetricsServer(ctx, c, reseeder, wg, errChan)
+
+	// A server that fails takes the others down with it
+	serverErr := waitForServerCompletion(wg, errChan)
+	cancel()
 
-	waitForServerCompletion(wg, errChan)
+	// Every server has shut down, or, when one failed, the others are
+	// shutting down on the cancelled context; either way let a rebuild in
+	// progress finish rather than cutting it off when the process exits
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer stopCancel()
+	if err := reseeder.Stop(stopCtx); err != nil {
+		lgr.WithError(err).Warn("Rebuild still running at shutdown")
+	}
+	if serverErr != nil {
+		return serverErr
+	}
+	lgr.Info("Reseed server stopped")
+	return nil
 }
 
-func getSupplementalNetDb(remote, password, path, samaddr string) {
+func getSupplementalNetDb(remote, password, path, samaddr string, updated func()) {
 	log.Println("Remote NetDB Update Loop")
 	for {
 		if err := downloadRemoteNetDB(remote, password, path, samaddr); err != nil {
@@ -1072,6 +2143,7 @@ func getSupplementalNetDb(remote, password, path, samaddr string) {
 			time.Sleep(time.Second * 30)
 		} else {
 			log.Println("Success downloading remote netDb", err)
+			updated()
 			time.Sleep(time.Minute * 30)
 		}
 	}
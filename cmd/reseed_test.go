@@ -0,0 +1,519 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/reseed"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+func TestNewTransportSelector(t *testing.T) {
+	selector, err := newTransportSelector("SSU2", 0.5)
+	if err != nil {
+		t.Fatalf("newTransportSelector() error: %v", err)
+	}
+	if selector.Transport != "ssu2" || selector.Share != 0.5 {
+		t.Errorf("Unexpected selector %+v", selector)
+	}
+
+	for _, tt := range []struct {
+		transport string
+		share     float64
+	}{
+		{"ssu", 0.5},
+		{"ntcp2", 0},
+		{"ntcp2", 1.5},
+	} {
+		if _, err := newTransportSelector(tt.transport, tt.share); err == nil {
+			t.Errorf("Expected error for transport %q share %v", tt.transport, tt.share)
+		}
+	}
+}
+
+func TestSetupRemoteNetDBSharing_NetDbReadOnly(t *testing.T) {
+	app := cli.NewApp()
+	app.Name = "test"
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{Name: "share-peer"},
+		&cli.StringFlag{Name: "share-password"},
+		&cli.StringFlag{Name: "netdb"},
+		&cli.StringFlag{Name: "samaddr"},
+		netDbReadOnlyFlag(),
+	}
+	app.Action = setupRemoteNetDBSharing
+
+	err := app.Run([]string{"test", "--share-peer=example.b32.i2p", "--netdb=" + t.TempDir(), "--netdb-readonly"})
+	if !errors.Is(err, errNetDbReadOnly) {
+		t.Fatalf("Expected errNetDbReadOnly, got: %v", err)
+	}
+}
+
+func TestValidateCanonicalURL(t *testing.T) {
+	tests := []struct {
+		canonical string
+		sitemap   bool
+		wantErr   bool
+	}{
+		{"", false, false},
+		{"", true, true},
+		{"https://reseed.example.org", true, false},
+		{"http://reseed.example.org/netdb/", false, false},
+		{"reseed.example.org", false, true},
+		{"ftp://reseed.example.org", false, true},
+	}
+	for _, tt := range tests {
+		if err := validateCanonicalURL(tt.canonical, tt.sitemap); (err != nil) != tt.wantErr {
+			t.Errorf("validateCanonicalURL(%q, %v) error = %v, wantErr %v", tt.canonical, tt.sitemap, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateAdminAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"127.0.0.1:8444", false},
+		{"[::1]:8444", false},
+		{"localhost:8444", false},
+		{":8444", true},
+		{"0.0.0.0:8444", true},
+		{"192.0.2.7:8444", true},
+		{"reseed.example.org:8444", true},
+		{"127.0.0.1", true},
+	}
+	for _, tt := range tests {
+		if err := validateAdminAddr(tt.addr); (err != nil) != tt.wantErr {
+			t.Errorf("validateAdminAddr(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateEphemeral(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "signer.pem")
+	cert := filepath.Join(dir, "tls.crt")
+	tlsKey := filepath.Join(dir, "tls.pem")
+	for _, path := range []string{key, cert, tlsKey} {
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"all provided", []string{"--key=" + key, "--tlsCert=" + cert, "--tlsKey=" + tlsKey}, ""},
+		{"tls behind proxy", []string{"--key=" + key, "--trustProxy"}, ""},
+		{"missing signing key", []string{"--key=" + filepath.Join(dir, "none.pem"), "--trustProxy"}, "signing key"},
+		{"missing tls cert", []string{"--key=" + key, "--tlsKey=" + tlsKey}, "TLS certificate"},
+		{"missing onion key", []string{"--key=" + key, "--trustProxy", "--onion", "--onionKey=" + filepath.Join(dir, "onion.key")}, "onion key"},
+		{"acme", []string{"--key=" + key, "--trustProxy", "--acme"}, "--acme"},
+		{"audit log", []string{"--key=" + key, "--trustProxy", "--audit-log=audit.jsonl"}, "--audit-log"},
+		{"pid file", []string{"--key=" + key, "--trustProxy", "--pid-file=reseed.pid"}, "--pid-file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := cli.NewApp()
+			app.Name = "test"
+			app.Flags = []cli.Flag{
+				&cli.StringFlag{Name: "key"},
+				&cli.StringFlag{Name: "tlsHost", Value: "reseed.example.org"},
+				&cli.StringFlag{Name: "tlsCert"},
+				&cli.StringFlag{Name: "tlsKey"},
+				&cli.BoolFlag{Name: "trustProxy"},
+				&cli.BoolFlag{Name: "onion"},
+				&cli.StringFlag{Name: "onionKey"},
+				&cli.BoolFlag{Name: "i2p"},
+				&cli.StringFlag{Name: "i2pKeys"},
+				&cli.BoolFlag{Name: "acme"},
+				&cli.StringFlag{Name: "share-peer"},
+				&cli.StringFlag{Name: "audit-log"},
+				&cli.StringFlag{Name: "pid-file"},
+			}
+			app.Action = func(c *cli.Context) error {
+				return validateEphemeral(c, "you@mail.i2p")
+			}
+
+			err := app.Run(append([]string{"test"}, tt.args...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error mentioning %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadMatchingSignerCert(t *testing.T) {
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certDer, err := su3.NewSigningCertificate("you@mail.i2p", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "you_at_mail.i2p.crt")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadMatchingSignerCert(path, privKey); err != nil {
+		t.Errorf("Expected the certificate to match its key, got: %v", err)
+	}
+	if _, err := loadMatchingSignerCert(path, otherKey); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected a mismatch error, got: %v", err)
+	}
+}
+
+func TestSignerCertValidity(t *testing.T) {
+	now := time.Now()
+	cert := &x509.Certificate{NotBefore: now.Add(-time.Hour), NotAfter: now.Add(10 * 24 * time.Hour)}
+	tests := []struct {
+		name    string
+		at      time.Time
+		window  time.Duration
+		wantErr string
+	}{
+		{"valid", now, 24 * time.Hour, ""},
+		{"inside window", now, 30 * 24 * time.Hour, "expires on"},
+		{"expired", now.Add(11 * 24 * time.Hour), 0, "expired on"},
+		{"not yet valid", now.Add(-2 * time.Hour), 0, "not valid until"},
+	}
+	for _, tt := range tests {
+		err := signerCertValidity(cert, tt.at, tt.window)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestCheckSignerCert(t *testing.T) {
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certDer, err := su3.NewSigningCertificate("you@mail.i2p", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "you_at_mail.i2p.crt")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(now time.Time, args ...string) error {
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = []cli.Flag{
+			&cli.StringFlag{Name: "signer-cert"},
+			&cli.DurationFlag{Name: "signer-cert-expiry-window", Value: 30 * 24 * time.Hour},
+			&cli.BoolFlag{Name: "require-valid-signer-cert"},
+		}
+		app.Action = func(c *cli.Context) error {
+			return checkSignerCert(c, "you@mail.i2p", privKey, now)
+		}
+		return app.Run(append([]string{"test"}, args...))
+	}
+
+	// Generated certificates are valid for ten years
+	if err := run(time.Now(), "--signer-cert="+path, "--require-valid-signer-cert"); err != nil {
+		t.Errorf("Expected a fresh certificate to pass, got %v", err)
+	}
+	nearExpiry := time.Now().AddDate(10, 0, -7)
+	if err := run(nearExpiry, "--signer-cert="+path); err != nil {
+		t.Errorf("Expected only a warning without --require-valid-signer-cert, got %v", err)
+	}
+	if err := run(nearExpiry, "--signer-cert="+path, "--require-valid-signer-cert"); err == nil {
+		t.Error("Expected a certificate inside the expiry window to be refused")
+	}
+	if err := run(time.Now(), "--signer-cert="+filepath.Join(t.TempDir(), "missing.crt"), "--require-valid-signer-cert"); err == nil {
+		t.Error("Expected a missing certificate to be refused")
+	}
+}
+
+func TestSetupClockCheck(t *testing.T) {
+	run := func(args ...string) error {
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = []cli.Flag{
+			&cli.StringFlag{Name: "ntp-server"},
+			&cli.DurationFlag{Name: "max-clock-skew", Value: 30 * time.Second},
+			&cli.DurationFlag{Name: "ntp-interval"},
+			&cli.BoolFlag{Name: "require-clock-sync"},
+		}
+		app.Action = func(c *cli.Context) error {
+			_, err := setupClockCheck(c)
+			return err
+		}
+		return app.Run(append([]string{"test"}, args...))
+	}
+
+	if err := run(); err != nil {
+		t.Errorf("Expected no check without --ntp-server, got %v", err)
+	}
+	if err := run("--require-clock-sync"); err == nil {
+		t.Error("Expected --require-clock-sync without --ntp-server to be refused")
+	}
+	// Nothing answers NTP on port 1, so the clock cannot be verified
+	if err := run("--ntp-server=127.0.0.1:1"); err != nil {
+		t.Errorf("Expected only a warning without --require-clock-sync, got %v", err)
+	}
+	if err := run("--ntp-server=127.0.0.1:1", "--require-clock-sync"); err == nil {
+		t.Error("Expected an unverifiable clock to be refused with --require-clock-sync")
+	}
+}
+
+func TestSetupMemoryGuard(t *testing.T) {
+	run := func(args ...string) (*reseed.MemoryGuard, error) {
+		var guard *reseed.MemoryGuard
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = []cli.Flag{
+			&cli.IntFlag{Name: "shed-memory"},
+			&cli.DurationFlag{Name: "shed-memory-interval", Value: time.Hour},
+		}
+		app.Action = func(c *cli.Context) error {
+			var err error
+			guard, err = setupMemoryGuard(c)
+			return err
+		}
+		err := app.Run(append([]string{"test"}, args...))
+		return guard, err
+	}
+
+	if guard, err := run(); guard != nil || err != nil {
+		t.Errorf("Expected no guard without --shed-memory, got %v, %v", guard, err)
+	}
+	if guard, err := run("--shed-memory=256"); err != nil || guard == nil || guard.Limit != 256<<20 {
+		t.Errorf("Expected a 256 MiB guard, got %+v, %v", guard, err)
+	}
+	if _, err := run("--shed-memory=-1"); err == nil {
+		t.Error("Expected a negative --shed-memory to be refused")
+	}
+	if _, err := run("--shed-memory=256", "--shed-memory-interval=0s"); err == nil {
+		t.Error("Expected a zero --shed-memory-interval to be refused")
+	}
+}
+
+func TestClearnetListenAddr(t *testing.T) {
+	run := func(args ...string) (listenAddr, error) {
+		var got listenAddr
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = NewReseedCommand().Flags
+		app.Action = func(c *cli.Context) error {
+			if err := validateListenerAddrs(c); err != nil {
+				return err
+			}
+			got = clearnetListenAddr(c)
+			return nil
+		}
+		err := app.Run(append([]string{"test", "--ip=192.0.2.1", "--port=8443"}, args...))
+		return got, err
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want listenAddr
+	}{
+		{nil, listenAddr{"--port", "192.0.2.1:8443"}},
+		{[]string{"--https-addr=203.0.113.5:443"}, listenAddr{"--https-addr", "203.0.113.5:443"}},
+		{[]string{"--trustProxy"}, listenAddr{"--port", "192.0.2.1:8443"}},
+		{[]string{"--trustProxy", "--http-addr=127.0.0.1:8080"}, listenAddr{"--http-addr", "127.0.0.1:8080"}},
+	} {
+		got, err := run(tt.args...)
+		if err != nil || got != tt.want {
+			t.Errorf("%v: got %+v, %v, want %+v", tt.args, got, err, tt.want)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"--https-addr=203.0.113.5"},
+		{"--trustProxy", "--https-addr=203.0.113.5:443"},
+		{"--http-addr=127.0.0.1:8080"},
+	} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("%v: expected the address flags to be refused", args)
+		}
+	}
+}
+
+func TestCalculateOnionPort(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want int
+	}{
+		{nil, 8444},
+		{[]string{"--https-addr=203.0.113.5:443"}, 444},
+		{[]string{"--trustProxy"}, 8444},
+		{[]string{"--trustProxy", "--http-addr=127.0.0.1:8080"}, 8081},
+	} {
+		var got int
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = NewReseedCommand().Flags
+		app.Action = func(c *cli.Context) (err error) {
+			got, err = calculateOnionPort(c)
+			return err
+		}
+		if err := app.Run(append([]string{"test", "--port=8443"}, tt.args...)); err != nil || got != tt.want {
+			t.Errorf("%v: got %d, %v, want %d", tt.args, got, err, tt.want)
+		}
+	}
+}
+
+func TestCheckPortsFree(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+	free := unusedAddr(t)
+
+	if err := checkPortsFree([]listenAddr{{"--port", free}}); err != nil {
+		t.Errorf("Expected a free port to pass, got %v", err)
+	}
+	_, port, _ := net.SplitHostPort(taken.Addr().String())
+	err = checkPortsFree([]listenAddr{{"--port", free}, {"--admin-addr", taken.Addr().String()}})
+	if err == nil || err.Error() != "--admin-addr: port "+port+" already in use" {
+		t.Errorf("Expected the taken admin port to be reported, got %v", err)
+	}
+	if err := checkPortsFree([]listenAddr{{"--port", free}, {"--admin-addr", free}}); err == nil {
+		t.Error("Expected the same address given twice to be refused")
+	}
+	// The free port was released again for the real listener
+	ln, err := net.Listen("tcp", free)
+	if err != nil {
+		t.Errorf("Expected the checked port to be released, got %v", err)
+	} else {
+		ln.Close()
+	}
+}
+
+func TestClearnetAddrs(t *testing.T) {
+	run := func(args ...string) []listenAddr {
+		var addrs []listenAddr
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = NewReseedCommand().Flags
+		app.Action = func(c *cli.Context) error {
+			addrs = clearnetAddrs(c)
+			return nil
+		}
+		if err := app.Run(append([]string{"test", "--ip=127.0.0.1", "--port=8443"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		return addrs
+	}
+
+	if addrs := run(); len(addrs) != 1 || addrs[0] != (listenAddr{"--port", "127.0.0.1:8443"}) {
+		t.Errorf("Expected only --port, got %v", addrs)
+	}
+	want := []listenAddr{{"--port", "127.0.0.1:8443"}, {"--onion", "127.0.0.1:8444"}, {"--admin-addr", "127.0.0.1:9000"}}
+	if addrs := run("--onion", "--admin-addr=127.0.0.1:9000"); !slices.Equal(addrs, want) {
+		t.Errorf("Expected %v, got %v", want, addrs)
+	}
+	want = []listenAddr{{"--port", "127.0.0.1:8443"}, {"--metrics-addr", "127.0.0.1:9100"}}
+	if addrs := run("--metrics", "--metrics-addr=127.0.0.1:9100"); !slices.Equal(addrs, want) {
+		t.Errorf("Expected %v, got %v", want, addrs)
+	}
+}
+
+// unusedAddr returns a loopback address nothing is listening on.
+func unusedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestProvidedReseeds_FriendsFile(t *testing.T) {
+	orig := reseed.ReseedPeers()
+	t.Cleanup(func() { reseed.SetReseedPeers(orig) })
+
+	path := filepath.Join(t.TempDir(), "friends.txt")
+	run := func(args ...string) error {
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = []cli.Flag{
+			&cli.StringSliceFlag{Name: "friends", Value: cli.NewStringSlice("https://flag.example.org/")},
+			&cli.StringFlag{Name: "friends-file"},
+		}
+		app.Action = func(c *cli.Context) error { return reloadReseeds(c) }
+		return app.Run(append([]string{"test"}, args...))
+	}
+
+	if err := run(); err != nil || reseed.ReseedPeers()[0] != "https://flag.example.org/" {
+		t.Fatalf("Expected the --friends list, got %v, %v", reseed.ReseedPeers(), err)
+	}
+
+	os.WriteFile(path, []byte("https://one.example.org/\n"), 0o644)
+	if err := run("--friends-file=" + path); err != nil || len(reseed.ReseedPeers()) != 1 || reseed.ReseedPeers()[0] != "https://one.example.org/" {
+		t.Fatalf("Expected the file to replace --friends, got %v, %v", reseed.ReseedPeers(), err)
+	}
+
+	// Editing the file and reloading picks up the change
+	os.WriteFile(path, []byte("https://one.example.org/\nhttps://two.example.org/\n"), 0o644)
+	if err := run("--friends-file=" + path); err != nil || len(reseed.ReseedPeers()) != 2 {
+		t.Fatalf("Expected the edited file to be read, got %v, %v", reseed.ReseedPeers(), err)
+	}
+
+	// A broken file is reported and leaves the list alone
+	os.WriteFile(path, []byte("ftp://bad.example.org/\n"), 0o644)
+	if err := run("--friends-file=" + path); err == nil || !strings.Contains(err.Error(), "--friends-file") {
+		t.Errorf("Expected a --friends-file error, got %v", err)
+	}
+	if len(reseed.ReseedPeers()) != 2 {
+		t.Errorf("Expected the previous list to be kept, got %v", reseed.ReseedPeers())
+	}
+}
+
+func TestWaitForServerCompletion(t *testing.T) {
+	_, cancel, wg, errChan := setupServerContext()
+	defer cancel()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errChan <- errors.New("listener failed")
+	}()
+	if err := waitForServerCompletion(wg, errChan); err == nil || err.Error() != "listener failed" {
+		t.Errorf("Expected the server error to be returned, got %v", err)
+	}
+
+	_, cancel, wg, errChan = setupServerContext()
+	defer cancel()
+	if err := waitForServerCompletion(wg, errChan); err != nil {
+		t.Errorf("Expected no error once every server has stopped, got %v", err)
+	}
+}
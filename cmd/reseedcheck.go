@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/reseed"
+)
+
+// reseedCheck runs reseed --check: it loads the signing key, signer certificate
+// and TLS certificate the server would use and scans the netDb once, writing
+// what it found to w. Nothing is generated, no port is bound and no rebuild is
+// started, so it is safe to run against a live deployment's configuration
+// before rolling it out. Every problem is reported, and any of them makes it
+// return an error so the process exits non-zero.
+func reseedCheck(c *cli.Context, w io.Writer, netdbDir, signerID string) error {
+	var problems []error
+	fail := func(err error) {
+		fmt.Fprintln(w, "FAIL:", err)
+		problems = append(problems, err)
+	}
+	now := time.Now()
+
+	signerKey := c.String("key")
+	if signerKey == "" {
+		signerKey = signerFile(signerID) + ".pem"
+	}
+	privKey, err := loadPrivateKey(signerKey)
+	if err == nil {
+		err = validateSigningKey(c, privKey)
+	}
+	if err != nil {
+		fail(fmt.Errorf("signing key %s: %w", signerKey, err))
+		privKey = nil
+	} else if bits, err := reseed.KeyStrength(privKey.Public()); err == nil {
+		fmt.Fprintf(w, "Signing key:        %s (%d-bit RSA-equivalent)\n", signerKey, bits)
+	}
+
+	if privKey != nil {
+		certPath := signerCertPath(c, signerID)
+		cert, err := loadMatchingSignerCert(certPath, privKey)
+		if err == nil {
+			err = signerCertValidity(cert, now, c.Duration("signer-cert-expiry-window"))
+		}
+		if err != nil {
+			fail(fmt.Errorf("signer certificate %s: %w", certPath, err))
+		} else {
+			fmt.Fprintf(w, "Signer certificate: %s, expires %s\n", certPath, cert.NotAfter.Format(time.DateOnly))
+		}
+	}
+
+	if err := checkTLSFiles(c, w, now); err != nil {
+		fail(err)
+	}
+
+	netdb := newReseedNetDb(c, netdbDir)
+	// Only counts are needed, so do not hold every file in memory
+	netdb.LazyData = true
+	check, err := netdb.Check(c.Int("numRi"), c.Float64("min-routerinfo-fraction"))
+	if err != nil {
+		fail(fmt.Errorf("netDb %s: %w", netdbDir, err))
+	} else if err := printNetDbCheck(w, netdbDir, check); err != nil {
+		problems = append(problems, err)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("--check found %d problem(s): %w", len(problems), errors.Join(problems...))
+	}
+	fmt.Fprintln(w, "OK: the configuration is ready to serve")
+	return nil
+}
+
+// checkTLSFiles loads the --tlsCert and --tlsKey pair the HTTPS server would
+// use, reporting when it expires, and refuses it as the server would at startup
+// if TLS cannot serve its key or the key is below --min-key-bits. It does nothing when the server would not
+// serve TLS itself, and only notes missing files under --acme, which requests
+// them at startup.
+func checkTLSFiles(c *cli.Context, w io.Writer, now time.Time) error {
+	tlsHost := c.String("tlsHost")
+	if tlsHost == "" || c.Bool("trustProxy") {
+		fmt.Fprintln(w, "TLS certificate:    not used")
+		return nil
+	}
+	config := &tlsConfiguration{tlsHost: tlsHost}
+	setupTLSKeyPaths(c, config)
+	setupTLSCertPaths(c, config)
+	if c.Bool("acme") && (!fileExists(config.tlsCert) || !fileExists(config.tlsKey)) {
+		fmt.Fprintf(w, "TLS certificate:    %s, requested from ACME at startup\n", config.tlsCert)
+		return nil
+	}
+
+	pair, err := tls.LoadX509KeyPair(config.tlsCert, config.tlsKey)
+	if err != nil {
+		return fmt.Errorf("TLS certificate %s: %w", config.tlsCert, err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("TLS certificate %s: %w", config.tlsCert, err)
+	}
+	if now.After(leaf.NotAfter) {
+		return fmt.Errorf("TLS certificate %s expired on %s", config.tlsCert, leaf.NotAfter.Format(time.DateOnly))
+	}
+	if err := reseed.CheckTLSCertificate(leaf, nil, c.Int("min-key-bits")); err != nil {
+		return fmt.Errorf("%s: %w", config.tlsCert, err)
+	}
+	fmt.Fprintf(w, "TLS certificate:    %s, expires %s\n", config.tlsCert, leaf.NotAfter.Format(time.DateOnly))
+	return nil
+}
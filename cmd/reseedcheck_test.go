@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+func TestReseedCheck(t *testing.T) {
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "you_at_mail.i2p.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privKey)}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	certDer, err := su3.NewSigningCertificate("you@mail.i2p", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := filepath.Join(dir, "you_at_mail.i2p.crt")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	netdbDir := t.TempDir()
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		app := cli.NewApp()
+		app.Name = "test"
+		app.Flags = NewReseedCommand().Flags
+		app.Action = func(c *cli.Context) error {
+			return reseedCheck(c, &out, netdbDir, "you@mail.i2p")
+		}
+		err := app.Run(append([]string{"test", "--key=" + keyPath, "--signer-cert=" + certPath}, args...))
+		return out.String(), err
+	}
+
+	// An empty netDb only passes when no routerInfos are required
+	out, err := run("--trustProxy", "--numRi=0")
+	if err != nil {
+		t.Fatalf("Expected a valid configuration to pass, got %v:\n%s", err, out)
+	}
+	for _, want := range []string{"2048-bit", "Signer certificate: " + certPath, "TLS certificate:    not used", "OK:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	out, err = run("--trustProxy")
+	if err == nil || strings.Contains(out, "OK:") {
+		t.Errorf("Expected an empty netDb to fail the check, got %v:\n%s", err, out)
+	}
+
+	out, err = run("--tlsHost=reseed.example.i2p", "--tlsCert="+filepath.Join(dir, "missing.crt"), "--tlsKey="+filepath.Join(dir, "missing.pem"), "--numRi=0")
+	if err == nil || !strings.Contains(out, "FAIL: TLS certificate") {
+		t.Errorf("Expected missing TLS files to fail the check, got %v:\n%s", err, out)
+	}
+
+	// The TLS key floor is the one the server applies at startup
+	tlsKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	tlsDer, err := x509.CreateCertificate(rand.Reader, template, template, &tlsKey.PublicKey, tlsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsCert, tlsKeyPath := filepath.Join(dir, "weak.crt"), filepath.Join(dir, "weak.pem")
+	if err := os.WriteFile(tlsCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsDer}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tlsKeyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(tlsKey)}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err = run("--tlsHost=reseed.example.i2p", "--tlsCert="+tlsCert, "--tlsKey="+tlsKeyPath, "--numRi=0", "--min-key-bits=0")
+	if err == nil || !strings.Contains(out, "below the 2048-bit minimum") {
+		t.Errorf("Expected a 1024-bit TLS key to fail the check, got %v:\n%s", err, out)
+	}
+
+	out, err = run("--trustProxy", "--numRi=0", "--key="+filepath.Join(dir, "missing.pem"), "--signer-cert="+filepath.Join(dir, "missing.crt"))
+	if err == nil || !strings.Contains(out, "FAIL: signing key") {
+		t.Errorf("Expected a missing signing key to fail the check, got %v:\n%s", err, out)
+	}
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/reseed"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+// NewSelftestBootstrapCommand creates a new CLI command that fetches a bundle
+// from a reseed server and tries to connect to a sample of the routers in it,
+// to tell whether the bundle would actually bootstrap a new router.
+func NewSelftestBootstrapCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "selftest-bootstrap",
+		Usage: "Fetch a reseed bundle and check how many of its routers are reachable",
+		Description: `Download i2pseeds.su3 from --url the way a router does, unzip it, and open
+a TCP connection to the NTCP2 address of up to --sample of its RouterInfos.
+Routers that publish no NTCP2 address, such as SSU2-only or firewalled ones,
+are counted but not probed. With --min-reachable, exits with status 1 when a
+smaller share of the sampled routers answered.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "url",
+				Value: "https://127.0.0.1:8443/i2pseeds.su3",
+				Usage: "URL of the reseed bundle to test",
+			},
+			&cli.BoolFlag{
+				Name:  "insecure",
+				Usage: "Skip TLS certificate verification, for servers with a self-signed certificate",
+			},
+			&cli.IntFlag{
+				Name:  "sample",
+				Value: 20,
+				Usage: "Number of routers to probe (0 probes every testable router)",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 5 * time.Second,
+				Usage: "How long to wait for each router to accept a connection",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Value: 8,
+				Usage: "Number of routers to probe at once",
+			},
+			&cli.Float64Flag{
+				Name:  "min-reachable",
+				Usage: "Fail unless at least this percentage of sampled routers is reachable (0 only reports)",
+			},
+		},
+		Action: selftestBootstrapAction,
+	}
+}
+
+func selftestBootstrapAction(c *cli.Context) error {
+	if c.Int("sample") < 0 {
+		return fmt.Errorf("--sample cannot be negative")
+	}
+	if c.Duration("timeout") <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	if p := c.Float64("min-reachable"); p < 0 || p > 100 {
+		return fmt.Errorf("--min-reachable must be between 0 and 100")
+	}
+
+	su3File, err := fetchBundle(c.String("url"), c.Bool("insecure"))
+	if err != nil {
+		return err
+	}
+	targets, err := reseed.BundleTargets(su3File.Content)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("bundle from %s has no parseable RouterInfos", c.String("url"))
+	}
+
+	report := reseed.ProbeTargets(context.Background(), targets, c.Int("sample"), c.Int("concurrency"), c.Duration("timeout"))
+	return printBootstrapReport(os.Stdout, c.String("url"), report, c.Float64("min-reachable"))
+}
+
+// fetchBundle downloads and parses a reseed SU3 with the User-Agent routers use.
+func fetchBundle(url string, insecure bool) (*su3.File, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", reseed.I2pUserAgent)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024))
+	if err != nil {
+		return nil, err
+	}
+
+	su3File := su3.New()
+	if err := su3File.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("%s is not a valid su3: %w", url, err)
+	}
+	if su3File.ContentType != su3.ContentTypeReseed {
+		return nil, fmt.Errorf("%s is a %s su3, not a reseed bundle", url, su3.ContentTypeName(su3File.ContentType))
+	}
+	return su3File, nil
+}
+
+// printBootstrapReport writes the reachability counts and each failed probe,
+// returning an error when fewer than minPercent of the sampled routers answered.
+func printBootstrapReport(w io.Writer, url string, report reseed.BootstrapReport, minPercent float64) error {
+	fmt.Fprintf(w, "Bundle:      %s\n", url)
+	fmt.Fprintf(w, "RouterInfos: %d\n", report.Routers)
+	fmt.Fprintf(w, "No NTCP2:    %d (not probed)\n", report.Untestable)
+	fmt.Fprintf(w, "Sampled:     %d\n", report.Sampled)
+	fmt.Fprintf(w, "Reachable:   %d (%.1f%%)\n", report.Reachable, report.ReachablePercent())
+	for _, probe := range report.Probes {
+		if probe.Err != nil {
+			fmt.Fprintf(w, "  %s: %s: %s\n", strings.TrimSuffix(probe.Name, ".dat"), probe.Addr, probe.Err)
+		}
+	}
+
+	if report.Sampled == 0 {
+		fmt.Fprintln(w, "FAIL: no router in the bundle publishes an NTCP2 address to probe")
+		return fmt.Errorf("bundle from %s has no routers to probe", url)
+	}
+	if minPercent > 0 && report.ReachablePercent() < minPercent {
+		fmt.Fprintf(w, "FAIL: fewer than %.1f%% of sampled routers are reachable\n", minPercent)
+		return fmt.Errorf("%.1f%% of sampled routers reachable, need %.1f%%", report.ReachablePercent(), minPercent)
+	}
+	fmt.Fprintln(w, "OK")
+	return nil
+}
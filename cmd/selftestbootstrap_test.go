@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"i2pgit.org/go-i2p/reseed-tools/reseed"
+)
+
+func TestPrintBootstrapReport(t *testing.T) {
+	report := reseed.BootstrapReport{
+		Routers: 61, Untestable: 11, Sampled: 4, Reachable: 3,
+		Probes: []reseed.BootstrapProbe{
+			{Name: "routerInfo-a.dat", Addr: "192.0.2.1:9000"},
+			{Name: "routerInfo-b.dat", Addr: "192.0.2.2:9000", Err: errors.New("connection refused")},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := printBootstrapReport(&out, "https://reseed.example/i2pseeds.su3", report, 50); err != nil {
+		t.Errorf("Expected 75%% to pass a 50%% minimum, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Reachable:   3 (75.0%)") || !strings.Contains(out.String(), "routerInfo-b: 192.0.2.2:9000: connection refused") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "routerInfo-a:") {
+		t.Errorf("Expected only failed probes to be listed, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := printBootstrapReport(&out, "https://reseed.example/i2pseeds.su3", report, 80); err == nil || !strings.Contains(out.String(), "FAIL:") {
+		t.Errorf("Expected 75%% to fail an 80%% minimum, got %v:\n%s", err, out.String())
+	}
+
+	out.Reset()
+	if err := printBootstrapReport(&out, "https://reseed.example/i2pseeds.su3", reseed.BootstrapReport{Routers: 5, Untestable: 5}, 0); err == nil {
+		t.Error("Expected a bundle with nothing to probe to fail")
+	}
+}
+
+func TestFetchBundle(t *testing.T) {
+	var userAgent string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		if r.URL.Path != "/i2pseeds.su3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("not an su3"))
+	}))
+	defer srv.Close()
+
+	if _, err := fetchBundle(srv.URL+"/i2pseeds.su3", false); err == nil {
+		t.Error("Expected a self-signed certificate to be refused without --insecure")
+	}
+	if _, err := fetchBundle(srv.URL+"/i2pseeds.su3", true); err == nil || !strings.Contains(err.Error(), "not a valid su3") {
+		t.Errorf("Expected invalid content to be refused, got %v", err)
+	}
+	if userAgent != reseed.I2pUserAgent {
+		t.Errorf("Expected the router User-Agent, got %q", userAgent)
+	}
+	if _, err := fetchBundle(srv.URL+"/missing.su3", true); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 to be reported, got %v", err)
+	}
+}
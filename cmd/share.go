@@ -6,6 +6,8 @@ import (
 
 	"archive/tar"
 	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,7 +17,6 @@ import (
 
 	"github.com/urfave/cli/v3"
 
-	"github.com/go-i2p/checki2cp/getmeanetdb"
 	"github.com/go-i2p/onramp"
 )
 
@@ -24,10 +25,7 @@ import (
 // and download router information from the local netDb directory for network synchronization.
 // Can be used to combine the local netDb with the netDb of a remote I2P router.
 func NewShareCommand() *cli.Command {
-	ndb, err := getmeanetdb.WhereIstheNetDB()
-	if err != nil {
-		lgr.WithError(err).Fatal("Fatal error in share")
-	}
+	ndb := findDefaultNetDbPath()
 	return &cli.Command{
 		Name:   "share",
 		Usage:  "Start a netDb sharing server",
@@ -57,6 +55,14 @@ func NewShareCommand() *cli.Command {
 				Value: "",
 				Usage: "Share the contents of your netDb directory privately over I2P as a tar.gz archive. Will fail is password is blank.",
 			},
+			&cli.IntFlag{
+				Name:  "max-downloads",
+				Usage: "Maximum number of simultaneous share downloads, further requests get 503 (0 for no limit)",
+			},
+			&cli.IntFlag{
+				Name:  "bandwidth-limit",
+				Usage: "Maximum outgoing bytes per second across all share downloads (0 for no limit)",
+			},
 		},
 	}
 }
@@ -69,22 +75,35 @@ type sharer struct {
 	http.Handler
 	Path     string
 	Password string
+	// slots bounds simultaneous downloads, nil when unlimited
+	slots chan struct{}
+	// limiter caps outgoing bandwidth across downloads, nil when unlimited
+	limiter *bandwidthLimiter
 }
 
 func (s *sharer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Extract password from custom reseed-password header
 	p, ok := r.Header[http.CanonicalHeaderKey("reseed-password")]
-	if !ok {
+	if !ok || len(p) == 0 || !s.checkPassword(p[0]) {
 		return
 	}
-	if p[0] != s.Password {
+	lgr.WithField("path", r.URL.Path).Debug("Request path")
+	release, ok := s.acquire()
+	if !ok {
+		lgr.WithField("path", r.URL.Path).Debug("Refusing share request: all download slots in use")
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "too many downloads in progress", http.StatusServiceUnavailable)
 		return
 	}
-	lgr.WithField("path", r.URL.Path).Debug("Request path")
+	defer release()
+	w = s.throttle(w, r)
 	if strings.HasSuffix(r.URL.Path, "tar.gz") {
 		lgr.Debug("Serving netdb")
 		archive, err := walker(s.Path)
 		if err != nil {
+			// never hand out a partial archive as if it were the whole netDb
+			lgr.WithError(err).WithField("netdb", s.Path).Error("Failed to archive netDb for share")
+			http.Error(w, "failed to archive netDb", http.StatusInternalServerError)
 			return
 		}
 		w.Write(archive.Bytes())
@@ -93,6 +112,18 @@ func (s *sharer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.Handler.ServeHTTP(w, r)
 }
 
+// checkPassword reports whether password matches the share password without
+// leaking, through response timing, how much of it or of its length matched.
+// A blank share password never matches, so a misconfigured server stays closed.
+func (s *sharer) checkPassword(password string) bool {
+	if s.Password == "" {
+		return false
+	}
+	// Compare fixed-size digests, since ConstantTimeCompare returns early on a length mismatch
+	got, want := sha256.Sum256([]byte(password)), sha256.Sum256([]byte(s.Password))
+	return subtle.ConstantTimeCompare(got[:], want[:]) == 1
+}
+
 // Sharer creates a new HTTP file server for sharing netDb files over I2P.
 // It sets up a password-protected file system server that can serve router information
 // to other I2P nodes. The netDbDir parameter specifies the directory containing router files.
@@ -113,8 +144,18 @@ func shareAction(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	if c.String("share-password") == "" {
+		return fmt.Errorf("--share-password is required")
+	}
+	if c.Int("max-downloads") < 0 {
+		return fmt.Errorf("--max-downloads cannot be negative")
+	}
+	if c.Int("bandwidth-limit") < 0 {
+		return fmt.Errorf("--bandwidth-limit cannot be negative")
+	}
 	// Create password-protected file server for netDb sharing
 	httpFs := Sharer(netDbDir, c.String("share-password"))
+	httpFs.SetLimits(c.Int("max-downloads"), c.Int("bandwidth-limit"))
 	// Initialize I2P garlic routing for hidden service hosting
 	garlic, err := onramp.NewGarlic("reseed", c.String("samaddr"), onramp.OPT_WIDE)
 	if err != nil {
@@ -199,19 +240,19 @@ func processFileForArchive(tw *tar.Writer, netDbDir, path string, info os.FileIn
 func addFileToArchive(tw *tar.Writer, file *os.File, info os.FileInfo, relativePath string) error {
 	header, err := tar.FileInfoHeader(info, relativePath)
 	if err != nil {
-		lgr.WithError(err).Fatal("Fatal error in share")
-		return err
+		lgr.WithError(err).WithField("file", relativePath).Error("Could not build a tar header for netDb file")
+		return fmt.Errorf("building tar header for %s: %w", relativePath, err)
 	}
 
 	header.Name = relativePath
 	if err = tw.WriteHeader(header); err != nil {
-		lgr.WithError(err).Fatal("Fatal error in share")
-		return err
+		lgr.WithError(err).WithField("file", relativePath).Error("Could not write tar header for netDb file")
+		return fmt.Errorf("writing tar header for %s: %w", relativePath, err)
 	}
 
 	if _, err := io.Copy(tw, file); err != nil {
-		lgr.WithError(err).Fatal("Fatal error in share")
-		return err
+		lgr.WithError(err).WithField("file", relativePath).Error("Could not copy netDb file into the tar archive")
+		return fmt.Errorf("copying %s into the tar archive: %w", relativePath, err)
 	}
 
 	return nil
@@ -5,9 +5,13 @@ import (
 	"bytes"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/urfave/cli/v3"
 )
 
 func TestNewShareCommand(t *testing.T) {
@@ -162,6 +166,19 @@ func TestWalker_NonexistentDirectory(t *testing.T) {
 	}
 }
 
+func TestSharer_ServeHTTP_ArchiveError(t *testing.T) {
+	password := "testpassword"
+	sharer := Sharer("/nonexistent/path/to/nowhere", password)
+
+	req := httptest.NewRequest(http.MethodGet, "/netDb.tar.gz", nil)
+	req.Header.Set("reseed-password", password)
+	w := httptest.NewRecorder()
+	sharer.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 when the netDb cannot be archived, got %d", w.Code)
+	}
+}
+
 // TestShareActionResourceCleanup verifies that resources are properly cleaned up
 // This is a basic test that can't fully test the I2P functionality but ensures
 // the command structure is correct
@@ -177,3 +194,50 @@ func TestShareActionResourceCleanup(t *testing.T) {
 	// 2. That resources are properly released on error paths
 	// 3. That the server can start and stop cleanly
 }
+
+func TestSharer_CheckPassword(t *testing.T) {
+	s := Sharer(t.TempDir(), "testpassword")
+	for password, want := range map[string]bool{
+		"testpassword":  true,
+		"testpasswor":   false,
+		"testpassword1": false,
+		"":              false,
+	} {
+		if got := s.checkPassword(password); got != want {
+			t.Errorf("checkPassword(%q) = %v, want %v", password, got, want)
+		}
+	}
+
+	if Sharer(t.TempDir(), "").checkPassword("") {
+		t.Error("Expected a blank share password to match nothing")
+	}
+}
+
+func TestSharer_ServeHTTP_WrongPassword(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "routerInfo-test.dat"), []byte("test router info data"), 0o644)
+	s := Sharer(dir, "testpassword")
+
+	for _, header := range []string{"", "wrong"} {
+		req := httptest.NewRequest("GET", "/routerInfo-test.dat", nil)
+		if header != "" {
+			req.Header.Set("reseed-password", header)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Body.Len() != 0 {
+			t.Errorf("Expected nothing to be served for password %q, got %q", header, rec.Body.String())
+		}
+	}
+}
+
+func TestShareAction_RequiresPassword(t *testing.T) {
+	app := cli.NewApp()
+	app.Name = "test"
+	app.Flags = NewShareCommand().Flags
+	app.Action = shareAction
+	err := app.Run([]string{"test", "--netdb", t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--share-password") {
+		t.Errorf("Expected a blank --share-password to be refused, got %v", err)
+	}
+}
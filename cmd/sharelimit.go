@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// bandwidthLimiter spreads writes from every download over time so that,
+// together, they stay under a fixed number of bytes per second.
+type bandwidthLimiter struct {
+	bytesPerSecond int
+	mu             sync.Mutex
+	// next is when the bandwidth already handed out has been used up
+	next time.Time
+}
+
+// chunkSize returns how much to write between waits: small enough to keep the
+// rate smooth, large enough not to wake up for every few bytes.
+func (l *bandwidthLimiter) chunkSize() int {
+	return min(max(l.bytesPerSecond/10, 1), 32*1024)
+}
+
+// wait blocks until n more bytes fit under the limit, or ctx is done.
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(n) * time.Second / time.Duration(l.bytesPerSecond))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttledWriter passes a response through a bandwidthLimiter.
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx     context.Context
+	limiter *bandwidthLimiter
+}
+
+func (w throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), w.limiter.chunkSize())]
+		if err := w.limiter.wait(w.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// SetLimits caps the share server at maxDownloads simultaneous requests and
+// bytesPerSecond of outgoing data across all of them, so sharing the netDb
+// leaves room for the reseed service on the same uplink. Zero disables either
+// limit. It must be called before the server starts.
+func (s *sharer) SetLimits(maxDownloads, bytesPerSecond int) {
+	s.slots = nil
+	if maxDownloads > 0 {
+		s.slots = make(chan struct{}, maxDownloads)
+	}
+	s.limiter = nil
+	if bytesPerSecond > 0 {
+		s.limiter = &bandwidthLimiter{bytesPerSecond: bytesPerSecond}
+	}
+}
+
+// acquire takes a download slot, reporting false when all are in use. The
+// returned function gives the slot back.
+func (s *sharer) acquire() (func(), bool) {
+	if s.slots == nil {
+		return func() {}, true
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return func() { <-s.slots }, true
+	default:
+		return nil, false
+	}
+}
+
+// throttle wraps w so writes to it respect the bandwidth limit, if one is set.
+func (s *sharer) throttle(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+	if s.limiter == nil {
+		return w
+	}
+	return throttledWriter{ResponseWriter: w, ctx: r.Context(), limiter: s.limiter}
+}
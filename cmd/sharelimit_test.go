@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSharer_MaxDownloads(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "routerInfo-test.dat"), []byte("test router info data"), 0o644)
+	s := Sharer(dir, "secret")
+	s.SetLimits(1, 0)
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/routerInfo-test.dat", nil)
+		req.Header.Set("reseed-password", "secret")
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get(); rec.Code != http.StatusOK {
+		t.Fatalf("Expected a free slot to be served, got %d", rec.Code)
+	}
+	release, ok := s.acquire()
+	if !ok {
+		t.Fatal("Expected the slot to be free again after the download")
+	}
+	if rec := get(); rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 503 with Retry-After while the slot is taken, got %d", rec.Code)
+	}
+	release()
+	if rec := get(); rec.Code != http.StatusOK {
+		t.Errorf("Expected the released slot to be usable, got %d", rec.Code)
+	}
+}
+
+func TestThrottledWriter(t *testing.T) {
+	limiter := &bandwidthLimiter{bytesPerSecond: 10000}
+	rec := httptest.NewRecorder()
+	w := throttledWriter{ResponseWriter: rec, ctx: context.Background(), limiter: limiter}
+
+	data := bytes.Repeat([]byte("x"), 3000)
+	start := time.Now()
+	if n, err := w.Write(data); err != nil || n != len(data) {
+		t.Fatalf("Write() = %d, %v", n, err)
+	}
+	// 1000-byte chunks at 10000 B/s: the second and third wait 100ms each
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected 3000 bytes at 10000 B/s to be spread out, took %v", elapsed)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), data) {
+		t.Error("Expected the throttled body to be written unchanged")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.ctx = ctx
+	if _, err := w.Write(data); err == nil {
+		t.Error("Expected a cancelled request to stop the write")
+	}
+}
+
+func TestSharer_SetLimitsOff(t *testing.T) {
+	s := Sharer(t.TempDir(), "secret")
+	s.SetLimits(0, 0)
+	if s.slots != nil || s.limiter != nil {
+		t.Error("Expected zero limits to disable limiting")
+	}
+	rec := httptest.NewRecorder()
+	if w := s.throttle(rec, httptest.NewRequest("GET", "/", nil)); w != http.ResponseWriter(rec) {
+		t.Error("Expected an unthrottled writer without a bandwidth limit")
+	}
+}
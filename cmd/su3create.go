@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+// NewSu3CreateCommand creates a new CLI command that signs any content file as
+// an SU3, such as a plugin or a news feed in a format the news command does not
+// build, with the same signing keys the reseed server uses.
+func NewSu3CreateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "create",
+		Usage: "Build a signed su3 of any content and file type",
+		Description: `Wrap --content, unchanged, in an su3 of the given --content-type and
+--file-type and sign it. Content types are unknown, router, plugin, reseed,
+news and blocklist; file types are zip, xml, html, xml.gz, txt.gz, dmg and exe.
+The content is not converted, so it must already be in the named file type.`,
+		Action: su3CreateAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "signer",
+				Value: getDefaultSigner(),
+				Usage: "Your su3 signing ID (ex. something@mail.i2p)",
+			},
+			&cli.StringFlag{
+				Name:  "key",
+				Usage: "Path to your su3 signing private key (defaults to <signer>.pem)",
+			},
+			&cli.StringFlag{
+				Name:  "content",
+				Usage: "Path to the file to wrap in the su3",
+			},
+			&cli.StringFlag{
+				Name:  "content-type",
+				Usage: "su3 content type: unknown, router, plugin, reseed, news or blocklist",
+			},
+			&cli.StringFlag{
+				Name:  "file-type",
+				Usage: "su3 file type of the content: zip, xml, html, xml.gz, txt.gz, dmg or exe",
+			},
+			&cli.StringFlag{
+				Name:  "su3-version",
+				Usage: "Version string to store in the su3 (defaults to the current Unix time, as routers expect for news and reseeds)",
+			},
+			&cli.StringFlag{
+				Name:  "out",
+				Usage: "Path to write the signed su3 (defaults to <content-type>.su3)",
+			},
+			base64Flag("Write the su3 as base64 text instead of binary"),
+		},
+	}
+}
+
+func su3CreateAction(c *cli.Context) error {
+	signerID := c.String("signer")
+	if signerID == "" {
+		return fmt.Errorf("--signer is required")
+	}
+	if c.String("content") == "" {
+		return fmt.Errorf("--content is required")
+	}
+	contentType, err := su3.ParseContentType(c.String("content-type"))
+	if err != nil {
+		return fmt.Errorf("--content-type: %w", err)
+	}
+	fileType, err := su3.ParseFileType(c.String("file-type"))
+	if err != nil {
+		return fmt.Errorf("--file-type: %w", err)
+	}
+
+	keyPath := c.String("key")
+	if keyPath == "" {
+		keyPath = signerFile(signerID) + ".pem"
+	}
+	privKey, err := loadPrivateKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(c.String("content"))
+	if err != nil {
+		lgr.WithError(err).WithField("content", c.String("content")).Error("Failed to read su3 content")
+		return err
+	}
+
+	data, err := buildSU3(content, contentType, fileType, c.String("su3-version"), signerID, privKey)
+	if err != nil {
+		return err
+	}
+	if c.Bool("base64") {
+		data = encodeSU3Base64(data)
+	}
+
+	out := c.String("out")
+	if out == "" {
+		out = su3.ContentTypeName(contentType) + ".su3"
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		lgr.WithError(err).WithField("out", out).Error("Failed to write su3")
+		return err
+	}
+
+	fmt.Printf("Signed %s su3 saved to: %s\n", su3.ContentTypeName(contentType), out)
+	return nil
+}
+
+// buildSU3 wraps content in an SU3 of the given types, signs it and returns
+// the encoded file. An empty version keeps the timestamp version of su3.New.
+func buildSU3(content []byte, contentType, fileType uint8, version, signerID string, privKey crypto.Signer) ([]byte, error) {
+	if len(content) == 0 {
+		return nil, fmt.Errorf("su3 content cannot be empty")
+	}
+	su3File := su3.New()
+	su3File.ContentType = contentType
+	su3File.FileType = fileType
+	su3File.Content = content
+	su3File.SignerID = []byte(signerID)
+	if len(version) > 255 {
+		return nil, fmt.Errorf("su3 version is %d bytes, at most 255 fit", len(version))
+	}
+	if version != "" {
+		su3File.Version = []byte(version)
+	}
+
+	if err := su3File.SignWith(privKey); err != nil {
+		lgr.WithError(err).WithField("signer_id", signerID).Error("Failed to sign su3")
+		return nil, err
+	}
+
+	return su3File.MarshalBinary()
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+func TestNewSu3CreateCommand(t *testing.T) {
+	cmd := NewSu3CreateCommand()
+	if cmd.Name != "create" {
+		t.Errorf("Expected command name 'create', got %s", cmd.Name)
+	}
+	if cmd.Action == nil {
+		t.Error("Command action should not be nil")
+	}
+}
+
+func TestSu3CreateAction_PluginRoundTrip(t *testing.T) {
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	certDer, err := su3.NewSigningCertificate("plugin@mail.i2p", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "plugin.pem")
+	contentFile := filepath.Join(dir, "plugin.zip")
+	outFile := filepath.Join(dir, "plugin.su3")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privKey)}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(contentFile, []byte("plugin zip payload"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := cli.NewApp()
+	app.Flags = NewSu3CreateCommand().Flags
+	app.Action = su3CreateAction
+	args := []string{"test", "--signer=plugin@mail.i2p", "--key=" + keyFile, "--content=" + contentFile,
+		"--content-type=plugin", "--file-type=zip", "--su3-version=1.2.3", "--out=" + outFile}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := su3.VerifyBytes(data, cert); err != nil {
+		t.Fatalf("Expected the su3 to verify, got %v", err)
+	}
+	file := su3.New()
+	if err := file.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if file.ContentType != su3.ContentTypePlugin || file.FileType != su3.FileTypeZIP {
+		t.Errorf("Expected a plugin zip, got content type %d, file type %d", file.ContentType, file.FileType)
+	}
+	if !bytes.Equal(file.Content, []byte("plugin zip payload")) {
+		t.Errorf("Expected the content unchanged, got %q", file.Content)
+	}
+	if !strings.HasPrefix(string(file.Version), "1.2.3") {
+		t.Errorf("Expected version 1.2.3, got %q", file.Version)
+	}
+}
+
+func TestSu3CreateAction_RejectsUnknownTypes(t *testing.T) {
+	for _, tt := range []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--content-type=firmware", "--file-type=zip"}, "--content-type"},
+		{[]string{"--content-type=news", "--file-type=tar"}, "--file-type"},
+		{[]string{"--content-type=news"}, "--file-type"},
+	} {
+		app := cli.NewApp()
+		app.Flags = NewSu3CreateCommand().Flags
+		app.Action = su3CreateAction
+		err := app.Run(append([]string{"test", "--signer=test@mail.i2p", "--content=news.xml"}, tt.args...))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%v: expected an error containing %q, got %v", tt.args, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/urfave/cli/v3"
+)
+
+// base64Flag returns the --base64 flag used by the commands that read or write
+// su3 files, so bundles can be carried through text-only channels.
+func base64Flag(usage string) *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "base64",
+		Usage: usage,
+	}
+}
+
+// encodeSU3Base64 encodes a marshaled su3 file as a single line of standard base64.
+func encodeSU3Base64(data []byte) []byte {
+	out := make([]byte, base64.StdEncoding.EncodedLen(len(data)), base64.StdEncoding.EncodedLen(len(data))+1)
+	base64.StdEncoding.Encode(out, data)
+	return append(out, '\n')
+}
+
+// newSU3Base64Reader decodes a base64 su3 file written by encodeSU3Base64 as
+// it is read from r. Line breaks and other whitespace are ignored, so text
+// that was wrapped or indented on its way through a config file or chat
+// channel still decodes.
+func newSU3Base64Reader(r io.Reader) io.Reader {
+	return &base64Reader{dec: base64.NewDecoder(base64.StdEncoding, &spaceSkipper{r: r})}
+}
+
+// base64Reader reports corrupt or truncated input from dec as an su3 that is
+// not base64.
+type base64Reader struct {
+	dec io.Reader
+}
+
+func (br *base64Reader) Read(p []byte) (int, error) {
+	n, err := br.dec.Read(p)
+	var corrupt base64.CorruptInputError
+	if errors.As(err, &corrupt) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = fmt.Errorf("su3 is not valid base64: %w", err)
+	}
+	return n, err
+}
+
+// spaceSkipper reads r with every whitespace byte dropped.
+type spaceSkipper struct {
+	r io.Reader
+}
+
+func (ss *spaceSkipper) Read(p []byte) (int, error) {
+	for {
+		n, err := ss.r.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			switch b {
+			case ' ', '\t', '\n', '\r', '\v', '\f':
+			default:
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
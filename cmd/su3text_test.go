@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+func TestSU3Base64_RoundTrip(t *testing.T) {
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	certDer, err := su3.NewSigningCertificate("news@mail.i2p", privKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(certDer)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	data, err := buildNewsSU3([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`), "news@mail.i2p", privKey)
+	if err != nil {
+		t.Fatalf("buildNewsSU3() error: %v", err)
+	}
+
+	text := encodeSU3Base64(data)
+	if bytes.Count(text, []byte("\n")) != 1 || text[len(text)-1] != '\n' {
+		t.Errorf("Expected a single line of base64, got %q", text)
+	}
+
+	// Wrap the text the way a config file or mail client might
+	var wrapped []byte
+	for len(text) > 64 {
+		wrapped = append(append(wrapped, text[:64]...), "\n  "...)
+		text = text[64:]
+	}
+	wrapped = append(wrapped, text...)
+	path := filepath.Join(t.TempDir(), "news.su3.b64")
+	if err := os.WriteFile(path, wrapped, 0o644); err != nil {
+		t.Fatalf("Failed to write su3: %v", err)
+	}
+
+	su3File, err := loadAndParseSU3File(path, true, nil)
+	if err != nil {
+		t.Fatalf("loadAndParseSU3File() error: %v", err)
+	}
+	if err := verifySignature(su3File, cert); err != nil {
+		t.Fatalf("verifySignature() error: %v", err)
+	}
+
+	if _, err := loadAndParseSU3File(path, false, nil); err == nil {
+		t.Error("Expected base64 text to be rejected as a binary su3")
+	}
+	if _, err := io.ReadAll(newSU3Base64Reader(strings.NewReader("not base64!"))); err == nil || !strings.Contains(err.Error(), "not valid base64") {
+		t.Error("Expected invalid base64 to be rejected")
+	}
+}
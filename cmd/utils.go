@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"bufio"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -11,8 +13,10 @@ import (
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -25,9 +29,112 @@ import (
 	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
 	"github.com/go-acme/lego/v4/lego"
 	"github.com/go-acme/lego/v4/registration"
+	"github.com/urfave/cli/v3"
 )
 
-func loadPrivateKey(path string) (*rsa.PrivateKey, error) {
+// errNetDbReadOnly is returned when an operation would modify a netDb that was
+// marked read-only with --netdb-readonly.
+var errNetDbReadOnly = errors.New("netDb is read-only (--netdb-readonly)")
+
+// netDbReadOnlyFlag makes a command treat the netDb as strictly read-only. It can
+// also be set through RESEED_NETDB_READONLY so that every command run against a
+// live router's netDb honors it.
+func netDbReadOnlyFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:    "netdb-readonly",
+		Usage:   "Never modify the netDb directory; operations that would write to it fail instead",
+		EnvVars: []string{"RESEED_NETDB_READONLY"},
+	}
+}
+
+// routerInfoFractionFlag sets the share of valid routerInfos, freshest first,
+// that bundles are drawn from. reseed and check-netdb share it so a check
+// counts the same routers a rebuild would use.
+func routerInfoFractionFlag() *cli.Float64Flag {
+	return &cli.Float64Flag{
+		Name:  "min-routerinfo-fraction",
+		Value: reseed.DefaultRouterInfoFraction,
+		Usage: "Fraction of valid routerInfos, freshest first, that bundles are drawn from; 1 uses every one, which helps small netDbs reach --numRi",
+	}
+}
+
+// validateRouterInfoFraction rejects a --min-routerinfo-fraction that would
+// keep no routers or more routers than there are.
+func validateRouterInfoFraction(fraction float64) error {
+	if fraction <= 0 || fraction > 1 {
+		return fmt.Errorf("--min-routerinfo-fraction must be greater than 0 and at most 1, got %v", fraction)
+	}
+	return nil
+}
+
+// checkNetDbWritable returns an error wrapping errNetDbReadOnly if operation would
+// modify the netDb at path while it is marked read-only.
+func checkNetDbWritable(readOnly bool, path, operation string) error {
+	if readOnly {
+		return fmt.Errorf("%s would modify %s: %w", operation, path, errNetDbReadOnly)
+	}
+	return nil
+}
+
+// certSubjectFlags lets the commands that generate certificates replace the
+// placeholder organization, unit and locality in their subject.
+func certSubjectFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "cert-organization",
+			Value: su3.DefaultCertificateOrganization,
+			Usage: "Organization (O) written into generated signing and TLS certificates",
+		},
+		&cli.StringFlag{
+			Name:  "cert-unit",
+			Value: su3.DefaultCertificateOrganizationalUnit,
+			Usage: "Organizational unit (OU) written into generated signing and TLS certificates",
+		},
+		&cli.StringFlag{
+			Name:  "cert-locality",
+			Value: su3.DefaultCertificateLocality,
+			Usage: "Locality (L) written into generated signing and TLS certificates",
+		},
+	}
+}
+
+// certSubject returns the certificate subject fields given by certSubjectFlags.
+func certSubject(c *cli.Context) su3.CertificateSubject {
+	return su3.CertificateSubject{
+		Organization:       c.String("cert-organization"),
+		OrganizationalUnit: c.String("cert-unit"),
+		Locality:           c.String("cert-locality"),
+	}
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the same
+// directory, so a reader never sees a partial file, and gives it perm.
+// CreateTemp makes the file private, but the init scripts and monitoring
+// agents that read the files written this way often run as another user.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadPrivateKey reads a signing key from path: an RSA key in PKCS#1 form, as
+// keygen writes by default, or any signing key in PKCS#8 form, as it writes
+// for --ed25519.
+func loadPrivateKey(path string) (crypto.Signer, error) {
 	privPem, err := os.ReadFile(path)
 	if nil != err {
 		lgr.WithError(err).WithField("key_path", path).Error("Failed to read private key file")
@@ -40,6 +147,18 @@ func loadPrivateKey(path string) (*rsa.PrivateKey, error) {
 		lgr.WithError(err).WithField("key_path", path).Error("Failed to decode PEM data")
 		return nil, err
 	}
+	if privDer.Type == "PRIVATE KEY" {
+		key, err := x509.ParsePKCS8PrivateKey(privDer.Bytes)
+		if nil != err {
+			lgr.WithError(err).WithField("key_path", path).Error("Failed to parse private key")
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported private key type %T", path, key)
+		}
+		return signer, nil
+	}
 	privKey, err := x509.ParsePKCS1PrivateKey(privDer.Bytes)
 	if nil != err {
 		lgr.WithError(err).WithField("key_path", path).Error("Failed to parse private key")
@@ -49,13 +168,36 @@ func loadPrivateKey(path string) (*rsa.PrivateKey, error) {
 	return privKey, nil
 }
 
+// loadCertificate reads the first PEM-encoded certificate from path.
+func loadCertificate(path string) (*x509.Certificate, error) {
+	certPem, err := os.ReadFile(path)
+	if nil != err {
+		lgr.WithError(err).WithField("cert_path", path).Error("Failed to read certificate file")
+		return nil, err
+	}
+
+	certDer, _ := pem.Decode(certPem)
+	if certDer == nil {
+		err := fmt.Errorf("no valid PEM block found in %s", path)
+		lgr.WithError(err).WithField("cert_path", path).Error("Failed to decode PEM data")
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(certDer.Bytes)
+	if nil != err {
+		lgr.WithError(err).WithField("cert_path", path).Error("Failed to parse certificate")
+		return nil, err
+	}
+
+	return cert, nil
+}
+
 // signerFile creates a filename-safe version of a signer ID.
 // This function provides consistent filename generation across the cmd package.
 func signerFile(signerID string) string {
 	return strings.Replace(signerID, "@", "_at_", 1)
 }
 
-func getOrNewSigningCert(signerKey *string, signerID string, auto bool) (*rsa.PrivateKey, error) {
+func getOrNewSigningCert(signerKey *string, signerID string, auto bool, subject su3.CertificateSubject) (crypto.Signer, error) {
 	// Check if signing key file exists before attempting to load
 	if _, err := os.Stat(*signerKey); nil != err {
 		lgr.WithError(err).WithField("signer_key", *signerKey).WithField("signer_id", signerID).Debug("Signing key file not found, prompting for generation")
@@ -71,7 +213,7 @@ func getOrNewSigningCert(signerKey *string, signerID string, auto bool) (*rsa.Pr
 			}
 		}
 		// Generate new signing certificate if user confirmed or auto mode
-		if err := createSigningCertificate(signerID); nil != err {
+		if err := createSigningCertificate(signerID, false, subject); nil != err {
 			lgr.WithError(err).WithField("signer_id", signerID).Error("Failed to create signing certificate")
 			return nil, err
 		}
@@ -308,7 +450,7 @@ func newAcmeIssuedCert(client *lego.Client, user MyUser, tlsHost string, tlsCert
 	return nil
 }
 
-func checkOrNewTLSCert(tlsHost string, tlsCert, tlsKey *string, auto bool) error {
+func checkOrNewTLSCert(tlsHost string, tlsCert, tlsKey *string, auto bool, subject su3.CertificateSubject) error {
 	_, certErr := os.Stat(*tlsCert)
 	_, keyErr := os.Stat(*tlsKey)
 	if certErr != nil || keyErr != nil {
@@ -329,7 +471,7 @@ func checkOrNewTLSCert(tlsHost string, tlsCert, tlsKey *string, auto bool) error
 			}
 		}
 
-		if err := createTLSCertificate(tlsHost); nil != err {
+		if err := createTLSCertificate(tlsHost, subject); nil != err {
 			return err
 		}
 
@@ -340,20 +482,36 @@ func checkOrNewTLSCert(tlsHost string, tlsCert, tlsKey *string, auto bool) error
 	return nil
 }
 
-// createSigningCertificate generates a new RSA private key and self-signed certificate for SU3 signing.
+// createSigningCertificate generates a new private key and self-signed certificate for SU3 signing.
 // This function creates the cryptographic materials needed to sign SU3 files for distribution
-// over the I2P network. The generated certificate is valid for 10 years and uses 4096-bit RSA keys.
-func createSigningCertificate(signerID string) error {
-	// Generate 4096-bit RSA private key for strong cryptographic security
-	signerKey, err := generateSigningPrivateKey()
-	if err != nil {
-		return err
-	}
+// over the I2P network. The generated certificate is valid for 10 years and uses a 4096-bit RSA
+// key, or an Ed25519 key when useEd25519 is set, with subject in the certificate.
+func createSigningCertificate(signerID string, useEd25519 bool, subject su3.CertificateSubject) error {
+	var (
+		signerKey  crypto.Signer
+		signerCert []byte
+	)
+	if useEd25519 {
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		if signerCert, err = su3.NewEd25519SigningCertificateWithSubject(signerID, edKey, subject); err != nil {
+			return err
+		}
+		signerKey = edKey
+	} else {
+		// Generate 4096-bit RSA private key for strong cryptographic security
+		rsaKey, err := generateSigningPrivateKey()
+		if err != nil {
+			return err
+		}
 
-	// Create self-signed certificate using SU3 certificate standards
-	signerCert, err := su3.NewSigningCertificate(signerID, signerKey)
-	if nil != err {
-		return err
+		// Create self-signed certificate using SU3 certificate standards
+		if signerCert, err = su3.NewSigningCertificateWithSubject(signerID, rsaKey, subject); nil != err {
+			return err
+		}
+		signerKey = rsaKey
 	}
 
 	// Save certificate to disk in PEM format for verification use
@@ -400,9 +558,10 @@ func saveSigningCertificateFile(signerID string, signerCert []byte) error {
 	return nil
 }
 
-// saveSigningPrivateKeyFile saves the signing private key in PKCS#1 PEM format with certificate bundle.
+// saveSigningPrivateKeyFile saves the signing private key in PEM format with certificate bundle,
+// PKCS#1 for RSA keys and PKCS#8 for any other key.
 // The private key is saved as <signerID>.pem with the certificate included for convenience.
-func saveSigningPrivateKeyFile(signerID string, signerKey *rsa.PrivateKey, signerCert []byte) error {
+func saveSigningPrivateKeyFile(signerID string, signerKey crypto.Signer, signerCert []byte) error {
 	privFile := signerFile(signerID) + ".pem"
 	keyOut, err := os.OpenFile(privFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
@@ -410,8 +569,16 @@ func saveSigningPrivateKeyFile(signerID string, signerKey *rsa.PrivateKey, signe
 	}
 	defer keyOut.Close()
 
-	// Write RSA private key in PKCS#1 format
-	pem.Encode(keyOut, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(signerKey)})
+	if rsaKey, ok := signerKey.(*rsa.PrivateKey); ok {
+		// Write RSA private key in PKCS#1 format
+		pem.Encode(keyOut, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(signerKey)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %v", privFile, err)
+		}
+		pem.Encode(keyOut, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
 
 	// Include certificate in the key file for convenience
 	pem.Encode(keyOut, &pem.Block{Type: "CERTIFICATE", Bytes: signerCert})
@@ -422,7 +589,7 @@ func saveSigningPrivateKeyFile(signerID string, signerKey *rsa.PrivateKey, signe
 
 // generateAndSaveSigningCRL generates and saves a Certificate Revocation List (CRL) for the signing certificate.
 // The CRL is saved as <signerID>.crl and includes the certificate as revoked for testing purposes.
-func generateAndSaveSigningCRL(signerID string, signerKey *rsa.PrivateKey, signerCert []byte) error {
+func generateAndSaveSigningCRL(signerID string, signerKey crypto.Signer, signerCert []byte) error {
 	crlFile := signerFile(signerID) + ".crl"
 	crlOut, err := os.OpenFile(crlFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
@@ -462,14 +629,15 @@ func generateAndSaveSigningCRL(signerID string, signerKey *rsa.PrivateKey, signe
 	return nil
 }
 
-func createTLSCertificate(host string) error {
-	return CreateTLSCertificate(host)
-}
-
 // CreateTLSCertificate generates a new ECDSA private key and self-signed TLS certificate.
 // This function creates cryptographic materials for HTTPS server operation, using P-384 elliptic
 // curve cryptography for efficient and secure TLS connections. The certificate is valid for the specified hostname.
 func CreateTLSCertificate(host string) error {
+	return createTLSCertificate(host, su3.CertificateSubject{})
+}
+
+// createTLSCertificate is CreateTLSCertificate with subject in the certificate.
+func createTLSCertificate(host string, subject su3.CertificateSubject) error {
 	// Generate P-384 ECDSA private key for TLS encryption
 	priv, err := generateTLSPrivateKey()
 	if err != nil {
@@ -477,7 +645,7 @@ func CreateTLSCertificate(host string) error {
 	}
 
 	// Create self-signed TLS certificate for the specified hostname
-	tlsCert, err := reseed.NewTLSCertificate(host, priv)
+	tlsCert, err := reseed.NewTLSCertificateWithSubject(host, priv, subject)
 	if nil != err {
 		return err
 	}
@@ -13,6 +13,9 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/urfave/cli/v3"
+	"i2pgit.org/go-i2p/reseed-tools/su3"
 )
 
 func TestCertificateExpirationLogic(t *testing.T) {
@@ -373,11 +376,51 @@ func TestLoadPrivateKey_ValidKey(t *testing.T) {
 	if loaded == nil {
 		t.Fatal("Expected non-nil key for valid PEM, got nil")
 	}
-	if loaded.N.Cmp(privateKey.N) != 0 {
+	if rsaKey, ok := loaded.(*rsa.PrivateKey); !ok || rsaKey.N.Cmp(privateKey.N) != 0 {
 		t.Error("Loaded key does not match original key")
 	}
 }
 
+func TestLoadCertificate(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test@mail.i2p"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	tmpDir := t.TempDir()
+	certPath := filepath.Join(tmpDir, "test.crt")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+
+	cert, err := loadCertificate(certPath)
+	if err != nil {
+		t.Fatalf("Expected no error for valid certificate, got: %v", err)
+	}
+	if cert.Subject.CommonName != "test@mail.i2p" {
+		t.Errorf("Unexpected certificate subject: %s", cert.Subject.CommonName)
+	}
+
+	garbage := filepath.Join(tmpDir, "garbage.crt")
+	os.WriteFile(garbage, []byte("not a certificate"), 0o644)
+	if _, err := loadCertificate(garbage); err == nil {
+		t.Error("Expected error for file without PEM data")
+	}
+	if _, err := loadCertificate(filepath.Join(tmpDir, "missing.crt")); err == nil {
+		t.Error("Expected error for nonexistent file")
+	}
+}
+
 // TestLoadPrivateKey_WrongPEMType verifies that loadPrivateKey returns a parse error
 // (not a panic) when the PEM block type is valid but contains non-PKCS1 data.
 func TestLoadPrivateKey_WrongPEMType(t *testing.T) {
@@ -435,3 +478,23 @@ func TestSignerFile(t *testing.T) {
 		}
 	}
 }
+
+func TestCertSubject(t *testing.T) {
+	var subject su3.CertificateSubject
+	app := cli.NewApp()
+	app.Name = "test"
+	app.Flags = certSubjectFlags()
+	app.Action = func(c *cli.Context) error {
+		subject = certSubject(c)
+		return nil
+	}
+	if err := app.Run([]string{"test", "--cert-organization=Example Org", "--cert-locality=Berlin"}); err != nil {
+		t.Fatal(err)
+	}
+	if subject.Organization != "Example Org" || subject.Locality != "Berlin" {
+		t.Errorf("Expected the flags to set the subject, got O=%q L=%q", subject.Organization, subject.Locality)
+	}
+	if subject.OrganizationalUnit != "I2P" {
+		t.Errorf("Expected an unset flag to keep the default OU, got %q", subject.OrganizationalUnit)
+	}
+}
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"bufio"
+	"crypto/sha256"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/urfave/cli/v3"
 	"i2pgit.org/go-i2p/reseed-tools/reseed"
@@ -51,7 +56,12 @@ func NewSu3VerifyCommand() *cli.Command {
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:  "extract",
-				Usage: "Also extract the contents of the su3",
+				Usage: "Also extract the contents of the su3 into the current directory, unzipping ZIP content and decompressing gzip content",
+			},
+			&cli.BoolFlag{
+				Name:  "deep",
+				Value: true,
+				Usage: "For reseed su3s, also unzip the bundle and parse every RouterInfo in it (--deep=false to check only the signature)",
 			},
 			&cli.StringFlag{
 				Name:  "signer",
@@ -60,60 +70,152 @@ func NewSu3VerifyCommand() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:  "keystore",
-				Value: filepath.Join(I2PHome(), "/certificates/reseed"),
-				Usage: "Path to the keystore",
+				Value: findCertificatesDir("reseed"),
+				Usage: "Path to the keystore; by default, the certificates directory of the local Java I2P or i2pd install for the file's content type",
 			},
+			base64Flag("Read the su3 as base64 text instead of binary"),
 		},
 	}
 }
 
 // su3VerifyAction performs comprehensive verification of SU3 files including signature validation.
 func su3VerifyAction(c *cli.Context) error {
-	su3File, err := loadAndParseSU3File(c.Args().Get(0))
+	// Only the bundle check of a reseed su3 and extraction need the content
+	keepContent := func(f *su3.File) bool {
+		return c.Bool("extract") || (c.Bool("deep") && f.ContentType == su3.ContentTypeReseed)
+	}
+	su3File, err := loadAndParseSU3File(c.Args().Get(0), c.Bool("base64"), keepContent)
 	if err != nil {
 		return err
 	}
 
 	fmt.Println(su3File.String())
+	fmt.Println(contentSummary(su3File.File, su3File.reader.ContentLength()))
 
-	cert, err := configureAndGetCertificate(c, su3File)
+	cert, certPath, err := findVerifyingCertificate(c, su3File)
 	if err != nil {
 		return err
 	}
+	fmt.Print(describeCertificate(cert, certPath, time.Now()))
 
-	err = verifySignature(su3File, cert)
-	if err != nil {
-		return err
+	if c.Bool("deep") && su3File.ContentType == su3.ContentTypeReseed {
+		if err := verifyBundleContent(su3File.File); err != nil {
+			return err
+		}
 	}
 
 	if c.Bool("extract") {
-		return extractSU3Content(su3File)
+		return extractSU3Content(su3File.File)
 	}
 
 	return nil
 }
 
-// loadAndParseSU3File reads and unmarshals an SU3 file from the specified path.
-func loadAndParseSU3File(filePath string) (*su3.File, error) {
-	su3File := su3.New()
+// su3Input is an SU3 file read for verification through su3.Reader. The
+// embedded File holds the header fields, and holds the content only if it was
+// kept in memory.
+type su3Input struct {
+	*su3.File
+	reader *su3.Reader
+}
+
+// VerifySignature checks the signature against cert, over the digest taken
+// while the content streamed past. Pure Ed25519 signs the whole file instead,
+// so such files are checked over the content kept for them.
+func (in *su3Input) VerifySignature(cert *x509.Certificate) error {
+	if in.SignatureType == su3.SigTypeEdDSASHA512Ed25519 {
+		return in.File.VerifySignature(cert)
+	}
+	return in.reader.VerifySignature(cert)
+}
 
-	data, err := os.ReadFile(filePath)
+// loadAndParseSU3File reads an SU3 file from the specified path through
+// su3.Reader, decoding it from base64 first when isBase64 is set. The content
+// is only held in memory when keepContent, given the header, asks for it, or
+// when the file is signed with pure Ed25519 and cannot be verified otherwise;
+// a large plugin or router update is streamed past while its signature digest
+// is taken. A nil keepContent keeps nothing.
+func loadAndParseSU3File(filePath string, isBase64 bool, keepContent func(*su3.File) bool) (*su3Input, error) {
+	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
-	if err := su3File.UnmarshalBinary(data); err != nil {
+	var r io.Reader = bufio.NewReader(f)
+	if isBase64 {
+		r = newSU3Base64Reader(r)
+	}
+	reader, err := su3.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	su3File := &su3Input{File: reader.File, reader: reader}
+	if (keepContent != nil && keepContent(su3File.File)) || su3File.SignatureType == su3.SigTypeEdDSASHA512Ed25519 {
+		if su3File.Content, err = io.ReadAll(reader.Content()); err != nil {
+			return nil, fmt.Errorf("failed to read content: %w", err)
+		}
+	}
+	if _, err := reader.ReadSignature(); err != nil {
 		return nil, err
 	}
 
 	return su3File, nil
 }
 
-// configureAndGetCertificate sets up keystore configuration and retrieves the reseeder certificate.
-func configureAndGetCertificate(c *cli.Context, su3File *su3.File) (*x509.Certificate, error) {
-	absPath, err := filepath.Abs(c.String("keystore"))
+// keystoreDirs maps SU3 content types to the certificates subdirectory where
+// the I2P router keeps the signer certificates it trusts for that content.
+var keystoreDirs = map[uint8]string{
+	su3.ContentTypeRouter: "router",
+	su3.ContentTypePlugin: "plugin",
+	su3.ContentTypeReseed: "reseed",
+	su3.ContentTypeNews:   "news",
+}
+
+// keystorePath returns the --keystore directory, or when it is not given, the
+// directory a local router keeps the certificates for contentType in.
+func keystorePath(c *cli.Context, contentType uint8) string {
+	// Routers keep signer certificates for each content type in their own directory
+	if dir, ok := keystoreDirs[contentType]; ok && !c.IsSet("keystore") {
+		return findCertificatesDir(dir)
+	}
+	return c.String("keystore")
+}
+
+// findVerifyingCertificate returns the certificate that verifies su3File and
+// the path it was read from. The signer's own certificate is tried first. If it
+// is missing or does not verify, and --signer was not given, every other
+// certificate in the keystore is tried, and the one that verifies is reported.
+func findVerifyingCertificate(c *cli.Context, su3File *su3Input) (*x509.Certificate, string, error) {
+	signerID := su3File.SignerID
+	cert, certPath, err := configureAndGetCertificate(c, su3File.File)
+	if err == nil {
+		if err = verifySignature(su3File, cert); err == nil {
+			return cert, certPath, nil
+		}
+	}
+	if c.IsSet("signer") {
+		return nil, "", err
+	}
+
+	// The signer ID is part of the signed data, so restore the file's own
+	su3File.SignerID = signerID
+	dir := keystorePath(c, su3File.ContentType)
+	cert, certPath, scanErr := findCertificateInDir(dir, su3File)
+	if scanErr != nil {
+		fmt.Println(scanErr)
+		return nil, "", fmt.Errorf("%w; %w", err, scanErr)
+	}
+	fmt.Printf("Signature is valid for signer '%s', verified by %s from %s\n", su3File.SignerID, cert.Subject.CommonName, dir)
+	return cert, certPath, nil
+}
+
+// configureAndGetCertificate sets up keystore configuration and retrieves the
+// reseeder certificate together with the path it was read from.
+func configureAndGetCertificate(c *cli.Context, su3File *su3.File) (*x509.Certificate, string, error) {
+	absPath, err := filepath.Abs(keystorePath(c, su3File.ContentType))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	keyStorePath := filepath.Dir(absPath)
@@ -131,14 +233,14 @@ func configureAndGetCertificate(c *cli.Context, su3File *su3.File) (*x509.Certif
 	cert, err := ks.DirReseederCertificate(reseedDir, su3File.SignerID)
 	if err != nil {
 		fmt.Println(err)
-		return nil, err
+		return nil, "", err
 	}
 
-	return cert, nil
+	return cert, ks.CertificatePath(reseedDir, su3File.SignerID), nil
 }
 
 // verifySignature validates the SU3 file signature against the provided certificate.
-func verifySignature(su3File *su3.File, cert *x509.Certificate) error {
+func verifySignature(su3File *su3Input, cert *x509.Certificate) error {
 	if err := su3File.VerifySignature(cert); err != nil {
 		return err
 	}
@@ -147,9 +249,70 @@ func verifySignature(su3File *su3.File, cert *x509.Certificate) error {
 	return nil
 }
 
-// extractSU3Content extracts the content from an SU3 file to a zip file.
-// It writes only the raw content payload (e.g. ZIP data), not the full SU3 binary.
+// describeCertificate returns the audit details of the certificate that verified
+// a signature: where it was read from, its subject, SHA-256 fingerprint and
+// validity window, relative to now.
+func describeCertificate(cert *x509.Certificate, path string, now time.Time) string {
+	sum := sha256.Sum256(cert.Raw)
+	fingerprint := make([]string, len(sum))
+	for i, b := range sum {
+		fingerprint[i] = fmt.Sprintf("%02X", b)
+	}
+
+	var expiry string
+	switch {
+	case now.Before(cert.NotBefore):
+		expiry = "not yet valid"
+	case now.After(cert.NotAfter):
+		expiry = fmt.Sprintf("expired %d days ago", int(now.Sub(cert.NotAfter).Hours()/24))
+	default:
+		expiry = fmt.Sprintf("expires in %d days", int(cert.NotAfter.Sub(now).Hours()/24))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Certificate: %s\n", path)
+	fmt.Fprintf(&b, "  Subject: %s\n", cert.Subject)
+	fmt.Fprintf(&b, "  SHA-256 fingerprint: %s\n", strings.Join(fingerprint, ":"))
+	fmt.Fprintf(&b, "  Valid: %s to %s (%s)\n", cert.NotBefore.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339), expiry)
+	return b.String()
+}
+
+// verifyBundleContent checks that a reseed SU3 holds a readable zip of parseable
+// RouterInfos, printing the router count and every entry that failed.
+func verifyBundleContent(su3File *su3.File) error {
+	report, err := reseed.InspectBundle(su3File.Content)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Bundle contains %d RouterInfos in %d entries\n", report.RouterInfos, report.Entries)
+	for _, failure := range report.Failures {
+		fmt.Printf("  %s: %s\n", failure.Name, failure.Err)
+	}
+	if len(report.Failures) > 0 {
+		return fmt.Errorf("bundle content is invalid: %d of %d entries failed to parse", len(report.Failures), report.Entries)
+	}
+	if report.RouterInfos == 0 {
+		return fmt.Errorf("bundle content is invalid: no RouterInfos")
+	}
+	return nil
+}
+
+// contentSummary describes the payload of an SU3 file in terms of its content
+// and file type, and its length in bytes, as declared by the header.
+func contentSummary(su3File *su3.File, length uint64) string {
+	return fmt.Sprintf("Content: %s (%s), %d bytes",
+		su3.ContentTypeName(su3File.ContentType), su3.FileTypeName(su3File.FileType), length)
+}
+
+// extractSU3Content extracts the content from an SU3 file into the working
+// directory, see su3.File.ExtractContent: ZIP content is unzipped, gzip content
+// decompressed, and news written to news.xml.
 func extractSU3Content(su3File *su3.File) error {
-	// @todo: don't assume zip
-	return os.WriteFile("extracted.zip", su3File.Content, 0o644)
+	if err := su3File.ExtractContent("."); err != nil {
+		return err
+	}
+	fmt.Printf("Extracted %s content (%s) to the current directory\n",
+		su3.ContentTypeName(su3File.ContentType), su3.FileTypeName(su3File.FileType))
+	return nil
 }
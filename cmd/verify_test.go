@@ -1,123 +1,214 @@
 package cmd
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"i2pgit.org/go-i2p/reseed-tools/su3"
 )
 
-func TestExtractSU3Content_WritesContentNotBodyBytes(t *testing.T) {
-	// Create an SU3 file with known content payload
-	su3File := su3.New()
-	expectedContent := []byte("this is the raw ZIP payload content")
-	su3File.Content = expectedContent
-	su3File.SignerID = []byte("test@example.com")
-	su3File.FileType = su3.FileTypeZIP
-	su3File.ContentType = su3.ContentTypeReseed
-
-	// Change to temp directory so we don't pollute the working directory
+// chdirTemp changes into a new temporary directory for the rest of the test.
+func chdirTemp(t *testing.T) {
+	t.Helper()
 	origDir, err := os.Getwd()
 	if err != nil {
 		t.Fatalf("failed to get working directory: %v", err)
 	}
-	tempDir, err := os.MkdirTemp("", "verify_test")
-	if err != nil {
-		t.Fatalf("failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
-	if err := os.Chdir(tempDir); err != nil {
+	if err := os.Chdir(t.TempDir()); err != nil {
 		t.Fatalf("failed to chdir: %v", err)
 	}
-	defer os.Chdir(origDir)
+	t.Cleanup(func() { os.Chdir(origDir) })
+}
+
+func TestExtractSU3Content_UnzipsBundle(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("routerInfo-test.dat")
+	w.Write([]byte("router info"))
+	zw.Close()
 
-	// Extract should write only the Content field, not the full SU3 binary
+	su3File := su3.New()
+	su3File.Content = buf.Bytes()
+	su3File.SignerID = []byte("test@example.com")
+	su3File.FileType = su3.FileTypeZIP
+	su3File.ContentType = su3.ContentTypeReseed
+
+	chdirTemp(t)
 	if err := extractSU3Content(su3File); err != nil {
 		t.Fatalf("extractSU3Content() returned error: %v", err)
 	}
 
-	// Read back the extracted file
-	extracted, err := os.ReadFile("extracted.zip")
+	extracted, err := os.ReadFile("routerInfo-test.dat")
 	if err != nil {
-		t.Fatalf("failed to read extracted.zip: %v", err)
+		t.Fatalf("failed to read the unzipped RouterInfo: %v", err)
 	}
-
-	// The extracted data must match the Content field exactly
-	if string(extracted) != string(expectedContent) {
-		t.Errorf("extracted content mismatch:\n  got:  %q\n  want: %q", extracted, expectedContent)
+	if string(extracted) != "router info" {
+		t.Errorf("extracted content mismatch: got %q", extracted)
+	}
+	if _, err := os.Stat("extracted.zip"); !os.IsNotExist(err) {
+		t.Error("expected the zip to be unpacked, not written as extracted.zip")
 	}
 
-	// Verify we did NOT get the full BodyBytes (which includes SU3 header + content)
-	bodyBytes := su3File.BodyBytes()
-	if string(extracted) == string(bodyBytes) {
-		t.Error("extracted content matches BodyBytes() — should only contain Content, not the full SU3 binary")
+	info, err := os.Stat("routerInfo-test.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Files should be created with 0644 permissions (not 0755)
+	if perm := info.Mode().Perm(); perm&0o111 != 0 {
+		t.Errorf("extracted file should not be executable, got permissions %o", perm)
 	}
 }
 
-func TestExtractSU3Content_EmptyContent(t *testing.T) {
+func TestExtractSU3Content_InvalidZip(t *testing.T) {
 	su3File := su3.New()
 	su3File.Content = []byte{}
 	su3File.SignerID = []byte("test@example.com")
 
-	origDir, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("failed to get working directory: %v", err)
-	}
-	tempDir, err := os.MkdirTemp("", "verify_test_empty")
-	if err != nil {
-		t.Fatalf("failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
-	if err := os.Chdir(tempDir); err != nil {
-		t.Fatalf("failed to chdir: %v", err)
-	}
-	defer os.Chdir(origDir)
-
-	if err := extractSU3Content(su3File); err != nil {
-		t.Fatalf("extractSU3Content() returned error: %v", err)
+	chdirTemp(t)
+	if err := extractSU3Content(su3File); err == nil {
+		t.Error("expected empty ZIP content to be refused")
 	}
+}
 
-	extracted, err := os.ReadFile("extracted.zip")
-	if err != nil {
-		t.Fatalf("failed to read extracted.zip: %v", err)
-	}
+func TestContentSummary(t *testing.T) {
+	f := su3.New()
+	f.ContentType = su3.ContentTypePlugin
+	f.FileType = su3.FileTypeZIP
+	f.Content = []byte("plugin")
 
-	if len(extracted) != 0 {
-		t.Errorf("expected empty extraction, got %d bytes", len(extracted))
+	if got, want := contentSummary(f, uint64(len(f.Content))), "Content: plugin (zip), 6 bytes"; got != want {
+		t.Errorf("contentSummary() = %q, want %q", got, want)
 	}
 }
 
-func TestExtractSU3Content_FilePermissions(t *testing.T) {
-	su3File := su3.New()
-	su3File.Content = []byte("test content")
-	su3File.SignerID = []byte("test@example.com")
-
-	origDir, err := os.Getwd()
+// TestLoadAndParseSU3File_StreamsContent verifies that the content is only
+// held in memory when asked for, or when pure Ed25519 needs it to verify.
+func TestLoadAndParseSU3File_StreamsContent(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
-		t.Fatalf("failed to get working directory: %v", err)
+		t.Fatal(err)
 	}
-	tempDir, err := os.MkdirTemp("", "verify_test_perms")
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
-		t.Fatalf("failed to create temp dir: %v", err)
+		t.Fatal(err)
 	}
-	defer os.RemoveAll(tempDir)
-	if err := os.Chdir(tempDir); err != nil {
-		t.Fatalf("failed to chdir: %v", err)
+	rsaCert, err := su3.NewSigningCertificate("plugin@mail.i2p", rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edCert, err := su3.NewEd25519SigningCertificate("plugin@mail.i2p", edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		key      crypto.Signer
+		certDer  []byte
+		pure     bool
+		keep     bool
+		wantHeld bool
+	}{
+		{"RSA streamed", rsaKey, rsaCert, false, false, false},
+		{"RSA kept", rsaKey, rsaCert, false, true, true},
+		{"Ed25519ph streamed", edKey, edCert, false, false, false},
+		{"pure Ed25519", edKey, edCert, true, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			file := su3.New()
+			file.ContentType = su3.ContentTypePlugin
+			file.SignerID = []byte("plugin@mail.i2p")
+			file.Content = []byte("plugin content")
+			if tc.pure {
+				// su3 only verifies type 7, so sign it by hand
+				file.SignatureType = su3.SigTypeEdDSASHA512Ed25519
+				file.Signature = ed25519.Sign(edKey, file.BodyBytes())
+			} else if err := file.SignWith(tc.key); err != nil {
+				t.Fatal(err)
+			}
+			data, err := file.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "plugin.su3")
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cert, err := x509.ParseCertificate(tc.certDer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			in, err := loadAndParseSU3File(path, false, func(*su3.File) bool { return tc.keep })
+			if err != nil {
+				t.Fatalf("loadAndParseSU3File() error: %v", err)
+			}
+			if held := in.Content != nil; held != tc.wantHeld {
+				t.Errorf("Content held = %v, want %v", held, tc.wantHeld)
+			}
+			if got := in.reader.ContentLength(); got != uint64(len("plugin content")) {
+				t.Errorf("ContentLength() = %d, want %d", got, len("plugin content"))
+			}
+			if err := in.VerifySignature(cert); err != nil {
+				t.Errorf("VerifySignature() error: %v", err)
+			}
+		})
 	}
-	defer os.Chdir(origDir)
+}
 
-	if err := extractSU3Content(su3File); err != nil {
-		t.Fatalf("extractSU3Content() returned error: %v", err)
+func TestVerifyBundleContent_RejectsCorruptOrEmptyBundles(t *testing.T) {
+	su3File := su3.New()
+	su3File.ContentType = su3.ContentTypeReseed
+	su3File.FileType = su3.FileTypeZIP
+
+	su3File.Content = []byte("not a zip")
+	if err := verifyBundleContent(su3File); err == nil {
+		t.Error("Expected corrupt zip content to fail")
 	}
 
-	info, err := os.Stat("extracted.zip")
-	if err != nil {
-		t.Fatalf("failed to stat extracted.zip: %v", err)
+	// An empty zip archive is a validly-formed but useless bundle
+	su3File.Content = []byte("PK\x05\x06" + string(make([]byte, 18)))
+	if err := verifyBundleContent(su3File); err == nil || !strings.Contains(err.Error(), "no RouterInfos") {
+		t.Errorf("Expected an empty bundle to fail with no RouterInfos, got: %v", err)
 	}
+}
 
-	// File should be created with 0644 permissions (not 0755)
-	perm := info.Mode().Perm()
-	if perm&0o111 != 0 {
-		t.Errorf("extracted.zip should not be executable, got permissions %o", perm)
+func TestDescribeCertificate(t *testing.T) {
+	notBefore := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cert := &x509.Certificate{
+		Raw:       []byte("certificate"),
+		Subject:   pkix.Name{CommonName: "you@mail.i2p", Organization: []string{"I2P Anonymous Network"}},
+		NotBefore: notBefore,
+		NotAfter:  notBefore.AddDate(10, 0, 0),
+	}
+	got := describeCertificate(cert, "/keystore/reseed/you_at_mail.i2p.crt", notBefore.AddDate(0, 0, 10))
+	for _, want := range []string{
+		"Certificate: /keystore/reseed/you_at_mail.i2p.crt\n",
+		"Subject: CN=you@mail.i2p,O=I2P Anonymous Network\n",
+		// sha256("certificate")
+		"SHA-256 fingerprint: 03:D6:6D:D0:88:35:C1:CA:3F:12:8C:CE:AC:D1:F3:1A:C9:41:63:09:6B:20:F4:45:AE:84:28:5B:C0:83:2D:72\n",
+		"Valid: 2025-01-01T00:00:00Z to 2035-01-01T00:00:00Z (expires in 3642 days)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("describeCertificate() missing %q in:\n%s", want, got)
+		}
+	}
+
+	if got := describeCertificate(cert, "", notBefore.AddDate(11, 0, 0)); !strings.Contains(got, "(expired ") {
+		t.Errorf("Expected an expired certificate to be reported, got:\n%s", got)
+	}
+	if got := describeCertificate(cert, "", notBefore.Add(-time.Hour)); !strings.Contains(got, "(not yet valid)") {
+		t.Errorf("Expected a not yet valid certificate to be reported, got:\n%s", got)
 	}
 }
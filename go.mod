@@ -3,18 +3,22 @@ module i2pgit.org/go-i2p/reseed-tools
 go 1.26.1
 
 require (
+	github.com/beevik/ntp v1.5.0
 	github.com/cretz/bine v0.2.0
 	github.com/eyedeekay/unembed v0.0.0-20230123014222-9916b121855b
 	github.com/go-acme/lego/v4 v4.3.1
 	github.com/go-i2p/checki2cp v0.0.0-20250819201001-7a3f89fafac8
 	github.com/go-i2p/common v0.1.54
+	github.com/go-i2p/crypto v0.1.54
 	github.com/go-i2p/go-sam-bridge v0.1.3
 	github.com/go-i2p/i2pkeys v0.33.92
 	github.com/go-i2p/logger v0.1.54
 	github.com/go-i2p/onramp v0.33.92
 	github.com/go-i2p/sam3 v0.33.92
+	github.com/gomodule/redigo v2.0.0+incompatible
 	github.com/gorilla/handlers v1.5.1
 	github.com/justinas/alice v1.2.0
+	github.com/oschwald/maxminddb-golang v1.13.1
 	github.com/otiai10/copy v1.14.0
 	github.com/rglonek/untar v0.0.1
 	github.com/throttled/throttled/v2 v2.7.1
@@ -28,7 +32,6 @@ require (
 	github.com/armon/circbuf v0.0.0-20190214190532-5111143e8da2 // indirect
 	github.com/atotto/clipboard v0.1.4 // indirect
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
-	github.com/beevik/ntp v1.5.0 // indirect
 	github.com/cenkalti/backoff/v4 v4.1.0 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/charmbracelet/bubbles v1.0.0 // indirect
@@ -46,7 +49,6 @@ require (
 	github.com/felixge/httpsnoop v1.0.4 // indirect
 	github.com/fsnotify/fsnotify v1.10.1 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.0 // indirect
-	github.com/go-i2p/crypto v0.1.54 // indirect
 	github.com/go-i2p/elgamal v0.1.54 // indirect
 	github.com/go-i2p/go-datagrams v0.1.1 // indirect
 	github.com/go-i2p/go-i2cp v0.1.1 // indirect
@@ -61,7 +63,6 @@ require (
 	github.com/go-i2p/red25519 v0.0.0-20260302212615-1093a31f680d // indirect
 	github.com/go-i2p/su3 v0.1.54 // indirect
 	github.com/go-viper/mapstructure/v2 v2.5.0 // indirect
-	github.com/gomodule/redigo v2.0.0+incompatible // indirect
 	github.com/hashicorp/golang-lru v0.5.4 // indirect
 	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
 	github.com/huin/goupnp v1.3.0 // indirect
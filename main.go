@@ -30,8 +30,16 @@ func main() {
 		cmd.NewReseedCommand(),
 		cmd.NewSu3VerifyCommand(),
 		cmd.NewKeygenCommand(),
+		cmd.NewGenkeysCommand(),
 		cmd.NewShareCommand(),
 		cmd.NewDiagnoseCommand(),
+		cmd.NewPruneCommand(),
+		cmd.NewCheckNetDbCommand(),
+		cmd.NewSelftestBootstrapCommand(),
+		cmd.NewMonitorNetDbCommand(),
+		cmd.NewNewsCommand(),
+		cmd.NewBlocklistCommand(),
+		cmd.NewSu3CreateCommand(),
 		cmd.NewVersionCommand(),
 		// cmd.NewSu3VerifyPublicCommand(),
 	}
@@ -0,0 +1,238 @@
+package reseed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// Admin serves operator endpoints under /admin/ on a listener of their own.
+// It performs no authentication, so it must only be bound to loopback; the
+// reseed command refuses any other --admin-addr.
+type Admin struct {
+	// Reseeder is the reseed service the endpoints act on
+	Reseeder *ReseederImpl
+	// NetDbStaging is the netDb directory /admin/netdb/switch rebuilds from.
+	// After a successful switch it names the previously active directory, so
+	// the two alternate as blue/green copies. Empty disables switching.
+	NetDbStaging string
+	// ReloadFriends re-reads the list of reseed servers to ping, for
+	// /admin/friends/reload. Nil disables reloading.
+	ReloadFriends func() error
+	// Blacklist is the list /admin/blacklist shows and edits. Nil disables
+	// the blacklist endpoints.
+	Blacklist *Blacklist
+
+	// mu serializes netDb switches and protects NetDbStaging
+	mu  sync.Mutex
+	mux *http.ServeMux
+}
+
+// NewAdmin creates the admin endpoints for reseeder.
+func NewAdmin(reseeder *ReseederImpl) *Admin {
+	admin := &Admin{Reseeder: reseeder, mux: http.NewServeMux()}
+	admin.mux.HandleFunc("GET /admin/netdb", admin.handleNetDb)
+	admin.mux.HandleFunc("POST /admin/netdb/switch", admin.handleNetDbSwitch)
+	admin.mux.HandleFunc("GET /admin/bundles", admin.handleBundles)
+	admin.mux.HandleFunc("POST /admin/bundles/pin", admin.handleBundlesPin)
+	admin.mux.HandleFunc("POST /admin/bundles/unpin", admin.handleBundlesUnpin)
+	admin.mux.HandleFunc("GET /admin/friends", admin.handleFriends)
+	admin.mux.HandleFunc("POST /admin/friends/reload", admin.handleFriendsReload)
+	admin.mux.HandleFunc("GET /admin/blacklist", admin.handleBlacklist)
+	admin.mux.HandleFunc("DELETE /admin/blacklist/{entry...}", admin.handleBlacklistDelete)
+	admin.mux.HandleFunc("GET /admin/debug/goroutines", handleGoroutines)
+	return admin
+}
+
+// EnableProfiling serves the net/http/pprof profiles under /debug/pprof/, for
+// finding goroutine leaks and CPU hotspots in a running server. Profiles
+// expose the server's internals, which is one more reason to keep the admin
+// listener private.
+func (admin *Admin) EnableProfiling() {
+	admin.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	admin.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	admin.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	admin.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	admin.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// GoroutineStatus is the response of /admin/debug/goroutines.
+type GoroutineStatus struct {
+	// Goroutines is the number of goroutines that currently exist
+	Goroutines int `json:"goroutines"`
+}
+
+// handleGoroutines reports the current goroutine count, so a count that keeps
+// growing across rebuilds or restarts of a transport shows up as a leak.
+func handleGoroutines(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, GoroutineStatus{Goroutines: runtime.NumGoroutine()})
+}
+
+// ServeHTTP dispatches to the admin endpoints.
+func (admin *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	admin.mux.ServeHTTP(w, r)
+}
+
+// NetDbStatus is the response of the /admin/netdb endpoints.
+type NetDbStatus struct {
+	// Active is the netDb directory bundles are currently built from
+	Active string `json:"active"`
+	// Staging is the directory the next switch rebuilds from
+	Staging string `json:"staging,omitempty"`
+	// Rebuild describes the rebuild a successful switch performed
+	Rebuild *RebuildStats `json:"rebuild,omitempty"`
+	// Error explains why a switch was refused or failed
+	Error string `json:"error,omitempty"`
+}
+
+// handleNetDb reports the active and staging netDb directories.
+func (admin *Admin) handleNetDb(w http.ResponseWriter, r *http.Request) {
+	admin.mu.Lock()
+	defer admin.mu.Unlock()
+	writeAdminJSON(w, http.StatusOK, NetDbStatus{Active: admin.Reseeder.NetDbPath(), Staging: admin.NetDbStaging})
+}
+
+// handleNetDbSwitch rebuilds from the staging netDb and makes it the active
+// one if the rebuild succeeds. A failed rebuild leaves everything as it was
+// and responds 422, so a new netDb can be tried out safely.
+func (admin *Admin) handleNetDbSwitch(w http.ResponseWriter, r *http.Request) {
+	admin.mu.Lock()
+	defer admin.mu.Unlock()
+
+	if admin.NetDbStaging == "" {
+		writeAdminJSON(w, http.StatusConflict, NetDbStatus{Active: admin.Reseeder.NetDbPath(), Error: "no staging netDb configured"})
+		return
+	}
+	previous := admin.Reseeder.NetDbPath()
+	stats, err := admin.Reseeder.SwitchNetDb(admin.NetDbStaging)
+	if err != nil {
+		lgr.WithError(err).WithField("staging", admin.NetDbStaging).Warn("netDb switch failed, keeping the active netDb")
+		writeAdminJSON(w, http.StatusUnprocessableEntity, NetDbStatus{Active: previous, Staging: admin.NetDbStaging, Error: err.Error()})
+		return
+	}
+	admin.NetDbStaging = previous
+	writeAdminJSON(w, http.StatusOK, NetDbStatus{Active: admin.Reseeder.NetDbPath(), Staging: admin.NetDbStaging, Rebuild: stats})
+}
+
+// BundleHistoryStatus is the response of the /admin/bundles endpoints.
+type BundleHistoryStatus struct {
+	// Serving is the generation bundles are currently served from
+	Serving uint64 `json:"serving"`
+	// Pinned is set while Serving was chosen with /admin/bundles/pin
+	Pinned bool `json:"pinned"`
+	// Generations lists the retained bundle sets, oldest first
+	Generations []BundleGeneration `json:"generations"`
+	// Error explains why a pin was refused
+	Error string `json:"error,omitempty"`
+}
+
+// bundleHistoryStatus describes the retained bundle sets as they are now.
+func (admin *Admin) bundleHistoryStatus() BundleHistoryStatus {
+	generations, serving, pinned := admin.Reseeder.BundleHistory()
+	return BundleHistoryStatus{Serving: serving, Pinned: pinned, Generations: generations}
+}
+
+// handleBundles lists the retained bundle sets.
+func (admin *Admin) handleBundles(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, admin.bundleHistoryStatus())
+}
+
+// handleBundlesPin serves the retained generation given by ?generation= in
+// place of the newest bundle set, to roll back a bad rebuild.
+func (admin *Admin) handleBundlesPin(w http.ResponseWriter, r *http.Request) {
+	generation, err := strconv.ParseUint(r.URL.Query().Get("generation"), 10, 64)
+	if err != nil {
+		status := admin.bundleHistoryStatus()
+		status.Error = "generation must be a generation number"
+		writeAdminJSON(w, http.StatusBadRequest, status)
+		return
+	}
+	if err := admin.Reseeder.PinGeneration(generation); err != nil {
+		status := admin.bundleHistoryStatus()
+		status.Error = err.Error()
+		writeAdminJSON(w, http.StatusNotFound, status)
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, admin.bundleHistoryStatus())
+}
+
+// handleBundlesUnpin goes back to serving the newest bundle set.
+func (admin *Admin) handleBundlesUnpin(w http.ResponseWriter, r *http.Request) {
+	admin.Reseeder.Unpin()
+	writeAdminJSON(w, http.StatusOK, admin.bundleHistoryStatus())
+}
+
+// FriendsStatus is the response of the /admin/friends endpoints.
+type FriendsStatus struct {
+	// Friends lists the reseed servers currently pinged
+	Friends []string `json:"friends"`
+	// Error explains why a reload was refused or failed
+	Error string `json:"error,omitempty"`
+}
+
+// handleFriends lists the reseed servers currently pinged.
+func (admin *Admin) handleFriends(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, FriendsStatus{Friends: ReseedPeers()})
+}
+
+// handleFriendsReload re-reads the reseed servers to ping. A list that fails
+// to load or parse leaves the current one in place and responds 422.
+func (admin *Admin) handleFriendsReload(w http.ResponseWriter, r *http.Request) {
+	if admin.ReloadFriends == nil {
+		writeAdminJSON(w, http.StatusConflict, FriendsStatus{Friends: ReseedPeers(), Error: "friends reloading is not configured"})
+		return
+	}
+	if err := admin.ReloadFriends(); err != nil {
+		lgr.WithError(err).Warn("Friends reload failed, keeping the current list")
+		writeAdminJSON(w, http.StatusUnprocessableEntity, FriendsStatus{Friends: ReseedPeers(), Error: err.Error()})
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, FriendsStatus{Friends: ReseedPeers()})
+}
+
+// BlacklistStatus is the response of the /admin/blacklist endpoints.
+type BlacklistStatus struct {
+	// Entries lists the blocked addresses and ranges with their sources
+	Entries []BlacklistEntry `json:"entries"`
+	// Error explains why a removal was refused
+	Error string `json:"error,omitempty"`
+}
+
+// handleBlacklist lists the blocked addresses and ranges.
+func (admin *Admin) handleBlacklist(w http.ResponseWriter, r *http.Request) {
+	if admin.Blacklist == nil {
+		writeAdminJSON(w, http.StatusConflict, BlacklistStatus{Entries: []BlacklistEntry{}, Error: "no blacklist configured"})
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, BlacklistStatus{Entries: admin.Blacklist.List()})
+}
+
+// handleBlacklistDelete unblocks the address or CIDR range in the path, such
+// as /admin/blacklist/192.0.2.7 or /admin/blacklist/192.0.2.0/24, responding
+// 404 when it was not blocked.
+func (admin *Admin) handleBlacklistDelete(w http.ResponseWriter, r *http.Request) {
+	if admin.Blacklist == nil {
+		writeAdminJSON(w, http.StatusConflict, BlacklistStatus{Entries: []BlacklistEntry{}, Error: "no blacklist configured"})
+		return
+	}
+	entry := r.PathValue("entry")
+	if !admin.Blacklist.Unblock(entry) {
+		writeAdminJSON(w, http.StatusNotFound, BlacklistStatus{Entries: admin.Blacklist.List(), Error: entry + " is not blacklisted"})
+		return
+	}
+	lgr.WithField("entry", entry).Info("Removed blacklist entry through the admin endpoint")
+	writeAdminJSON(w, http.StatusOK, BlacklistStatus{Entries: admin.Blacklist.List()})
+}
+
+// writeAdminJSON writes v as the JSON body of an admin response.
+func writeAdminJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		lgr.WithError(err).Error("Error writing admin response")
+	}
+}
@@ -0,0 +1,190 @@
+package reseed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// adminRequest sends method path to admin and decodes the NetDbStatus response.
+func adminRequest(t *testing.T, admin *Admin, method, path string) (int, NetDbStatus) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	var status NetDbStatus
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatalf("%s %s: decoding response: %v", method, path, err)
+	}
+	return w.Code, status
+}
+
+func TestAdmin_NetDbSwitch(t *testing.T) {
+	active, staging := t.TempDir(), t.TempDir()
+	admin := NewAdmin(NewReseeder(NewLocalNetDb(active, 72*time.Hour)))
+
+	if code, status := adminRequest(t, admin, http.MethodPost, "/admin/netdb/switch"); code != http.StatusConflict || status.Error == "" {
+		t.Errorf("Expected 409 without a staging netDb, got %d %+v", code, status)
+	}
+
+	admin.NetDbStaging = staging
+	code, status := adminRequest(t, admin, http.MethodGet, "/admin/netdb")
+	if code != http.StatusOK || status.Active != active || status.Staging != staging {
+		t.Errorf("GET /admin/netdb = %d %+v, want active %s and staging %s", code, status, active, staging)
+	}
+
+	// An empty staging netDb cannot produce a bundle, so nothing is switched
+	code, status = adminRequest(t, admin, http.MethodPost, "/admin/netdb/switch")
+	if code != http.StatusUnprocessableEntity || status.Error == "" {
+		t.Errorf("Expected 422 for an unusable staging netDb, got %d %+v", code, status)
+	}
+	if got := admin.Reseeder.NetDbPath(); got != active {
+		t.Errorf("Active netDb changed to %s after a failed switch", got)
+	}
+	if admin.NetDbStaging != staging {
+		t.Errorf("Staging netDb changed to %s after a failed switch", admin.NetDbStaging)
+	}
+
+	w := httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/netdb/switch", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET /admin/netdb/switch to be refused, got %d", w.Code)
+	}
+}
+
+func TestAdmin_Goroutines(t *testing.T) {
+	admin := NewAdmin(NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour)))
+	w := httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/debug/goroutines", nil))
+	var status GoroutineStatus
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatalf("Decoding response: %v", err)
+	}
+	if w.Code != http.StatusOK || status.Goroutines < 1 {
+		t.Errorf("Expected a positive goroutine count, got %d %+v", w.Code, status)
+	}
+
+	// Profiles are only served once enabled
+	w = httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected pprof to be off by default, got %d", w.Code)
+	}
+	admin.EnableProfiling()
+	w = httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Errorf("Expected the goroutine profile, got %d", w.Code)
+	}
+}
+
+func TestAdmin_BundlesPin(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.HistorySize = 1
+	reseeder.publish([][]byte{[]byte("gen-1")}, nil)
+	reseeder.publish([][]byte{[]byte("gen-2")}, nil)
+	admin := NewAdmin(reseeder)
+
+	request := func(method, path string) (int, BundleHistoryStatus) {
+		w := httptest.NewRecorder()
+		admin.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		var status BundleHistoryStatus
+		if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+			t.Fatalf("%s %s: decoding response: %v", method, path, err)
+		}
+		return w.Code, status
+	}
+
+	if code, status := request(http.MethodGet, "/admin/bundles"); code != http.StatusOK || status.Serving != 2 || len(status.Generations) != 2 {
+		t.Errorf("Expected two generations serving the second, got %d %+v", code, status)
+	}
+	if code, status := request(http.MethodPost, "/admin/bundles/pin?generation=x"); code != http.StatusBadRequest || status.Error == "" {
+		t.Errorf("Expected 400 for a malformed generation, got %d %+v", code, status)
+	}
+	if code, status := request(http.MethodPost, "/admin/bundles/pin?generation=7"); code != http.StatusNotFound || status.Serving != 2 {
+		t.Errorf("Expected 404 for a generation not retained, got %d %+v", code, status)
+	}
+	if code, status := request(http.MethodPost, "/admin/bundles/pin?generation=1"); code != http.StatusOK || status.Serving != 1 || !status.Pinned {
+		t.Errorf("Expected generation 1 to be pinned, got %d %+v", code, status)
+	}
+	if code, status := request(http.MethodPost, "/admin/bundles/unpin"); code != http.StatusOK || status.Serving != 2 || status.Pinned {
+		t.Errorf("Expected the newest generation after unpinning, got %d %+v", code, status)
+	}
+}
+
+func TestAdmin_FriendsReload(t *testing.T) {
+	restoreReseedPeers(t)
+	SetReseedPeers([]string{"https://before.example.org/"})
+	admin := NewAdmin(NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour)))
+
+	friendsRequest := func(method, path string) (int, FriendsStatus) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		admin.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		var status FriendsStatus
+		if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+			t.Fatalf("%s %s: decoding response: %v", method, path, err)
+		}
+		return w.Code, status
+	}
+
+	if code, status := friendsRequest(http.MethodPost, "/admin/friends/reload"); code != http.StatusConflict || status.Error == "" {
+		t.Errorf("Expected 409 without a reload function, got %d %+v", code, status)
+	}
+
+	next := []string{"https://after.example.org/"}
+	admin.ReloadFriends = func() error { return SetReseedPeers(next) }
+	code, status := friendsRequest(http.MethodPost, "/admin/friends/reload")
+	if code != http.StatusOK || len(status.Friends) != 1 || status.Friends[0] != "https://after.example.org/" {
+		t.Errorf("Expected the reloaded list, got %d %+v", code, status)
+	}
+
+	next = []string{"ftp://bad.example.org/"}
+	code, status = friendsRequest(http.MethodPost, "/admin/friends/reload")
+	if code != http.StatusUnprocessableEntity || status.Error == "" || status.Friends[0] != "https://after.example.org/" {
+		t.Errorf("Expected a bad list to be refused and the current one kept, got %d %+v", code, status)
+	}
+
+	if code, status := friendsRequest(http.MethodGet, "/admin/friends"); code != http.StatusOK || len(status.Friends) != 1 {
+		t.Errorf("GET /admin/friends = %d %+v", code, status)
+	}
+}
+
+func TestAdmin_Blacklist(t *testing.T) {
+	admin := NewAdmin(NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour)))
+
+	blacklistRequest := func(method, path string) (int, BlacklistStatus) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		admin.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		var status BlacklistStatus
+		if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+			t.Fatalf("%s %s: decoding response: %v", method, path, err)
+		}
+		return w.Code, status
+	}
+
+	if code, status := blacklistRequest(http.MethodGet, "/admin/blacklist"); code != http.StatusConflict || status.Error == "" {
+		t.Errorf("Expected 409 without a blacklist, got %d %+v", code, status)
+	}
+
+	admin.Blacklist = NewBlacklist()
+	admin.Blacklist.BlockIP("198.51.100.7")
+	if err := admin.Blacklist.BlockRange("192.0.2.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	code, status := blacklistRequest(http.MethodGet, "/admin/blacklist")
+	if code != http.StatusOK || len(status.Entries) != 2 || status.Entries[1] != (BlacklistEntry{"198.51.100.7", BlacklistSourceRuntime}) {
+		t.Errorf("GET /admin/blacklist = %d %+v", code, status)
+	}
+
+	code, status = blacklistRequest(http.MethodDelete, "/admin/blacklist/192.0.2.0/24")
+	if code != http.StatusOK || len(status.Entries) != 1 || admin.Blacklist.isBlocked("192.0.2.5") {
+		t.Errorf("Expected the range to be removed, got %d %+v", code, status)
+	}
+	if code, status := blacklistRequest(http.MethodDelete, "/admin/blacklist/203.0.113.1"); code != http.StatusNotFound || status.Error == "" || len(status.Entries) != 1 {
+		t.Errorf("Expected 404 for an entry that is not blocked, got %d %+v", code, status)
+	}
+}
@@ -0,0 +1,64 @@
+package reseed
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/justinas/alice"
+)
+
+// Allowlist holds client addresses that are exempt from the per-client bundle
+// download limit, such as the shared NAT exit of a group of routers. It takes
+// the same IP and CIDR entries as a Blacklist and matches them the same way.
+type Allowlist struct {
+	entries *Blacklist
+}
+
+// NewAllowlist creates an empty allowlist.
+func NewAllowlist() *Allowlist {
+	return &Allowlist{entries: NewBlacklist()}
+}
+
+// LoadFile adds the IP addresses and CIDR ranges in file, one per line, in
+// the format of Blacklist.LoadFile.
+func (a *Allowlist) LoadFile(file string) error {
+	return a.entries.loadFile(file, "allowlist")
+}
+
+// AllowIP exempts a single IP address.
+func (a *Allowlist) AllowIP(ip string) {
+	a.entries.BlockIP(ip)
+}
+
+// AllowRange exempts every address in a CIDR range such as 192.0.2.0/24.
+func (a *Allowlist) AllowRange(cidr string) error {
+	return a.entries.BlockRange(cidr)
+}
+
+// Contains reports whether ip is on the allowlist.
+func (a *Allowlist) Contains(ip string) bool {
+	return a.entries.isBlocked(ip)
+}
+
+// allowlisted wraps a rate limit so that clients on srv.Allowlist skip it and
+// go straight to the next handler. The check runs before the limiter sees the
+// request, so allowlisted downloads do not use up anyone's quota either.
+func (srv *Server) allowlisted(limit alice.Constructor) alice.Constructor {
+	return func(next http.Handler) http.Handler {
+		limited := limit(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if srv.Allowlist != nil {
+				ip, _, err := net.SplitHostPort(r.RemoteAddr)
+				if err != nil {
+					// proxiedMiddleware leaves a bare address without a port
+					ip = r.RemoteAddr
+				}
+				if srv.Allowlist.Contains(ip) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package reseed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowlist_Contains(t *testing.T) {
+	al := NewAllowlist()
+	al.AllowIP("198.51.100.7")
+	if err := al.AllowRange("192.0.2.0/24"); err != nil {
+		t.Fatalf("AllowRange() error: %v", err)
+	}
+	if err := al.AllowRange("192.0.2.1"); err == nil {
+		t.Error("Expected an address without a prefix length to be refused")
+	}
+	for ip, want := range map[string]bool{"198.51.100.7": true, "192.0.2.200": true, "198.51.100.8": false, "not-an-ip": false} {
+		if got := al.Contains(ip); got != want {
+			t.Errorf("Contains(%q) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestAllowlist_BypassesSu3RateLimit(t *testing.T) {
+	srv := newReadyServer(t)
+	get := func(remoteAddr string) int {
+		req := httptest.NewRequest("GET", "/i2pseeds.su3", nil)
+		req.Header.Set("User-Agent", I2pUserAgent)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	limited := false
+	for i := 0; i < 5 && !limited; i++ {
+		limited = get("198.51.100.1:1234") == http.StatusTooManyRequests
+	}
+	if !limited {
+		t.Fatal("Expected a client off the allowlist to be rate limited")
+	}
+
+	srv.Allowlist = NewAllowlist()
+	if err := srv.Allowlist.AllowRange("192.0.2.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if code := get("192.0.2.1:1234"); code != http.StatusOK {
+			t.Fatalf("Request %d from an allowlisted client: got %d, want 200", i+1, code)
+		}
+	}
+	if get("198.51.100.1:1234") != http.StatusTooManyRequests {
+		t.Error("Expected the allowlist not to lift limits for other clients")
+	}
+}
@@ -1,11 +1,18 @@
 package reseed
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Blacklist manages a thread-safe collection of blocked IP addresses for reseed service security.
@@ -15,6 +22,15 @@ import (
 type Blacklist struct {
 	// blacklist stores the blocked IP addresses as a map for O(1) lookup performance
 	blacklist map[string]bool
+	// ranges stores blocked CIDR ranges, checked only when the exact lookup misses
+	ranges []*net.IPNet
+	// remote and remoteRanges hold the list last fetched by LoadURL, kept
+	// apart so a refresh replaces it without touching other entries
+	remote       map[string]bool
+	remoteRanges []*net.IPNet
+	// fromFile records the entries LoadFile added, so List can tell them
+	// apart from ones blocked at runtime
+	fromFile map[string]bool
 	// m provides thread-safe access to the blacklist map using read-write semantics
 	m sync.RWMutex
 }
@@ -23,22 +39,39 @@ type Blacklist struct {
 // Returns a ready-to-use Blacklist that can immediately accept IP blocking operations and
 // concurrent access from multiple goroutines handling network connections.
 func NewBlacklist() *Blacklist {
-	return &Blacklist{blacklist: make(map[string]bool), m: sync.RWMutex{}}
+	return &Blacklist{blacklist: make(map[string]bool), fromFile: make(map[string]bool), m: sync.RWMutex{}}
 }
 
 // LoadFile reads IP addresses from a text file and adds them to the blacklist.
-// Each line in the file should contain one IP address. Empty lines are ignored.
+// Each line in the file should contain one IP address or CIDR range, such as
+// 192.0.2.0/24. Empty lines are ignored, and malformed ranges are skipped.
 // Returns error if file cannot be read, otherwise successfully populates the blacklist.
 func (s *Blacklist) LoadFile(file string) error {
+	return s.loadFile(file, "blacklist")
+}
+
+// loadFile is LoadFile, naming the file in its log entries as a file of list,
+// such as "blacklist" or "allowlist", for the lists built on a Blacklist.
+func (s *Blacklist) loadFile(file, list string) error {
 	// Skip processing if empty filename provided to avoid unnecessary file operations
 	if file != "" {
 		if content, err := os.ReadFile(file); err == nil {
 			// Process each line as a separate IP address for blocking
 			for _, ip := range strings.Split(string(content), "\n") {
+				if strings.Contains(ip, "/") && !strings.HasPrefix(strings.TrimSpace(ip), "#") {
+					if err := s.BlockRange(strings.TrimSpace(ip)); err != nil {
+						lgr.WithError(err).WithField(list+"_file", file).Warn("Skipping malformed CIDR range in " + list + " file")
+						continue
+					}
+					_, ipnet, _ := net.ParseCIDR(strings.TrimSpace(ip))
+					s.markFromFile(ipnet.String())
+					continue
+				}
 				s.BlockIP(ip)
+				s.markFromFile(strings.TrimSpace(ip))
 			}
 		} else {
-			lgr.WithError(err).WithField("blacklist_file", file).Error("Failed to load blacklist file")
+			lgr.WithError(err).WithField(list+"_file", file).Error("Failed to load " + list + " file")
 			return err
 		}
 	}
@@ -46,6 +79,79 @@ func (s *Blacklist) LoadFile(file string) error {
 	return nil
 }
 
+// blacklistClient fetches remote blacklists. The timeout keeps a hung list
+// server from stalling the refresher.
+var blacklistClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxBlacklistSize bounds how much of a remote blacklist is read.
+const maxBlacklistSize = 10 * 1024 * 1024
+
+// LoadURL fetches a blacklist over HTTP(S) with one IP address or CIDR range
+// per line and replaces the list fetched last time with it, in a single swap.
+// Blank lines and lines starting with # are skipped, and so are lines that are
+// neither an address nor a range. On error the previous list is kept.
+// Entries from LoadFile, BlockIP and BlockRange are not affected.
+func (s *Blacklist) LoadURL(url string) error {
+	resp, err := blacklistClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching blacklist %s: %s", url, resp.Status)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxBlacklistSize+1))
+	if err != nil {
+		return err
+	}
+	if len(content) > maxBlacklistSize {
+		return fmt.Errorf("blacklist %s is larger than %d bytes", url, maxBlacklistSize)
+	}
+
+	remote := make(map[string]bool)
+	var remoteRanges []*net.IPNet
+	skipped := 0
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, ipnet, err := net.ParseCIDR(line); err == nil {
+			remoteRanges = append(remoteRanges, ipnet)
+		} else if ip := net.ParseIP(line); ip != nil {
+			remote[ip.String()] = true
+		} else {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		lgr.WithField("blacklist_url", url).WithField("skipped", skipped).Warn("Skipped blacklist lines that are not an IP address or CIDR range")
+	}
+
+	s.m.Lock()
+	s.remote, s.remoteRanges = remote, remoteRanges
+	s.m.Unlock()
+	lgr.WithField("blacklist_url", url).WithField("addresses", len(remote)).WithField("ranges", len(remoteRanges)).Info("Loaded remote blacklist")
+	return nil
+}
+
+// Refresh calls LoadURL every interval until ctx is done. A failed refresh is
+// logged and the previously fetched list stays in force.
+func (s *Blacklist) Refresh(ctx context.Context, url string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.LoadURL(url); err != nil {
+				lgr.WithError(err).WithField("blacklist_url", url).Warn("Blacklist refresh failed, keeping the previous list")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // BlockIP adds an IP address to the blacklist for connection filtering.
 // The IP will be rejected in all future connection attempts until the blacklist is cleared.
 // This method is thread-safe and can be called concurrently from multiple goroutines.
@@ -57,14 +163,161 @@ func (s *Blacklist) BlockIP(ip string) {
 	s.blacklist[ip] = true
 }
 
+// BlockRange adds a CIDR range, such as 192.0.2.0/24 or 2001:db8::/32, to the
+// blacklist so that every address in it is rejected. Returns an error if cidr
+// is not a valid range.
+func (s *Blacklist) BlockRange(cidr string) error {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.ranges = append(s.ranges, ipnet)
+	return nil
+}
+
+// markFromFile records that entry was loaded from a blacklist file.
+func (s *Blacklist) markFromFile(entry string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.fromFile[entry] = true
+}
+
+// Sources of a BlacklistEntry.
+const (
+	// BlacklistSourceFile marks entries loaded with LoadFile
+	BlacklistSourceFile = "file"
+	// BlacklistSourceURL marks entries fetched with LoadURL
+	BlacklistSourceURL = "url"
+	// BlacklistSourceRuntime marks entries added with BlockIP or BlockRange
+	// while the server runs, such as automatic abuse blocks
+	BlacklistSourceRuntime = "runtime"
+)
+
+// BlacklistEntry is one blocked address or CIDR range and where it came from.
+type BlacklistEntry struct {
+	Entry  string `json:"entry"`
+	Source string `json:"source"`
+}
+
+// List returns the blocked addresses and CIDR ranges in the order of Entries,
+// each with its source. Comment lines from loaded files are left out.
+func (s *Blacklist) List() []BlacklistEntry {
+	entries := s.Entries()
+
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	list := make([]BlacklistEntry, 0, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry, "#") {
+			continue
+		}
+		source := BlacklistSourceRuntime
+		if s.fromFile[entry] {
+			source = BlacklistSourceFile
+		} else if s.remote[entry] || slices.ContainsFunc(s.remoteRanges, func(ipnet *net.IPNet) bool { return ipnet.String() == entry }) {
+			source = BlacklistSourceURL
+		}
+		list = append(list, BlacklistEntry{Entry: entry, Source: source})
+	}
+	return list
+}
+
+// Unblock removes an address or CIDR range, written as List reports it, from
+// every source and reports whether it was blocked. The removal lasts until the
+// entry is loaded again: at the next LoadURL refresh for remote entries, or
+// the next start for ones from a file.
+func (s *Blacklist) Unblock(entry string) bool {
+	entry = strings.TrimSpace(entry)
+	if _, ipnet, err := net.ParseCIDR(entry); err == nil {
+		entry = ipnet.String()
+	} else if ip := net.ParseIP(entry); ip != nil {
+		entry = ip.String()
+	}
+	matches := func(ipnet *net.IPNet) bool { return ipnet.String() == entry }
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	removed := false
+	// Keys from files keep the whitespace of their line, so compare trimmed
+	for ip := range s.blacklist {
+		if strings.TrimSpace(ip) == entry {
+			delete(s.blacklist, ip)
+			removed = true
+		}
+	}
+	if s.remote[entry] {
+		delete(s.remote, entry)
+		removed = true
+	}
+	if n := len(s.ranges) + len(s.remoteRanges); n > 0 {
+		s.ranges = slices.DeleteFunc(s.ranges, matches)
+		s.remoteRanges = slices.DeleteFunc(s.remoteRanges, matches)
+		removed = removed || len(s.ranges)+len(s.remoteRanges) < n
+	}
+	delete(s.fromFile, entry)
+	return removed
+}
+
+// Entries returns the blocked addresses and CIDR ranges in sorted order, with
+// surrounding whitespace removed and blank lines from loaded files dropped.
+func (s *Blacklist) Entries() []string {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	entries := make([]string, 0, len(s.blacklist)+len(s.ranges)+len(s.remote)+len(s.remoteRanges))
+	seen := make(map[string]bool, cap(entries))
+	for _, list := range []map[string]bool{s.blacklist, s.remote} {
+		for ip, blocked := range list {
+			ip = strings.TrimSpace(ip)
+			if !blocked || ip == "" || seen[ip] {
+				continue
+			}
+			seen[ip] = true
+			entries = append(entries, ip)
+		}
+	}
+	for _, ipnet := range slices.Concat(s.ranges, s.remoteRanges) {
+		if cidr := ipnet.String(); !seen[cidr] {
+			seen[cidr] = true
+			entries = append(entries, cidr)
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
 func (s *Blacklist) isBlocked(ip string) bool {
 	// Use read lock for concurrent access during connection checking
 	s.m.RLock()
 	defer s.m.RUnlock()
 
-	blocked, found := s.blacklist[ip]
+	if s.blacklist[ip] || s.remote[ip] {
+		return true
+	}
+	if len(s.ranges) == 0 && len(s.remoteRanges) == 0 {
+		return false
+	}
 
-	return found && blocked
+	// Only fall back to a linear scan of the ranges when the exact lookup misses
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, ranges := range [][]*net.IPNet{s.ranges, s.remoteRanges} {
+		for _, ipnet := range ranges {
+			if ipnet.Contains(addr) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type blacklistListener struct {
@@ -90,6 +343,7 @@ func (ln blacklistListener) Accept() (net.Conn, error) {
 
 	// Reject connection immediately if IP is blacklisted for security
 	if ln.blacklist.isBlocked(ip) {
+		metrics.blacklisted.Add(1)
 		lgr.WithField("blocked_ip", ip).Warn("Connection rejected: IP address is blacklisted")
 		tc.Close()
 		return nil, errors.New("connection rejected: IP address is blacklisted")
@@ -1,9 +1,13 @@
 package reseed
 
 import (
+	"context"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -157,6 +161,48 @@ func TestBlacklist_LoadFile_Success(t *testing.T) {
 	}
 }
 
+func TestBlacklist_LoadFile_CIDR(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "blacklist.txt")
+	ipList := "198.51.100.7\n192.0.2.0/24\n2001:db8::/32\r\n10.0.0.0/33\n# see https://example.com/abuse\n"
+	if err := os.WriteFile(tempFile, []byte(ipList), 0o644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	bl := NewBlacklist()
+	if err := bl.LoadFile(tempFile); err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+
+	for _, ip := range []string{"198.51.100.7", "192.0.2.0", "192.0.2.255", "2001:db8::1"} {
+		if !bl.isBlocked(ip) {
+			t.Errorf("IP %s should be blocked", ip)
+		}
+	}
+	for _, ip := range []string{"198.51.100.8", "192.0.3.1", "10.0.0.1", "2001:db9::1", "not-an-ip"} {
+		if bl.isBlocked(ip) {
+			t.Errorf("IP %s should not be blocked", ip)
+		}
+	}
+
+	got := strings.Join(bl.Entries(), ",")
+	if want := "# see https://example.com/abuse,192.0.2.0/24,198.51.100.7,2001:db8::/32"; got != want {
+		t.Errorf("Entries() = %q, want %q", got, want)
+	}
+}
+
+func TestBlacklist_BlockRange_Invalid(t *testing.T) {
+	bl := NewBlacklist()
+	if err := bl.BlockRange("192.0.2.1"); err == nil {
+		t.Error("Expected an address without a prefix length to be refused")
+	}
+	if err := bl.BlockRange("192.0.2.0/24"); err != nil {
+		t.Fatalf("BlockRange() error: %v", err)
+	}
+	if !bl.isBlocked("192.0.2.42") {
+		t.Error("Expected an address inside the range to be blocked")
+	}
+}
+
 func TestBlacklist_LoadFile_EmptyFile(t *testing.T) {
 	// Create empty temporary file
 	tempDir := t.TempDir()
@@ -410,3 +456,158 @@ func TestBlacklist_ThreadSafety(t *testing.T) {
 
 	// If we get here without data races, the test passes
 }
+
+func TestBlacklist_Entries(t *testing.T) {
+	bl := NewBlacklist()
+	for _, ip := range []string{"192.0.2.2", "", "192.0.2.1\r", " 192.0.2.1", "2001:db8::1"} {
+		bl.BlockIP(ip)
+	}
+
+	got := strings.Join(bl.Entries(), ",")
+	if want := "192.0.2.1,192.0.2.2,2001:db8::1"; got != want {
+		t.Errorf("Entries() = %q, want %q", got, want)
+	}
+}
+
+func TestBlacklist_LoadURL(t *testing.T) {
+	var mu sync.Mutex
+	list, status := "198.51.100.7\n192.0.2.0/24\n# comment\n\nnot-an-ip\n", http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte(list))
+	}))
+	defer ts.Close()
+	serve := func(newList string, newStatus int) {
+		mu.Lock()
+		list, status = newList, newStatus
+		mu.Unlock()
+	}
+
+	bl := NewBlacklist()
+	bl.BlockIP("203.0.113.9")
+	if err := bl.LoadURL(ts.URL); err != nil {
+		t.Fatalf("LoadURL() error: %v", err)
+	}
+	for _, ip := range []string{"198.51.100.7", "192.0.2.44", "203.0.113.9"} {
+		if !bl.isBlocked(ip) {
+			t.Errorf("IP %s should be blocked", ip)
+		}
+	}
+
+	serve("2001:db8::1\n", http.StatusOK)
+	if err := bl.LoadURL(ts.URL); err != nil {
+		t.Fatalf("LoadURL() error: %v", err)
+	}
+	if bl.isBlocked("198.51.100.7") || bl.isBlocked("192.0.2.44") {
+		t.Error("Expected the second fetch to replace the first list")
+	}
+	if !bl.isBlocked("2001:db8::1") || !bl.isBlocked("203.0.113.9") {
+		t.Error("Expected the new list and the local entry to be blocked")
+	}
+
+	serve("", http.StatusInternalServerError)
+	if err := bl.LoadURL(ts.URL); err == nil {
+		t.Error("Expected a 500 to be reported")
+	}
+	if err := bl.LoadURL("http://127.0.0.1:0/blacklist.txt"); err == nil {
+		t.Error("Expected an unreachable URL to be reported")
+	}
+	if !bl.isBlocked("2001:db8::1") {
+		t.Error("Expected a failed fetch to keep the previous list")
+	}
+	if got := strings.Join(bl.Entries(), ","); got != "2001:db8::1,203.0.113.9" {
+		t.Errorf("Entries() = %q", got)
+	}
+}
+
+func TestBlacklist_Refresh(t *testing.T) {
+	var mu sync.Mutex
+	list := "198.51.100.7\n"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(list))
+	}))
+	defer ts.Close()
+
+	bl := NewBlacklist()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		bl.Refresh(ctx, ts.URL, 10*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !bl.isBlocked("198.51.100.7") {
+		if time.Now().After(deadline) {
+			t.Fatal("Refresh never loaded the list")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	list = "198.51.100.8\n"
+	mu.Unlock()
+	for !bl.isBlocked("198.51.100.8") || bl.isBlocked("198.51.100.7") {
+		if time.Now().After(deadline) {
+			t.Fatal("Refresh never picked up the changed list")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Refresh did not return after its context was cancelled")
+	}
+}
+
+func TestBlacklist_ListAndUnblock(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "blacklist.txt")
+	if err := os.WriteFile(tempFile, []byte("198.51.100.7\r\n192.0.2.0/24\n# abusers\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("203.0.113.0/28\n"))
+	}))
+	defer ts.Close()
+
+	bl := NewBlacklist()
+	if err := bl.LoadFile(tempFile); err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if err := bl.LoadURL(ts.URL); err != nil {
+		t.Fatalf("LoadURL() failed: %v", err)
+	}
+	bl.BlockIP("2001:db8::1")
+
+	want := []BlacklistEntry{
+		{"192.0.2.0/24", BlacklistSourceFile},
+		{"198.51.100.7", BlacklistSourceFile},
+		{"2001:db8::1", BlacklistSourceRuntime},
+		{"203.0.113.0/28", BlacklistSourceURL},
+	}
+	if got := bl.List(); !slices.Equal(got, want) {
+		t.Fatalf("List() = %v, want %v", got, want)
+	}
+
+	for _, entry := range []string{"198.51.100.7", "192.0.2.0/24", "2001:0db8::1", "203.0.113.0/28"} {
+		if !bl.Unblock(entry) {
+			t.Errorf("Unblock(%q) = false, want true", entry)
+		}
+	}
+	if bl.Unblock("198.51.100.7") {
+		t.Error("Expected a second Unblock of the same entry to report false")
+	}
+	for _, ip := range []string{"198.51.100.7", "192.0.2.9", "2001:db8::1", "203.0.113.3"} {
+		if bl.isBlocked(ip) {
+			t.Errorf("IP %s is still blocked after Unblock", ip)
+		}
+	}
+	if got := bl.List(); len(got) != 0 {
+		t.Errorf("Expected an empty list, got %v", got)
+	}
+}
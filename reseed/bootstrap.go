@@ -0,0 +1,160 @@
+package reseed
+
+import (
+	"context"
+	"fmt"
+	rand2 "math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-i2p/common/router_info"
+)
+
+// BootstrapTarget is a router from a reseed bundle and the NTCP2 addresses it
+// publishes, which are the ones a plain TCP connect can test.
+type BootstrapTarget struct {
+	Name  string
+	Addrs []string
+}
+
+// BootstrapProbe is the outcome of trying to reach one sampled router.
+type BootstrapProbe struct {
+	Name string
+	// Addr is the address that answered, or the last one tried
+	Addr string
+	// Err is nil when the router was reached
+	Err error
+}
+
+// BootstrapReport summarises how many routers of a bundle answer right now.
+type BootstrapReport struct {
+	// Routers is the number of RouterInfos in the bundle
+	Routers int
+	// Sampled is the number of routers that were probed
+	Sampled int
+	// Reachable is the number of sampled routers that accepted a connection
+	Reachable int
+	// Untestable is the number of routers without a published NTCP2 address,
+	// such as SSU2-only or firewalled routers, which were not sampled
+	Untestable int
+	Probes     []BootstrapProbe
+}
+
+// ReachablePercent returns the share of sampled routers that were reached.
+func (r BootstrapReport) ReachablePercent() float64 {
+	if r.Sampled == 0 {
+		return 0
+	}
+	return 100 * float64(r.Reachable) / float64(r.Sampled)
+}
+
+// dialProbe opens a TCP connection to addr to see if a router is listening. It
+// is a variable so tests can stand in for the network.
+var dialProbe = func(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// BundleTargets unzips the content of a reseed SU3 and returns every
+// RouterInfo in it with its NTCP2 addresses. Entries that do not parse are
+// skipped, see InspectBundle for reporting them.
+func BundleTargets(content []byte) ([]BootstrapTarget, error) {
+	seeds, err := uzipSeeds(content)
+	if err != nil {
+		return nil, fmt.Errorf("bundle content is not a valid zip: %w", err)
+	}
+
+	targets := make([]BootstrapTarget, 0, len(seeds))
+	for _, seed := range seeds {
+		ri, _, err := router_info.ReadRouterInfo(seed.Data)
+		if err != nil {
+			lgr.WithError(err).WithField("name", seed.Name).Debug("Skipping unparseable RouterInfo in bundle")
+			continue
+		}
+		targets = append(targets, BootstrapTarget{Name: seed.Name, Addrs: ntcp2Addrs(&ri)})
+	}
+	return targets, nil
+}
+
+// ntcp2Addrs returns the host:port of each published NTCP2 address of ri.
+func ntcp2Addrs(ri *router_info.RouterInfo) []string {
+	var addrs []string
+	for _, addr := range ri.RouterAddresses() {
+		if !addr.IsNTCP2() {
+			continue
+		}
+		host, err := addr.Host()
+		if err != nil {
+			continue
+		}
+		port, err := addr.Port()
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, net.JoinHostPort(host.String(), port))
+	}
+	return addrs
+}
+
+// ProbeTargets connects to a random sample of at most sample testable targets,
+// up to concurrency at a time, giving each address timeout to answer. A router
+// counts as reachable once any of its addresses accepts a connection.
+func ProbeTargets(ctx context.Context, targets []BootstrapTarget, sample, concurrency int, timeout time.Duration) BootstrapReport {
+	report := BootstrapReport{Routers: len(targets)}
+	var testable []BootstrapTarget
+	for _, target := range targets {
+		if len(target.Addrs) == 0 {
+			report.Untestable++
+			continue
+		}
+		testable = append(testable, target)
+	}
+	rand2.Shuffle(len(testable), func(i, j int) { testable[i], testable[j] = testable[j], testable[i] })
+	if sample > 0 && len(testable) > sample {
+		testable = testable[:sample]
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	report.Sampled = len(testable)
+	report.Probes = make([]BootstrapProbe, len(testable))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range testable {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			report.Probes[i] = probeTarget(ctx, target, timeout)
+		}()
+	}
+	wg.Wait()
+
+	for _, probe := range report.Probes {
+		if probe.Err == nil {
+			report.Reachable++
+		}
+	}
+	return report
+}
+
+// probeTarget tries each address of target in turn until one answers.
+func probeTarget(ctx context.Context, target BootstrapTarget, timeout time.Duration) BootstrapProbe {
+	probe := BootstrapProbe{Name: target.Name}
+	for _, addr := range target.Addrs {
+		dialCtx, cancel := context.WithTimeout(ctx, timeout)
+		probe.Addr, probe.Err = addr, dialProbe(dialCtx, addr)
+		cancel()
+		if probe.Err == nil {
+			break
+		}
+	}
+	return probe
+}
@@ -0,0 +1,69 @@
+package reseed
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestProbeTargets(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	targets := []BootstrapTarget{
+		{Name: "up", Addrs: []string{ln.Addr().String()}},
+		{Name: "second-address", Addrs: []string{closed.Addr().String(), ln.Addr().String()}},
+		{Name: "down", Addrs: []string{closed.Addr().String()}},
+		{Name: "ssu2-only"},
+	}
+	report := ProbeTargets(context.Background(), targets, 0, 2, time.Second)
+
+	if report.Routers != 4 || report.Sampled != 3 || report.Reachable != 2 || report.Untestable != 1 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	for _, probe := range report.Probes {
+		if reached := probe.Err == nil; reached == (probe.Name == "down") {
+			t.Errorf("Probe of %s: addr %s, err %v", probe.Name, probe.Addr, probe.Err)
+		}
+		if probe.Name == "second-address" && probe.Addr != ln.Addr().String() {
+			t.Errorf("Expected the second address to be the one reached, got %s", probe.Addr)
+		}
+	}
+	if got := report.ReachablePercent(); got < 66 || got > 67 {
+		t.Errorf("ReachablePercent() = %v, want 66.7", got)
+	}
+}
+
+func TestProbeTargets_Sample(t *testing.T) {
+	orig := dialProbe
+	dialProbe = func(ctx context.Context, addr string) error { return nil }
+	defer func() { dialProbe = orig }()
+
+	var targets []BootstrapTarget
+	for i := 0; i < 50; i++ {
+		targets = append(targets, BootstrapTarget{Name: fmt.Sprint(i), Addrs: []string{fmt.Sprintf("192.0.2.%d:9000", i)}})
+	}
+	report := ProbeTargets(context.Background(), targets, 10, 4, time.Second)
+	if report.Routers != 50 || report.Sampled != 10 || report.Reachable != 10 || len(report.Probes) != 10 {
+		t.Errorf("Expected 10 of 50 routers to be sampled, got %+v", report)
+	}
+	if (BootstrapReport{}).ReachablePercent() != 0 {
+		t.Error("Expected an empty sample to report 0%")
+	}
+}
+
+func TestBundleTargets_InvalidZip(t *testing.T) {
+	if _, err := BundleTargets([]byte("not a zip")); err == nil {
+		t.Error("Expected non-zip content to be refused")
+	}
+}
@@ -0,0 +1,131 @@
+package reseed
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bundleIndexName is the file listing the numbered bundle URLs in multi-bundle mode.
+const bundleIndexName = "i2pseeds-index.txt"
+
+// DefaultDownloadName is the filename offered for served bundles unless
+// Server.DownloadName overrides it.
+const DefaultDownloadName = "i2pseeds.su3"
+
+// ValidateDownloadName checks that name can be sent unquoted in a
+// Content-Disposition header: a plain filename of letters, digits, '.', '_' and '-'.
+func ValidateDownloadName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("download name %q is not a filename", name)
+	}
+	for _, r := range name {
+		if !isPrefixChar(r) || r == '~' {
+			return fmt.Errorf("download name %q contains %q; use only letters, digits, '.', '_' and '-'", name, r)
+		}
+	}
+	return nil
+}
+
+// downloadName returns the Content-Disposition filename for bundle n of the
+// current set, or for the per-peer bundle when n is negative.
+func (srv *Server) downloadName(n int) string {
+	name := srv.DownloadName
+	if name == "" {
+		name = DefaultDownloadName
+	}
+	if n < 0 {
+		return name
+	}
+	ext := path.Ext(name)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext)
+}
+
+// bundleRouter dispatches the numbered bundle URLs and the bundle index to their
+// handlers when MultiBundle is enabled, and everything else to next. Bundle
+// numbers are not fixed ahead of time, so they cannot be registered on the mux.
+func (srv *Server) bundleRouter(prefix string, next, bundle, index http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if srv.MultiBundle {
+			if r.URL.Path == prefix+"/"+bundleIndexName {
+				index.ServeHTTP(w, r)
+				return
+			}
+			if _, ok := bundleIndex(prefix, r.URL.Path); ok {
+				bundle.ServeHTTP(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bundleIndex parses a prefix+"/i2pseeds-N.su3" path and returns N.
+func bundleIndex(prefix, path string) (int, bool) {
+	name, ok := strings.CutPrefix(path, prefix+"/i2pseeds-")
+	if !ok {
+		return 0, false
+	}
+	name, ok = strings.CutSuffix(name, ".su3")
+	if !ok || name == "" || strings.TrimLeft(name, "0123456789") != "" {
+		return 0, false
+	}
+	if len(name) > 1 && name[0] == '0' {
+		// Only one spelling per bundle, so caches see a single stable URL
+		return 0, false
+	}
+	n, err := strconv.Atoi(name)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// bundleHandler serves a single bundle of the current set by its index.
+func (srv *Server) bundleHandler(prefix string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n, _ := bundleIndex(prefix, r.URL.Path)
+		su3Bytes, err := srv.Reseeder.su3At(n)
+		if nil != err {
+			http.Error(w, "404 Reseed file not found", http.StatusNotFound)
+			return
+		}
+
+		if srv.oversized(su3Bytes) {
+			http.Error(w, "503 Reseed file unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		srv.setFreshnessHeaders(w, time.Now())
+		if notModified(w, r, srv.Reseeder.su3SHA256(su3Bytes)) {
+			return
+		}
+		w.Header().Set("Content-Disposition", "attachment; filename="+srv.downloadName(n))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.FormatInt(int64(len(su3Bytes)), 10))
+		if r.Method == http.MethodHead {
+			return
+		}
+		srv.Reseeder.countServed()
+
+		io.Copy(w, bytes.NewReader(su3Bytes))
+	}
+}
+
+// bundleIndexHandler lists the URL path of every bundle in the current set, one per line.
+func (srv *Server) bundleIndexHandler(prefix string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var index strings.Builder
+		for i := 0; i < srv.Reseeder.BundleCount(); i++ {
+			fmt.Fprintf(&index, "%s/i2pseeds-%d.su3\n", prefix, i)
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Length", strconv.Itoa(index.Len()))
+		io.WriteString(w, index.String())
+	}
+}
@@ -0,0 +1,120 @@
+package reseed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBundleIndex(t *testing.T) {
+	tests := []struct {
+		path   string
+		prefix string
+		want   int
+		ok     bool
+	}{
+		{"/i2pseeds-0.su3", "", 0, true},
+		{"/i2pseeds-42.su3", "", 42, true},
+		{"/netdb/i2pseeds-3.su3", "/netdb", 3, true},
+		{"/i2pseeds-3.su3", "/netdb", 0, false},
+		{"/i2pseeds.su3", "", 0, false},
+		{"/i2pseeds-.su3", "", 0, false},
+		{"/i2pseeds-01.su3", "", 0, false},
+		{"/i2pseeds--1.su3", "", 0, false},
+		{"/i2pseeds-1a.su3", "", 0, false},
+		{"/i2pseeds-1.su3/x", "", 0, false},
+		{"/i2pseeds-99999999999999999999.su3", "", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := bundleIndex(tt.prefix, tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("bundleIndex(%q, %q) = %d, %v; want %d, %v", tt.prefix, tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMultiBundle(t *testing.T) {
+	srv := NewServer("/netdb", false, "", 100, 100, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle-0"), []byte("bundle-1")})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("User-Agent", I2pUserAgent)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/netdb/i2pseeds-1.su3"); w.Code == http.StatusOK {
+		t.Error("Numbered bundles should not be served unless MultiBundle is set")
+	}
+
+	srv.MultiBundle = true
+
+	w := get("/netdb/i2pseeds-index.txt")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected index to be served, got %d", w.Code)
+	}
+	if want := "/netdb/i2pseeds-0.su3\n/netdb/i2pseeds-1.su3\n"; w.Body.String() != want {
+		t.Errorf("Unexpected index:\n  got:  %q\n  want: %q", w.Body.String(), want)
+	}
+
+	w = get("/netdb/i2pseeds-1.su3")
+	if w.Code != http.StatusOK || w.Body.String() != "bundle-1" {
+		t.Errorf("Expected bundle 1, got %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != "attachment; filename=i2pseeds-1.su3" {
+		t.Errorf("Unexpected Content-Disposition %q", got)
+	}
+
+	if w := get("/netdb/i2pseeds-2.su3"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a bundle outside the set, got %d", w.Code)
+	}
+
+	// Numbered bundles require the same User-Agent as the single-file endpoint
+	req := httptest.NewRequest("GET", "/netdb/i2pseeds-0.su3", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a non-reseed User-Agent, got %d", w.Code)
+	}
+}
+
+func TestDownloadName(t *testing.T) {
+	srv := NewServer("", false, "", 100, 100, 2000)
+	if got := srv.downloadName(-1); got != "i2pseeds.su3" {
+		t.Errorf("Expected the default name, got %q", got)
+	}
+	if got := srv.downloadName(3); got != "i2pseeds-3.su3" {
+		t.Errorf("Expected the default numbered name, got %q", got)
+	}
+
+	srv.DownloadName = "mirror_seeds.su3"
+	if got := srv.downloadName(-1); got != "mirror_seeds.su3" {
+		t.Errorf("Expected the configured name, got %q", got)
+	}
+	if got := srv.downloadName(0); got != "mirror_seeds-0.su3" {
+		t.Errorf("Expected the bundle index before the extension, got %q", got)
+	}
+	srv.DownloadName = "seeds"
+	if got := srv.downloadName(2); got != "seeds-2" {
+		t.Errorf("Expected the bundle index appended without an extension, got %q", got)
+	}
+}
+
+func TestValidateDownloadName(t *testing.T) {
+	for _, name := range []string{"i2pseeds.su3", "my-seeds_1.su3", "seeds"} {
+		if err := ValidateDownloadName(name); err != nil {
+			t.Errorf("ValidateDownloadName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "a/b.su3", `seeds".su3`, "seeds;x.su3", "my seeds.su3", "~seeds.su3"} {
+		if err := ValidateDownloadName(name); err == nil {
+			t.Errorf("ValidateDownloadName(%q) = nil, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package reseed
+
+const (
+	// RecommendedMinNumRi is the fewest RouterInfos per bundle that reliably
+	// bootstraps a new router, in line with the 50-75 I2P routers ask for.
+	RecommendedMinNumRi = 50
+	// thinPoolFactor is how many bundles' worth of RouterInfos the pool should
+	// hold; below it the bundles overlap so much that clients reseeding from
+	// different bundles learn mostly the same routers.
+	thinPoolFactor = 3
+)
+
+// warnSmallNumRi logs a warning, and reports true, when NumRi is below
+// RecommendedMinNumRi, since such bundles often leave a new router unable to
+// build its first tunnels.
+func (rs *ReseederImpl) warnSmallNumRi() bool {
+	if rs.NumRi >= RecommendedMinNumRi {
+		return false
+	}
+	lgr.WithField("numRi", rs.NumRi).WithField("recommended", RecommendedMinNumRi).
+		Warn("RouterInfos per bundle is below the recommended minimum; new routers may fail to bootstrap from these bundles")
+	return true
+}
+
+// warnThinPool logs a warning, and reports true, when the pool of RouterInfos
+// left after filtering holds fewer than thinPoolFactor bundles' worth, so the
+// bundles built from it are thin copies of each other.
+func (rs *ReseederImpl) warnThinPool(pool int) bool {
+	if pool >= rs.NumRi*thinPoolFactor {
+		return false
+	}
+	lgr.WithField("pool", pool).WithField("numRi", rs.NumRi).WithField("recommended_pool", rs.NumRi*thinPoolFactor).
+		Warn("RouterInfo pool is small for the bundle size; bundles will share most of their routers. Let the netDb grow or relax the RouterInfo filters")
+	return true
+}
@@ -0,0 +1,24 @@
+package reseed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBundleSizeWarnings(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	if reseeder.warnSmallNumRi() {
+		t.Errorf("Expected no warning for the default NumRi of %d", reseeder.NumRi)
+	}
+	if reseeder.warnThinPool(reseeder.NumRi * thinPoolFactor) {
+		t.Error("Expected no warning for a pool of thinPoolFactor bundles")
+	}
+	if !reseeder.warnThinPool(reseeder.NumRi*thinPoolFactor - 1) {
+		t.Error("Expected a warning for a pool just below thinPoolFactor bundles")
+	}
+
+	reseeder.NumRi = RecommendedMinNumRi - 1
+	if !reseeder.warnSmallNumRi() {
+		t.Errorf("Expected a warning for NumRi %d", reseeder.NumRi)
+	}
+}
@@ -0,0 +1,33 @@
+package reseed
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// bundleTTL returns how long a bundle is meant to stay fresh: BundleTTL, or
+// RebuildInterval when it is not set.
+func (rs *ReseederImpl) bundleTTL() time.Duration {
+	if rs.BundleTTL > 0 {
+		return rs.BundleTTL
+	}
+	return rs.RebuildInterval
+}
+
+// setFreshnessHeaders tells a client when the bundle it is served was built
+// and how long it is meant to be used before fetching another. The times are
+// those of the served bundle set, which is older than the last rebuild while
+// a generation is pinned. Bundles differ per client, so shared caches are told
+// not to store them.
+func (srv *Server) setFreshnessHeaders(w http.ResponseWriter, now time.Time) {
+	built, ok := srv.Reseeder.servedBuilt()
+	if !ok {
+		return
+	}
+	expires := built.Add(srv.Reseeder.bundleTTL())
+	maxAge := max(int(expires.Sub(now).Seconds()), 0)
+	w.Header().Set("Last-Modified", built.UTC().Format(http.TimeFormat))
+	w.Header().Set("Expires", expires.UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+}
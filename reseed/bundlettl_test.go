@@ -0,0 +1,84 @@
+package reseed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetFreshnessHeaders(t *testing.T) {
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.RebuildInterval = 12 * time.Hour
+
+	w := httptest.NewRecorder()
+	srv.setFreshnessHeaders(w, time.Now())
+	if w.Header().Get("Cache-Control") != "" {
+		t.Error("Expected no freshness headers before the first rebuild")
+	}
+
+	built := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	srv.Reseeder.built.Store(built)
+	w = httptest.NewRecorder()
+	srv.setFreshnessHeaders(w, built.Add(2*time.Hour))
+	if got := w.Header().Get("Cache-Control"); got != "private, max-age=36000" {
+		t.Errorf("Cache-Control = %q, want the 10h left of the rebuild interval", got)
+	}
+	if got := w.Header().Get("Last-Modified"); got != "Sun, 01 Mar 2026 12:00:00 GMT" {
+		t.Errorf("Last-Modified = %q", got)
+	}
+	if got := w.Header().Get("Expires"); got != "Mon, 02 Mar 2026 00:00:00 GMT" {
+		t.Errorf("Expires = %q", got)
+	}
+
+	srv.Reseeder.BundleTTL = time.Hour
+	w = httptest.NewRecorder()
+	srv.setFreshnessHeaders(w, built.Add(2*time.Hour))
+	if got := w.Header().Get("Cache-Control"); got != "private, max-age=0" {
+		t.Errorf("Expected an expired BundleTTL to give max-age=0, got %q", got)
+	}
+}
+
+// TestSetFreshnessHeaders_Pinned verifies that a pinned generation is served
+// with its own build time, not that of a later rebuild.
+func TestSetFreshnessHeaders_Pinned(t *testing.T) {
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.RebuildInterval = 12 * time.Hour
+	srv.Reseeder.HistorySize = 1
+
+	srv.Reseeder.publish([][]byte{[]byte("first")}, nil)
+	srv.Reseeder.publish([][]byte{[]byte("second")}, nil)
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	srv.Reseeder.history[0].Built = first
+	srv.Reseeder.lastRebuild.Store(&RebuildStats{Time: first.Add(6 * time.Hour), Bundles: 1})
+	if err := srv.Reseeder.PinGeneration(1); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	srv.setFreshnessHeaders(w, first.Add(2*time.Hour))
+	if got := w.Header().Get("Last-Modified"); got != "Sun, 01 Mar 2026 12:00:00 GMT" {
+		t.Errorf("Last-Modified = %q, want the pinned set's build time", got)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "private, max-age=36000" {
+		t.Errorf("Cache-Control = %q, want the 10h left of the pinned set", got)
+	}
+}
+
+func TestReseedHandler_FreshnessHeaders(t *testing.T) {
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle")})
+	srv.Reseeder.built.Store(time.Now())
+
+	req := httptest.NewRequest("GET", "/i2pseeds.su3", nil)
+	req.Header.Set("User-Agent", I2pUserAgent)
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Last-Modified") == "" || w.Header().Get("Expires") == "" {
+		t.Errorf("Expected a served bundle to carry freshness headers, got %d %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,67 @@
+package reseed
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// PeerSu3SHA256 returns the SHA-256 of the bundle PeerSu3Bytes would serve
+// peer, without counting it as served.
+func (rs *ReseederImpl) PeerSu3SHA256(peer Peer) ([sha256.Size]byte, error) {
+	return rs.peerSu3SHA256In(peer, 0, 1)
+}
+
+// peerSu3SHA256In is PeerSu3SHA256 restricted to partition part of parts of
+// the bundle set, matching peerSu3In.
+func (rs *ReseederImpl) peerSu3SHA256In(peer Peer, part, parts int) ([sha256.Size]byte, error) {
+	su3Bytes, err := rs.peerSu3In(peer, part, parts)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return rs.su3SHA256(su3Bytes), nil
+}
+
+// requestPeer identifies the client of r the way bundles are chosen for it.
+func requestPeer(r *http.Request) Peer {
+	if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return Peer(ip)
+	}
+	return Peer(r.RemoteAddr)
+}
+
+// checksumHandler serves the SHA-256 of the bundle the client would download
+// from the bundle URL, in the "hash  filename" format sha256sum -c reads.
+// It answers 404 unless Checksum is set, and 503 when the bundle itself would
+// be refused for exceeding MaxSu3Size.
+func (srv *Server) checksumHandler(w http.ResponseWriter, r *http.Request) {
+	if !srv.Checksum {
+		http.NotFound(w, r)
+		return
+	}
+	peer := requestPeer(r)
+	part, parts := srv.geoPartition(peer)
+	su3Bytes, err := srv.Reseeder.peerSu3In(peer, part, parts)
+	if err != nil {
+		lgr.WithError(err).WithField("peer", peer).Error("Error serving su3 checksum")
+		http.Error(w, "500 Unable to serve su3 checksum", http.StatusInternalServerError)
+		return
+	}
+	if srv.oversized(su3Bytes) {
+		http.Error(w, "503 Reseed file unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	body := fmt.Sprintf("%x  %s\n", srv.Reseeder.su3SHA256(su3Bytes), srv.downloadName(-1))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	srv.setFreshnessHeaders(w, time.Now())
+	if r.Method == http.MethodHead {
+		return
+	}
+	io.WriteString(w, body)
+}
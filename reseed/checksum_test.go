@@ -0,0 +1,68 @@
+package reseed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChecksumHandler(t *testing.T) {
+	srv := newReadyServer(t)
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle zero"), []byte("bundle one"), []byte("bundle two")})
+	get := func(path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("User-Agent", I2pUserAgent)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/i2pseeds.su3.sha256", "192.0.2.1:1234"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 with checksums disabled, got %d", w.Code)
+	}
+
+	srv.Checksum = true
+	for i := 1; i <= 6; i++ {
+		addr := fmt.Sprintf("192.0.2.%d:1234", i)
+		w := get("/i2pseeds.su3.sha256", addr)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got %d, want 200", addr, w.Code)
+		}
+		bundle := get("/i2pseeds.su3", addr).Body.Bytes()
+		sum := sha256.Sum256(bundle)
+		if want := hex.EncodeToString(sum[:]) + "  i2pseeds.su3\n"; w.Body.String() != want {
+			t.Errorf("%s: checksum %q does not match downloaded bundle %q", addr, w.Body.String(), bundle)
+		}
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+			t.Errorf("Unexpected Content-Type %q", w.Header().Get("Content-Type"))
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/i2pseeds.su3.sha256", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected other User-Agents to be refused, got %d", w.Code)
+	}
+}
+
+func TestPeerSu3SHA256(t *testing.T) {
+	srv := newReadyServer(t)
+	sum, err := srv.Reseeder.PeerSu3SHA256(Peer("192.0.2.1"))
+	if err != nil {
+		t.Fatalf("PeerSu3SHA256() error: %v", err)
+	}
+	if sum != sha256.Sum256([]byte("bundle")) {
+		t.Errorf("PeerSu3SHA256() = %x", sum)
+	}
+	srv.Reseeder.su3s.Store([][]byte{})
+	if _, err := srv.Reseeder.PeerSu3SHA256(Peer("192.0.2.1")); err == nil {
+		t.Error("Expected an error with no bundles built")
+	}
+}
@@ -0,0 +1,83 @@
+package reseed
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/beevik/ntp"
+)
+
+// ClockCheck compares the system clock against an NTP server. Bundles carry the
+// local time as their su3 version and are signed under a certificate whose
+// validity is judged by the same clock, so a clock that is far off produces
+// bundles routers reject.
+type ClockCheck struct {
+	// Server is the NTP server queried, as host or host:port
+	Server string
+	// MaxSkew is the largest clock offset tolerated
+	MaxSkew time.Duration
+	// Required makes rebuilds refuse to sign bundles while the clock is off by
+	// more than MaxSkew; otherwise the skew is only logged
+	Required bool
+
+	skewed atomic.Bool
+	offset atomic.Int64
+}
+
+// ntpOffset queries server for the offset of the local clock.
+var ntpOffset = func(server string) (time.Duration, error) {
+	resp, err := ntp.Query(server)
+	if err != nil {
+		return 0, err
+	}
+	if err := resp.Validate(); err != nil {
+		return 0, err
+	}
+	return resp.ClockOffset, nil
+}
+
+// Check queries the NTP server and records whether the clock is within
+// MaxSkew. It returns an error if the server could not be queried, in which
+// case the previous result is kept, or if the clock is off by too much.
+func (cc *ClockCheck) Check() error {
+	offset, err := ntpOffset(cc.Server)
+	if err != nil {
+		return fmt.Errorf("querying NTP server %s: %w", cc.Server, err)
+	}
+	cc.offset.Store(int64(offset))
+	skew := offset.Abs()
+	cc.skewed.Store(skew > cc.MaxSkew)
+	if skew > cc.MaxSkew {
+		return fmt.Errorf("system clock is off by %s according to %s, more than the %s allowed", offset.Round(time.Millisecond), cc.Server, cc.MaxSkew)
+	}
+	lgr.WithField("ntp_server", cc.Server).WithField("offset", offset.Round(time.Millisecond)).Debug("System clock is in sync")
+	return nil
+}
+
+// Watch runs Check every interval until ctx is cancelled, logging problems
+// as warnings.
+func (cc *ClockCheck) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := cc.Check(); err != nil {
+				lgr.WithError(err).WithField("required", cc.Required).Warn("Clock sanity check failed")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// signingAllowed returns an error when Required is set and the last successful
+// Check found the clock off by more than MaxSkew. A nil ClockCheck allows signing.
+func (cc *ClockCheck) signingAllowed() error {
+	if cc == nil || !cc.Required || !cc.skewed.Load() {
+		return nil
+	}
+	return fmt.Errorf("refusing to sign bundles: system clock is off by %s according to %s", time.Duration(cc.offset.Load()).Round(time.Millisecond), cc.Server)
+}
@@ -0,0 +1,108 @@
+package reseed
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubNTP makes ntpOffset report offset, or err, for every server.
+func stubNTP(t *testing.T, offset time.Duration, err error) {
+	t.Helper()
+	orig := ntpOffset
+	ntpOffset = func(server string) (time.Duration, error) { return offset, err }
+	t.Cleanup(func() { ntpOffset = orig })
+}
+
+func TestClockCheck(t *testing.T) {
+	clock := &ClockCheck{Server: "ntp.example", MaxSkew: 30 * time.Second, Required: true}
+
+	stubNTP(t, -10*time.Second, nil)
+	if err := clock.Check(); err != nil {
+		t.Errorf("Expected a 10s offset to pass, got %v", err)
+	}
+	if err := clock.signingAllowed(); err != nil {
+		t.Errorf("Expected signing to be allowed, got %v", err)
+	}
+
+	stubNTP(t, -2*time.Minute, nil)
+	if err := clock.Check(); err == nil {
+		t.Error("Expected a 2m offset to fail")
+	}
+	if err := clock.signingAllowed(); err == nil || !strings.Contains(err.Error(), "-2m0s") {
+		t.Errorf("Expected signing to be refused with the offset, got %v", err)
+	}
+
+	// An unreachable server keeps the last result
+	stubNTP(t, 0, errors.New("timeout"))
+	if err := clock.Check(); err == nil {
+		t.Error("Expected the query error")
+	}
+	if err := clock.signingAllowed(); err == nil {
+		t.Error("Expected signing to stay refused until a query succeeds")
+	}
+
+	clock.Required = false
+	if err := clock.signingAllowed(); err != nil {
+		t.Errorf("Expected only a warning without Required, got %v", err)
+	}
+	if err := (*ClockCheck)(nil).signingAllowed(); err != nil {
+		t.Errorf("Expected a nil ClockCheck to allow signing, got %v", err)
+	}
+}
+
+func TestRebuild_RefusesWithSkewedClock(t *testing.T) {
+	stubNTP(t, time.Hour, nil)
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.ClockCheck = &ClockCheck{Server: "ntp.example", MaxSkew: time.Minute, Required: true}
+	reseeder.ClockCheck.Check()
+	reseeder.su3s.Store([][]byte{[]byte("signed earlier")})
+
+	if err := reseeder.rebuild(); err == nil || !strings.Contains(err.Error(), "refusing to sign") {
+		t.Errorf("Expected the rebuild to refuse to sign, got %v", err)
+	}
+	if reseeder.BundleCount() != 1 {
+		t.Error("Expected the current bundles to be kept")
+	}
+}
+
+// TestWatchStops checks that the background watchers started by the reseed
+// command return once their context is cancelled.
+func TestWatchStops(t *testing.T) {
+	testCases := []struct {
+		name  string
+		stub  func(t *testing.T)
+		watch func(ctx context.Context, interval time.Duration)
+	}{
+		{
+			name:  "ClockCheck",
+			stub:  func(t *testing.T) { stubNTP(t, 0, nil) },
+			watch: (&ClockCheck{Server: "ntp.example", MaxSkew: time.Second}).Watch,
+		},
+		{
+			name:  "MemoryGuard",
+			stub:  func(t *testing.T) { stubHeap(t, 0) },
+			watch: (&MemoryGuard{Limit: 100 << 20}).Watch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.stub(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				tc.watch(ctx, time.Millisecond)
+				close(done)
+			}()
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected Watch to return once its context is cancelled")
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package reseed
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	rand2 "math/rand"
+	"slices"
+	"time"
+)
+
+// deterministicRand returns the source a Deterministic rebuild draws bundles
+// from. Its seed is a hash of seed and of every router's file name, which
+// carries the router hash, and modification time, so it changes only when a
+// router joins, leaves or republishes.
+func deterministicRand(ris []RouterInfo, seed []byte) *rand2.Rand {
+	names := make([]string, 0, len(ris))
+	modTimes := make(map[string]int64, len(ris))
+	for _, ri := range ris {
+		names = append(names, ri.Name)
+		modTimes[ri.Name] = ri.ModTime.UnixNano()
+	}
+	slices.Sort(names)
+
+	h := sha256.New()
+	h.Write(seed)
+	var buf [8]byte
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		binary.BigEndian.PutUint64(buf[:], uint64(modTimes[name]))
+		h.Write(buf[:])
+	}
+	sum := h.Sum(nil)
+	return rand2.New(rand2.NewSource(int64(binary.BigEndian.Uint64(sum))))
+}
+
+// newestModTime returns the latest modification time in ris, which versions
+// the bundles of a Deterministic rebuild in place of the time it ran.
+func newestModTime(ris []RouterInfo) time.Time {
+	var newest time.Time
+	for _, ri := range ris {
+		if ri.ModTime.After(newest) {
+			newest = ri.ModTime
+		}
+	}
+	return newest
+}
+
+// sortBundles puts su3s and their selection in the order of the selected
+// names, undoing the arrival order of the parallel su3 builders.
+func sortBundles(su3s [][]byte, selection [][]string) {
+	order := make([]int, len(su3s))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return slices.Compare(selection[a], selection[b])
+	})
+	sortedSu3s := make([][]byte, len(su3s))
+	sortedSelection := make([][]string, len(selection))
+	for i, j := range order {
+		sortedSu3s[i], sortedSelection[i] = su3s[j], selection[j]
+	}
+	copy(su3s, sortedSu3s)
+	copy(selection, sortedSelection)
+}
+
+// warnNondeterministicSigner logs a warning, and reports true, when
+// Deterministic is set but the signing key produces a different signature
+// each time, so the bundles' selection and content are stable but their bytes
+// are not.
+func (rs *ReseederImpl) warnNondeterministicSigner() bool {
+	if !rs.Deterministic {
+		return false
+	}
+	_, isECDSA := rs.SigningKey.(*ecdsa.PrivateKey)
+	if !isECDSA && !rs.RSAPSS {
+		return false
+	}
+	kind := "ECDSA"
+	if rs.RSAPSS {
+		kind = "RSA-PSS"
+	}
+	lgr.WithField("signature", kind).
+		Warn("Deterministic bundles are signed with a randomized signature scheme; their content is stable across rebuilds but their bytes are not. Use an RSA (PKCS#1 v1.5) or Ed25519 key for byte-identical bundles")
+	return true
+}
@@ -0,0 +1,110 @@
+package reseed
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRebuild_Deterministic(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 6; i++ {
+		writeTestRouterInfo(t, dir, fmt.Sprint(i), "0.9.64", "XfR")
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuild := func(seed string) [][]byte {
+		t.Helper()
+		reseeder := NewReseeder(NewLocalNetDb(dir, 72*time.Hour))
+		reseeder.SigningKey = key
+		reseeder.SignerID = []byte("test@example.i2p")
+		reseeder.NumRi = 3
+		reseeder.NumSu3 = 8
+		reseeder.MinRouterInfoFraction = 1
+		reseeder.Deterministic = true
+		reseeder.DeterministicSeed = []byte(seed)
+		if err := reseeder.rebuild(); err != nil {
+			t.Fatalf("rebuild() error: %v", err)
+		}
+		return reseeder.su3s.Load().([][]byte)
+	}
+
+	first := rebuild("")
+	if len(first) != 8 {
+		t.Fatalf("Expected 8 bundles, got %d", len(first))
+	}
+	if again := rebuild(""); !slices.EqualFunc(first, again, bytes.Equal) {
+		t.Error("Expected two rebuilds of the same netDb to produce identical bundles")
+	}
+
+	// A republished router changes the pool, and so the seed and the version
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "routerInfo-0.dat"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if changed := rebuild(""); slices.EqualFunc(first, changed, bytes.Equal) {
+		t.Error("Expected a changed netDb to produce different bundles")
+	}
+}
+
+func TestDeterministicRand_Seed(t *testing.T) {
+	now := time.Now()
+	ris := []RouterInfo{{Name: "routerInfo-a.dat", ModTime: now}, {Name: "routerInfo-b.dat", ModTime: now.Add(-time.Hour)}}
+	reversed := []RouterInfo{ris[1], ris[0]}
+
+	if deterministicRand(ris, nil).Int63() != deterministicRand(reversed, nil).Int63() {
+		t.Error("Expected the seed not to depend on the order of the pool")
+	}
+	if deterministicRand(ris, nil).Int63() == deterministicRand(ris, []byte("other")).Int63() {
+		t.Error("Expected DeterministicSeed to change the seed")
+	}
+	if !newestModTime(ris).Equal(now) {
+		t.Errorf("newestModTime() = %v, want %v", newestModTime(ris), now)
+	}
+}
+
+func TestSortBundles(t *testing.T) {
+	su3s := [][]byte{[]byte("c"), []byte("a"), []byte("b")}
+	selection := [][]string{{"routerInfo-c.dat"}, {"routerInfo-a.dat"}, {"routerInfo-b.dat"}}
+	sortBundles(su3s, selection)
+	for i, want := range []string{"a", "b", "c"} {
+		if string(su3s[i]) != want || selection[i][0] != "routerInfo-"+want+".dat" {
+			t.Errorf("Position %d: bundle %q with %v, want %s", i, su3s[i], selection[i], want)
+		}
+	}
+}
+
+func TestWarnNondeterministicSigner(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.SigningKey = ecKey
+	if reseeder.warnNondeterministicSigner() {
+		t.Error("Expected no warning without Deterministic")
+	}
+	reseeder.Deterministic = true
+	if !reseeder.warnNondeterministicSigner() {
+		t.Error("Expected a warning for an ECDSA key")
+	}
+	reseeder.SigningKey = edKey
+	if reseeder.warnNondeterministicSigner() {
+		t.Error("Expected no warning for an Ed25519 key")
+	}
+}
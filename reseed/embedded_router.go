@@ -11,9 +11,8 @@ import (
 )
 
 func (g *Server) getSAMAddr() string {
-	addr := net.JoinHostPort(g.Garlic.Config.SamHost, g.Garlic.Config.SamPort)
-	if addr != "" {
-		return addr
+	if g.samAddr != "" {
+		return g.samAddr
 	}
 	return onramp.SAM_ADDR
 }
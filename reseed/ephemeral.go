@@ -0,0 +1,66 @@
+package reseed
+
+import (
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentFS returns the homepage content tree. In ephemeral mode, see
+// Server.Ephemeral, it is the embedded content itself; otherwise it is the
+// on-disk copy in the working directory, unembedded on first use so operators
+// can customise it.
+func contentFS(ephemeral bool) (fs.FS, error) {
+	if ephemeral {
+		return fs.Sub(f, "content")
+	}
+	BaseContentPath, err := StableContentPath()
+	if err != nil {
+		return nil, err
+	}
+	return os.DirFS(BaseContentPath), nil
+}
+
+var (
+	// pingResultsMu protects pingResults from concurrent access.
+	pingResultsMu sync.RWMutex
+	// pingResults holds ephemeral ping results keyed by the name the .ping file
+	// would have had, without the extension, so ReadOut renders both the same way.
+	pingResults = map[string]string{}
+)
+
+// storePingResult records an ephemeral ping result for name.
+func storePingResult(name, result string) {
+	pingResultsMu.Lock()
+	defer pingResultsMu.Unlock()
+	pingResults[name] = result
+}
+
+// hasPingResult reports whether an ephemeral ping result exists for name.
+func hasPingResult(name string) bool {
+	pingResultsMu.RLock()
+	defer pingResultsMu.RUnlock()
+	_, ok := pingResults[name]
+	return ok
+}
+
+// todaysPingResults returns today's ephemeral ping results sorted by name,
+// dropping older days so the map does not grow for the life of the process.
+func todaysPingResults() []PingResult {
+	date := time.Now().Format("2006-01-02")
+	pingResultsMu.Lock()
+	defer pingResultsMu.Unlock()
+	var entries []PingResult
+	for name, result := range pingResults {
+		if !strings.HasSuffix(name, "-"+date) {
+			delete(pingResults, name)
+			continue
+		}
+		entries = append(entries, PingResult{Host: name, Status: result})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Host < entries[j].Host })
+	return entries
+}
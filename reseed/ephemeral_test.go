@@ -0,0 +1,101 @@
+package reseed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// ephemeralDir switches into an empty working directory and reports any file
+// written there during the test, which an ephemeral server must never do.
+func ephemeralDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(origDir)
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			t.Errorf("Ephemeral mode wrote %s to the working directory", entry.Name())
+		}
+	})
+}
+
+func TestEphemeral_ServesEmbeddedContent(t *testing.T) {
+	ephemeralDir(t)
+
+	cachedDataMu.Lock()
+	delete(CachedDataPages, "style.css")
+	cachedDataMu.Unlock()
+	cachedLanguageMu.Lock()
+	delete(CachedLanguagePages, "en")
+	cachedLanguageMu.Unlock()
+
+	want, err := f.ReadFile("content/style.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{Ephemeral: true}
+	w := httptest.NewRecorder()
+	srv.handleAFile(w, "", "style.css")
+	if w.Body.String() != string(want) {
+		t.Errorf("Expected the embedded style.css, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	srv.handleALocalizedFile(w, "en")
+	if strings.Contains(w.Body.String(), "Oops!") || !strings.Contains(w.Body.String(), "<div id=") {
+		t.Errorf("Expected rendered embedded markdown, got %q", w.Body.String())
+	}
+
+	if err := srv.validateContentPath(); err != nil {
+		t.Errorf("validateContentPath() = %v, want nil without a content directory", err)
+	}
+}
+
+func TestEphemeral_KeepsPingResultsInMemory(t *testing.T) {
+	ephemeralDir(t)
+	t.Cleanup(func() {
+		pingResultsMu.Lock()
+		pingResults = map[string]string{}
+		pingResultsMu.Unlock()
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	restoreReseedPeers(t)
+	if err := SetReseedPeers([]string{server.URL + "/", "https://stale.example.org/"}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	ReadOut(w, true)
+	if !strings.Contains(w.Body.String(), "No ping files found") {
+		t.Errorf("Expected no results before pinging, got %q", w.Body.String())
+	}
+
+	if err := PingWriteContent(server.URL+"/", true); err != nil {
+		t.Fatalf("PingWriteContent() error: %v", err)
+	}
+	storePingResult("stale.example.org-2000-01-01", "Alive: Status OK")
+
+	w = httptest.NewRecorder()
+	ReadOut(w, true)
+	body := w.Body.String()
+	if !strings.Contains(body, "Alive: Status OK") {
+		t.Errorf("Expected the in-memory ping result, got %q", body)
+	}
+	if strings.Contains(body, "stale.example.org") {
+		t.Errorf("Expected results from earlier days to be dropped, got %q", body)
+	}
+}
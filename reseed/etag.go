@@ -0,0 +1,42 @@
+package reseed
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// su3ETag returns the strong entity tag of a bundle with SHA-256 sum: the
+// quoted hex digest, the same one i2pseeds.su3.sha256 serves.
+func su3ETag(sum [sha256.Size]byte) string {
+	return fmt.Sprintf("%q", fmt.Sprintf("%x", sum))
+}
+
+// etagMatches reports whether the If-None-Match header value names etag. As
+// RFC 9110 requires for If-None-Match, weak tags match by their opaque part
+// and "*" matches any bundle.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag of the bundle with SHA-256 sum on w and, when the
+// client already holds that bundle according to If-None-Match, answers 304 and
+// reports true. The headers set on w before the call are sent with the 304.
+func notModified(w http.ResponseWriter, r *http.Request, sum [sha256.Size]byte) bool {
+	etag := su3ETag(sum)
+	w.Header().Set("ETag", etag)
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch == "" || !etagMatches(ifNoneMatch, etag) {
+		return false
+	}
+	metrics.su3NotModified.Add(1)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,97 @@
+package reseed
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReseedHandler_ETag(t *testing.T) {
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle-0"), []byte("bundle-1")})
+	srv.Reseeder.built.Store(time.Now())
+	srv.MultiBundle = true
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("User-Agent", I2pUserAgent)
+		req.RemoteAddr = "192.0.2.1:1234"
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/i2pseeds.su3", "/i2pseeds-1.su3"} {
+		w := get(path, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got %d, want 200", path, w.Code)
+		}
+		etag := w.Header().Get("ETag")
+		if want := fmt.Sprintf("%q", fmt.Sprintf("%x", sha256.Sum256(w.Body.Bytes()))); etag != want {
+			t.Errorf("%s: ETag = %s, want the SHA-256 of the body %s", path, etag, want)
+		}
+
+		served := metrics.su3Served.Load()
+		w = get(path, `"stale", W/`+etag)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("%s: expected an empty 304 for a matching If-None-Match, got %d with %d bytes", path, w.Code, w.Body.Len())
+		}
+		if w.Header().Get("ETag") != etag || w.Header().Get("Cache-Control") == "" {
+			t.Errorf("%s: expected the 304 to carry ETag and Cache-Control, got %v", path, w.Header())
+		}
+		if metrics.su3Served.Load() != served {
+			t.Errorf("%s: expected a 304 not to count as a served bundle", path)
+		}
+
+		if w := get(path, `"stale"`); w.Code != http.StatusOK {
+			t.Errorf("%s: expected a different ETag to get the bundle, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := su3ETag(sha256.Sum256([]byte("bundle")))
+	for _, tt := range []struct {
+		header string
+		want   bool
+	}{
+		{etag, true},
+		{"W/" + etag, true},
+		{`"a", ` + etag, true},
+		{"*", true},
+		{`"a"`, false},
+		{etag[1 : len(etag)-1], false},
+	} {
+		if got := etagMatches(tt.header, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSu3SHA256_TakenAtPublish(t *testing.T) {
+	rs := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	su3s := [][]byte{[]byte("bundle-0"), []byte("bundle-1")}
+	rs.publish(su3s, nil)
+
+	sums, _ := rs.sums.Load().(su3Sums)
+	for _, su3Bytes := range su3s {
+		if sum, ok := sums[&su3Bytes[0]]; !ok || sum != sha256.Sum256(su3Bytes) {
+			t.Errorf("Expected the digest of %q to be taken when it was published", su3Bytes)
+		}
+		if rs.su3SHA256(su3Bytes) != sha256.Sum256(su3Bytes) {
+			t.Errorf("su3SHA256(%q) does not match its SHA-256", su3Bytes)
+		}
+	}
+
+	// A bundle that was never published, with the same content, is hashed anew
+	other := []byte("bundle-0")
+	if _, ok := sums[&other[0]]; ok || rs.su3SHA256(other) != sha256.Sum256(other) {
+		t.Error("Expected an unpublished bundle to be hashed on the spot")
+	}
+}
@@ -0,0 +1,144 @@
+package reseed
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gorilla/handlers"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// geoRecord holds the fields read from a MaxMind database. Country databases
+// fill in Country and ASN databases the AS number, so one lookup type works
+// for either, or for both opened together.
+type geoRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	ASN uint `maxminddb:"autonomous_system_number"`
+}
+
+// geoLookup resolves a client address to its country and AS number.
+type geoLookup interface {
+	lookup(ip net.IP) geoRecord
+}
+
+// GeoIP annotates access log entries with the client's country and ASN from
+// one or more MaxMind (GeoIP2/GeoLite2) databases.
+type GeoIP struct {
+	readers []*maxminddb.Reader
+}
+
+// OpenGeoIP opens the MaxMind databases at paths, typically a Country and an
+// ASN database. Later databases only fill in fields the earlier ones left empty.
+func OpenGeoIP(paths ...string) (*GeoIP, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no GeoIP database given")
+	}
+	geo := &GeoIP{}
+	for _, path := range paths {
+		reader, err := maxminddb.Open(path)
+		if err != nil {
+			geo.Close()
+			return nil, fmt.Errorf("opening GeoIP database %s: %w", path, err)
+		}
+		geo.readers = append(geo.readers, reader)
+	}
+	return geo, nil
+}
+
+// Close releases the databases.
+func (geo *GeoIP) Close() error {
+	var errs []error
+	for _, reader := range geo.readers {
+		errs = append(errs, reader.Close())
+	}
+	geo.readers = nil
+	return errors.Join(errs...)
+}
+
+func (geo *GeoIP) lookup(ip net.IP) geoRecord {
+	var merged geoRecord
+	for _, reader := range geo.readers {
+		var record geoRecord
+		if err := reader.Lookup(ip, &record); err != nil {
+			lgr.WithError(err).WithField("ip", ip.String()).Debug("GeoIP lookup failed")
+			continue
+		}
+		if merged.Country.ISOCode == "" {
+			merged.Country.ISOCode = record.Country.ISOCode
+		}
+		if merged.ASN == 0 {
+			merged.ASN = record.ASN
+		}
+	}
+	return merged
+}
+
+// logSuffix formats the record as the fields appended to an access log line,
+// using "-" for anything unknown in the same way the combined log format does.
+func (record geoRecord) logSuffix() string {
+	country, asn := "-", "-"
+	if record.Country.ISOCode != "" {
+		country = record.Country.ISOCode
+	}
+	if record.ASN != 0 {
+		asn = strconv.FormatUint(uint64(record.ASN), 10)
+	}
+	return " country=" + country + " asn=" + asn
+}
+
+// suffixWriter appends suffix to each line written through it.
+type suffixWriter struct {
+	out    io.Writer
+	suffix string
+}
+
+func (sw suffixWriter) Write(p []byte) (int, error) {
+	line := p
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+	}
+	buf := make([]byte, 0, len(line)+len(sw.suffix)+1)
+	buf = append(append(append(buf, line...), sw.suffix...), '\n')
+	if _, err := sw.out.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// accessLog is where loggingMiddleware writes; a variable so tests can capture it.
+var accessLog io.Writer = os.Stdout
+
+// loggingMiddleware writes a combined-format access log line for each request.
+// With GeoIP configured the line ends with the client's country and ASN. It runs
+// after proxiedMiddleware, so behind a trusted proxy the forwarded client
+// address is the one looked up.
+func (srv *Server) loggingMiddleware(next http.Handler) http.Handler {
+	plain := handlers.CombinedLoggingHandler(accessLog, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if srv.GeoIP == nil {
+			plain.ServeHTTP(w, r)
+			return
+		}
+		var record geoRecord
+		if ip := clientIP(r); ip != nil {
+			record = srv.GeoIP.lookup(ip)
+		}
+		handlers.CombinedLoggingHandler(suffixWriter{out: accessLog, suffix: record.logSuffix()}, next).ServeHTTP(w, r)
+	})
+}
+
+// clientIP parses the client address from r.RemoteAddr, which may or may not carry a port.
+func clientIP(r *http.Request) net.IP {
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return net.ParseIP(host)
+}
@@ -0,0 +1,76 @@
+package reseed
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeGeo answers lookups from a fixed table.
+type fakeGeo map[string]geoRecord
+
+func (f fakeGeo) lookup(ip net.IP) geoRecord { return f[ip.String()] }
+
+func newGeoRecord(country string, asn uint) geoRecord {
+	var record geoRecord
+	record.Country.ISOCode = country
+	record.ASN = asn
+	return record
+}
+
+func TestLoggingMiddleware_GeoIP(t *testing.T) {
+	var out bytes.Buffer
+	orig := accessLog
+	accessLog = &out
+	t.Cleanup(func() { accessLog = orig })
+
+	srv := NewServer("", true, "", 100, 100, 2000)
+	handler := srv.loggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(remoteAddr, forwardedFor string) string {
+		out.Reset()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		srv.proxiedMiddleware(handler).ServeHTTP(httptest.NewRecorder(), req)
+		return out.String()
+	}
+
+	if line := serve("198.51.100.7:1234", ""); strings.Contains(line, "country=") {
+		t.Errorf("Expected no GeoIP fields without a database, got %q", line)
+	}
+
+	srv.GeoIP = fakeGeo{
+		"198.51.100.7": newGeoRecord("DE", 3320),
+		"203.0.113.9":  newGeoRecord("NL", 0),
+	}
+	if line := serve("198.51.100.7:1234", ""); !strings.HasSuffix(line, " country=DE asn=3320\n") || strings.Count(line, "\n") != 1 {
+		t.Errorf("Expected country and ASN at the end of the line, got %q", line)
+	}
+	// Behind a trusted proxy the forwarded client address is looked up
+	if line := serve("192.0.2.1:1234", "198.51.100.7, 203.0.113.9"); !strings.HasSuffix(line, " country=NL asn=-\n") {
+		t.Errorf("Expected the forwarded client to be looked up, got %q", line)
+	}
+	if line := serve("192.0.2.50:1234", ""); !strings.HasSuffix(line, " country=- asn=-\n") {
+		t.Errorf("Expected placeholders for an unknown client, got %q", line)
+	}
+}
+
+func TestOpenGeoIP_Errors(t *testing.T) {
+	if _, err := OpenGeoIP(); err == nil {
+		t.Error("Expected an error without any database")
+	}
+	bad := filepath.Join(t.TempDir(), "bad.mmdb")
+	if err := os.WriteFile(bad, []byte("not a MaxMind database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenGeoIP(bad); err == nil {
+		t.Error("Expected an error for a file that is not a MaxMind database")
+	}
+}
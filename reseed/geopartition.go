@@ -0,0 +1,82 @@
+package reseed
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// geoPartitionSeparator separates the countries of one --geo-partition group,
+// since commas already separate repeated values of the flag.
+const geoPartitionSeparator = "|"
+
+// GeoPartitions splits the bundle set into one contiguous group per entry, so
+// clients from the same region are all served from the same few bundles. A
+// client whose country is in a group hashes over that group's bundles only;
+// any other client, or one GeoIP cannot place, hashes over the whole set.
+type GeoPartitions struct {
+	groups  map[string]int
+	entries int
+}
+
+// ParseGeoPartitions reads one group per spec, each a list of ISO 3166-1
+// alpha-2 country codes such as "DE|FR|NL". A country may only be in one group.
+func ParseGeoPartitions(specs []string) (*GeoPartitions, error) {
+	if len(specs) == 0 {
+		return nil, errors.New("no country group given")
+	}
+	parts := &GeoPartitions{groups: map[string]int{}, entries: len(specs)}
+	for i, spec := range specs {
+		countries := strings.Split(spec, geoPartitionSeparator)
+		for _, country := range countries {
+			country = strings.ToUpper(strings.TrimSpace(country))
+			if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+				return nil, fmt.Errorf("group %q: %q is not a two-letter country code", spec, country)
+			}
+			if prev, ok := parts.groups[country]; ok && prev != i {
+				return nil, fmt.Errorf("group %q: %s is already in group %q", spec, country, specs[prev])
+			}
+			parts.groups[country] = i
+		}
+	}
+	return parts, nil
+}
+
+// group returns the partition for country and the number of partitions, or
+// 0 of 1 (the whole set) when country is in no group.
+func (parts *GeoPartitions) group(country string) (int, int) {
+	if parts == nil {
+		return 0, 1
+	}
+	if i, ok := parts.groups[strings.ToUpper(country)]; ok {
+		return i, parts.entries
+	}
+	return 0, 1
+}
+
+// geoPartition returns the bundle partition for peer, resolved through GeoIP.
+func (srv *Server) geoPartition(peer Peer) (int, int) {
+	if srv.GeoPartitions == nil || srv.GeoIP == nil {
+		return 0, 1
+	}
+	ip := net.ParseIP(string(peer))
+	if ip == nil {
+		return 0, 1
+	}
+	return srv.GeoPartitions.group(srv.GeoIP.lookup(ip).Country.ISOCode)
+}
+
+// partitionBundles returns partition part of parts contiguous slices of m. A
+// partition left empty because there are fewer bundles than partitions falls
+// back to the whole set so its clients are still served.
+func partitionBundles(m [][]byte, part, parts int) [][]byte {
+	if parts <= 1 {
+		return m
+	}
+	group := m[len(m)*part/parts : len(m)*(part+1)/parts]
+	if len(group) == 0 {
+		return m
+	}
+	return group
+}
@@ -0,0 +1,100 @@
+package reseed
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseGeoPartitions(t *testing.T) {
+	parts, err := ParseGeoPartitions([]string{"de|fr|NL", "US"})
+	if err != nil {
+		t.Fatalf("ParseGeoPartitions() error: %v", err)
+	}
+	for _, tt := range []struct {
+		country     string
+		part, parts int
+	}{
+		{"DE", 0, 2}, {"nl", 0, 2}, {"US", 1, 2}, {"JP", 0, 1}, {"", 0, 1},
+	} {
+		if part, n := parts.group(tt.country); part != tt.part || n != tt.parts {
+			t.Errorf("group(%q) = %d of %d, want %d of %d", tt.country, part, n, tt.part, tt.parts)
+		}
+	}
+
+	for _, bad := range [][]string{nil, {"DE|"}, {"DEU"}, {"D1"}, {"DE", "FR|de"}} {
+		if _, err := ParseGeoPartitions(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestPartitionBundles(t *testing.T) {
+	m := make([][]byte, 5)
+	for i := range m {
+		m[i] = []byte{byte(i)}
+	}
+	if got := partitionBundles(m, 0, 1); len(got) != 5 {
+		t.Errorf("Expected the whole set for a single partition, got %d bundles", len(got))
+	}
+	if got := partitionBundles(m, 0, 2); len(got) != 2 || got[0][0] != 0 {
+		t.Errorf("Expected bundles 0-1 in the first of two partitions, got %v", got)
+	}
+	if got := partitionBundles(m, 1, 2); len(got) != 3 || got[0][0] != 2 {
+		t.Errorf("Expected bundles 2-4 in the second of two partitions, got %v", got)
+	}
+	// Fewer bundles than partitions leaves some empty; they use the whole set
+	if got := partitionBundles(m[:1], 0, 2); len(got) != 1 {
+		t.Errorf("Expected an empty partition to fall back to the whole set, got %v", got)
+	}
+}
+
+// TestReseedHandler_GeoPartitions verifies that clients are served only from
+// their country group's bundles, and everyone else from the whole set.
+func TestReseedHandler_GeoPartitions(t *testing.T) {
+	srv := NewServer("", false, "", 1000, 1000, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	var bundles [][]byte
+	for i := 0; i < 4; i++ {
+		bundles = append(bundles, []byte(fmt.Sprintf("bundle-%d", i)))
+	}
+	srv.Reseeder.su3s.Store(bundles)
+	srv.Reseeder.rebuilding.Store(true)
+
+	geo := fakeGeo{}
+	for i := 0; i < 50; i++ {
+		geo[fmt.Sprintf("192.0.2.%d", i)] = newGeoRecord("DE", 0)
+		geo[fmt.Sprintf("198.51.100.%d", i)] = newGeoRecord("US", 0)
+	}
+	srv.GeoIP = geo
+	srv.GeoPartitions, _ = ParseGeoPartitions([]string{"DE|FR", "US"})
+
+	served := func(prefix string) map[string]bool {
+		seen := map[string]bool{}
+		for i := 0; i < 50; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/i2pseeds.su3", nil)
+			req.Header.Set("User-Agent", I2pUserAgent)
+			req.RemoteAddr = fmt.Sprintf("%s%d:1234", prefix, i)
+			w := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET from %s: expected 200, got %d", req.RemoteAddr, w.Code)
+			}
+			seen[w.Body.String()] = true
+		}
+		return seen
+	}
+
+	if seen := served("192.0.2."); len(seen) == 0 || seen["bundle-2"] || seen["bundle-3"] {
+		t.Errorf("Expected DE clients to get only bundles 0-1, got %v", seen)
+	}
+	if seen := served("198.51.100."); len(seen) == 0 || seen["bundle-0"] || seen["bundle-1"] {
+		t.Errorf("Expected US clients to get only bundles 2-3, got %v", seen)
+	}
+	// Clients GeoIP cannot place hash over the whole set
+	if seen := served("203.0.113."); len(seen) < 3 {
+		t.Errorf("Expected unknown clients to be spread over the whole set, got %v", seen)
+	}
+}
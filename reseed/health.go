@@ -0,0 +1,288 @@
+package reseed
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-i2p/i2pkeys"
+)
+
+// Transport names reported by the readiness endpoint.
+const (
+	TransportHTTP  = "http"
+	TransportHTTPS = "https"
+	TransportI2P   = "i2p"
+	TransportOnion = "onion"
+)
+
+// samProbeInterval bounds how often readiness checks open a connection to the
+// SAM bridge, so frequent orchestration probes do not hammer the router.
+const samProbeInterval = 5 * time.Second
+
+// samProbeTimeout is the time allowed for the SAM bridge to answer a HELLO.
+const samProbeTimeout = 3 * time.Second
+
+// TransportStatus describes the health of a single transport.
+type TransportStatus struct {
+	Up      bool   `json:"up"`
+	Address string `json:"address,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HealthStatus is the readiness report served at /readyz.
+type HealthStatus struct {
+	Ready      bool                       `json:"ready"`
+	Bundles    int                        `json:"bundles"`
+	Transports map[string]TransportStatus `json:"transports"`
+	// LastRebuild times the most recent SU3 cache rebuild and swap
+	LastRebuild *RebuildStats `json:"last_rebuild,omitempty"`
+	// WarmingUp is set while readiness is held back by the warmup rules of Health
+	WarmingUp bool `json:"warming_up,omitempty"`
+}
+
+// transportEntry records which server carries a transport and why it stopped.
+type transportEntry struct {
+	srv     *Server
+	stopped error
+}
+
+// Health tracks every transport the reseed service is exposed on. Each transport
+// runs its own Server, so the servers share one Health and /readyz on any of them
+// reports the state of all of them.
+//
+// Until the first fresh rebuild has completed the service is warming up and is
+// reported as not ready, even if bundles from an earlier run are being served.
+type Health struct {
+	mu         sync.RWMutex
+	transports map[string]*transportEntry
+	// started is when the first server began serving, zero until then
+	started time.Time
+
+	// WarmupGrace additionally holds readiness back for this long after the
+	// first server starts serving, giving load balancers time to settle on a
+	// new instance
+	WarmupGrace time.Duration
+	// ServeStale reports the service as ready while it has bundles to serve,
+	// without waiting for the first fresh rebuild
+	ServeStale bool
+}
+
+// DefaultHealth is the Health shared by servers created with NewServer.
+var DefaultHealth = NewHealth()
+
+// NewHealth creates an empty Health with no expected transports.
+func NewHealth() *Health {
+	return &Health{transports: make(map[string]*transportEntry)}
+}
+
+// Expect declares a transport as enabled. Expected transports that have not
+// started serving yet are reported as down, so a transport that fails during
+// setup is not silently missing from the report.
+func (h *Health) Expect(transport string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.transports[transport]; !ok {
+		h.transports[transport] = &transportEntry{}
+	}
+}
+
+// serving records that srv has started accepting connections for transport.
+func (h *Health) serving(transport string, srv *Server) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.transports[transport] = &transportEntry{srv: srv}
+	if h.started.IsZero() {
+		h.started = time.Now()
+	}
+}
+
+// stopped records that the server for transport is no longer accepting connections.
+func (h *Health) stopped(transport string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if entry, ok := h.transports[transport]; ok {
+		if err == nil {
+			err = http.ErrServerClosed
+		}
+		entry.stopped = err
+	}
+}
+
+// Status queries the live state of every expected transport. The service is
+// ready when all of them are up, at least one reseed bundle is available and it
+// is no longer warming up.
+func (h *Health) Status() HealthStatus {
+	h.mu.RLock()
+	names := make([]string, 0, len(h.transports))
+	entries := make(map[string]transportEntry, len(h.transports))
+	for name, entry := range h.transports {
+		names = append(names, name)
+		entries[name] = *entry
+	}
+	started := h.started
+	h.mu.RUnlock()
+	sort.Strings(names)
+
+	status := HealthStatus{Ready: true, Transports: make(map[string]TransportStatus, len(names))}
+	for _, name := range names {
+		entry := entries[name]
+		var ts TransportStatus
+		switch {
+		case entry.srv == nil:
+			ts.Error = "not started"
+		case entry.stopped != nil:
+			ts.Error = "stopped: " + entry.stopped.Error()
+		default:
+			ts = entry.srv.transportStatus(name)
+			if status.Bundles == 0 && entry.srv.Reseeder != nil {
+				status.Bundles = entry.srv.Reseeder.BundleCount()
+				status.LastRebuild = entry.srv.Reseeder.LastRebuild()
+			}
+		}
+		if !ts.Up {
+			status.Ready = false
+		}
+		status.Transports[name] = ts
+	}
+	if status.Bundles == 0 {
+		status.Ready = false
+	}
+	if h.warmingUp(status, started) {
+		status.Ready = false
+		status.WarmingUp = true
+	}
+	return status
+}
+
+// warmingUp reports whether readiness is still held back: until WarmupGrace
+// has passed since started, and, unless ServeStale is set, while the bundles
+// being served do not come from a rebuild completed by this process.
+func (h *Health) warmingUp(status HealthStatus, started time.Time) bool {
+	if h.WarmupGrace > 0 && (started.IsZero() || time.Since(started) < h.WarmupGrace) {
+		return true
+	}
+	return !h.ServeStale && status.Bundles > 0 && status.LastRebuild == nil
+}
+
+// health returns the Health the server reports to, defaulting to DefaultHealth.
+func (srv *Server) health() *Health {
+	if srv.Health == nil {
+		return DefaultHealth
+	}
+	return srv.Health
+}
+
+// serveTransport serves ln while keeping the server's Health up to date.
+func (srv *Server) serveTransport(transport string, ln net.Listener) error {
+	srv.transport.Store(transport)
+	srv.health().serving(transport, srv)
+	err := srv.Serve(ln)
+	srv.health().stopped(transport, err)
+	return err
+}
+
+// transportStatus inspects the listener and tunnel backing transport. For I2P
+// the SAM bridge is probed as well, since its tunnels die with the bridge while
+// the local listener object remains open.
+func (srv *Server) transportStatus(transport string) TransportStatus {
+	var ln net.Listener
+	switch transport {
+	case TransportI2P:
+		srv.i2pMu.Lock()
+		garlic, i2pLn := srv.Garlic, srv.I2PListener
+		srv.i2pMu.Unlock()
+		if garlic == nil {
+			return TransportStatus{Error: "no SAM session"}
+		}
+		ln = i2pLn
+	case TransportOnion:
+		if srv.Onion == nil {
+			return TransportStatus{Error: "no Tor instance"}
+		}
+		ln = srv.OnionListener
+	default:
+		ln = srv.ServerListener
+	}
+	if ln == nil {
+		return TransportStatus{Error: "not listening"}
+	}
+
+	ts := TransportStatus{Up: true, Address: listenerAddress(ln)}
+	if transport == TransportI2P {
+		if err := srv.probeSAM(); err != nil {
+			ts.Up = false
+			ts.Error = "SAM bridge unreachable: " + err.Error()
+		}
+	}
+	return ts
+}
+
+// listenerAddress formats a listener address, using the base32 form for I2P.
+func listenerAddress(ln net.Listener) string {
+	if addr, ok := ln.Addr().(i2pkeys.I2PAddr); ok {
+		return addr.Base32()
+	}
+	return ln.Addr().String()
+}
+
+// probeSAM checks that the SAM bridge still answers a HELLO, caching the result
+// for samProbeInterval.
+func (srv *Server) probeSAM() error {
+	srv.samProbeMutex.Lock()
+	defer srv.samProbeMutex.Unlock()
+	if time.Since(srv.samProbeAt) < samProbeInterval {
+		return srv.samProbeErr
+	}
+	srv.samProbeErr = samHello(srv.samAddr)
+	srv.samProbeAt = time.Now()
+	return srv.samProbeErr
+}
+
+// samHello performs a SAM version handshake against addr.
+func samHello(addr string) error {
+	if addr == "" {
+		return fmt.Errorf("no SAM address configured")
+	}
+	conn, err := net.DialTimeout("tcp", addr, samProbeTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(samProbeTimeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write([]byte("HELLO VERSION MIN=3.0 MAX=3.3\n")); err != nil {
+		return err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(reply, "RESULT=OK") {
+		return fmt.Errorf("unexpected reply %q", strings.TrimSpace(reply))
+	}
+	return nil
+}
+
+// readyzHandler reports per-transport health as JSON, answering 503 unless every
+// expected transport is up and bundles are available.
+func (srv *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	status := srv.health().Status()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if status.Ready {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		lgr.WithError(err).Error("Error writing readiness response")
+	}
+}
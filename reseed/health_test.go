@@ -0,0 +1,180 @@
+package reseed
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newReadyServer returns a server reporting to its own Health with one bundle built.
+func newReadyServer(t *testing.T) *Server {
+	t.Helper()
+	srv := NewServer("", false, "", 4, 40, 2000)
+	srv.Health = NewHealth()
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle")})
+	srv.Reseeder.lastRebuild.Store(&RebuildStats{Time: time.Now(), Bundles: 1})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	srv.ServerListener = ln
+	return srv
+}
+
+func getReadyz(t *testing.T, srv *Server) (int, HealthStatus) {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+
+	var status HealthStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode readiness response %q: %v", w.Body.String(), err)
+	}
+	return w.Code, status
+}
+
+func TestReadyz_AllTransportsUp(t *testing.T) {
+	srv := newReadyServer(t)
+	srv.Health.serving(TransportHTTPS, srv)
+
+	code, status := getReadyz(t, srv)
+	if code != http.StatusOK || !status.Ready {
+		t.Fatalf("Expected ready 200, got %d %+v", code, status)
+	}
+	if status.Bundles != 1 {
+		t.Errorf("Expected 1 bundle, got %d", status.Bundles)
+	}
+	if ts := status.Transports[TransportHTTPS]; !ts.Up || ts.Address == "" {
+		t.Errorf("Expected https to be up with an address, got %+v", ts)
+	}
+}
+
+func TestReadyz_HTTPUpI2PDown(t *testing.T) {
+	srv := newReadyServer(t)
+	srv.Health.serving(TransportHTTPS, srv)
+	srv.Health.Expect(TransportI2P)
+
+	code, status := getReadyz(t, srv)
+	if code != http.StatusServiceUnavailable || status.Ready {
+		t.Fatalf("Expected 503 while I2P is down, got %d %+v", code, status)
+	}
+	if !status.Transports[TransportHTTPS].Up {
+		t.Error("Expected https to be reported up")
+	}
+	if ts := status.Transports[TransportI2P]; ts.Up || ts.Error == "" {
+		t.Errorf("Expected i2p to be reported down with a reason, got %+v", ts)
+	}
+}
+
+func TestReadyz_StoppedTransportAndNoBundles(t *testing.T) {
+	srv := newReadyServer(t)
+	srv.Reseeder.su3s.Store([][]byte{})
+	srv.Health.serving(TransportHTTP, srv)
+
+	if code, status := getReadyz(t, srv); code != http.StatusServiceUnavailable || status.Bundles != 0 {
+		t.Errorf("Expected 503 without bundles, got %d %+v", code, status)
+	}
+
+	srv.Health.stopped(TransportHTTP, nil)
+	_, status := getReadyz(t, srv)
+	if ts := status.Transports[TransportHTTP]; ts.Up || ts.Error == "" {
+		t.Errorf("Expected stopped http transport to be down, got %+v", ts)
+	}
+}
+
+// fakeSAM answers a single SAM HELLO with reply.
+func fakeSAM(t *testing.T, reply string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err := bufio.NewReader(conn).ReadString('\n'); err == nil {
+			conn.Write([]byte(reply))
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestSamHello(t *testing.T) {
+	if err := samHello(fakeSAM(t, "HELLO REPLY RESULT=OK VERSION=3.3\n")); err != nil {
+		t.Errorf("Expected healthy SAM bridge, got %v", err)
+	}
+	if err := samHello(fakeSAM(t, "HELLO REPLY RESULT=NOVERSION\n")); err == nil {
+		t.Error("Expected error for rejected handshake")
+	}
+	if err := samHello(""); err == nil {
+		t.Error("Expected error without a SAM address")
+	}
+}
+
+func TestReadyz_WarmupUntilFreshRebuild(t *testing.T) {
+	srv := newReadyServer(t)
+	srv.Health.serving(TransportHTTPS, srv)
+	// Bundles without rebuild stats stand in for a cache left over from an earlier run
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("stale")})
+
+	code, status := getReadyz(t, srv)
+	if code != http.StatusServiceUnavailable || status.Ready || !status.WarmingUp {
+		t.Fatalf("Expected 503 while warming up on a stale cache, got %d %+v", code, status)
+	}
+
+	srv.Health.ServeStale = true
+	if code, status := getReadyz(t, srv); code != http.StatusOK || status.WarmingUp {
+		t.Errorf("Expected ready with ServeStale, got %d %+v", code, status)
+	}
+
+	srv.Health.ServeStale = false
+	srv.Reseeder.lastRebuild.Store(&RebuildStats{Time: time.Now(), Bundles: 1})
+	if code, status := getReadyz(t, srv); code != http.StatusOK || status.WarmingUp {
+		t.Errorf("Expected ready after the first fresh rebuild, got %d %+v", code, status)
+	}
+}
+
+func TestReadyz_WarmupGrace(t *testing.T) {
+	srv := newReadyServer(t)
+	srv.Health.serving(TransportHTTPS, srv)
+	srv.Health.WarmupGrace = time.Hour
+
+	if code, status := getReadyz(t, srv); code != http.StatusServiceUnavailable || !status.WarmingUp {
+		t.Fatalf("Expected 503 during the warmup grace, got %d %+v", code, status)
+	}
+
+	srv.Health.started = time.Now().Add(-2 * time.Hour)
+	if code, status := getReadyz(t, srv); code != http.StatusOK || status.WarmingUp {
+		t.Errorf("Expected ready once the warmup grace has passed, got %d %+v", code, status)
+	}
+}
+
+func TestHealth_StartedOnFirstServing(t *testing.T) {
+	h := NewHealth()
+	if !h.started.IsZero() {
+		t.Fatal("Expected the warmup clock not to start before a server does")
+	}
+	srv := newReadyServer(t)
+	h.serving(TransportHTTPS, srv)
+	started := h.started
+	if started.IsZero() {
+		t.Fatal("Expected the warmup clock to start with the first server")
+	}
+	h.serving(TransportOnion, srv)
+	if h.started != started {
+		t.Error("Expected a later transport not to restart the warmup clock")
+	}
+}
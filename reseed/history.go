@@ -0,0 +1,140 @@
+package reseed
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// BundleGeneration describes one bundle set produced by a rebuild.
+type BundleGeneration struct {
+	// Generation numbers the rebuilds of this process, starting at 1
+	Generation uint64 `json:"generation"`
+	// Built is when the set was swapped in
+	Built time.Time `json:"built"`
+	// Bundles is the number of bundles in the set
+	Bundles int `json:"bundles"`
+}
+
+// bundleSet is a retained bundle set together with its RouterInfo selection
+// and bundle digests.
+type bundleSet struct {
+	BundleGeneration
+	su3s      [][]byte
+	selection [][]string
+	sums      su3Sums
+}
+
+// su3Sums maps the first byte of each bundle in a set to the SHA-256 of the
+// bundle. Bundles are never modified once built, so the address identifies the
+// bundle for as long as anyone holds it, and a lookup cannot return the digest
+// of another set's bundle while the served set is being swapped.
+type su3Sums map[*byte][sha256.Size]byte
+
+// newSu3Sums hashes every bundle of su3s.
+func newSu3Sums(su3s [][]byte) su3Sums {
+	sums := make(su3Sums, len(su3s))
+	for _, su3Bytes := range su3s {
+		if len(su3Bytes) > 0 {
+			sums[&su3Bytes[0]] = sha256.Sum256(su3Bytes)
+		}
+	}
+	return sums
+}
+
+// publish records a freshly built bundle set as the newest generation, dropping
+// generations beyond HistorySize, and serves it unless a generation is pinned.
+// The bundles are hashed here, once per rebuild, for the ETags and checksums
+// served with them.
+func (rs *ReseederImpl) publish(su3s [][]byte, selection [][]string) {
+	sums := newSu3Sums(su3s)
+	rs.historyMu.Lock()
+	defer rs.historyMu.Unlock()
+	rs.generation++
+	set := &bundleSet{
+		BundleGeneration: BundleGeneration{Generation: rs.generation, Built: time.Now(), Bundles: len(su3s)},
+		su3s:             su3s,
+		selection:        selection,
+		sums:             sums,
+	}
+	rs.history = append(rs.history, set)
+	if excess := len(rs.history) - (max(rs.HistorySize, 0) + 1); excess > 0 {
+		// copy so the dropped sets can be garbage collected
+		rs.history = append([]*bundleSet(nil), rs.history[excess:]...)
+	}
+	if rs.pinned == nil {
+		rs.serveSet(set)
+	}
+}
+
+// serveSet makes set the bundle set handed out to clients.
+func (rs *ReseederImpl) serveSet(set *bundleSet) {
+	rs.sums.Store(set.sums)
+	rs.built.Store(set.Built)
+	rs.served.Store(set.Generation)
+	rs.su3s.Store(set.su3s)
+	rs.selection.Store(set.selection)
+}
+
+// su3SHA256 returns the SHA-256 of su3Bytes, a bundle of the served set, from
+// the digests taken when the set was published. A bundle without one, such
+// as one swapped in without publish, is hashed on the spot.
+func (rs *ReseederImpl) su3SHA256(su3Bytes []byte) [sha256.Size]byte {
+	if sums, _ := rs.sums.Load().(su3Sums); len(su3Bytes) > 0 {
+		if sum, ok := sums[&su3Bytes[0]]; ok {
+			return sum
+		}
+	}
+	return sha256.Sum256(su3Bytes)
+}
+
+// servedBuilt returns when the bundle set being served was built, which is
+// an earlier rebuild than LastRebuild while a generation is pinned.
+func (rs *ReseederImpl) servedBuilt() (time.Time, bool) {
+	built, ok := rs.built.Load().(time.Time)
+	return built, ok
+}
+
+// PinGeneration serves the retained bundle set of generation instead of the
+// newest one, until Unpin. Later rebuilds are still recorded in the history
+// but not served while a generation is pinned.
+func (rs *ReseederImpl) PinGeneration(generation uint64) error {
+	rs.historyMu.Lock()
+	defer rs.historyMu.Unlock()
+	for _, set := range rs.history {
+		if set.Generation == generation {
+			rs.pinned = set
+			rs.serveSet(set)
+			lgr.WithField("generation", generation).Warn("Pinned an earlier bundle set; rebuilds will not be served until it is unpinned")
+			return nil
+		}
+	}
+	return fmt.Errorf("generation %d is not retained", generation)
+}
+
+// Unpin goes back to serving the newest bundle set.
+func (rs *ReseederImpl) Unpin() {
+	rs.historyMu.Lock()
+	defer rs.historyMu.Unlock()
+	rs.pinned = nil
+	if len(rs.history) > 0 {
+		rs.serveSet(rs.history[len(rs.history)-1])
+	}
+}
+
+// BundleHistory lists the retained generations, oldest first, together with
+// the generation being served and whether it is pinned.
+func (rs *ReseederImpl) BundleHistory() (generations []BundleGeneration, serving uint64, pinned bool) {
+	rs.historyMu.Lock()
+	defer rs.historyMu.Unlock()
+	for _, set := range rs.history {
+		generations = append(generations, set.BundleGeneration)
+	}
+	switch {
+	case rs.pinned != nil:
+		serving, pinned = rs.pinned.Generation, true
+	case len(rs.history) > 0:
+		serving = rs.history[len(rs.history)-1].Generation
+	}
+	return generations, serving, pinned
+}
@@ -0,0 +1,61 @@
+package reseed
+
+import (
+	"testing"
+	"time"
+)
+
+// servedBundle returns the first bundle of the set being served.
+func servedBundle(rs *ReseederImpl) string {
+	m := rs.su3s.Load().([][]byte)
+	if len(m) == 0 {
+		return ""
+	}
+	return string(m[0])
+}
+
+func TestBundleHistory(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.HistorySize = 2
+	for _, name := range []string{"gen-1", "gen-2", "gen-3", "gen-4"} {
+		reseeder.publish([][]byte{[]byte(name)}, [][]string{{name}})
+	}
+
+	generations, serving, pinned := reseeder.BundleHistory()
+	if len(generations) != 3 || generations[0].Generation != 2 || generations[2].Generation != 4 {
+		t.Fatalf("Expected generations 2-4 to be retained, got %+v", generations)
+	}
+	if serving != 4 || pinned || servedBundle(reseeder) != "gen-4" {
+		t.Errorf("Expected the newest generation to be served, got %d pinned=%v", serving, pinned)
+	}
+
+	if err := reseeder.PinGeneration(1); err == nil {
+		t.Error("Expected a dropped generation to be refused")
+	}
+	if err := reseeder.PinGeneration(2); err != nil {
+		t.Fatalf("PinGeneration(2) error: %v", err)
+	}
+	if servedBundle(reseeder) != "gen-2" {
+		t.Errorf("Expected the pinned set to be served, got %s", servedBundle(reseeder))
+	}
+
+	// Rebuilds while pinned are recorded but not served
+	reseeder.publish([][]byte{[]byte("gen-5")}, [][]string{{"gen-5"}})
+	if _, serving, pinned := reseeder.BundleHistory(); serving != 2 || !pinned || servedBundle(reseeder) != "gen-2" {
+		t.Errorf("Expected generation 2 to stay pinned, got %d pinned=%v", serving, pinned)
+	}
+
+	reseeder.Unpin()
+	if _, serving, pinned := reseeder.BundleHistory(); serving != 5 || pinned || servedBundle(reseeder) != "gen-5" {
+		t.Errorf("Expected the newest generation after Unpin, got %d pinned=%v", serving, pinned)
+	}
+}
+
+func TestBundleHistory_DefaultKeepsOnlyCurrent(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.publish([][]byte{[]byte("gen-1")}, nil)
+	reseeder.publish([][]byte{[]byte("gen-2")}, nil)
+	if generations, _, _ := reseeder.BundleHistory(); len(generations) != 1 || generations[0].Generation != 2 {
+		t.Errorf("Expected only the current generation, got %+v", generations)
+	}
+}
@@ -2,9 +2,13 @@ package reseed
 
 import (
 	"embed"
+	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
@@ -71,10 +75,55 @@ func StableContentPath() (string, error) {
 	return BaseContentPath, ContentPathError
 }
 
+// activeLanguages is the subset of SupportedLanguages the homepage offers, as
+// configured with SetLanguages. English always comes first so that it is the
+// fallback for clients matching none of them.
+var activeLanguages = SupportedLanguages
+
 // matcher provides language matching functionality for reseed server internationalization.
-// It uses the SupportedLanguages list to match client browser language preferences
+// It uses the activeLanguages list to match client browser language preferences
 // with available localized content for optimal user experience.
-var matcher = language.NewMatcher(SupportedLanguages)
+var matcher = language.NewMatcher(activeLanguages)
+
+// SetLanguages restricts the homepage to the given languages, each of which must
+// be one of the SupportedLanguages (matched by base language, so "zh" selects
+// Simplified Chinese). English is always kept as the fallback. An empty list
+// restores every supported language. It should be called before serving starts.
+func SetLanguages(langs []string) error {
+	if len(langs) == 0 {
+		activeLanguages = SupportedLanguages
+		matcher = language.NewMatcher(activeLanguages)
+		return nil
+	}
+	active := []language.Tag{language.English}
+	for _, lang := range langs {
+		tag, err := language.Parse(strings.TrimSpace(lang))
+		if err != nil {
+			return fmt.Errorf("invalid language %q: %w", lang, err)
+		}
+		supported, ok := supportedLanguage(tag)
+		if !ok {
+			return fmt.Errorf("language %q is not one of the supported homepage languages", lang)
+		}
+		if !slices.Contains(active, supported) {
+			active = append(active, supported)
+		}
+	}
+	activeLanguages = active
+	matcher = language.NewMatcher(activeLanguages)
+	return nil
+}
+
+// supportedLanguage returns the entry of SupportedLanguages sharing tag's base language.
+func supportedLanguage(tag language.Tag) (language.Tag, bool) {
+	base, _ := tag.Base()
+	for _, supported := range SupportedLanguages {
+		if supportedBase, _ := supported.Base(); supportedBase == base {
+			return supported, true
+		}
+	}
+	return language.Und, false
+}
 
 // header contains the standard HTML document header for reseed server web pages.
 // This template includes essential meta tags, CSS stylesheet links, and JavaScript
@@ -134,20 +183,26 @@ func (srv *Server) HandleARealBrowser(w http.ResponseWriter, r *http.Request) {
 // validateContentPath ensures the content directory exists and is accessible.
 // Returns an error if content cannot be served.
 func (srv *Server) validateContentPath() error {
+	if srv.Ephemeral {
+		return nil
+	}
 	_, ContentPathError := StableContentPath()
 	return ContentPathError
 }
 
 // determineClientLanguage extracts and processes language preferences from the HTTP request.
-// It uses both cookie values and Accept-Language headers to determine the best language match.
+// It uses the lang query parameter, cookie values and Accept-Language headers to determine
+// the best language match.
 func (srv *Server) determineClientLanguage(r *http.Request) string {
 	lang, _ := r.Cookie("lang")
+	query := r.URL.Query().Get("lang")
 	accept := r.Header.Get("Accept-Language")
 
-	lgr.WithField("lang", lang).WithField("accept", accept).Debug("Processing language preferences")
+	lgr.WithField("lang", lang).WithField("query", query).WithField("accept", accept).Debug("Processing language preferences")
 	srv.logRequestHeaders(r)
 
-	tag, _ := language.MatchStrings(matcher, lang.String(), accept)
+	// An explicit ?lang= wins, so each translation has a stable URL
+	tag, _ := language.MatchStrings(matcher, query, lang.String(), accept)
 	lgr.WithField("tag", tag).Debug("Matched language tag")
 
 	base, _ := tag.Base()
@@ -166,9 +221,13 @@ func (srv *Server) logRequestHeaders(r *http.Request) {
 }
 
 // routeRequest dispatches HTTP requests to the appropriate content handler based on URL path.
-// Supports CSS files, JavaScript files, images, ping functionality, readout pages, and localized content.
+// Supports the sitemap, the homepage data as JSON, CSS files, JavaScript files, images, ping functionality, readout pages, and localized content.
 func (srv *Server) routeRequest(w http.ResponseWriter, r *http.Request, baseLanguage string) {
-	if strings.HasSuffix(r.URL.Path, "style.css") {
+	if srv.Sitemap && srv.CanonicalURL != "" && r.URL.Path == "/sitemap.xml" {
+		srv.handleSitemapRequest(w)
+	} else if r.URL.Path == homepageDataPath {
+		srv.handleHomepageDataRequest(w, baseLanguage)
+	} else if strings.HasSuffix(r.URL.Path, "style.css") {
 		srv.handleCSSRequest(w)
 	} else if strings.HasSuffix(r.URL.Path, "script.js") {
 		srv.handleJavaScriptRequest(w)
@@ -180,13 +239,13 @@ func (srv *Server) routeRequest(w http.ResponseWriter, r *http.Request, baseLang
 // handleCSSRequest serves CSS stylesheet files with appropriate content type headers.
 func (srv *Server) handleCSSRequest(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "text/css")
-	handleAFile(w, "", "style.css")
+	srv.handleAFile(w, "", "style.css")
 }
 
 // handleJavaScriptRequest serves JavaScript files with appropriate content type headers.
 func (srv *Server) handleJavaScriptRequest(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "text/javascript")
-	handleAFile(w, "", "script.js")
+	srv.handleAFile(w, "", "script.js")
 }
 
 // handleDynamicRequest processes requests for images, special functions, and localized content.
@@ -201,7 +260,7 @@ func (srv *Server) handleDynamicRequest(w http.ResponseWriter, r *http.Request,
 	} else if strings.HasPrefix(image, "readout") {
 		srv.handleReadoutRequest(w)
 	} else {
-		srv.handleHomepageRequest(w, baseLanguage)
+		srv.handleHomepageRequest(w, r, baseLanguage)
 	}
 }
 
@@ -209,12 +268,12 @@ func (srv *Server) handleDynamicRequest(w http.ResponseWriter, r *http.Request,
 func (srv *Server) handleImageRequest(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "image/png")
 	imagePath := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"), "images")
-	handleAFile(w, "images", imagePath)
+	srv.handleAFile(w, "images", imagePath)
 }
 
 // handlePingRequest processes ping functionality and redirects to homepage.
 func (srv *Server) handlePingRequest(w http.ResponseWriter, r *http.Request) {
-	PingEverybody()
+	PingEverybody(srv.Ephemeral)
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
@@ -222,43 +281,49 @@ func (srv *Server) handlePingRequest(w http.ResponseWriter, r *http.Request) {
 func (srv *Server) handleReadoutRequest(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "text/html")
 	w.Write([]byte(header))
-	ReadOut(w)
+	ReadOut(w, srv.Ephemeral)
 	w.Write([]byte(footer))
 }
 
 // handleHomepageRequest serves the main homepage with localized content and reseed functionality.
-func (srv *Server) handleHomepageRequest(w http.ResponseWriter, baseLanguage string) {
+// The dynamic parts come from homepageData, which is also served as JSON.
+func (srv *Server) handleHomepageRequest(w http.ResponseWriter, r *http.Request, baseLanguage string) {
+	data := srv.homepageData(baseLanguage)
+
 	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(header))
-	handleALocalizedFile(w, baseLanguage)
+	w.Write(srv.pageHeader(r))
+	srv.handleALocalizedFile(w, data.Language)
 
 	// Add reseed form with one-time token
-	reseedForm := `<ul><li><form method="post" action="/i2pseeds" class="inline">
-		<input type="hidden" name="onetime" value="` + srv.Acceptable() + `">
+	reseedForm := `<ul><li><form method="post" action="` + data.ReseedFormAction + `" class="inline">
+		<input type="hidden" name="onetime" value="` + data.ReseedToken + `">
 		<button type="submit" name="submit_param" value="submit_value" class="link-button">
 		Reseed
 		</button>
 		</form></li></ul>`
 	w.Write([]byte(reseedForm))
 
-	ReadOut(w)
-	w.Write([]byte(footer))
+	renderPingResults(w, data.Pings)
+	w.Write(footer)
 }
 
 // handleAFile serves static files from the reseed server content directory with caching.
-// It loads files from the filesystem on first access and caches them in memory for
+// It loads files from the content tree (see contentFS) on first access and caches them in memory for
 // improved performance on subsequent requests, supporting CSS, JavaScript, and image files.
-func handleAFile(w http.ResponseWriter, dirPath, file string) {
-	BaseContentPath, _ := StableContentPath()
-	file = filepath.Join(dirPath, file)
+func (srv *Server) handleAFile(w http.ResponseWriter, dirPath, file string) {
+	file = path.Join(dirPath, file)
 
 	cachedDataMu.RLock()
 	cached, prs := CachedDataPages[file]
 	cachedDataMu.RUnlock()
 
 	if !prs {
-		path := filepath.Join(BaseContentPath, file)
-		f, err := os.ReadFile(path)
+		content, err := contentFS(srv.Ephemeral)
+		if err != nil {
+			w.Write([]byte("Oops! Something went wrong handling your language. Please file a bug at https://i2pgit.org/go-i2p/reseed-tools\n\t" + err.Error()))
+			return
+		}
+		f, err := fs.ReadFile(content, file)
 		if err != nil {
 			w.Write([]byte("Oops! Something went wrong handling your language. Please file a bug at https://i2pgit.org/go-i2p/reseed-tools\n\t" + err.Error()))
 			return
@@ -277,15 +342,19 @@ func handleAFile(w http.ResponseWriter, dirPath, file string) {
 // handleALocalizedFile processes and serves language-specific content with markdown rendering.
 // It reads markdown files from language subdirectories, converts them to HTML, and caches
 // the results for efficient serving of multilingual reseed server interface content.
-func handleALocalizedFile(w http.ResponseWriter, dirPath string) {
+func (srv *Server) handleALocalizedFile(w http.ResponseWriter, dirPath string) {
 	cachedLanguageMu.RLock()
 	cached, prs := CachedLanguagePages[dirPath]
 	cachedLanguageMu.RUnlock()
 
 	if !prs {
-		BaseContentPath, _ := StableContentPath()
-		dir := filepath.Join(BaseContentPath, "lang", dirPath)
-		files, err := os.ReadDir(dir)
+		content, err := contentFS(srv.Ephemeral)
+		if err != nil {
+			w.Write([]byte("Oops! Something went wrong handling your language. Please file a bug at https://i2pgit.org/go-i2p/reseed-tools\n\t" + err.Error()))
+			return
+		}
+		dir := path.Join("lang", dirPath)
+		files, err := fs.ReadDir(content, dir)
 		if err != nil {
 			w.Write([]byte("Oops! Something went wrong handling your language. Please file a bug at https://i2pgit.org/go-i2p/reseed-tools\n\t" + err.Error()))
 			return
@@ -296,8 +365,7 @@ func handleALocalizedFile(w http.ResponseWriter, dirPath string) {
 				continue
 			}
 			trimmedName := strings.TrimSuffix(file.Name(), ".md")
-			path := filepath.Join(dir, file.Name())
-			b, err := os.ReadFile(path)
+			b, err := fs.ReadFile(content, path.Join(dir, file.Name()))
 			if err != nil {
 				w.Write([]byte("Oops! Something went wrong handling your language. Please file a bug at https://i2pgit.org/go-i2p/reseed-tools\n\t" + err.Error()))
 				return
@@ -5,9 +5,12 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+
+	"golang.org/x/text/language"
 )
 
 // TestHandleAFile_CachesContent verifies that handleAFile reads from disk on
@@ -37,7 +40,7 @@ func TestHandleAFile_CachesContent(t *testing.T) {
 
 	// First request — should read from disk
 	w := httptest.NewRecorder()
-	handleAFile(w, "", "style.css")
+	(&Server{}).handleAFile(w, "", "style.css")
 	if w.Body.String() != testContent {
 		t.Errorf("first call: got %q, want %q", w.Body.String(), testContent)
 	}
@@ -55,7 +58,7 @@ func TestHandleAFile_CachesContent(t *testing.T) {
 
 	// Second request — should serve from cache
 	w2 := httptest.NewRecorder()
-	handleAFile(w2, "", "style.css")
+	(&Server{}).handleAFile(w2, "", "style.css")
 	if w2.Body.String() != testContent {
 		t.Errorf("second call: got %q, want %q", w2.Body.String(), testContent)
 	}
@@ -78,7 +81,7 @@ func TestHandleAFile_FileNotFound(t *testing.T) {
 	defer os.Chdir(origDir)
 
 	w := httptest.NewRecorder()
-	handleAFile(w, "", "nonexistent.css")
+	(&Server{}).handleAFile(w, "", "nonexistent.css")
 	body := w.Body.String()
 	if !strings.Contains(body, "Oops!") {
 		t.Errorf("expected error message, got: %q", body)
@@ -119,7 +122,7 @@ func TestHandleAFile_ConcurrentAccess(t *testing.T) {
 			defer wg.Done()
 			file := strings.Replace("file_X.css", "X", string(rune('a'+(idx%10))), 1)
 			w := httptest.NewRecorder()
-			handleAFile(w, "", file)
+			(&Server{}).handleAFile(w, "", file)
 		}(i)
 	}
 	wg.Wait()
@@ -155,7 +158,7 @@ func TestHandleALocalizedFile_SkipsNonMarkdownFiles(t *testing.T) {
 	defer os.Chdir(origDir)
 
 	w := httptest.NewRecorder()
-	handleALocalizedFile(w, "en")
+	(&Server{}).handleALocalizedFile(w, "en")
 	body := w.Body.String()
 
 	// Before the fix: `return` on .DS_Store would produce empty output.
@@ -195,7 +198,7 @@ func TestHandleALocalizedFile_CachesContent(t *testing.T) {
 
 	// First call
 	w1 := httptest.NewRecorder()
-	handleALocalizedFile(w1, "de")
+	(&Server{}).handleALocalizedFile(w1, "de")
 	first := w1.Body.String()
 	if first == "" {
 		t.Fatal("first call produced empty output")
@@ -211,7 +214,7 @@ func TestHandleALocalizedFile_CachesContent(t *testing.T) {
 
 	// Second call from cache
 	w2 := httptest.NewRecorder()
-	handleALocalizedFile(w2, "de")
+	(&Server{}).handleALocalizedFile(w2, "de")
 	if w2.Body.String() != first {
 		t.Errorf("cached content differs: %q vs %q", w2.Body.String(), first)
 	}
@@ -252,7 +255,7 @@ func TestHandleALocalizedFile_ConcurrentAccess(t *testing.T) {
 			defer wg.Done()
 			lang := langs[idx%len(langs)]
 			w := httptest.NewRecorder()
-			handleALocalizedFile(w, lang)
+			(&Server{}).handleALocalizedFile(w, lang)
 		}(i)
 	}
 	wg.Wait()
@@ -277,7 +280,7 @@ func TestHandleALocalizedFile_MissingDirectory(t *testing.T) {
 	defer os.Chdir(origDir)
 
 	w := httptest.NewRecorder()
-	handleALocalizedFile(w, "xx")
+	(&Server{}).handleALocalizedFile(w, "xx")
 	body := w.Body.String()
 	if !strings.Contains(body, "Oops!") {
 		t.Errorf("expected error message for missing directory, got: %q", body)
@@ -307,13 +310,16 @@ func TestHandleALocalizedFile_ReturnsOnReadError(t *testing.T) {
 		t.Skip("cannot change file permissions on this OS")
 	}
 	defer os.Chmod(mdFile, 0o644) // cleanup
+	if _, err := os.ReadFile(mdFile); err == nil {
+		t.Skip("file permissions are not enforced for this user")
+	}
 
 	origDir, _ := os.Getwd()
 	os.Chdir(tmpDir)
 	defer os.Chdir(origDir)
 
 	w := httptest.NewRecorder()
-	handleALocalizedFile(w, "ko")
+	(&Server{}).handleALocalizedFile(w, "ko")
 	body := w.Body.String()
 	if !strings.Contains(body, "Oops!") {
 		t.Errorf("expected error for unreadable file, got: %q", body)
@@ -342,7 +348,7 @@ func TestHandleALocalizedFile_OnlyMarkdownFiles(t *testing.T) {
 	defer os.Chdir(origDir)
 
 	w := httptest.NewRecorder()
-	handleALocalizedFile(w, "jp")
+	(&Server{}).handleALocalizedFile(w, "jp")
 	body := w.Body.String()
 
 	if !strings.Contains(body, "First") || !strings.Contains(body, "Second") {
@@ -454,3 +460,113 @@ func TestHandleARealBrowser_Smoke(t *testing.T) {
 		t.Error("expected non-zero status code")
 	}
 }
+
+// TestDetermineClientLanguage_QueryParam verifies that ?lang= overrides the
+// cookie and Accept-Language preferences.
+func TestDetermineClientLanguage_QueryParam(t *testing.T) {
+	srv := &Server{}
+	req := httptest.NewRequest("GET", "/?lang=ru", nil)
+	req.Header.Set("Accept-Language", "de")
+	req.AddCookie(&http.Cookie{Name: "lang", Value: "fr"})
+	if got := srv.determineClientLanguage(req); got != "ru" {
+		t.Errorf("Expected ?lang=ru to win, got %q", got)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "de")
+	if got := srv.determineClientLanguage(req); got != "de" {
+		t.Errorf("Expected Accept-Language fallback, got %q", got)
+	}
+}
+
+// TestPageHeader_CanonicalAndHreflang verifies the homepage header links every
+// translation and points canonical at the requested one.
+func TestPageHeader_CanonicalAndHreflang(t *testing.T) {
+	srv := &Server{}
+	req := httptest.NewRequest("GET", "/?lang=ja", nil)
+	if got := srv.pageHeader(req); string(got) != string(header) {
+		t.Error("Expected the plain header without CanonicalURL")
+	}
+
+	srv.CanonicalURL = "https://reseed.example.org/"
+	got := string(srv.pageHeader(req))
+	if !strings.Contains(got, `<link rel="canonical" href="https://reseed.example.org/?lang=ja">`) {
+		t.Errorf("Missing canonical link for the requested translation:\n%s", got)
+	}
+	for _, tag := range SupportedLanguages {
+		want := `<link rel="alternate" hreflang="` + tag.String() + `" href="https://reseed.example.org/?lang=` + tag.String() + `">`
+		if !strings.Contains(got, want) {
+			t.Errorf("Missing hreflang link %s", want)
+		}
+	}
+	if !strings.Contains(got, `hreflang="x-default" href="https://reseed.example.org/"`) {
+		t.Error("Missing x-default hreflang link")
+	}
+	if strings.Index(got, "canonical") > strings.Index(got, "</head>") {
+		t.Error("Links must be inside <head>")
+	}
+
+	// Unsupported languages are not echoed into the canonical URL
+	got = string(srv.pageHeader(httptest.NewRequest("GET", "/?lang=%22%3E", nil)))
+	if !strings.Contains(got, `<link rel="canonical" href="https://reseed.example.org/">`) {
+		t.Errorf("Expected canonical to fall back to the base URL:\n%s", got)
+	}
+}
+
+func TestHandleSitemapRequest(t *testing.T) {
+	srv := &Server{CanonicalURL: "https://reseed.example.org", Sitemap: true}
+	w := httptest.NewRecorder()
+	srv.routeRequest(w, httptest.NewRequest("GET", "/sitemap.xml", nil), "en")
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("Expected XML content type, got %q", ct)
+	}
+	body := w.Body.String()
+	if got := strings.Count(body, "<url>"); got != len(SupportedLanguages) {
+		t.Errorf("Expected %d sitemap entries, got %d", len(SupportedLanguages), got)
+	}
+	if !strings.Contains(body, "<loc>https://reseed.example.org/?lang=ru</loc>") {
+		t.Errorf("Missing Russian translation entry:\n%s", body)
+	}
+	if !strings.Contains(body, `<xhtml:link rel="alternate" hreflang="zh-Hans" href="https://reseed.example.org/?lang=zh-Hans"></xhtml:link>`) {
+		t.Errorf("Missing hreflang alternate:\n%s", body)
+	}
+}
+
+func TestSetLanguages(t *testing.T) {
+	t.Cleanup(func() { SetLanguages(nil) })
+
+	if err := SetLanguages([]string{"ru", "zh", "ru"}); err != nil {
+		t.Fatalf("SetLanguages() error: %v", err)
+	}
+	want := []language.Tag{language.English, language.Russian, language.SimplifiedChinese}
+	if !slices.Equal(activeLanguages, want) {
+		t.Errorf("activeLanguages = %v, want %v", activeLanguages, want)
+	}
+
+	srv := &Server{}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "de")
+	if got := srv.determineClientLanguage(req); got != "en" {
+		t.Errorf("Expected an inactive language to fall back to English, got %q", got)
+	}
+	req.Header.Set("Accept-Language", "ru")
+	if got := srv.determineClientLanguage(req); got != "ru" {
+		t.Errorf("Expected Russian to be matched, got %q", got)
+	}
+
+	srv.CanonicalURL = "https://reseed.example.org"
+	if got := string(srv.pageHeader(req)); strings.Contains(got, `hreflang="de"`) {
+		t.Errorf("Expected no hreflang link for an inactive language:\n%s", got)
+	}
+
+	for _, bad := range []string{"xx", "not a tag"} {
+		if err := SetLanguages([]string{bad}); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+
+	if err := SetLanguages(nil); err != nil || len(activeLanguages) != len(SupportedLanguages) {
+		t.Errorf("Expected an empty list to restore every language, got %v (%v)", activeLanguages, err)
+	}
+}
@@ -0,0 +1,63 @@
+package reseed
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// homepageDataPath is where the homepage data is served as JSON.
+const homepageDataPath = "/homepage.json"
+
+// reseedFormAction is where the homepage reseed form posts its one-time token.
+const reseedFormAction = "/i2pseeds"
+
+// HomepageData is everything the homepage shows apart from its static text.
+// The built-in HTML homepage renders it, and it is served as JSON at
+// /homepage.json so alternative frontends can render it themselves.
+type HomepageData struct {
+	// Version is the reseed-tools release serving the page
+	Version string `json:"version"`
+	// Language is the homepage language negotiated for the request
+	Language string `json:"language"`
+	// Languages lists every language the homepage is offered in
+	Languages []string `json:"languages"`
+	// Bundles is the number of reseed bundles currently available
+	Bundles int `json:"bundles"`
+	// ReseedFormAction and ReseedToken are the target and one-time token of
+	// the reseed form, which lets a browser download a bundle
+	ReseedFormAction string `json:"reseed_form_action"`
+	ReseedToken      string `json:"reseed_token"`
+	// Pings holds today's reseed server ping results
+	Pings []PingResult `json:"pings"`
+}
+
+// homepageData assembles the homepage data for a client using baseLanguage.
+// Each call issues a new one-time reseed token.
+func (srv *Server) homepageData(baseLanguage string) HomepageData {
+	data := HomepageData{
+		Version:          Version,
+		Language:         baseLanguage,
+		ReseedFormAction: reseedFormAction,
+		ReseedToken:      srv.Acceptable(),
+		Pings:            []PingResult{},
+	}
+	for _, tag := range activeLanguages {
+		data.Languages = append(data.Languages, tag.String())
+	}
+	if srv.Reseeder != nil {
+		data.Bundles = srv.Reseeder.BundleCount()
+	}
+	if pings, err := pingEntries(srv.Ephemeral); err == nil {
+		data.Pings = pings
+	}
+	return data
+}
+
+// handleHomepageDataRequest serves the homepage data as JSON.
+func (srv *Server) handleHomepageDataRequest(w http.ResponseWriter, baseLanguage string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(srv.homepageData(baseLanguage)); err != nil {
+		lgr.WithError(err).Error("Error writing homepage data")
+	}
+}
@@ -0,0 +1,71 @@
+package reseed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHomepageData_JSON(t *testing.T) {
+	ephemeralDir(t)
+	// Start from an empty store, whatever earlier tests left in it, and leave one
+	resetPingResults := func() {
+		pingResultsMu.Lock()
+		pingResults = map[string]string{}
+		pingResultsMu.Unlock()
+	}
+	resetPingResults()
+	t.Cleanup(resetPingResults)
+	restoreReseedPeers(t)
+	if err := SetReseedPeers([]string{"https://reseed.example/"}); err != nil {
+		t.Fatal(err)
+	}
+	storePingResult("reseed.example-"+time.Now().Format("2006-01-02"), "Alive: Status OK")
+
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.Ephemeral = true
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("a"), []byte("b")})
+
+	req := httptest.NewRequest(http.MethodGet, "/homepage.json?lang=de", nil)
+	w := httptest.NewRecorder()
+	srv.HandleARealBrowser(w, req)
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Expected JSON, got %q: %s", ct, w.Body.String())
+	}
+
+	var data HomepageData
+	if err := json.Unmarshal(w.Body.Bytes(), &data); err != nil {
+		t.Fatalf("Failed to decode homepage data: %v", err)
+	}
+	if data.Version != Version || data.Language != "de" || data.Bundles != 2 || data.ReseedFormAction != "/i2pseeds" {
+		t.Errorf("Unexpected homepage data %+v", data)
+	}
+	if len(data.Languages) != len(activeLanguages) || data.Languages[0] != "en" {
+		t.Errorf("Expected every active language, got %v", data.Languages)
+	}
+	if len(data.Pings) != 1 || data.Pings[0].Status != "Alive: Status OK" {
+		t.Errorf("Expected today's ping result, got %+v", data.Pings)
+	}
+	if !srv.CheckAcceptable(data.ReseedToken) {
+		t.Error("Expected the reseed token to be accepted by the reseed form")
+	}
+}
+
+func TestHomepage_RendersHomepageData(t *testing.T) {
+	ephemeralDir(t)
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.Ephemeral = true
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	srv.HandleARealBrowser(w, req)
+
+	body := w.Body.String()
+	if !strings.Contains(body, `action="/i2pseeds"`) || !strings.Contains(body, `name="onetime" value="`) {
+		t.Errorf("Expected the reseed form in the homepage, got:\n%s", body)
+	}
+}
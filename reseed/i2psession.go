@@ -0,0 +1,124 @@
+package reseed
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-i2p/onramp"
+)
+
+const (
+	// samReconnectMin is the delay before the first attempt to re-establish a lost SAM session
+	samReconnectMin = 2 * time.Second
+	// samReconnectMax caps the exponential backoff between reconnection attempts
+	samReconnectMax = 5 * time.Minute
+	// samWatchInterval is how often a live session checks that the SAM bridge still answers
+	samWatchInterval = 30 * time.Second
+)
+
+// serveI2P serves over an I2P session created through the SAM bridge at samaddr,
+// re-establishing the session with exponential backoff whenever it is lost, for
+// example because the router restarted. Sessions are created by onramp under the
+// "reseed" tunnel name, whose keys it keeps in its keystore, so the destination
+// stays the same across reconnections. It returns once the server is shut down.
+func (srv *Server) serveI2P(samaddr, service string, listen func(Tunnel) (net.Listener, error)) error {
+	srv.samAddr = samaddr
+	backoff := samReconnectMin
+	for {
+		started := time.Now()
+		err := srv.serveI2PSession(samaddr, service, listen)
+		if errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		// A session that stayed up for a while starts the backoff over
+		if time.Since(started) > samReconnectMax {
+			backoff = samReconnectMin
+		}
+		lgr.WithError(err).WithField("service", service).WithField("retry_in", backoff).Warn("I2P SAM session lost, reconnecting")
+		select {
+		case <-srv.stopping:
+			return http.ErrServerClosed
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, samReconnectMax)
+	}
+}
+
+// serveI2PSession establishes one SAM session and serves on it until it fails
+// or the server is shut down, closing the session afterwards.
+func (srv *Server) serveI2PSession(samaddr, service string, listen func(Tunnel) (net.Listener, error)) error {
+	srv.i2pMu.Lock()
+	if srv.isStopping() {
+		srv.i2pMu.Unlock()
+		return http.ErrServerClosed
+	}
+	if srv.Garlic == nil {
+		garlic, err := newGarlic("reseed", samaddr, onramp.OPT_WIDE)
+		if err != nil {
+			srv.i2pMu.Unlock()
+			return err
+		}
+		srv.Garlic = garlic
+	}
+	ln, err := listen(srv.Garlic)
+	if err != nil {
+		srv.closeGarlicLocked()
+		srv.i2pMu.Unlock()
+		return err
+	}
+	srv.I2PListener = ln
+	srv.i2pMu.Unlock()
+
+	lgr.WithField("service", service).WithField("address", listenerAddress(ln)).Debug("I2P server started")
+	done := make(chan struct{})
+	go srv.watchSAM(ln, done)
+	err = srv.serveTransport(TransportI2P, ln)
+	close(done)
+
+	srv.i2pMu.Lock()
+	srv.closeGarlicLocked()
+	srv.i2pMu.Unlock()
+	return err
+}
+
+// watchSAM closes ln when the SAM bridge stops answering, since a listener whose
+// bridge died can otherwise block in Accept without ever reporting an error.
+func (srv *Server) watchSAM(ln net.Listener, done <-chan struct{}) {
+	ticker := time.NewTicker(samWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := samHello(srv.samAddr); err != nil {
+				lgr.WithError(err).WithField("sam_address", srv.samAddr).Warn("SAM bridge unreachable, dropping I2P session")
+				ln.Close()
+				return
+			}
+		}
+	}
+}
+
+// closeGarlicLocked closes and forgets the current SAM session. srv.i2pMu must be held.
+func (srv *Server) closeGarlicLocked() {
+	if srv.Garlic == nil {
+		return
+	}
+	if err := srv.Garlic.Close(); err != nil {
+		lgr.WithError(err).Debug("Error closing I2P Garlic tunnel")
+	}
+	srv.Garlic = nil
+}
+
+// isStopping reports whether Shutdown has been called.
+func (srv *Server) isStopping() bool {
+	select {
+	case <-srv.stopping:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,52 @@
+package reseed
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/i2pkeys"
+)
+
+// unreachableSAM returns the address of a port that refuses connections.
+func unreachableSAM(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestListenAndServeI2P_RetriesUntilShutdown(t *testing.T) {
+	srv := NewServer("", false, "", 4, 40, 2000)
+	srv.Health = NewHealth()
+
+	samaddr := unreachableSAM(t)
+	result := make(chan error, 1)
+	go func() { result <- srv.ListenAndServeI2P(samaddr, i2pkeys.I2PKeys{}) }()
+
+	// The first session attempt fails immediately; the server must keep retrying
+	select {
+	case err := <-result:
+		t.Fatalf("Expected ListenAndServeI2P to keep retrying, returned %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error: %v", err)
+	}
+	select {
+	case err := <-result:
+		if !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("Expected http.ErrServerClosed after shutdown, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Shutdown to end the reconnection backoff")
+	}
+}
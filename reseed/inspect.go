@@ -0,0 +1,49 @@
+package reseed
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-i2p/common/router_info"
+)
+
+// BundleEntryError describes a file of a reseed bundle that is not a usable RouterInfo.
+type BundleEntryError struct {
+	Name string
+	Err  error
+}
+
+// BundleReport summarises the contents of a reseed bundle's zip payload.
+type BundleReport struct {
+	// Entries is the number of files in the zip
+	Entries int
+	// RouterInfos is the number of entries that parsed as RouterInfos
+	RouterInfos int
+	// Failures lists the entries that are misnamed or could not be parsed
+	Failures []BundleEntryError
+}
+
+// InspectBundle unzips the content of a reseed SU3 and parses every RouterInfo
+// in it, so a bundle that is validly signed but corrupt or empty can be told
+// apart from a healthy one. The error is only non-nil when the content is not a
+// readable zip; per-entry problems are reported in BundleReport.Failures.
+func InspectBundle(content []byte) (BundleReport, error) {
+	seeds, err := uzipSeeds(content)
+	if err != nil {
+		return BundleReport{}, fmt.Errorf("bundle content is not a valid zip: %w", err)
+	}
+
+	report := BundleReport{Entries: len(seeds)}
+	for _, seed := range seeds {
+		if !routerInfoRegex.MatchString(seed.Name) {
+			report.Failures = append(report.Failures, BundleEntryError{Name: seed.Name, Err: errors.New("not a routerInfo-<hash>.dat file")})
+			continue
+		}
+		if _, _, err := router_info.ReadRouterInfo(seed.Data); err != nil {
+			report.Failures = append(report.Failures, BundleEntryError{Name: seed.Name, Err: err})
+			continue
+		}
+		report.RouterInfos++
+	}
+	return report, nil
+}
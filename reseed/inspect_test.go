@@ -0,0 +1,62 @@
+package reseed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInspectBundle(t *testing.T) {
+	content, err := zipSeeds([]RouterInfo{
+		{Name: "routerInfo-AAAA.dat", Data: []byte("not a routerinfo"), ModTime: time.Now()},
+		{Name: "README.txt", Data: []byte("hello"), ModTime: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := InspectBundle(content)
+	if err != nil {
+		t.Fatalf("InspectBundle() error: %v", err)
+	}
+	if report.Entries != 2 || report.RouterInfos != 0 || len(report.Failures) != 2 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	if report.Failures[0].Name != "routerInfo-AAAA.dat" || report.Failures[1].Name != "README.txt" {
+		t.Errorf("Expected both entries reported in order, got %+v", report.Failures)
+	}
+
+	if _, err := InspectBundle([]byte("definitely not a zip")); err == nil {
+		t.Error("Expected an error for corrupt zip content")
+	}
+
+	empty, err := zipSeeds(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report, err := InspectBundle(empty); err != nil || report.Entries != 0 {
+		t.Errorf("Expected an empty report for an empty zip, got %+v (%v)", report, err)
+	}
+}
+
+func TestInspectBundle_ValidRouterInfo(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRouterInfo(t, dir, "valid", "0.9.64", "XfR")
+	data, err := os.ReadFile(filepath.Join(dir, "routerInfo-valid.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := zipSeeds([]RouterInfo{{Name: "routerInfo-valid.dat", Data: data, ModTime: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := InspectBundle(content)
+	if err != nil {
+		t.Fatalf("InspectBundle() error: %v", err)
+	}
+	if report.Entries != 1 || report.RouterInfos != 1 || len(report.Failures) != 0 {
+		t.Errorf("Expected the RouterInfo to parse cleanly, got %+v", report)
+	}
+}
@@ -0,0 +1,71 @@
+package reseed
+
+import (
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+)
+
+// KeyStrength returns the strength of pub in RSA-equivalent bits, so one
+// minimum can be applied to RSA, ECDSA and Ed25519 keys alike. RSA and DSA keys
+// count their modulus size; elliptic curve keys the RSA size of the same
+// security level (NIST SP 800-57: P-256 and Ed25519 3072, P-384 7680, P-521 15360).
+func KeyStrength(pub crypto.PublicKey) (int, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return pub.N.BitLen(), nil
+	case *dsa.PublicKey:
+		return pub.P.BitLen(), nil
+	case *ecdsa.PublicKey:
+		switch bits := pub.Curve.Params().BitSize; {
+		case bits >= 512:
+			return 15360, nil
+		case bits >= 384:
+			return 7680, nil
+		case bits >= 256:
+			return 3072, nil
+		case bits >= 224:
+			return 2048, nil
+		default:
+			return 1024, nil
+		}
+	case ed25519.PublicKey:
+		return 3072, nil
+	default:
+		return 0, fmt.Errorf("unsupported key type %T", pub)
+	}
+}
+
+// CheckKeySize returns an error if pub is weaker than minBits RSA-equivalent
+// bits, see KeyStrength. A minBits of zero or less accepts any key.
+func CheckKeySize(pub crypto.PublicKey, minBits int) error {
+	if minBits <= 0 {
+		return nil
+	}
+	bits, err := KeyStrength(pub)
+	if err != nil {
+		return err
+	}
+	if bits < minBits {
+		return fmt.Errorf("%s key is %d bits (RSA-equivalent), below the %d-bit minimum", keyTypeName(pub), bits, minBits)
+	}
+	return nil
+}
+
+func keyTypeName(pub crypto.PublicKey) string {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return "RSA"
+	case *dsa.PublicKey:
+		return "DSA"
+	case *ecdsa.PublicKey:
+		return "ECDSA " + pub.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return fmt.Sprintf("%T", pub)
+	}
+}
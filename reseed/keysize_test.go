@@ -0,0 +1,109 @@
+package reseed
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckKeySize(t *testing.T) {
+	rsa1024, _ := rsa.GenerateKey(rand.Reader, 1024)
+	rsa2048, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name    string
+		pub     crypto.PublicKey
+		minBits int
+		wantErr string
+	}{
+		{"disabled", &rsa1024.PublicKey, 0, ""},
+		{"short RSA key", &rsa1024.PublicKey, 2048, "RSA key is 1024 bits"},
+		{"RSA at the minimum", &rsa2048.PublicKey, 2048, ""},
+		{"RSA below a raised minimum", &rsa2048.PublicKey, 3072, "below the 3072-bit minimum"},
+		{"P-256 counts as 3072", &p256.PublicKey, 3072, ""},
+		{"P-256 below 4096", &p256.PublicKey, 4096, "ECDSA P-256 key is 3072 bits"},
+		{"P-384", &p384.PublicKey, 4096, ""},
+		{"Ed25519", edPub, 3072, ""},
+		{"unsupported key", "not a key", 2048, "unsupported key type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckKeySize(tt.pub, tt.minBits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected key to be accepted, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestListenAndServeTLS_RejectsWeakKey verifies MinKeyBits, and the 2048-bit
+// floor under it, are enforced when the certificate is loaded, before the
+// server starts listening.
+func TestListenAndServeTLS_RejectsWeakKey(t *testing.T) {
+	for _, tt := range []struct {
+		keyBits, minKeyBits int
+		wantErr             string
+	}{
+		{2048, 4096, "below the 4096-bit minimum"},
+		{1024, 0, "below the 2048-bit minimum"},
+	} {
+		listenWithRSAKey(t, tt.keyBits, tt.minKeyBits, tt.wantErr)
+	}
+}
+
+// listenWithRSAKey starts a TLS server with MinKeyBits set to minKeyBits and
+// a certificate for a keyBits RSA key, and expects it to fail with wantErr.
+func listenWithRSAKey(t *testing.T, keyBits, minKeyBits int, wantErr string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600)
+
+	srv := NewServer("", false, "", 4, 40, 2000)
+	srv.Health = NewHealth()
+	srv.Addr = "127.0.0.1:0"
+	srv.MinKeyBits = minKeyBits
+	err = srv.ListenAndServeTLS(certFile, keyFile)
+	if err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Fatalf("Expected a key size error containing %q, got: %v", wantErr, err)
+	}
+	if srv.ServerListener != nil {
+		t.Error("Server should not listen with a weak certificate key")
+	}
+}
@@ -30,10 +30,14 @@ func (ks *KeyStore) DirReseederCertificate(dir string, signer []byte) (*x509.Cer
 	return ks.reseederCertificate(dir, signer)
 }
 
+// CertificatePath returns the file DirReseederCertificate reads signer's certificate from.
+func (ks *KeyStore) CertificatePath(dir string, signer []byte) string {
+	return filepath.Join(ks.Path, dir, filepath.Base(SignerFilename(string(signer))))
+}
+
 // reseederCertificate is a helper method to load certificates from the keystore.
 func (ks *KeyStore) reseederCertificate(dir string, signer []byte) (*x509.Certificate, error) {
-	certFile := filepath.Base(SignerFilename(string(signer)))
-	certPath := filepath.Join(ks.Path, dir, certFile)
+	certPath := ks.CertificatePath(dir, signer)
 	certString, err := os.ReadFile(certPath)
 	if nil != err {
 		lgr.WithError(err).WithField("cert_file", certPath).WithField("signer", string(signer)).Error("Failed to read reseed certificate file")
@@ -2,12 +2,13 @@ package reseed
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net"
 
 	"github.com/cretz/bine/tor"
 	"github.com/go-i2p/i2pkeys"
 	"github.com/go-i2p/logger"
-	"github.com/go-i2p/onramp"
 )
 
 var lgr = logger.GetGoI2PLogger()
@@ -24,7 +25,8 @@ func (srv *Server) ListenAndServe() error {
 		return err
 	}
 
-	return srv.Serve(newBlacklistListener(ln, srv.Blacklist))
+	srv.ServerListener = newBlacklistListener(ln, srv.Blacklist)
+	return srv.serveTransport(TransportHTTP, srv.ServerListener)
 }
 
 // ListenAndServeTLS starts the server using HTTPS with the provided certificate
@@ -50,13 +52,28 @@ func (srv *Server) ListenAndServeTLS(certFile, keyFile string) error {
 		return err
 	}
 
+	// Refuse certificates that would only fail later, during client handshakes
+	leaf := srv.TLSConfig.Certificates[0].Leaf
+	if leaf == nil {
+		if leaf, err = x509.ParseCertificate(srv.TLSConfig.Certificates[0].Certificate[0]); err != nil {
+			return err
+		}
+	}
+	if err := CheckTLSCertificate(leaf, srv.TLSConfig, srv.MinKeyBits); err != nil {
+		return fmt.Errorf("%s: %w", certFile, err)
+	}
+
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return err
 	}
+	if err := srv.startSessionTicketRotation(); err != nil {
+		ln.Close()
+		return fmt.Errorf("session ticket keys: %w", err)
+	}
 
-	tlsListener := tls.NewListener(newBlacklistListener(ln, srv.Blacklist), srv.TLSConfig)
-	return srv.Serve(tlsListener)
+	srv.ServerListener = tls.NewListener(newBlacklistListener(ln, srv.Blacklist), srv.TLSConfig)
+	return srv.serveTransport(TransportHTTPS, srv.ServerListener)
 }
 
 // ListenAndServeOnionTLS starts the server as a Tor onion v3 hidden service
@@ -64,7 +81,7 @@ func (srv *Server) ListenAndServeTLS(certFile, keyFile string) error {
 func (srv *Server) ListenAndServeOnionTLS(startConf *tor.StartConf, listenConf *tor.ListenConf, certFile, keyFile string) error {
 	lgr.WithField("service", "onionv3-https").Debug("Starting and registering OnionV3 HTTPS service, please wait a couple of minutes...")
 	var err error
-	srv.Onion, err = onramp.NewOnion("reseed")
+	srv.Onion, err = newOnion("reseed")
 	if err != nil {
 		return err
 	}
@@ -74,7 +91,7 @@ func (srv *Server) ListenAndServeOnionTLS(startConf *tor.StartConf, listenConf *
 	}
 	lgr.WithField("service", "onionv3-https").WithField("address", srv.OnionListener.Addr().String()+".onion").WithField("protocol", "https").Debug("Onionv3 server started")
 
-	return srv.Serve(srv.OnionListener)
+	return srv.serveTransport(TransportOnion, srv.OnionListener)
 }
 
 // ListenAndServeOnion starts the server as a Tor onion v3 hidden service
@@ -82,7 +99,7 @@ func (srv *Server) ListenAndServeOnionTLS(startConf *tor.StartConf, listenConf *
 func (srv *Server) ListenAndServeOnion(startConf *tor.StartConf, listenConf *tor.ListenConf) error {
 	lgr.WithField("service", "onionv3-http").Debug("Starting and registering OnionV3 HTTP service, please wait a couple of minutes...")
 	var err error
-	srv.Onion, err = onramp.NewOnion("reseed")
+	srv.Onion, err = newOnion("reseed")
 	if err != nil {
 		return err
 	}
@@ -92,43 +109,25 @@ func (srv *Server) ListenAndServeOnion(startConf *tor.StartConf, listenConf *tor
 	}
 	lgr.WithField("service", "onionv3-http").WithField("address", srv.OnionListener.Addr().String()+".onion").WithField("protocol", "http").Debug("Onionv3 server started")
 
-	return srv.Serve(srv.OnionListener)
+	return srv.serveTransport(TransportOnion, srv.OnionListener)
 }
 
 // ListenAndServeI2PTLS starts the server as an I2P hidden service with TLS
-// encryption, connecting through the SAM bridge at the given address.
+// encryption, connecting through the SAM bridge at the given address. The SAM
+// session is re-established with backoff if it is lost.
 func (srv *Server) ListenAndServeI2PTLS(samaddr string, I2PKeys i2pkeys.I2PKeys, certFile, keyFile string) error {
 	lgr.WithField("service", "i2p-https").WithField("sam_address", samaddr).Debug("Starting and registering I2P HTTPS service, please wait a couple of minutes...")
-	var err error
-	if srv.Garlic == nil {
-		srv.Garlic, err = onramp.NewGarlic("reseed", samaddr, onramp.OPT_WIDE)
-		if err != nil {
-			lgr.WithError(err).Warn("Failed to create Garlic instance for I2P")
-		}
-	}
-	srv.I2PListener, err = srv.Garlic.ListenTLS()
-	if err != nil {
-		return err
-	}
-	lgr.WithField("service", "i2p-https").WithField("address", srv.I2PListener.Addr().(i2pkeys.I2PAddr).Base32()).WithField("protocol", "https").Debug("I2P server started")
-	return srv.Serve(srv.I2PListener)
+	return srv.serveI2P(samaddr, "i2p-https", func(g Tunnel) (net.Listener, error) {
+		return g.ListenTLS()
+	})
 }
 
 // ListenAndServeI2P starts the server as an I2P hidden service using plain HTTP,
-// connecting through the SAM bridge at the given address.
+// connecting through the SAM bridge at the given address. The SAM session is
+// re-established with backoff if it is lost.
 func (srv *Server) ListenAndServeI2P(samaddr string, I2PKeys i2pkeys.I2PKeys) error {
 	lgr.WithField("service", "i2p-http").WithField("sam_address", samaddr).Debug("Starting and registering I2P service, please wait a couple of minutes...")
-	var err error
-	if srv.Garlic == nil {
-		srv.Garlic, err = onramp.NewGarlic("reseed", samaddr, onramp.OPT_WIDE)
-		if err != nil {
-			lgr.WithError(err).Warn("Failed to create Garlic instance for I2P")
-		}
-	}
-	srv.I2PListener, err = srv.Garlic.Listen()
-	if err != nil {
-		return err
-	}
-	lgr.WithField("service", "i2p-http").WithField("address", srv.I2PListener.Addr().(i2pkeys.I2PAddr).Base32()+".b32.i2p").WithField("protocol", "http").Debug("I2P server started")
-	return srv.Serve(srv.I2PListener)
+	return srv.serveI2P(samaddr, "i2p-http", func(g Tunnel) (net.Listener, error) {
+		return g.Listen()
+	})
 }
@@ -0,0 +1,91 @@
+package reseed
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryGuard sheds reseed requests while the heap is above a limit. Rebuilds
+// briefly hold a second bundle set in memory, and on a small server that spike
+// together with a burst of downloads can exhaust memory; turning downloads away
+// until the heap shrinks keeps the process alive.
+type MemoryGuard struct {
+	// Limit is the heap size, in bytes, above which reseed requests are shed
+	Limit uint64
+
+	over atomic.Bool
+	heap atomic.Uint64
+	shed atomic.Uint64
+}
+
+// heapInUse reports the bytes of live heap objects.
+var heapInUse = func() uint64 {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return mem.HeapAlloc
+}
+
+// Check samples the heap and records whether it is above Limit, logging when
+// shedding starts and stops.
+func (mg *MemoryGuard) Check() {
+	heap := heapInUse()
+	mg.heap.Store(heap)
+	over := heap > mg.Limit
+	if mg.over.Swap(over) == over {
+		return
+	}
+	entry := lgr.WithField("heap_kb", heap/1024).WithField("limit_kb", mg.Limit/1024)
+	if over {
+		entry.Warn("Heap above --shed-memory, shedding reseed requests")
+	} else {
+		entry.WithField("shed", mg.shed.Load()).Info("Heap back under --shed-memory, serving reseed requests again")
+	}
+}
+
+// Watch runs Check every interval until ctx is cancelled.
+func (mg *MemoryGuard) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			mg.Check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Shed returns the number of requests turned away so far.
+func (mg *MemoryGuard) Shed() uint64 {
+	return mg.shed.Load()
+}
+
+// shedding reports whether the last Check found the heap above Limit. A nil
+// MemoryGuard never sheds.
+func (mg *MemoryGuard) shedding() bool {
+	return mg != nil && mg.over.Load()
+}
+
+// memoryGuardRetryAfter is the delay, in seconds, suggested to shed clients.
+const memoryGuardRetryAfter = 60
+
+// memoryGuardMiddleware answers 503 instead of serving a bundle while the
+// reseeder's MemoryGuard is shedding. It runs before the rate limits so shed
+// requests do not count against a client's quota.
+func (srv *Server) memoryGuardMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if srv.Reseeder != nil && srv.Reseeder.MemoryGuard.shedding() {
+			srv.Reseeder.MemoryGuard.shed.Add(1)
+			w.Header().Set("Retry-After", strconv.Itoa(memoryGuardRetryAfter))
+			http.Error(w, "503 Server busy, try again later", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
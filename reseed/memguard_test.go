@@ -0,0 +1,71 @@
+package reseed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubHeap makes heapInUse report heap.
+func stubHeap(t *testing.T, heap uint64) {
+	t.Helper()
+	orig := heapInUse
+	heapInUse = func() uint64 { return heap }
+	t.Cleanup(func() { heapInUse = orig })
+}
+
+func TestMemoryGuard(t *testing.T) {
+	guard := &MemoryGuard{Limit: 100 << 20}
+	stubHeap(t, 50<<20)
+	guard.Check()
+	if guard.shedding() {
+		t.Error("Expected no shedding under the limit")
+	}
+	stubHeap(t, 150<<20)
+	guard.Check()
+	if !guard.shedding() {
+		t.Error("Expected shedding above the limit")
+	}
+	stubHeap(t, 99<<20)
+	guard.Check()
+	if guard.shedding() {
+		t.Error("Expected shedding to stop once the heap recovers")
+	}
+	if (*MemoryGuard)(nil).shedding() {
+		t.Error("Expected a nil MemoryGuard never to shed")
+	}
+}
+
+// TestReseedHandler_MemoryGuard verifies that bundle downloads get 503 with
+// Retry-After while the guard is shedding, and are served again afterwards.
+func TestReseedHandler_MemoryGuard(t *testing.T) {
+	srv := NewServer("", false, "", 1000, 1000, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle")})
+	srv.Reseeder.MemoryGuard = &MemoryGuard{Limit: 100 << 20}
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/i2pseeds.su3", nil)
+		req.Header.Set("User-Agent", I2pUserAgent)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	stubHeap(t, 150<<20)
+	srv.Reseeder.MemoryGuard.Check()
+	if w := get(); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 503 with Retry-After while shedding, got %d %v", w.Code, w.Header())
+	}
+	if got := srv.Reseeder.MemoryGuard.Shed(); got != 1 {
+		t.Errorf("Expected 1 shed request, got %d", got)
+	}
+
+	stubHeap(t, 10<<20)
+	srv.Reseeder.MemoryGuard.Check()
+	if w := get(); w.Code != http.StatusOK || w.Body.String() != "bundle" {
+		t.Errorf("Expected the bundle once memory recovered, got %d %q", w.Code, w.Body.String())
+	}
+}
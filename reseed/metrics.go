@@ -0,0 +1,68 @@
+package reseed
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// requestMetrics counts reseed traffic for the Prometheus endpoint. The counts
+// are process-wide, so the HTTPS, I2P and Tor servers add up to one total.
+type requestMetrics struct {
+	su3Served         atomic.Uint64
+	su3NotModified    atomic.Uint64
+	su3RateLimited    atomic.Uint64
+	webRateLimited    atomic.Uint64
+	globalRateLimited atomic.Uint64
+	blacklisted       atomic.Uint64
+	rebuilds          atomic.Uint64
+	rebuildFailures   atomic.Uint64
+}
+
+var metrics requestMetrics
+
+// countingDeniedHandler returns a throttled DeniedHandler that counts each
+// rejection in counter before sending the usual 429.
+func countingDeniedHandler(counter *atomic.Uint64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter.Add(1)
+		http.Error(w, "limit exceeded", http.StatusTooManyRequests)
+	})
+}
+
+// MetricsHandler serves reseed request counters and the state of rs's SU3
+// cache in the Prometheus text exposition format.
+func MetricsHandler(rs *ReseederImpl) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w, rs)
+	})
+}
+
+// writeMetrics writes every metric, reading the cache state from rs.
+func writeMetrics(w io.Writer, rs *ReseederImpl) {
+	metric := func(name, kind, help string, value any) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+	metric("reseed_su3_requests_total", "counter", "SU3 bundles served.", metrics.su3Served.Load())
+	metric("reseed_su3_not_modified_total", "counter", "SU3 requests answered 304 because the client already held the bundle.", metrics.su3NotModified.Load())
+	fmt.Fprintf(w, "# HELP reseed_rate_limited_total Requests rejected by a rate limit.\n# TYPE reseed_rate_limited_total counter\n")
+	fmt.Fprintf(w, "reseed_rate_limited_total{limit=\"su3\"} %d\n", metrics.su3RateLimited.Load())
+	fmt.Fprintf(w, "reseed_rate_limited_total{limit=\"web\"} %d\n", metrics.webRateLimited.Load())
+	fmt.Fprintf(w, "reseed_rate_limited_total{limit=\"global\"} %d\n", metrics.globalRateLimited.Load())
+	metric("reseed_blacklist_rejections_total", "counter", "Connections refused because the client address is blacklisted.", metrics.blacklisted.Load())
+	metric("reseed_rebuilds_total", "counter", "SU3 cache rebuilds that completed.", metrics.rebuilds.Load())
+	metric("reseed_rebuild_failures_total", "counter", "SU3 cache rebuilds that failed, leaving the previous bundles in place.", metrics.rebuildFailures.Load())
+
+	bundles, _ := rs.su3s.Load().([][]byte)
+	metric("reseed_su3_cache_bundles", "gauge", "SU3 bundles in the cache being served.", len(bundles))
+	var routerInfos, scanned int
+	var lastRebuild int64
+	if stats := rs.LastRebuild(); stats != nil {
+		routerInfos, scanned, lastRebuild = stats.Valid, stats.Scanned, stats.Time.Unix()
+	}
+	metric("reseed_router_infos", "gauge", "RouterInfos that passed filtering at the last successful rebuild.", routerInfos)
+	metric("reseed_router_infos_scanned", "gauge", "RouterInfo files found in the netDb at the last successful rebuild.", scanned)
+	metric("reseed_last_rebuild_timestamp_seconds", "gauge", "Unix time of the last successful rebuild, 0 before the first.", lastRebuild)
+}
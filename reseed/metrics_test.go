@@ -0,0 +1,57 @@
+package reseed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	rs := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	rs.su3s.Store([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	rs.lastRebuild.Store(&RebuildStats{Time: time.Unix(1700000000, 0), Scanned: 120, Valid: 100, Bundles: 3})
+	served := metrics.su3Served.Load()
+	rs.countServed()
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(rs).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected Content-Type %q", rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE reseed_su3_requests_total counter\nreseed_su3_requests_total " + strconv.FormatUint(served+1, 10) + "\n",
+		"reseed_rate_limited_total{limit=\"global\"} ",
+		"# TYPE reseed_su3_cache_bundles gauge\nreseed_su3_cache_bundles 3\n",
+		"reseed_router_infos 100\n",
+		"reseed_router_infos_scanned 120\n",
+		"reseed_last_rebuild_timestamp_seconds 1700000000\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsHandler_BeforeFirstRebuild(t *testing.T) {
+	rec := httptest.NewRecorder()
+	MetricsHandler(NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "reseed_su3_cache_bundles 0\n") || !strings.Contains(body, "reseed_last_rebuild_timestamp_seconds 0\n") {
+		t.Errorf("Expected empty cache metrics, got:\n%s", body)
+	}
+}
+
+func TestCountingDeniedHandler(t *testing.T) {
+	before := metrics.webRateLimited.Load()
+	rec := httptest.NewRecorder()
+	countingDeniedHandler(&metrics.webRateLimited).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429, got %d", rec.Code)
+	}
+	if metrics.webRateLimited.Load() != before+1 {
+		t.Error("Expected the rejection to be counted")
+	}
+}
@@ -1,27 +1,38 @@
 package reseed
 
 import (
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 )
 
-// TestProxiedMiddleware_ValidSingleIP tests that a single valid IP is extracted correctly
-func TestProxiedMiddleware_ValidSingleIP(t *testing.T) {
-	handler := proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.RemoteAddr != "192.168.1.100" {
-			t.Errorf("Expected RemoteAddr to be '192.168.1.100', got '%s'", r.RemoteAddr)
-		}
+// proxiedRemoteAddr runs a request from remoteAddr carrying headers through
+// srv's proxiedMiddleware and returns the RemoteAddr the next handler sees.
+func proxiedRemoteAddr(srv *Server, remoteAddr string, headers map[string]string) string {
+	var got string
+	handler := srv.proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
 	}))
-
 	req := httptest.NewRequest("GET", "/", nil)
-	req.Header.Set("X-Forwarded-For", "192.168.1.100")
-	req.RemoteAddr = "10.0.0.1:12345" // Original RemoteAddr
-
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 	handler.ServeHTTP(httptest.NewRecorder(), req)
+	return got
+}
+
+// TestProxiedMiddleware_ValidSingleIP tests that a single valid IP is extracted correctly
+func TestProxiedMiddleware_ValidSingleIP(t *testing.T) {
+	if got := proxiedRemoteAddr(&Server{}, "10.0.0.1:12345", map[string]string{"X-Forwarded-For": "192.168.1.100"}); got != "192.168.1.100" {
+		t.Errorf("Expected RemoteAddr to be '192.168.1.100', got '%s'", got)
+	}
 }
 
-// TestProxiedMiddleware_MultipleIPs tests that the first IP is extracted from comma-separated list
+// TestProxiedMiddleware_MultipleIPs tests that, with only the connecting proxy
+// trusted, the rightmost hop of a comma-separated list is the client: every
+// hop to its left was written by the client itself
 func TestProxiedMiddleware_MultipleIPs(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -31,43 +42,36 @@ func TestProxiedMiddleware_MultipleIPs(t *testing.T) {
 		{
 			name:         "Two IPs",
 			headerValue:  "1.2.3.4, 5.6.7.8",
-			expectedAddr: "1.2.3.4",
+			expectedAddr: "5.6.7.8",
 		},
 		{
 			name:         "Three IPs",
 			headerValue:  "1.2.3.4, 5.6.7.8, 9.10.11.12",
-			expectedAddr: "1.2.3.4",
+			expectedAddr: "9.10.11.12",
 		},
 		{
 			name:         "Multiple IPs with varying whitespace",
 			headerValue:  "203.0.113.45,  192.168.1.1,   10.0.0.1",
-			expectedAddr: "203.0.113.45",
+			expectedAddr: "10.0.0.1",
 		},
 		{
 			name:         "IPs with tabs and spaces",
-			headerValue:  "	172.16.0.1	, 192.168.1.1 , 10.0.0.1",
-			expectedAddr: "172.16.0.1",
+			headerValue:  "	172.16.0.1	, 192.168.1.1 , 10.0.0.1	",
+			expectedAddr: "10.0.0.1",
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			handler := proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if r.RemoteAddr != tc.expectedAddr {
-					t.Errorf("Expected RemoteAddr to be '%s', got '%s'", tc.expectedAddr, r.RemoteAddr)
-				}
-			}))
-
-			req := httptest.NewRequest("GET", "/", nil)
-			req.Header.Set("X-Forwarded-For", tc.headerValue)
-			req.RemoteAddr = "10.0.0.1:12345"
-
-			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if got := proxiedRemoteAddr(&Server{}, "10.0.0.1:12345", map[string]string{"X-Forwarded-For": tc.headerValue}); got != tc.expectedAddr {
+				t.Errorf("Expected RemoteAddr to be '%s', got '%s'", tc.expectedAddr, got)
+			}
 		})
 	}
 }
 
 // TestProxiedMiddleware_IPv6 tests that IPv6 addresses are handled correctly
+// and written in canonical form, so one client cannot appear as several
 func TestProxiedMiddleware_IPv6(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -77,33 +81,30 @@ func TestProxiedMiddleware_IPv6(t *testing.T) {
 		{
 			name:         "Single IPv6",
 			headerValue:  "2001:0db8:85a3::8a2e:0370:7334",
-			expectedAddr: "2001:0db8:85a3::8a2e:0370:7334",
+			expectedAddr: "2001:db8:85a3::8a2e:370:7334",
 		},
 		{
-			name:         "IPv6 with IPv4",
-			headerValue:  "2001:0db8:85a3::8a2e:0370:7334, 192.168.1.1",
-			expectedAddr: "2001:0db8:85a3::8a2e:0370:7334",
+			name:         "IPv4 with IPv6",
+			headerValue:  "192.168.1.1, 2001:0db8:85a3::8a2e:0370:7334",
+			expectedAddr: "2001:db8:85a3::8a2e:370:7334",
 		},
 		{
 			name:         "Compressed IPv6",
-			headerValue:  "::1, 127.0.0.1",
+			headerValue:  "127.0.0.1, ::1",
 			expectedAddr: "::1",
 		},
+		{
+			name:         "IPv4-mapped IPv6",
+			headerValue:  "::ffff:192.0.2.1",
+			expectedAddr: "192.0.2.1",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			handler := proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if r.RemoteAddr != tc.expectedAddr {
-					t.Errorf("Expected RemoteAddr to be '%s', got '%s'", tc.expectedAddr, r.RemoteAddr)
-				}
-			}))
-
-			req := httptest.NewRequest("GET", "/", nil)
-			req.Header.Set("X-Forwarded-For", tc.headerValue)
-			req.RemoteAddr = "10.0.0.1:12345"
-
-			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if got := proxiedRemoteAddr(&Server{}, "10.0.0.1:12345", map[string]string{"X-Forwarded-For": tc.headerValue}); got != tc.expectedAddr {
+				t.Errorf("Expected RemoteAddr to be '%s', got '%s'", tc.expectedAddr, got)
+			}
 		})
 	}
 }
@@ -111,131 +112,64 @@ func TestProxiedMiddleware_IPv6(t *testing.T) {
 // TestProxiedMiddleware_InvalidIP tests that invalid IPs don't override RemoteAddr
 func TestProxiedMiddleware_InvalidIP(t *testing.T) {
 	testCases := []struct {
-		name         string
-		headerValue  string
-		originalAddr string
+		name        string
+		headerValue string
 	}{
-		{
-			name:         "Malformed IP",
-			headerValue:  "999.999.999.999",
-			originalAddr: "10.0.0.1:12345",
-		},
-		{
-			name:         "Invalid format",
-			headerValue:  "not-an-ip-address",
-			originalAddr: "10.0.0.1:12345",
-		},
-		{
-			name:         "Injection attempt",
-			headerValue:  "1.2.3.4, <script>alert('xss')</script>",
-			originalAddr: "10.0.0.1:12345",
-		},
-		{
-			name:         "SQL injection attempt",
-			headerValue:  "1' OR '1'='1",
-			originalAddr: "10.0.0.1:12345",
-		},
-		{
-			name:         "Empty string in list",
-			headerValue:  ", 192.168.1.1",
-			originalAddr: "10.0.0.1:12345",
-		},
-		{
-			name:         "Only whitespace",
-			headerValue:  "   ",
-			originalAddr: "10.0.0.1:12345",
-		},
+		{name: "Malformed IP", headerValue: "999.999.999.999"},
+		{name: "Invalid format", headerValue: "not-an-ip-address"},
+		{name: "Hostname", headerValue: "proxy.example.com"},
+		{name: "Injection attempt", headerValue: "1.2.3.4, <script>alert('xss')</script>"},
+		{name: "SQL injection attempt", headerValue: "1' OR '1'='1"},
+		{name: "Empty string at the end", headerValue: "192.168.1.1, "},
+		{name: "Only whitespace", headerValue: "   "},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			handler := proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// RemoteAddr should remain unchanged when invalid IP is provided
-				if r.RemoteAddr != tc.originalAddr {
-					t.Errorf("Expected RemoteAddr to remain '%s', got '%s'", tc.originalAddr, r.RemoteAddr)
-				}
-			}))
-
-			req := httptest.NewRequest("GET", "/", nil)
-			req.Header.Set("X-Forwarded-For", tc.headerValue)
-			req.RemoteAddr = tc.originalAddr
-
-			handler.ServeHTTP(httptest.NewRecorder(), req)
+			// RemoteAddr should remain unchanged when invalid IP is provided
+			if got := proxiedRemoteAddr(&Server{}, "10.0.0.1:12345", map[string]string{"X-Forwarded-For": tc.headerValue}); got != "10.0.0.1:12345" {
+				t.Errorf("Expected RemoteAddr to remain '10.0.0.1:12345', got '%s'", got)
+			}
 		})
 	}
 }
 
 // TestProxiedMiddleware_NoHeader tests that RemoteAddr is unchanged when no X-Forwarded-For header is present
 func TestProxiedMiddleware_NoHeader(t *testing.T) {
-	originalAddr := "10.0.0.1:12345"
-	handler := proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.RemoteAddr != originalAddr {
-			t.Errorf("Expected RemoteAddr to remain '%s', got '%s'", originalAddr, r.RemoteAddr)
-		}
-	}))
-
-	req := httptest.NewRequest("GET", "/", nil)
-	req.RemoteAddr = originalAddr
-	// No X-Forwarded-For header set
-
-	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got := proxiedRemoteAddr(&Server{}, "10.0.0.1:12345", nil); got != "10.0.0.1:12345" {
+		t.Errorf("Expected RemoteAddr to remain '10.0.0.1:12345', got '%s'", got)
+	}
 }
 
 // TestProxiedMiddleware_EmptyHeader tests that empty X-Forwarded-For header is handled safely
 func TestProxiedMiddleware_EmptyHeader(t *testing.T) {
-	originalAddr := "10.0.0.1:12345"
-	handler := proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.RemoteAddr != originalAddr {
-			t.Errorf("Expected RemoteAddr to remain '%s', got '%s'", originalAddr, r.RemoteAddr)
-		}
-	}))
-
-	req := httptest.NewRequest("GET", "/", nil)
-	req.Header.Set("X-Forwarded-For", "")
-	req.RemoteAddr = originalAddr
-
-	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got := proxiedRemoteAddr(&Server{}, "10.0.0.1:12345", map[string]string{"X-Forwarded-For": ""}); got != "10.0.0.1:12345" {
+		t.Errorf("Expected RemoteAddr to remain '10.0.0.1:12345', got '%s'", got)
+	}
 }
 
-// TestProxiedMiddleware_RateLimitingBypass tests the original vulnerability
-// This test verifies that the fix prevents rate limiting bypass via malformed headers
+// TestProxiedMiddleware_RateLimitingBypass tests that a client cannot pick its
+// own rate limit bucket by sending an X-Forwarded-For header of its choosing
 func TestProxiedMiddleware_RateLimitingBypass(t *testing.T) {
-	// This is the attack scenario from the audit:
-	// Attacker sends X-Forwarded-For: "1.2.3.4, 5.6.7.8" which previously
-	// would be assigned directly to r.RemoteAddr, breaking rate limiting
-
-	handler := proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// After the fix, r.RemoteAddr should contain only the first valid IP
-		if r.RemoteAddr != "1.2.3.4" {
-			t.Errorf("Expected RemoteAddr to be '1.2.3.4' (first IP), got '%s'", r.RemoteAddr)
-		}
-	}))
-
-	req := httptest.NewRequest("GET", "/i2pseeds.su3", nil)
-	req.Header.Set("X-Forwarded-For", "1.2.3.4, 5.6.7.8")
-	req.RemoteAddr = "10.0.0.1:12345"
-
-	handler.ServeHTTP(httptest.NewRecorder(), req)
+	// The client 5.6.7.8 sends "X-Forwarded-For: 1.2.3.4" and the proxy
+	// appends the address it really connected from
+	got := proxiedRemoteAddr(&Server{}, "10.0.0.1:12345", map[string]string{"X-Forwarded-For": "1.2.3.4, 5.6.7.8"})
+	if got != "5.6.7.8" {
+		t.Errorf("Expected RemoteAddr to be '5.6.7.8' (the real client), got '%s'", got)
+	}
 }
 
-// TestProxiedMiddleware_BlacklistEvasion tests that blacklist checks work correctly
-// This verifies that an attacker can't evade blacklisting by injecting comma-separated IPs
+// TestProxiedMiddleware_BlacklistEvasion tests that a blacklisted client cannot
+// hide behind an address it writes into X-Forwarded-For itself
 func TestProxiedMiddleware_BlacklistEvasion(t *testing.T) {
-	// Scenario: IP 203.0.113.100 is blacklisted
-	// Attacker tries to evade by sending: "203.0.113.100, 192.168.1.1"
-	// The first IP should still be extracted for blacklist checking
-
-	handler := proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.RemoteAddr != "203.0.113.100" {
-			t.Errorf("Expected RemoteAddr to be '203.0.113.100' (blacklisted IP), got '%s'", r.RemoteAddr)
+	// Scenario: IP 203.0.113.100 is blacklisted and sends a forged
+	// "X-Forwarded-For: 192.168.1.1"; the proxy appends 203.0.113.100
+	for _, forged := range []string{"192.168.1.1", "garbage", "<script>"} {
+		got := proxiedRemoteAddr(&Server{}, "10.0.0.1:12345", map[string]string{"X-Forwarded-For": forged + ", 203.0.113.100"})
+		if got != "203.0.113.100" {
+			t.Errorf("Forged %q: expected RemoteAddr to be '203.0.113.100' (blacklisted IP), got '%s'", forged, got)
 		}
-	}))
-
-	req := httptest.NewRequest("GET", "/i2pseeds.su3", nil)
-	req.Header.Set("X-Forwarded-For", "203.0.113.100, 192.168.1.1")
-	req.RemoteAddr = "10.0.0.1:12345"
-
-	handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
 }
 
 // TestProxiedMiddleware_EdgeCases tests various edge cases
@@ -243,48 +177,148 @@ func TestProxiedMiddleware_EdgeCases(t *testing.T) {
 	testCases := []struct {
 		name         string
 		headerValue  string
-		originalAddr string
 		expectedAddr string
 	}{
 		{
 			name:         "Single comma",
 			headerValue:  ",",
-			originalAddr: "10.0.0.1:12345",
 			expectedAddr: "10.0.0.1:12345", // Should remain unchanged
 		},
 		{
 			name:         "Multiple commas",
 			headerValue:  ",,,",
-			originalAddr: "10.0.0.1:12345",
 			expectedAddr: "10.0.0.1:12345", // Should remain unchanged
 		},
 		{
-			name:         "Valid IP followed by garbage",
-			headerValue:  "192.168.1.1, garbage, more-garbage",
-			originalAddr: "10.0.0.1:12345",
-			expectedAddr: "192.168.1.1", // Should extract first valid IP
+			name:         "Garbage followed by a valid IP",
+			headerValue:  "garbage, more-garbage, 192.168.1.1",
+			expectedAddr: "192.168.1.1", // Hops left of the client are never read
+		},
+		{
+			name:         "Empty string before a valid IP",
+			headerValue:  ", 192.168.1.1",
+			expectedAddr: "192.168.1.1",
 		},
 		{
 			name:         "Spaces only between commas",
 			headerValue:  " , , ",
-			originalAddr: "10.0.0.1:12345",
 			expectedAddr: "10.0.0.1:12345", // Should remain unchanged
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			handler := proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if r.RemoteAddr != tc.expectedAddr {
-					t.Errorf("Expected RemoteAddr to be '%s', got '%s'", tc.expectedAddr, r.RemoteAddr)
-				}
-			}))
+			if got := proxiedRemoteAddr(&Server{}, "10.0.0.1:12345", map[string]string{"X-Forwarded-For": tc.headerValue}); got != tc.expectedAddr {
+				t.Errorf("Expected RemoteAddr to be '%s', got '%s'", tc.expectedAddr, got)
+			}
+		})
+	}
+}
 
-			req := httptest.NewRequest("GET", "/", nil)
-			req.Header.Set("X-Forwarded-For", tc.headerValue)
-			req.RemoteAddr = tc.originalAddr
+// TestProxiedMiddleware_TrustedProxies tests following the chain through a
+// CDN in front of a local reverse proxy
+func TestProxiedMiddleware_TrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"127.0.0.1", "173.245.48.0/20", "2400:cb00::/32"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error: %v", err)
+	}
+	srv := &Server{TrustedProxies: proxies}
+
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		headers      map[string]string
+		expectedAddr string
+	}{
+		{
+			name:         "Client behind CDN and local proxy",
+			remoteAddr:   "127.0.0.1:40000",
+			headers:      map[string]string{"X-Forwarded-For": "6.6.6.6, 203.0.113.7, 173.245.48.1"},
+			expectedAddr: "203.0.113.7",
+		},
+		{
+			name:         "IPv6 CDN edge",
+			remoteAddr:   "127.0.0.1:40000",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7, 2400:cb00::1"},
+			expectedAddr: "203.0.113.7",
+		},
+		{
+			name:         "Client forging a trusted hop",
+			remoteAddr:   "127.0.0.1:40000",
+			headers:      map[string]string{"X-Forwarded-For": "173.245.48.9, 203.0.113.7, 173.245.48.1"},
+			expectedAddr: "203.0.113.7",
+		},
+		{
+			name:         "Every hop trusted",
+			remoteAddr:   "127.0.0.1:40000",
+			headers:      map[string]string{"X-Forwarded-For": "173.245.48.9, 173.245.48.1"},
+			expectedAddr: "173.245.48.9",
+		},
+		{
+			name:         "Connection not from a trusted proxy",
+			remoteAddr:   "198.51.100.1:40000",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7", "X-Real-IP": "203.0.113.7"},
+			expectedAddr: "198.51.100.1:40000",
+		},
+		{
+			name:         "X-Real-IP without X-Forwarded-For",
+			remoteAddr:   "127.0.0.1:40000",
+			headers:      map[string]string{"X-Real-IP": " 203.0.113.8 "},
+			expectedAddr: "203.0.113.8",
+		},
+		{
+			name:         "X-Forwarded-For wins over X-Real-IP",
+			remoteAddr:   "127.0.0.1:40000",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7", "X-Real-IP": "203.0.113.8"},
+			expectedAddr: "203.0.113.7",
+		},
+		{
+			name:         "Invalid X-Real-IP",
+			remoteAddr:   "127.0.0.1:40000",
+			headers:      map[string]string{"X-Real-IP": "unknown"},
+			expectedAddr: "127.0.0.1:40000",
+		},
+	}
 
-			handler.ServeHTTP(httptest.NewRecorder(), req)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := proxiedRemoteAddr(srv, tc.remoteAddr, tc.headers); got != tc.expectedAddr {
+				t.Errorf("Expected RemoteAddr to be '%s', got '%s'", tc.expectedAddr, got)
+			}
 		})
 	}
 }
+
+// TestProxiedMiddleware_RepeatedHeaders tests that several X-Forwarded-For
+// headers are read as one chain
+func TestProxiedMiddleware_RepeatedHeaders(t *testing.T) {
+	handler := (&Server{}).proxiedMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RemoteAddr != "203.0.113.7" {
+			t.Errorf("Expected the last hop of the last header, got '%s'", r.RemoteAddr)
+		}
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:12345"
+	req.Header.Add("X-Forwarded-For", "1.2.3.4")
+	req.Header.Add("X-Forwarded-For", "203.0.113.7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"192.0.2.1", " 10.0.0.0/8 ", "", "::1"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error: %v", err)
+	}
+	srv := &Server{TrustedProxies: proxies}
+	for ip, want := range map[string]bool{"192.0.2.1": true, "192.0.2.2": false, "10.200.0.1": true, "::1": true, "::2": false} {
+		if got := srv.trustedProxy(net.ParseIP(ip)); got != want {
+			t.Errorf("trustedProxy(%s) = %v, want %v", ip, got, want)
+		}
+	}
+
+	for _, bad := range []string{"proxy.example.com", "10.0.0.0/33", "192.0.2.1/"} {
+		if _, err := ParseTrustedProxies([]string{bad}); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package reseed
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// maxArchiveRouterInfoSize bounds how much of one archive entry is read. A
+// RouterInfo is a few KiB, so anything larger is not one.
+const maxArchiveRouterInfoSize = 64 * 1024
+
+// gzipMagic is the two-byte header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// isNetDbArchive reports whether path is a regular file, which a netDb can
+// only be as a tar archive of one, such as the netDb.tar.gz served by share.
+func isNetDbArchive(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// scanArchive is scanRouterInfos for a netDb given as a tar archive,
+// gzip-compressed or not, as told by its first bytes. Entries are read in one
+// pass straight from the archive, with the same filters as files on disk and
+// their modification times taken from the tar headers. Their content is
+// always kept in memory, whatever LazyData says, since there is no file to
+// read it again from. An entry that appears twice counts once, as its last
+// copy, which is the one extracting the archive would leave. MaxFiles counts
+// distinct entries in archive order, so the entries left out are the ones
+// stored last, whatever their names.
+func (db *LocalNetDbImpl) scanArchive(ctx context.Context) (routerInfos []RouterInfo, scan netDbScan, err error) {
+	f, err := os.Open(db.Path)
+	if err != nil {
+		return nil, netDbScan{}, fmt.Errorf("error opening netDb archive %q: %w", db.Path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReader(f)
+	if magic, _ := r.(*bufio.Reader).Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, netDbScan{}, fmt.Errorf("error reading netDb archive %q: %w", db.Path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	type entry struct {
+		name    string
+		modTime time.Time
+		data    []byte
+		tooOld  bool
+	}
+	entries := make(map[string]entry)
+	tr := tar.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, netDbScan{}, err
+		}
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, netDbScan{}, fmt.Errorf("error reading netDb archive %q: %w", db.Path, err)
+		}
+		name := path.Base(header.Name)
+		if header.Typeflag != tar.TypeReg || !routerInfoRegex.MatchString(name) {
+			continue
+		}
+		if _, seen := entries[header.Name]; !seen && db.scanFull(len(entries)) {
+			db.warnScanCapped()
+			break
+		}
+		// ignore outdated routerInfos without reading them
+		if time.Since(header.ModTime) > db.MaxRouterInfoAge {
+			entries[header.Name] = entry{tooOld: true}
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxArchiveRouterInfoSize+1))
+		if err != nil {
+			return nil, netDbScan{}, fmt.Errorf("error reading %s from netDb archive %q: %w", header.Name, db.Path, err)
+		}
+		if len(data) > maxArchiveRouterInfoSize {
+			// leave it to the parser to refuse, without holding all of it
+			data = nil
+		}
+		entries[header.Name] = entry{name: name, modTime: header.ModTime, data: data}
+	}
+	scan.scanned = len(entries)
+
+	// Filter in entry name order, as a directory scan does in path order
+	for _, entryName := range slices.Sorted(maps.Keys(entries)) {
+		e := entries[entryName]
+		if e.tooOld {
+			scan.skipped.count(skipTooOld)
+			continue
+		}
+		ri, reason := db.parseRouterInfo(filepath.Join(db.Path, entryName), e.data, true)
+		if reason != notSkipped {
+			scan.skipped.count(reason)
+			continue
+		}
+		ri.Name, ri.ModTime = e.name, e.modTime
+		routerInfos = append(routerInfos, ri)
+	}
+	return routerInfos, scan, nil
+}
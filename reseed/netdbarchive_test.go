@@ -0,0 +1,180 @@
+package reseed
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestArchive tars every file in dir under r<i>/ subdirectories, the
+// layout of a netDb, plus extra entries, and writes it to a file in the test's
+// temp dir, gzipped if compress is set.
+func writeTestArchive(t *testing.T, dir string, compress bool, extra map[string][]byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	add := func(name string, data []byte, modTime time.Time) {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := file.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		add(fmt.Sprintf("/r%d/%s", i, file.Name()), data, info.ModTime())
+	}
+	for name, data := range extra {
+		add(name, data, time.Now())
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	content := buf.Bytes()
+	if compress {
+		var zbuf bytes.Buffer
+		zw := gzip.NewWriter(&zbuf)
+		zw.Write(content)
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		content = zbuf.Bytes()
+	}
+	path := filepath.Join(t.TempDir(), "netDb.tar.gz")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestScanRouterInfos_Archive(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRouterInfo(t, dir, "a", "0.9.64", "XfR")
+	writeTestRouterInfo(t, dir, "b", "0.9.64", "XfR")
+	writeTestRouterInfo(t, dir, "unreachable", "0.9.64", "XfU")
+	writeTestRouterInfo(t, dir, "stale", "0.9.64", "XfR")
+	stale := time.Now().Add(-100 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "routerInfo-stale.dat"), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	extra := map[string][]byte{
+		"/rX/routerInfo-garbage.dat": []byte("not a routerInfo"),
+		"/README":                    []byte("not a routerInfo file either"),
+	}
+
+	for _, compress := range []bool{true, false} {
+		netdb := NewLocalNetDb(writeTestArchive(t, dir, compress, extra), 72*time.Hour)
+		// LazyData cannot apply, since there is no file to read again
+		netdb.LazyData = true
+		ris, scan, err := netdb.scanRouterInfos(context.Background())
+		if err != nil {
+			t.Fatalf("compress=%v: scanRouterInfos() error: %v", compress, err)
+		}
+		if scan.scanned != 5 {
+			t.Errorf("compress=%v: expected 5 routerInfo entries scanned, got %d", compress, scan.scanned)
+		}
+		if want := (RouterInfoSkips{Unreadable: 1, TooOld: 1, Capabilities: 1}); scan.skipped != want {
+			t.Errorf("compress=%v: skipped = %+v, want %+v", compress, scan.skipped, want)
+		}
+		if len(ris) != 2 || ris[0].Name != "routerInfo-a.dat" || ris[1].Name != "routerInfo-b.dat" {
+			t.Fatalf("compress=%v: expected routers a and b, got %+v", compress, ris)
+		}
+		for _, ri := range ris {
+			if ri.Data == nil || ri.Path != "" || ri.Ident == "" {
+				t.Errorf("compress=%v: expected %s to be held in memory with its identity, got %+v", compress, ri.Name, ri)
+			}
+		}
+	}
+}
+
+func TestRebuild_FromArchive(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		writeTestRouterInfo(t, dir, name, "0.9.64", "XfR")
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reseeder := NewReseeder(NewLocalNetDb(writeTestArchive(t, dir, true, nil), 72*time.Hour))
+	reseeder.SigningKey = key
+	reseeder.SignerID = []byte("test@example.i2p")
+	reseeder.NumRi = 3
+	reseeder.NumSu3 = 2
+	reseeder.MinRouterInfoFraction = 1
+	if err := reseeder.rebuild(); err != nil {
+		t.Fatalf("rebuild() error: %v", err)
+	}
+	if stats := reseeder.LastRebuild(); stats.Bundles != 2 || stats.Valid != 3 {
+		t.Errorf("Expected 2 bundles from 3 valid routers, got %+v", stats)
+	}
+}
+
+func TestScanRouterInfos_NotAnArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netDb.tar.gz")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("x"), 1024), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewLocalNetDb(path, 72*time.Hour).RouterInfos(); err == nil {
+		t.Error("Expected a file that is not a tar archive to fail the scan")
+	}
+}
+
+// TestScanRouterInfos_ArchiveMaxFiles verifies that MaxFiles keeps the first
+// entries stored in an archive, whatever their names.
+func TestScanRouterInfos_ArchiveMaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		writeTestRouterInfo(t, dir, name, "0.9.64", "XfR")
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"c", "b", "a"} {
+		data, err := os.ReadFile(filepath.Join(dir, "routerInfo-"+name+".dat"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: "r" + name + "/routerInfo-" + name + ".dat", Mode: 0o644, Size: int64(len(data)), ModTime: time.Now(), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(data)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "netDb.tar")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	netdb := NewLocalNetDb(path, 72*time.Hour)
+	netdb.MaxFiles = 2
+	ris, scan, err := netdb.scanRouterInfos(context.Background())
+	if err != nil {
+		t.Fatalf("scanRouterInfos() error: %v", err)
+	}
+	if scan.scanned != 2 || len(ris) != 2 || ris[0].Name != "routerInfo-b.dat" || ris[1].Name != "routerInfo-c.dat" {
+		t.Errorf("Expected the first two entries, b and c, got %d scanned: %+v", scan.scanned, ris)
+	}
+}
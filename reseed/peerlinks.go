@@ -0,0 +1,161 @@
+package reseed
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// reseedEntrySeparator separates a reseed server's primary URL from its
+// alternate addresses in a reseed list entry, for example
+// "https://reseed.example/|http://xyz.b32.i2p/|http://abc.onion/".
+const reseedEntrySeparator = "|"
+
+// PeerLink is one address a reseed server can be reached at.
+type PeerLink struct {
+	// Transport is "https", "http", "i2p" or "onion"
+	Transport string `json:"transport"`
+	// URL is the address itself
+	URL string `json:"url"`
+}
+
+var (
+	// peerLinksMu protects peerLinks from concurrent access.
+	peerLinksMu sync.RWMutex
+	// peerLinks holds the addresses of each known reseed server, keyed by the
+	// host name its ping results are recorded under.
+	peerLinks = map[string][]PeerLink{}
+)
+
+// SetReseedPeers replaces the list of known reseed servers. Each entry is a
+// primary URL, optionally followed by alternate I2P, onion or HTTPS addresses
+// of the same server, separated by "|". The primary URLs become AllReseeds and
+// are the ones pinged, and every address is linked next to the server's ping
+// result on the homepage.
+func SetReseedPeers(entries []string) error {
+	reseeds := make([]string, 0, len(entries))
+	links := make(map[string][]PeerLink, len(entries))
+	for _, entry := range entries {
+		primary, peer, err := parseReseedEntry(entry)
+		if err != nil {
+			return err
+		}
+		reseeds = append(reseeds, primary.String())
+		links[trimPath(primary.Host)] = peer
+	}
+
+	peerLinksMu.Lock()
+	AllReseeds = reseeds
+	peerLinks = links
+	peerLinksMu.Unlock()
+
+	// Servers new to the list are pinged without waiting out the daily limit
+	resetPingLimit()
+	return nil
+}
+
+// ReseedPeers returns a copy of AllReseeds that is safe to use while
+// SetReseedPeers replaces the list.
+func ReseedPeers() []string {
+	peerLinksMu.RLock()
+	defer peerLinksMu.RUnlock()
+	return slices.Clone(AllReseeds)
+}
+
+// LoadReseedPeersFile reads reseed list entries from path, one per line in the
+// format SetReseedPeers takes. Blank lines and lines starting with # are
+// skipped.
+func LoadReseedPeersFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, nil
+}
+
+// parseReseedEntry splits a reseed list entry into its primary URL and the
+// links for every address it names, the primary one first.
+func parseReseedEntry(entry string) (*url.URL, []PeerLink, error) {
+	var primary *url.URL
+	var links []PeerLink
+	for _, raw := range strings.Split(entry, reseedEntrySeparator) {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, nil, fmt.Errorf("reseed server %q: %w", entry, err)
+		}
+		transport, err := linkTransport(u)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reseed server %q: %w", entry, err)
+		}
+		if primary == nil {
+			primary = u
+		}
+		links = append(links, PeerLink{Transport: transport, URL: u.String()})
+	}
+	return primary, links, nil
+}
+
+// linkTransport names the transport a reseed address is reached over. Only
+// http and https URLs are accepted, so anything linked from the homepage is
+// safe for a browser to follow.
+func linkTransport(u *url.URL) (string, error) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%q must be an http or https URL", u.String())
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("%q has no host", u.String())
+	}
+	switch host := strings.ToLower(u.Hostname()); {
+	case strings.HasSuffix(host, ".i2p"):
+		return "i2p", nil
+	case strings.HasSuffix(host, ".onion"):
+		return "onion", nil
+	}
+	return u.Scheme, nil
+}
+
+// pingHost returns the host part of a ping result named "<host>-<date>".
+func pingHost(name string) string {
+	if len(name) > len("-2006-01-02") {
+		name = name[:len(name)-len("-2006-01-02")]
+	}
+	return name
+}
+
+// pingHostLinks returns the addresses of the reseed server a ping result
+// named "<host>-<date>" belongs to.
+func pingHostLinks(name string) []PeerLink {
+	peerLinksMu.RLock()
+	defer peerLinksMu.RUnlock()
+	return peerLinks[pingHost(name)]
+}
+
+// currentPingResults drops the results of servers that are no longer in
+// ReseedPeers, such as ones removed when the list was reloaded; their results
+// from earlier in the day stay on disk but are not shown.
+func currentPingResults(entries []PingResult) []PingResult {
+	hosts := map[string]bool{}
+	for _, peer := range ReseedPeers() {
+		if u, err := url.Parse(peer); err == nil {
+			hosts[trimPath(u.Host)] = true
+		}
+	}
+	current := entries[:0]
+	for _, entry := range entries {
+		if hosts[pingHost(entry.Host)] {
+			current = append(current, entry)
+		}
+	}
+	return current
+}
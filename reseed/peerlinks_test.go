@@ -0,0 +1,131 @@
+package reseed
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// restoreReseedPeers puts back the reseed list once a test is done with it.
+func restoreReseedPeers(t *testing.T) {
+	reseeds, links := AllReseeds, peerLinks
+	t.Cleanup(func() { AllReseeds, peerLinks = reseeds, links })
+}
+
+func TestSetReseedPeers(t *testing.T) {
+	restoreReseedPeers(t)
+	err := SetReseedPeers([]string{
+		"https://reseed.example.org/|http://abcdefgh.b32.i2p/|http://xyz.onion/",
+		"https://plain.example.net:8443/",
+	})
+	if err != nil {
+		t.Fatalf("SetReseedPeers() error: %v", err)
+	}
+	if want := []string{"https://reseed.example.org/", "https://plain.example.net:8443/"}; !reflect.DeepEqual(AllReseeds, want) {
+		t.Errorf("AllReseeds = %v, want %v", AllReseeds, want)
+	}
+	want := []PeerLink{
+		{Transport: "https", URL: "https://reseed.example.org/"},
+		{Transport: "i2p", URL: "http://abcdefgh.b32.i2p/"},
+		{Transport: "onion", URL: "http://xyz.onion/"},
+	}
+	if got := pingHostLinks("reseed.example.org-2026-10-14"); !reflect.DeepEqual(got, want) {
+		t.Errorf("pingHostLinks() = %v, want %v", got, want)
+	}
+	if got := pingHostLinks("plain.example.net:8443-2026-10-14"); len(got) != 1 || got[0].Transport != "https" {
+		t.Errorf("Expected the primary link for a server without mirrors, got %v", got)
+	}
+
+	for _, entry := range []string{"javascript:alert(1)", "https://ok.example/|ftp://mirror.example/", "https://"} {
+		if err := SetReseedPeers([]string{entry}); err == nil {
+			t.Errorf("SetReseedPeers(%q) succeeded, want an error", entry)
+		}
+	}
+	if len(AllReseeds) != 2 {
+		t.Errorf("A rejected list should leave the previous one in place, got %v", AllReseeds)
+	}
+}
+
+// TestSetReseedPeers_Reload verifies that replacing the list hides the
+// results of removed servers and lets the new ones be pinged straight away.
+func TestSetReseedPeers_Reload(t *testing.T) {
+	ephemeralDir(t)
+	restoreReseedPeers(t)
+	t.Cleanup(func() {
+		pingResultsMu.Lock()
+		pingResults = map[string]string{}
+		pingResultsMu.Unlock()
+	})
+	date := time.Now().Format("2006-01-02")
+	if err := SetReseedPeers([]string{"https://old.example.org/", "https://kept.example.org/"}); err != nil {
+		t.Fatal(err)
+	}
+	storePingResult("old.example.org-"+date, "Alive: Status OK")
+	storePingResult("kept.example.org-"+date, "Alive: Status OK")
+	pingMu.Lock()
+	lastPing = time.Now()
+	pingMu.Unlock()
+
+	if err := SetReseedPeers([]string{"https://kept.example.org/", "https://new.example.org/"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readPingEntries(true)
+	if err != nil {
+		t.Fatalf("readPingEntries() error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Host != "kept.example.org-"+date {
+		t.Errorf("Expected only the kept server's result, got %+v", entries)
+	}
+	pingMu.Lock()
+	limited := lastPing.After(yday())
+	pingMu.Unlock()
+	if limited {
+		t.Error("Expected the reload to lift the daily ping limit")
+	}
+}
+
+func TestRenderPingResults_Links(t *testing.T) {
+	var buf bytes.Buffer
+	renderPingResults(&buf, []PingResult{{
+		Host:   "reseed.example.org-2026-10-14",
+		Status: "Alive: Status OK",
+		Links:  []PeerLink{{Transport: "i2p", URL: `http://abcdefgh.b32.i2p/?a="b"`}},
+	}})
+	out := buf.String()
+	if want := `<a class="peerlink" href="http://abcdefgh.b32.i2p/?a=&#34;b&#34;">[i2p]</a>`; !strings.Contains(out, want) {
+		t.Errorf("Expected escaped peer link %s in %s", want, out)
+	}
+}
+
+func TestLoadReseedPeersFile(t *testing.T) {
+	restoreReseedPeers(t)
+	path := filepath.Join(t.TempDir(), "friends.txt")
+	os.WriteFile(path, []byte("# reseed servers to ping\nhttps://reseed.example.org/|http://abc.b32.i2p/\n\n  https://plain.example.net:8443/  \n"), 0o644)
+
+	entries, err := LoadReseedPeersFile(path)
+	if err != nil {
+		t.Fatalf("LoadReseedPeersFile() error: %v", err)
+	}
+	if want := []string{"https://reseed.example.org/|http://abc.b32.i2p/", "https://plain.example.net:8443/"}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("LoadReseedPeersFile() = %q, want %q", entries, want)
+	}
+	if err := SetReseedPeers(entries); err != nil {
+		t.Fatal(err)
+	}
+	peers := ReseedPeers()
+	if want := []string{"https://reseed.example.org/", "https://plain.example.net:8443/"}; !reflect.DeepEqual(peers, want) {
+		t.Errorf("ReseedPeers() = %v, want %v", peers, want)
+	}
+	peers[0] = "changed"
+	if AllReseeds[0] == "changed" {
+		t.Error("Expected ReseedPeers to return a copy")
+	}
+
+	if _, err := LoadReseedPeersFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected a missing file to be an error")
+	}
+}
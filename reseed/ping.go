@@ -3,6 +3,8 @@ package reseed
 import (
 	"fmt"
 	"html"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -19,6 +21,50 @@ var pingClient = &http.Client{
 	Timeout: 30 * time.Second,
 }
 
+// SetPingSourceAddr makes outbound ping requests originate from a specific local
+// address, given as an IP or a network interface name, for multi-homed hosts whose
+// routing or firewall policy depends on the source. An empty value restores the
+// default. It should be called before any pings are started. Nothing else uses
+// this client: share downloads are dialed through SAM and are not affected.
+func SetPingSourceAddr(source string) error {
+	if source == "" {
+		pingClient = &http.Client{Timeout: 30 * time.Second}
+		return nil
+	}
+
+	ip, err := resolveSourceIP(source)
+	if err != nil {
+		return err
+	}
+	dialer := &net.Dialer{LocalAddr: &net.TCPAddr{IP: ip}, Timeout: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	pingClient = &http.Client{Timeout: 30 * time.Second, Transport: transport}
+	return nil
+}
+
+// resolveSourceIP returns source as an IP, or the first address of the network
+// interface named source.
+func resolveSourceIP(source string) (net.IP, error) {
+	if ip := net.ParseIP(source); ip != nil {
+		return ip, nil
+	}
+	iface, err := net.InterfaceByName(source)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither an IP address nor a network interface: %w", source, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok {
+			return ipnet.IP, nil
+		}
+	}
+	return nil, fmt.Errorf("network interface %q has no IP address", source)
+}
+
 // Ping tests the availability of a reseed server by requesting an SU3 file.
 // It appends "i2pseeds.su3" to the URL if not present and validates the server response.
 // Returns true if the server responds with HTTP 200, false and error details otherwise.
@@ -59,7 +105,8 @@ func trimPath(s string) string {
 // PingWriteContent performs a ping test and writes the result to a timestamped file.
 // Creates daily ping status files in the content directory for status tracking and
 // web interface display. Files are named with host and date to prevent conflicts.
-func PingWriteContent(urlInput string) error {
+// With ephemeral set the result is kept in memory under the same name instead.
+func PingWriteContent(urlInput string, ephemeral bool) error {
 	lgr.WithField("url", urlInput).Debug("Calling PWC")
 	// Generate date stamp for daily ping file organization
 	date := time.Now().Format("2006-01-02")
@@ -71,24 +118,34 @@ func PingWriteContent(urlInput string) error {
 	// Create clean filename from host and date for ping result storage
 	path := trimPath(u.Host)
 	lgr.WithField("path", path).Debug("Calling PWC path")
+	if ephemeral {
+		// Keep the result in memory rather than writing a .ping file
+		name := path + "-" + date
+		if !hasPingResult(name) {
+			storePingResult(name, pingStatus(urlInput))
+		}
+		return nil
+	}
 	BaseContentPath, _ := StableContentPath()
 	path = filepath.Join(BaseContentPath, path+"-"+date+".ping")
 	// Only ping if daily result file doesn't exist to prevent spam
 	if _, err := os.Stat(path); err != nil {
-		result, err := Ping(urlInput)
-		if result {
-			lgr.WithField("url", urlInput).Debug("Ping: OK")
-			err := os.WriteFile(path, []byte("Alive: Status OK"), 0o644)
-			return err
-		} else {
-			lgr.WithField("url", urlInput).WithError(err).Error("Ping: failed")
-			err := os.WriteFile(path, []byte("Dead: "+err.Error()), 0o644)
-			return err
-		}
+		return os.WriteFile(path, []byte(pingStatus(urlInput)), 0o644)
 	}
 	return nil
 }
 
+// pingStatus pings urlInput and describes the outcome as stored in ping results.
+func pingStatus(urlInput string) string {
+	result, err := Ping(urlInput)
+	if result {
+		lgr.WithField("url", urlInput).Debug("Ping: OK")
+		return "Alive: Status OK"
+	}
+	lgr.WithField("url", urlInput).WithError(err).Error("Ping: failed")
+	return "Dead: " + err.Error()
+}
+
 func yday() time.Time {
 	// Calculate yesterday's date for rate limiting ping operations
 	today := time.Now()
@@ -107,11 +164,20 @@ var pingMu sync.Mutex
 // Access must be protected by pingMu.
 var lastPing = yday()
 
+// resetPingLimit lets the next PingEverybody run straight away. Servers that
+// already have a result for today are not pinged again.
+func resetPingLimit() {
+	pingMu.Lock()
+	defer pingMu.Unlock()
+	lastPing = yday()
+}
+
 // PingEverybody tests all known reseed servers and returns their status results.
 // Implements rate limiting to prevent excessive pinging (once per 24 hours) and
 // returns a slice of status strings indicating success or failure for each server.
+// Results are kept in memory with ephemeral set, see PingWriteContent.
 // Thread-safe: uses pingMu to synchronize access to lastPing.
-func PingEverybody() []string {
+func PingEverybody(ephemeral bool) []string {
 	pingMu.Lock()
 	// Enforce rate limiting to prevent server abuse
 	if lastPing.After(yday()) {
@@ -124,8 +190,8 @@ func PingEverybody() []string {
 
 	var nonerrs []string
 	// Test each reseed server and collect results for display
-	for _, urlInput := range AllReseeds {
-		err := PingWriteContent(urlInput)
+	for _, urlInput := range ReseedPeers() {
+		err := PingWriteContent(urlInput, ephemeral)
 		if err == nil {
 			nonerrs = append(nonerrs, urlInput)
 		} else {
@@ -162,25 +228,76 @@ func GetPingFiles() ([]string, error) {
 // Displays the current status of all known reseed servers in a user-friendly format
 // for the web interface, including warnings about experimental nature of the feature.
 // All dynamic content is HTML-escaped to prevent injection from ping result data.
-func ReadOut(w http.ResponseWriter) {
+func ReadOut(w http.ResponseWriter, ephemeral bool) {
+	entries, _ := pingEntries(ephemeral)
+	renderPingResults(w, entries)
+}
+
+// renderPingResults writes the HTML status display for a set of ping results.
+func renderPingResults(w io.Writer, entries []PingResult) {
+	if len(entries) == 0 {
+		fmt.Fprintf(w, "<h4>No ping files found, check back later for reseed stats</h4>")
+		return
+	}
+	// Generate HTML status display with ping results
+	fmt.Fprintf(w, "<h3>Reseed Server Statuses</h3>")
+	fmt.Fprintf(w, "<div class=\"pingtest\">This feature is experimental and may not always provide accurate results.</div>")
+	fmt.Fprintf(w, "<div class=\"homepage\"><p><ul>")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "<li><strong>%s</strong> - %s", html.EscapeString(entry.Host), html.EscapeString(entry.Status))
+		for _, link := range entry.Links {
+			fmt.Fprintf(w, " <a class=\"peerlink\" href=\"%s\">[%s]</a>", html.EscapeString(link.URL), html.EscapeString(link.Transport))
+		}
+		fmt.Fprintf(w, "</li>\n")
+	}
+	fmt.Fprintf(w, "</ul></p></div>")
+}
+
+// PingResult is today's ping outcome for one reseed server, as listed on the homepage.
+type PingResult struct {
+	// Host names the reseed server and the day it was pinged
+	Host string `json:"host"`
+	// Status is the result of the ping
+	Status string `json:"status"`
+	// Links lists the addresses the reseed server can be reached at
+	Links []PeerLink `json:"links,omitempty"`
+}
+
+// pingEntries collects today's ping results, from memory with ephemeral set
+// and from the .ping files in the content directory otherwise, each with the
+// addresses its reseed server is known by.
+func pingEntries(ephemeral bool) ([]PingResult, error) {
+	entries, err := readPingEntries(ephemeral)
+	for i := range entries {
+		entries[i].Links = pingHostLinks(entries[i].Host)
+	}
+	return entries, err
+}
+
+// readPingEntries reads today's ping results for the current ReseedPeers,
+// without their links.
+func readPingEntries(ephemeral bool) ([]PingResult, error) {
+	if ephemeral {
+		entries := currentPingResults(todaysPingResults())
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("no ping results found")
+		}
+		return entries, nil
+	}
 	pinglist, err := GetPingFiles()
-	if err == nil {
-		// Generate HTML status display with ping results
-		fmt.Fprintf(w, "<h3>Reseed Server Statuses</h3>")
-		fmt.Fprintf(w, "<div class=\"pingtest\">This feature is experimental and may not always provide accurate results.</div>")
-		fmt.Fprintf(w, "<div class=\"homepage\"><p><ul>")
-		for _, file := range pinglist {
-			ping, err := os.ReadFile(file)
-			host := strings.Replace(file, ".ping", "", 1)
-			host = filepath.Base(host)
-			if err == nil {
-				fmt.Fprintf(w, "<li><strong>%s</strong> - %s</li>\n", html.EscapeString(host), html.EscapeString(string(ping)))
-			} else {
-				fmt.Fprintf(w, "<li><strong>%s</strong> - No ping file found</li>\n", html.EscapeString(host))
-			}
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]PingResult, 0, len(pinglist))
+	for _, file := range pinglist {
+		ping, err := os.ReadFile(file)
+		host := strings.Replace(file, ".ping", "", 1)
+		host = filepath.Base(host)
+		status := "No ping file found"
+		if err == nil {
+			status = string(ping)
 		}
-		fmt.Fprintf(w, "</ul></p></div>")
-	} else {
-		fmt.Fprintf(w, "<h4>No ping files found, check back later for reseed stats</h4>")
+		entries = append(entries, PingResult{Host: host, Status: status})
 	}
+	return currentPingResults(entries), nil
 }
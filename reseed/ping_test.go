@@ -183,7 +183,7 @@ func TestPingEverybody_RateLimiting(t *testing.T) {
 	pingMu.Unlock()
 
 	// Call should be rate-limited and return nil immediately
-	result := PingEverybody()
+	result := PingEverybody(false)
 	if result != nil {
 		t.Errorf("expected nil from rate-limited PingEverybody, got %d results", len(result))
 	}
@@ -206,7 +206,7 @@ func TestPingEverybody_ConcurrentSafety(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			PingEverybody()
+			PingEverybody(false)
 		}()
 	}
 	wg.Wait()
@@ -215,7 +215,7 @@ func TestPingEverybody_ConcurrentSafety(t *testing.T) {
 
 // TestPingWriteContent_InvalidURL tests PingWriteContent with a malformed URL.
 func TestPingWriteContent_InvalidURL(t *testing.T) {
-	err := PingWriteContent("://bad-url")
+	err := PingWriteContent("://bad-url", false)
 	if err == nil {
 		t.Error("expected error for invalid URL")
 	}
@@ -247,7 +247,7 @@ func TestPingWriteContent_WritesFile(t *testing.T) {
 	StableContentPath()
 
 	// Use URL with trailing slash so i2pseeds.su3 suffix is appended correctly
-	err = PingWriteContent(server.URL + "/")
+	err = PingWriteContent(server.URL+"/", false)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -298,13 +298,13 @@ func TestPingWriteContent_SkipsExistingFile(t *testing.T) {
 	StableContentPath()
 
 	// First call creates the file
-	err = PingWriteContent(server.URL + "/")
+	err = PingWriteContent(server.URL+"/", false)
 	if err != nil {
 		t.Fatalf("first call error: %v", err)
 	}
 
 	// Second call should skip (file exists)
-	err = PingWriteContent(server.URL + "/")
+	err = PingWriteContent(server.URL+"/", false)
 	if err != nil {
 		t.Fatalf("second call should succeed silently: %v", err)
 	}
@@ -332,7 +332,7 @@ func TestPingWriteContent_FailedPing(t *testing.T) {
 	StableContentPath()
 
 	// Use trailing slash for valid URL formation
-	err = PingWriteContent(server.URL + "/")
+	err = PingWriteContent(server.URL+"/", false)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -463,6 +463,10 @@ func TestReadOut_WithPingFiles(t *testing.T) {
 	}
 
 	date := time.Now().Format("2006-01-02")
+	restoreReseedPeers(t)
+	if err := SetReseedPeers([]string{"https://test-server/"}); err != nil {
+		t.Fatal(err)
+	}
 
 	// Create a ping file with content that needs HTML escaping
 	pingFile := filepath.Join(BaseContentPath, "test-server-"+date+".ping")
@@ -471,7 +475,7 @@ func TestReadOut_WithPingFiles(t *testing.T) {
 	}
 
 	w := httptest.NewRecorder()
-	ReadOut(w)
+	ReadOut(w, false)
 
 	body := w.Body.String()
 	if !strings.Contains(body, "Reseed Server Statuses") {
@@ -500,7 +504,7 @@ func TestReadOut_NoPingFiles(t *testing.T) {
 	defer os.Chdir(origDir)
 
 	w := httptest.NewRecorder()
-	ReadOut(w)
+	ReadOut(w, false)
 
 	body := w.Body.String()
 	if !strings.Contains(body, "No ping files found") {
@@ -533,13 +537,17 @@ func TestReadOut_HTMLEscapesHostnames(t *testing.T) {
 	// Create a ping file with a "malicious" hostname component
 	// Use & which needs escaping but is safe in filenames
 	hostPart := "bad&host"
+	restoreReseedPeers(t)
+	if err := SetReseedPeers([]string{"https://" + hostPart + "/"}); err != nil {
+		t.Fatal(err)
+	}
 	pingFile := filepath.Join(BaseContentPath, fmt.Sprintf("%s-%s.ping", hostPart, date))
 	if err := os.WriteFile(pingFile, []byte("Alive: OK"), 0o644); err != nil {
 		t.Fatal(err)
 	}
 
 	w := httptest.NewRecorder()
-	ReadOut(w)
+	ReadOut(w, false)
 
 	body := w.Body.String()
 	// The raw & should be escaped to &amp; in the HTML output
@@ -570,3 +578,33 @@ func TestPingMutex_ProtectsLastPing(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// TestSetPingSourceAddr verifies that pings originate from the configured local
+// address and that invalid sources are rejected.
+func TestSetPingSourceAddr(t *testing.T) {
+	defer SetPingSourceAddr("")
+
+	var remote string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote = r.RemoteAddr
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if err := SetPingSourceAddr("127.0.0.1"); err != nil {
+		t.Fatalf("SetPingSourceAddr(127.0.0.1) failed: %v", err)
+	}
+	if pingClient.Timeout != 30*time.Second {
+		t.Errorf("expected 30s timeout to be kept, got %v", pingClient.Timeout)
+	}
+	if ok, err := Ping(server.URL + "/"); !ok {
+		t.Fatalf("Ping via bound source failed: %v", err)
+	}
+	if !strings.HasPrefix(remote, "127.0.0.1:") {
+		t.Errorf("expected request from 127.0.0.1, got %s", remote)
+	}
+
+	if err := SetPingSourceAddr("no-such-interface0"); err == nil {
+		t.Error("expected error for unknown interface")
+	}
+}
@@ -0,0 +1,106 @@
+package reseed
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges, such as 127.0.0.1
+// or 173.245.48.0/20, into the networks of Server.TrustedProxies. Unlike the
+// blacklist, a malformed entry is an error: silently trusting too few proxies
+// would attribute every request to the proxy itself.
+func ParseTrustedProxies(entries []string) ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %q is not an IP address or CIDR range", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q is not an IP address or CIDR range", entry)
+		}
+		proxies = append(proxies, ipnet)
+	}
+	return proxies, nil
+}
+
+// trustedProxy reports whether ip is one of srv.TrustedProxies.
+func (srv *Server) trustedProxy(ip net.IP) bool {
+	for _, ipnet := range srv.TrustedProxies {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedClient picks the client address out of the X-Forwarded-For hops,
+// oldest first as proxies append them. Only the right end of the chain can be
+// believed, since the client writes whatever it likes to the left, so the
+// hops are walked from the right past every trusted proxy and the first one
+// that is not a proxy is the client. A malformed hop on that walk means the
+// chain cannot be followed, and nil is returned.
+func (srv *Server) forwardedClient(hops []string) net.IP {
+	var client net.IP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return nil
+		}
+		client = ip
+		if !srv.trustedProxy(ip) {
+			break
+		}
+	}
+	return client
+}
+
+// proxiedMiddleware replaces r.RemoteAddr with the client address reported by
+// the proxy in front of the server, so blacklisting, rate limits and logs see
+// the client rather than the proxy. With TrustedProxies set, the headers are
+// only believed from a connection whose address is listed, and the
+// X-Forwarded-For chain is followed past every listed proxy; without it only
+// the proxy that connected is trusted, and the rightmost hop is the client.
+// X-Real-IP is used when no X-Forwarded-For is sent. RemoteAddr is left
+// unchanged when neither header gives a valid address.
+func (srv *Server) proxiedMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		peer, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			peer = r.RemoteAddr
+		}
+		if peerIP := net.ParseIP(peer); len(srv.TrustedProxies) > 0 && (peerIP == nil || !srv.trustedProxy(peerIP)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var client net.IP
+		if prior := r.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+			// Repeated headers form one list, in the order they were added
+			client = srv.forwardedClient(strings.Split(strings.Join(prior, ","), ","))
+		} else if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			client = net.ParseIP(strings.TrimSpace(realIP))
+		}
+		if client != nil {
+			r.RemoteAddr = client.String()
+		}
+
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,109 @@
+package reseed
+
+import "time"
+
+// RebuildStats describes the most recent SU3 cache rebuild. The swap itself is
+// a single atomic store, so bundles keep being served from the previous set while
+// a rebuild runs; ServedDuringRebuild counts those requests and SwapSeconds shows
+// how long replacing the set took.
+type RebuildStats struct {
+	// Time is when the new bundle set started being served
+	Time time.Time `json:"time"`
+	// RebuildSeconds is how long building and signing the new set took
+	RebuildSeconds float64 `json:"rebuild_seconds"`
+	// SwapSeconds is how long replacing the served set with the new one took
+	SwapSeconds float64 `json:"swap_seconds"`
+	// ServedDuringRebuild counts bundles served from the previous set while rebuilding
+	ServedDuringRebuild uint64 `json:"served_during_rebuild"`
+	// Bundles is the number of SU3 files in the new set
+	Bundles int `json:"bundles"`
+	// Scanned is the number of routerInfo files found in the netDb
+	Scanned int `json:"scanned"`
+	// Skipped breaks down the scanned files that were not valid by reason
+	Skipped RouterInfoSkips `json:"skipped"`
+	// Valid is the number of routerInfos left after age and quality filtering
+	Valid int `json:"valid"`
+	// Discarded is the number of valid routerInfos dropped for being outside
+	// the freshest MinRouterInfoFraction
+	Discarded int `json:"discarded"`
+	// UniqueRouters is the number of distinct routerInfos across all bundles
+	UniqueRouters int `json:"unique_routers"`
+	// BundleBytes is the combined size of every SU3 file in the new set
+	BundleBytes int `json:"bundle_bytes"`
+}
+
+// RouterInfoSkips counts the routerInfo files a netDb scan left out, by the
+// filter that rejected them.
+type RouterInfoSkips struct {
+	// Unreadable files could not be read or parsed as a RouterInfo
+	Unreadable int `json:"unreadable"`
+	// TooOld files were last modified longer than the routerInfo age ago
+	TooOld int `json:"too_old"`
+	// Version counts routers outside the supported versions or below
+	// MinRouterVersion
+	Version int `json:"version"`
+	// Capabilities counts routers that are unreachable or congested
+	Capabilities int `json:"capabilities"`
+}
+
+// skipReason is the filter loadRouterInfo rejected a file with, or notSkipped.
+type skipReason int
+
+const (
+	notSkipped skipReason = iota
+	skipUnreadable
+	skipTooOld
+	skipVersion
+	skipCapabilities
+)
+
+// count adds one file skipped for reason.
+func (skips *RouterInfoSkips) count(reason skipReason) {
+	switch reason {
+	case skipUnreadable:
+		skips.Unreadable++
+	case skipTooOld:
+		skips.TooOld++
+	case skipVersion:
+		skips.Version++
+	case skipCapabilities:
+		skips.Capabilities++
+	}
+}
+
+// summarizeBundles fills in UniqueRouters and BundleBytes from the marshaled
+// bundles and the routerInfo file names each one contains.
+func (stats *RebuildStats) summarizeBundles(su3s [][]byte, selection [][]string) {
+	unique := make(map[string]struct{})
+	for _, names := range selection {
+		for _, name := range names {
+			unique[name] = struct{}{}
+		}
+	}
+	stats.UniqueRouters = len(unique)
+	stats.BundleBytes = 0
+	for _, data := range su3s {
+		stats.BundleBytes += len(data)
+	}
+}
+
+// countServed records that a bundle was handed out, attributing it to the
+// rebuild in progress if there is one.
+func (rs *ReseederImpl) countServed() {
+	metrics.su3Served.Add(1)
+	if rs.rebuilding.Load() {
+		rs.servedDuringRebuild.Add(1)
+	}
+}
+
+// LastRebuild returns statistics for the most recent completed rebuild, or nil
+// if the cache has not been built yet.
+func (rs *ReseederImpl) LastRebuild() *RebuildStats {
+	stats, _ := rs.lastRebuild.Load().(*RebuildStats)
+	return stats
+}
+
+// LastRebuildStats is an alias for LastRebuild.
+func (rs *ReseederImpl) LastRebuildStats() *RebuildStats {
+	return rs.LastRebuild()
+}
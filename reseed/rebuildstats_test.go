@@ -0,0 +1,244 @@
+package reseed
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/logger"
+)
+
+func TestCountServed_OnlyDuringRebuild(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.su3s.Store([][]byte{[]byte("bundle")})
+
+	if _, err := reseeder.PeerSu3Bytes(Peer("peer")); err != nil {
+		t.Fatal(err)
+	}
+	if got := reseeder.servedDuringRebuild.Load(); got != 0 {
+		t.Errorf("Expected no requests counted outside a rebuild, got %d", got)
+	}
+
+	reseeder.rebuilding.Store(true)
+	reseeder.PeerSu3Bytes(Peer("peer"))
+	reseeder.Su3Bytes(0)
+	reseeder.Su3Bytes(5) // not served, so not counted
+	if got := reseeder.servedDuringRebuild.Load(); got != 2 {
+		t.Errorf("Expected 2 requests counted during the rebuild, got %d", got)
+	}
+}
+
+func TestReadyz_ReportsLastRebuild(t *testing.T) {
+	srv := newReadyServer(t)
+	srv.Health.serving(TransportHTTPS, srv)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle")})
+
+	if _, status := getReadyz(t, srv); status.LastRebuild != nil {
+		t.Errorf("Expected no rebuild stats before the first rebuild, got %+v", status.LastRebuild)
+	}
+
+	srv.Reseeder.lastRebuild.Store(&RebuildStats{Time: time.Now(), RebuildSeconds: 2.5, SwapSeconds: 0.000001, ServedDuringRebuild: 7, Bundles: 1})
+	_, status := getReadyz(t, srv)
+	if status.LastRebuild == nil || status.LastRebuild.ServedDuringRebuild != 7 || status.LastRebuild.RebuildSeconds != 2.5 {
+		t.Errorf("Expected the last rebuild stats in /readyz, got %+v", status.LastRebuild)
+	}
+}
+
+func TestRebuildStats_SummarizeBundles(t *testing.T) {
+	stats := &RebuildStats{}
+	stats.summarizeBundles(
+		[][]byte{make([]byte, 100), make([]byte, 250)},
+		[][]string{{"routerInfo-a.dat", "routerInfo-b.dat"}, {"routerInfo-b.dat", "routerInfo-c.dat"}},
+	)
+	if stats.UniqueRouters != 3 {
+		t.Errorf("Expected 3 unique routers, got %d", stats.UniqueRouters)
+	}
+	if stats.BundleBytes != 350 {
+		t.Errorf("Expected 350 bundle bytes, got %d", stats.BundleBytes)
+	}
+}
+
+func TestScanRouterInfos_CountsScannedFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"routerInfo-AAAA.dat", "routerInfo-BBBB.dat", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("not a routerInfo"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ris, scan, err := NewLocalNetDb(dir, 72*time.Hour).scanRouterInfos(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scan.scanned != 2 {
+		t.Errorf("Expected 2 routerInfo files scanned, got %d", scan.scanned)
+	}
+	if scan.skipped != (RouterInfoSkips{Unreadable: 2}) {
+		t.Errorf("Expected both files skipped as unreadable, got %+v", scan.skipped)
+	}
+	if len(ris) != 0 {
+		t.Errorf("Expected unparseable files to be filtered out, got %d", len(ris))
+	}
+}
+
+func TestRebuild_CountsSkippedRouterInfos(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		writeTestRouterInfo(t, dir, name, "0.9.64", "XfR")
+	}
+	writeTestRouterInfo(t, dir, "old", "0.9.20", "XfR")
+	writeTestRouterInfo(t, dir, "unreachable", "0.9.64", "XfU")
+	writeTestRouterInfo(t, dir, "stale", "0.9.64", "XfR")
+	stale := time.Now().Add(-100 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "routerInfo-stale.dat"), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "routerInfo-garbage.dat"), []byte("not a routerInfo"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reseeder := NewReseeder(NewLocalNetDb(dir, 72*time.Hour))
+	reseeder.SigningKey = key
+	reseeder.SignerID = []byte("test@example.i2p")
+	reseeder.NumRi = 3
+	reseeder.NumSu3 = 1
+	reseeder.MinRouterInfoFraction = 1
+	if err := reseeder.rebuild(); err != nil {
+		t.Fatalf("rebuild() error: %v", err)
+	}
+	stats := reseeder.LastRebuild()
+	if reseeder.LastRebuildStats() != stats {
+		t.Error("Expected LastRebuildStats to return the same stats as LastRebuild")
+	}
+	if stats.Scanned != 7 || stats.Valid != 3 {
+		t.Errorf("Expected 7 files scanned and 3 valid, got %d and %d", stats.Scanned, stats.Valid)
+	}
+	if want := (RouterInfoSkips{Unreadable: 1, TooOld: 1, Version: 1, Capabilities: 1}); stats.Skipped != want {
+		t.Errorf("Skipped = %+v, want %+v", stats.Skipped, want)
+	}
+}
+
+func TestScanRouterInfos_MaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"routerInfo-AAAA.dat", "routerInfo-BBBB.dat", "routerInfo-CCCC.dat"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("not a routerInfo"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	netdb := NewLocalNetDb(dir, 72*time.Hour)
+	netdb.MaxFiles = 2
+	_, scan, err := netdb.scanRouterInfos(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scan.scanned != 2 {
+		t.Errorf("Expected the scan to stop at 2 files, got %d", scan.scanned)
+	}
+}
+
+func TestRouterInfosContext_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "routerInfo-AAAA.dat"), []byte("not a routerInfo"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewLocalNetDb(dir, 72*time.Hour).RouterInfosContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled scan to fail with context.Canceled, got %v", err)
+	}
+	if _, err := NewLocalNetDb(dir, 72*time.Hour).RouterInfos(); err != nil {
+		t.Errorf("RouterInfos() error: %v", err)
+	}
+}
+
+// BenchmarkLoadRouterInfos compares reading a netDb on one worker with reading
+// it on GOMAXPROCS workers, as scanRouterInfos does. The netDb holds copies of
+// a few real routerInfos, so every file is parsed and verified in full.
+func BenchmarkLoadRouterInfos(b *testing.B) {
+	// Every file would otherwise log its routerInfo when DEBUG_I2P is set
+	level := lgr.GetLevel()
+	lgr.SetLevel(logger.PanicLevel)
+	b.Cleanup(func() { lgr.SetLevel(level) })
+
+	src := b.TempDir()
+	var routerInfos [][]byte
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("%02d", i)
+		writeTestRouterInfo(b, src, name, "0.9.64", "XfR")
+		data, err := os.ReadFile(filepath.Join(src, "routerInfo-"+name+".dat"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		routerInfos = append(routerInfos, data)
+	}
+
+	dir := b.TempDir()
+	files := make(map[string]os.FileInfo)
+	for i := 0; i < 2000; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("routerInfo-%05d.dat", i))
+		if err := os.WriteFile(path, routerInfos[i%len(routerInfos)], 0o644); err != nil {
+			b.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		files[path] = info
+	}
+	netdb := NewLocalNetDb(dir, 72*time.Hour)
+
+	for _, bench := range []struct {
+		name    string
+		workers int
+	}{{"sequential", 1}, {"parallel", runtime.GOMAXPROCS(0)}} {
+		workers := bench.workers
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ris, _, err := netdb.loadRouterInfos(context.Background(), files, workers)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(ris) != len(files) {
+					b.Fatalf("Expected all %d routerInfos to load, got %d", len(files), len(ris))
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRouterInfos_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "routerInfo-AAAA.dat")
+	if err := os.WriteFile(path, []byte("not a routerInfo"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]os.FileInfo{path: info}
+	netdb := NewLocalNetDb(dir, 72*time.Hour)
+
+	if ris, _, err := netdb.loadRouterInfos(context.Background(), files, 8); err != nil || len(ris) != 0 {
+		t.Errorf("Expected no usable routerInfos and no error, got %d, %v", len(ris), err)
+	}
+	if _, _, err := netdb.loadRouterInfos(context.Background(), nil, 8); err != nil {
+		t.Errorf("Expected an empty netDb to load, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := netdb.loadRouterInfos(ctx, files, 8); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
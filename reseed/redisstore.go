@@ -0,0 +1,87 @@
+package reseed
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	throttled "github.com/throttled/throttled/v2"
+	"github.com/throttled/throttled/v2/store/redigostore"
+)
+
+// RateStores are the stores backing a server's three rate limiters, as
+// passed in ServerConfig.
+type RateStores struct {
+	Request throttled.Store
+	Web     throttled.Store
+	Global  throttled.Store
+}
+
+// redisTimeout bounds each Redis round trip, so a slow or unreachable Redis
+// fails requests quickly instead of holding them open.
+const redisTimeout = 2 * time.Second
+
+// RedisRateStores returns rate limit stores that keep their counters in the
+// Redis server at rawURL, written redis://[:password@]host[:port][/db] or
+// rediss:// for TLS. Every reseed instance pointed at the same server shares
+// one set of counters, so the per-IP limits hold across a cluster rather than
+// per instance. It returns an error if the URL is invalid or the server does
+// not answer a PING.
+func RedisRateStores(rawURL string) (RateStores, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return RateStores{}, err
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return RateStores{}, fmt.Errorf("redis URL %s must start with redis:// or rediss://", u.Redacted())
+	}
+	// redigostore selects its database on every connection, overriding the one
+	// DialURL picked, so it has to be told the database from the URL as well
+	db := 0
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		if db, err = strconv.Atoi(path); err != nil {
+			return RateStores{}, fmt.Errorf("redis URL %s: database %q is not a number", u.Redacted(), path)
+		}
+	}
+
+	pool := &redis.Pool{
+		MaxIdle:     16,
+		IdleTimeout: 5 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(rawURL,
+				redis.DialConnectTimeout(redisTimeout),
+				redis.DialReadTimeout(redisTimeout),
+				redis.DialWriteTimeout(redisTimeout))
+		},
+	}
+	conn := pool.Get()
+	_, err = conn.Do("PING")
+	conn.Close()
+	if err != nil {
+		pool.Close()
+		return RateStores{}, fmt.Errorf("redis %s: %w", u.Redacted(), err)
+	}
+
+	// Each limiter keys its counters differently, by client address or by
+	// method, so each gets a key prefix of its own
+	var stores RateStores
+	for _, s := range []struct {
+		store  *throttled.Store
+		prefix string
+	}{
+		{&stores.Request, "reseed:ratelimit:su3:"},
+		{&stores.Web, "reseed:ratelimit:web:"},
+		{&stores.Global, "reseed:ratelimit:global:"},
+	} {
+		store, err := redigostore.New(pool, s.prefix, db)
+		if err != nil {
+			pool.Close()
+			return RateStores{}, err
+		}
+		*s.store = store
+	}
+	return stores, nil
+}
@@ -0,0 +1,80 @@
+package reseed
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeRedis answers every command with +PONG, enough for RedisRateStores'
+// startup check.
+func fakeRedis(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					// A command is an array header followed by a length and a value per argument
+					header, err := r.ReadString('\n')
+					if err != nil || !strings.HasPrefix(header, "*") {
+						return
+					}
+					for i := 0; i < 2*int(header[1]-'0'); i++ {
+						if _, err := r.ReadString('\n'); err != nil {
+							return
+						}
+					}
+					conn.Write([]byte("+PONG\r\n"))
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestRedisRateStores(t *testing.T) {
+	stores, err := RedisRateStores("redis://" + fakeRedis(t) + "/2")
+	if err != nil {
+		t.Fatalf("RedisRateStores() error: %v", err)
+	}
+	if stores.Request == nil || stores.Web == nil || stores.Global == nil {
+		t.Fatalf("Expected three stores, got %+v", stores)
+	}
+	if _, err := NewServerWithConfig(ServerConfig{RequestRateLimit: 4, WebRateLimit: 40, GlobalRateLimit: 2000,
+		RequestRateStore: stores.Request, WebRateStore: stores.Web, GlobalRateStore: stores.Global}); err != nil {
+		t.Errorf("NewServerWithConfig() with Redis stores: %v", err)
+	}
+}
+
+func TestRedisRateStores_Invalid(t *testing.T) {
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	for _, rawURL := range []string{
+		"http://:secret@127.0.0.1:6379",
+		"redis://:secret@127.0.0.1:6379/cache",
+		"redis://:secret@" + closed.Addr().String(),
+	} {
+		_, err := RedisRateStores(rawURL)
+		if err == nil {
+			t.Errorf("RedisRateStores(%q) succeeded, want an error", rawURL)
+		} else if strings.Contains(err.Error(), "secret") {
+			t.Errorf("Error %q reveals the Redis password", err)
+		}
+	}
+}
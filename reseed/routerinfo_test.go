@@ -0,0 +1,147 @@
+package reseed
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/common/certificate"
+	"github.com/go-i2p/common/data"
+	"github.com/go-i2p/common/key_certificate"
+	"github.com/go-i2p/common/keys_and_cert"
+	"github.com/go-i2p/common/router_address"
+	"github.com/go-i2p/common/router_identity"
+	"github.com/go-i2p/common/router_info"
+	"github.com/go-i2p/common/signature"
+	"github.com/go-i2p/crypto/ed25519"
+	elgamal "github.com/go-i2p/crypto/elg"
+	"github.com/go-i2p/crypto/rand"
+	"github.com/go-i2p/crypto/types"
+)
+
+// writeTestRouterInfo writes a signed RouterInfo publishing version and caps
+// to dir/routerInfo-name.dat.
+func writeTestRouterInfo(t testing.TB, dir, name, version, caps string) {
+	t.Helper()
+	signingKey, err := ed25519.GenerateEd25519Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey := signingKey.(ed25519.Ed25519PrivateKey)
+	pubKey, err := privKey.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var elgKey elgamal.PrivateKey
+	if err := elgamal.ElgamalGenerate(&elgKey.PrivateKey, rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	var elgPub elgamal.ElgPublicKey
+	y := elgKey.PublicKey.Y.Bytes()
+	copy(elgPub[256-len(y):], y)
+
+	// Key certificate: Ed25519 signing key, ElGamal encryption key
+	var payload bytes.Buffer
+	sigType, _ := data.NewIntegerFromInt(signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519, 2)
+	cryptoType, _ := data.NewIntegerFromInt(0, 2)
+	payload.Write(*sigType)
+	payload.Write(*cryptoType)
+	cert, err := certificate.NewCertificateWithType(certificate.CERT_KEY, payload.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyCert, err := key_certificate.KeyCertificateFromCertificate(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padding := make([]byte, keys_and_cert.KEYS_AND_CERT_DATA_SIZE-keyCert.CryptoSize()-keyCert.SigningPublicKeySize())
+	rand.Read(padding)
+	identity, err := router_identity.NewRouterIdentity(elgPub, pubKey.(types.SigningPublicKey), cert, padding)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := router_address.NewRouterAddress(3, time.Time{}, "NTCP2", map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ri, err := router_info.NewRouterInfo(identity, time.Now(), []*router_address.RouterAddress{addr},
+		map[string]string{"router.version": version, "caps": caps}, &privKey, signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	riBytes, err := ri.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "routerInfo-"+name+".dat"), riBytes, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouterInfos_VersionFilter(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRouterInfo(t, dir, "current", "0.9.64", "XfR")
+	writeTestRouterInfo(t, dir, "old", "0.9.20", "XfR")
+	writeTestRouterInfo(t, dir, "garbled", "not-a-version", "XfR")
+	writeTestRouterInfo(t, dir, "unreachable", "0.9.64", "XfU")
+
+	ris, err := NewLocalNetDb(dir, 72*time.Hour).RouterInfos()
+	if err != nil {
+		t.Fatalf("RouterInfos() error: %v", err)
+	}
+	if len(ris) != 1 || ris[0].Name != "routerInfo-current.dat" {
+		var names []string
+		for _, ri := range ris {
+			names = append(names, ri.Name)
+		}
+		t.Fatalf("Expected only the current, reachable router to be kept, got %v", names)
+	}
+}
+
+// TestRebuild_BarelyLargeEnoughNetDb checks the freshest-fraction slice against
+// a netDb with only just enough routers: the default 75% keeps 3 of 4, and
+// MinRouterInfoFraction 1 lets all 4 fill a bundle.
+func TestRebuild_BarelyLargeEnoughNetDb(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c", "d"} {
+		writeTestRouterInfo(t, dir, name, "0.9.64", "XfR")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuild := func(numRi int, fraction float64) error {
+		reseeder := NewReseeder(NewLocalNetDb(dir, 72*time.Hour))
+		reseeder.SigningKey = key
+		reseeder.SignerID = []byte("test@example.i2p")
+		reseeder.NumRi = numRi
+		reseeder.NumSu3 = 1
+		if fraction != 0 {
+			reseeder.MinRouterInfoFraction = fraction
+		}
+		return reseeder.rebuild()
+	}
+
+	if err := rebuild(3, 0); err != nil {
+		t.Errorf("Expected 3 of 4 routers to be enough for NumRi 3, got %v", err)
+	}
+	if err := rebuild(4, 0); err == nil || !strings.Contains(err.Error(), "have: 3, need: 4") {
+		t.Errorf("Expected the default slice to leave too few routers for NumRi 4, got %v", err)
+	}
+	if err := rebuild(4, 1); err != nil {
+		t.Errorf("Expected MinRouterInfoFraction 1 to keep all 4 routers, got %v", err)
+	}
+
+	check, err := NewLocalNetDb(dir, 72*time.Hour).Check(4, 0.5)
+	if err != nil || check.Valid != 4 || check.Usable != 2 || check.OK() {
+		t.Errorf("Expected half of 4 routers to be usable and not enough, got %+v, %v", check, err)
+	}
+}
@@ -0,0 +1,55 @@
+package reseed
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseRouterVersion splits a dotted router version such as "0.9.62" into its
+// numeric components.
+func parseRouterVersion(version string) ([]int, error) {
+	fields := strings.Split(strings.TrimSpace(version), ".")
+	parts := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid router version %q", version)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+// ValidateRouterVersion checks that version can be used as a minimum router version.
+func ValidateRouterVersion(version string) error {
+	_, err := parseRouterVersion(version)
+	return err
+}
+
+// routerVersionAtLeast reports whether version is the same as or newer than
+// minimum. Missing components count as zero, so "0.9" equals "0.9.0". A version
+// that cannot be parsed never satisfies the minimum.
+func routerVersionAtLeast(version, minimum string) bool {
+	have, err := parseRouterVersion(version)
+	if err != nil {
+		return false
+	}
+	want, err := parseRouterVersion(minimum)
+	if err != nil {
+		return false
+	}
+	for i := 0; i < max(len(have), len(want)); i++ {
+		var h, w int
+		if i < len(have) {
+			h = have[i]
+		}
+		if i < len(want) {
+			w = want[i]
+		}
+		if h != w {
+			return h > w
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package reseed
+
+import "testing"
+
+func TestRouterVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version, minimum string
+		want             bool
+	}{
+		{"0.9.62", "0.9.62", true},
+		{"0.9.63", "0.9.62", true},
+		{"0.9.61", "0.9.62", false},
+		{"0.9.100", "0.9.62", true},
+		{"1.0.0", "0.9.62", true},
+		{"0.9", "0.9.0", true},
+		{"0.9", "0.9.1", false},
+		{"0.9.62.1", "0.9.62", true},
+		{"", "0.9.62", false},
+		{"0.9.x", "0.9.62", false},
+	}
+	for _, tt := range tests {
+		if got := routerVersionAtLeast(tt.version, tt.minimum); got != tt.want {
+			t.Errorf("routerVersionAtLeast(%q, %q) = %v, want %v", tt.version, tt.minimum, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRouterVersion(t *testing.T) {
+	if err := ValidateRouterVersion("0.9.62"); err != nil {
+		t.Errorf("Expected 0.9.62 to be valid, got %v", err)
+	}
+	for _, version := range []string{"", "0.9.", "v0.9.62", "0.-9.62"} {
+		if err := ValidateRouterVersion(version); err == nil {
+			t.Errorf("Expected %q to be rejected", version)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package reseed
+
+import (
+	"fmt"
+	"math"
+	rand2 "math/rand"
+	"sort"
+	"strings"
+)
+
+// BundleSelector decides which RouterInfos go into each reseed bundle. Operators
+// with different composition policies (freshest, most diverse, floodfill-heavy)
+// can set ReseederImpl.Selector to their own implementation.
+type BundleSelector interface {
+	// SelectBundles returns numBundles selections of up to perBundle RouterInfos
+	// drawn from pool. Selections may be shorter than perBundle when the pool
+	// does not hold enough distinct routers; empty selections are skipped.
+	SelectBundles(pool []RouterInfo, numBundles, perBundle int) [][]RouterInfo
+}
+
+// RandomSelector is the default BundleSelector. Each bundle is a uniform random
+// sample of the pool that never includes the same router identity twice.
+type RandomSelector struct {
+	// Rand is the source of randomness; a securely seeded source is used when nil
+	Rand *rand2.Rand
+}
+
+// SelectBundles implements BundleSelector.
+func (s RandomSelector) SelectBundles(pool []RouterInfo, numBundles, perBundle int) [][]RouterInfo {
+	rng := s.Rand
+	if rng == nil {
+		rng = newSecureRand()
+	}
+	lenRis := len(pool)
+	bundles := make([][]RouterInfo, 0, numBundles)
+
+	// Pre-allocate index array; reused across iterations to reduce allocation.
+	// Partial Fisher-Yates shuffle selects only perBundle elements per iteration,
+	// reducing random number calls from O(n) to O(perBundle) per SU3 file.
+	indices := make([]int, lenRis)
+	for i := 0; i < numBundles; i++ {
+		// Reset index array for uniform selection
+		for k := range indices {
+			indices[k] = k
+		}
+		// Partial Fisher-Yates: shuffle only as many positions as it takes to
+		// collect perBundle distinct routers, skipping any router already in this bundle
+		seeds := make([]RouterInfo, 0, perBundle)
+		seen := make(map[string]struct{}, perBundle)
+		for z := 0; z < lenRis && len(seeds) < perBundle; z++ {
+			// Use thread-local RNG to avoid global mutex contention
+			j := z + rng.Intn(lenRis-z)
+			indices[z], indices[j] = indices[j], indices[z]
+			seed := pool[indices[z]]
+			if _, dup := seen[seed.identityKey()]; dup {
+				continue
+			}
+			seen[seed.identityKey()] = struct{}{}
+			seeds = append(seeds, seed)
+		}
+		bundles = append(bundles, seeds)
+	}
+	return bundles
+}
+
+// TransportSelector weights bundle composition towards routers that advertise a
+// given transport, for clients whose network conditions favour it (for example
+// SSU2 behind NATs that break NTCP2). Share is the fraction of each bundle drawn
+// from routers with the transport; 1 includes only such routers unless there are
+// too few of them, in which case the rest of the bundle is filled from the others.
+// Likewise, when there are too few other routers, the bundle is topped up with
+// further routers that have the transport.
+type TransportSelector struct {
+	// Transport is the lowercase transport style to prefer, such as "ntcp2" or "ssu2"
+	Transport string
+	// Share is the fraction of each bundle, between 0 and 1, reserved for Transport
+	Share float64
+	// Rand is the source of randomness; a securely seeded source is used when nil
+	Rand *rand2.Rand
+}
+
+// SelectBundles implements BundleSelector.
+func (s TransportSelector) SelectBundles(pool []RouterInfo, numBundles, perBundle int) [][]RouterInfo {
+	rng := s.Rand
+	if rng == nil {
+		rng = newSecureRand()
+	}
+	random := RandomSelector{Rand: rng}
+
+	var preferred, others []RouterInfo
+	for _, ri := range pool {
+		if ri.supportsTransport(s.Transport) {
+			preferred = append(preferred, ri)
+		} else {
+			others = append(others, ri)
+		}
+	}
+	want := int(math.Ceil(s.Share * float64(perBundle)))
+
+	bundles := make([][]RouterInfo, 0, numBundles)
+	for i := 0; i < numBundles; i++ {
+		seeds := random.SelectBundles(preferred, 1, want)[0]
+		seen := make(map[string]struct{}, perBundle)
+		for _, seed := range seeds {
+			seen[seed.identityKey()] = struct{}{}
+		}
+		fill := func(from []RouterInfo) {
+			for _, seed := range from {
+				if len(seeds) >= perBundle {
+					return
+				}
+				if _, dup := seen[seed.identityKey()]; dup {
+					continue
+				}
+				seen[seed.identityKey()] = struct{}{}
+				seeds = append(seeds, seed)
+			}
+		}
+		// Draw enough extra to cover identities already taken from preferred
+		fill(random.SelectBundles(others, 1, perBundle)[0])
+		if len(seeds) < perBundle {
+			// Too few other routers: top up with preferred ones not yet taken
+			fill(random.SelectBundles(preferred, 1, len(preferred))[0])
+		}
+		rng.Shuffle(len(seeds), func(a, b int) { seeds[a], seeds[b] = seeds[b], seeds[a] })
+		bundles = append(bundles, seeds)
+	}
+	return bundles
+}
+
+// transportDistribution counts how many routers advertise each transport style.
+func transportDistribution(ris []RouterInfo) map[string]int {
+	counts := make(map[string]int)
+	for _, ri := range ris {
+		for _, t := range ri.Transports {
+			counts[t]++
+		}
+	}
+	return counts
+}
+
+// formatDistribution renders transport counts as "ntcp2=10 ssu2=8" in a stable order.
+func formatDistribution(counts map[string]int) string {
+	transports := make([]string, 0, len(counts))
+	for t := range counts {
+		transports = append(transports, t)
+	}
+	sort.Strings(transports)
+	parts := make([]string, len(transports))
+	for i, t := range transports {
+		parts[i] = fmt.Sprintf("%s=%d", t, counts[t])
+	}
+	return strings.Join(parts, " ")
+}
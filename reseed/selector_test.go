@@ -0,0 +1,133 @@
+package reseed
+
+import (
+	"fmt"
+	mrand "math/rand"
+	"testing"
+	"time"
+)
+
+func TestRandomSelector_SelectBundles(t *testing.T) {
+	pool := make([]RouterInfo, 40)
+	for i := range pool {
+		pool[i] = RouterInfo{Name: fmt.Sprintf("routerInfo-%04d.dat", i), ModTime: time.Now()}
+	}
+
+	bundles := RandomSelector{Rand: mrand.New(mrand.NewSource(1))}.SelectBundles(pool, 7, 10)
+	if len(bundles) != 7 {
+		t.Fatalf("Expected 7 bundles, got %d", len(bundles))
+	}
+	for i, bundle := range bundles {
+		if len(bundle) != 10 {
+			t.Errorf("Bundle %d: expected 10 RouterInfos, got %d", i, len(bundle))
+		}
+		seen := make(map[string]bool)
+		for _, ri := range bundle {
+			if seen[ri.Name] {
+				t.Errorf("Bundle %d contains %s twice", i, ri.Name)
+			}
+			seen[ri.Name] = true
+		}
+	}
+
+	// A nil Rand falls back to a securely seeded source
+	if got := (RandomSelector{}).SelectBundles(pool, 2, 5); len(got) != 2 {
+		t.Errorf("Expected 2 bundles without an explicit Rand, got %d", len(got))
+	}
+}
+
+// newestSelector puts the most recently modified RouterInfos in every bundle.
+type newestSelector struct{}
+
+func (newestSelector) SelectBundles(pool []RouterInfo, numBundles, perBundle int) [][]RouterInfo {
+	newest := pool[0]
+	for _, ri := range pool {
+		if ri.ModTime.After(newest.ModTime) {
+			newest = ri
+		}
+	}
+	bundles := make([][]RouterInfo, numBundles)
+	// Leave the last selection empty to check it is skipped
+	for i := 0; i < numBundles-1; i++ {
+		bundles[i] = []RouterInfo{newest}
+	}
+	return bundles
+}
+
+func TestSeedsProducer_UsesConfiguredSelector(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.NumRi = 1
+	reseeder.NumSu3 = 4
+	reseeder.Selector = newestSelector{}
+
+	now := time.Now()
+	pool := []RouterInfo{
+		{Name: "routerInfo-old.dat", ModTime: now.Add(-time.Hour)},
+		{Name: "routerInfo-new.dat", ModTime: now},
+	}
+
+	count := 0
+	for batch := range reseeder.seedsProducer(pool, mrand.New(mrand.NewSource(1))) {
+		count++
+		if len(batch) != 1 || batch[0].Name != "routerInfo-new.dat" {
+			t.Errorf("Expected the configured selector's choice, got %v", batch)
+		}
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 non-empty bundles, got %d", count)
+	}
+}
+
+// transportPool returns n routers advertising ntcp2 followed by m advertising only ssu2.
+func transportPool(n, m int) []RouterInfo {
+	var pool []RouterInfo
+	for i := 0; i < n; i++ {
+		pool = append(pool, RouterInfo{Name: fmt.Sprintf("routerInfo-ntcp2-%d.dat", i), Transports: []string{"ntcp2"}})
+	}
+	for i := 0; i < m; i++ {
+		pool = append(pool, RouterInfo{Name: fmt.Sprintf("routerInfo-ssu2-%d.dat", i), Transports: []string{"ssu2"}})
+	}
+	return pool
+}
+
+func TestTransportSelector_SelectBundles(t *testing.T) {
+	tests := []struct {
+		name      string
+		pool      []RouterInfo
+		share     float64
+		wantSSU2  int
+		wantTotal int
+	}{
+		{"weighted", transportPool(50, 50), 0.75, 8, 10},
+		{"filter", transportPool(50, 50), 1, 10, 10},
+		{"too few preferred routers", transportPool(50, 4), 1, 4, 10},
+		{"too few other routers", transportPool(1, 50), 0.75, 9, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector := TransportSelector{Transport: "ssu2", Share: tt.share, Rand: mrand.New(mrand.NewSource(1))}
+			bundles := selector.SelectBundles(tt.pool, 5, 10)
+			if len(bundles) != 5 {
+				t.Fatalf("Expected 5 bundles, got %d", len(bundles))
+			}
+			for i, bundle := range bundles {
+				if len(bundle) != tt.wantTotal {
+					t.Errorf("Bundle %d: expected %d RouterInfos, got %d", i, tt.wantTotal, len(bundle))
+				}
+				if got := transportDistribution(bundle)["ssu2"]; got != tt.wantSSU2 {
+					t.Errorf("Bundle %d: expected %d ssu2 routers, got %d", i, tt.wantSSU2, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatDistribution(t *testing.T) {
+	pool := append(transportPool(2, 1), RouterInfo{Name: "both", Transports: []string{"ssu2", "ntcp2"}})
+	if got, want := formatDistribution(transportDistribution(pool)), "ntcp2=3 ssu2=2"; got != want {
+		t.Errorf("formatDistribution() = %q, want %q", got, want)
+	}
+	if got := formatDistribution(nil); got != "" {
+		t.Errorf("Expected empty string for no routers, got %q", got)
+	}
+}
@@ -11,16 +11,15 @@ import (
 	"math"
 	"net"
 	"net/http"
-	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-i2p/go-sam-bridge/lib/embedding"
-	"github.com/go-i2p/onramp"
-	"github.com/gorilla/handlers"
 	"github.com/justinas/alice"
 	throttled "github.com/throttled/throttled/v2"
 	"github.com/throttled/throttled/v2/store/memstore"
@@ -39,17 +38,97 @@ type Server struct {
 	Reseeder *ReseederImpl
 	// Blacklist manages IP-based access control for security
 	Blacklist *Blacklist
+	// Allowlist exempts matching clients from the per-client bundle download
+	// limit; the global limit still applies to them
+	Allowlist *Allowlist
+	// TrustedProxies lists the proxies whose X-Forwarded-For hops are
+	// believed when TrustProxy is set; see proxiedMiddleware
+	TrustedProxies []*net.IPNet
 
 	// ServerListener handles standard HTTP/HTTPS connections
 	ServerListener net.Listener
 
 	// I2P Listener configuration for serving over I2P network
-	Garlic      *onramp.Garlic
+	Garlic      Tunnel
 	I2PListener net.Listener
+	// i2pMu guards Garlic and I2PListener while the SAM session is re-established
+	i2pMu sync.Mutex
+	// stopping is closed by Shutdown to end SAM reconnection attempts
+	stopping chan struct{}
+	stopOnce sync.Once
 
 	// Tor Listener configuration for serving over Tor network
 	OnionListener net.Listener
-	Onion         *onramp.Onion
+	Onion         Tunnel
+
+	// MultiBundle additionally serves every bundle of the current set at
+	// prefix+"/i2pseeds-N.su3", listed in prefix+"/i2pseeds-index.txt"
+	MultiBundle bool
+	// Checksum serves prefix+"/i2pseeds.su3.sha256", the SHA-256 of the bundle
+	// the requesting client gets from prefix+"/i2pseeds.su3"
+	Checksum bool
+	// MaxSu3Size, when positive, is the largest bundle in bytes the server
+	// sends. A bigger one, usually the result of a misconfigured NumRi, is
+	// refused with 503 and logged instead of being streamed to every client.
+	MaxSu3Size int64
+	// DownloadName is the filename offered in Content-Disposition for served
+	// bundles. Numbered bundles insert "-N" before its extension. Empty means
+	// DefaultDownloadName.
+	DownloadName string
+
+	// CanonicalURL is the public base URL of the homepage; when set, pages carry
+	// canonical and hreflang links for each active homepage language
+	CanonicalURL string
+	// Sitemap serves /sitemap.xml listing every homepage translation (needs CanonicalURL)
+	Sitemap bool
+
+	// Health collects the transport state reported at /readyz
+	Health *Health
+
+	// SessionTicketKeyFile holds TLS session ticket keys shared between servers,
+	// see LoadSessionTicketKeys; it is re-read every SessionTicketRotation
+	SessionTicketKeyFile string
+	// SessionTicketRotation rotates the HTTPS session ticket keys at this
+	// interval, generating them locally unless SessionTicketKeyFile is set
+	SessionTicketRotation time.Duration
+	// MinKeyBits refuses to serve a TLS certificate whose key is weaker than
+	// this many RSA-equivalent bits, see CheckKeySize. Below 2048 it has no
+	// effect, since TLS keys under 2048-bit RSA are always refused
+	MinKeyBits int
+
+	// GeoIP, when set, adds the client's country and ASN to access log lines
+	GeoIP geoLookup
+	// GeoPartitions, when set together with GeoIP, restricts each client to
+	// the bundles of its country's group
+	GeoPartitions *GeoPartitions
+
+	// AllowedUserAgents lists the exact User-Agent values that are served
+	// bundles; any other agent gets 403 on the bundle URLs and the homepage
+	// elsewhere. Empty means only I2pUserAgent.
+	AllowedUserAgents []string
+
+	// SlowRequestThreshold logs a warning for every request taking longer than
+	// this; zero disables the check
+	SlowRequestThreshold time.Duration
+
+	// Ephemeral makes the server avoid every write to disk: the homepage is
+	// served straight from the embedded content instead of being unembedded
+	// into the working directory, and reseed ping results are kept in memory
+	// instead of in .ping files. It is meant for read-only root filesystems
+	// and immutable containers.
+	Ephemeral bool
+
+	// transport names the transport this server was last started on, for logging
+	transport atomic.Value
+	// oversizedLogged is one more than the bundle generation an oversized
+	// bundle was last logged for, so the error is logged once per rebuild
+	oversizedLogged atomic.Uint64
+
+	// SAM bridge address and cached result of the last readiness probe
+	samAddr       string
+	samProbeMutex sync.Mutex
+	samProbeAt    time.Time
+	samProbeErr   error
 
 	// Rate limiting configuration for request throttling
 	RequestRateLimit   int
@@ -71,12 +150,103 @@ type Server struct {
 	acceptablesMutex sync.RWMutex
 }
 
+// DefaultRateLimitStoreSize is the maximum number of distinct client addresses
+// tracked by each per-IP rate limit store unless ServerConfig.RateLimitStoreSize
+// says otherwise. The stores evict the least recently seen address once full, so
+// memory stays bounded and proportional to active clients.
+const DefaultRateLimitStoreSize = 65536
+
+// globalRateStoreSize bounds the global limiter's store, which is keyed by HTTP
+// method rather than client address and so only ever holds a handful of keys.
+const globalRateStoreSize = 16
+
+// NormalizePrefix validates a URL path prefix for NewServer and returns it in
+// canonical form: a leading slash, no trailing slash, and "" for the root. Each
+// segment may only contain letters, digits and "-._~", which keeps the prefix
+// free of ServeMux pattern syntax and of anything a client would have to escape.
+func NormalizePrefix(prefix string) (string, error) {
+	trimmed := strings.Trim(prefix, "/")
+	if trimmed == "" {
+		return "", nil
+	}
+	for _, segment := range strings.Split(trimmed, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("invalid prefix %q: empty, \".\" and \"..\" path segments are not allowed", prefix)
+		}
+		for _, r := range segment {
+			if !isPrefixChar(r) {
+				return "", fmt.Errorf("invalid prefix %q: character %q is not allowed, use only letters, digits and -._~", prefix, r)
+			}
+		}
+	}
+	return "/" + trimmed, nil
+}
+
+// isPrefixChar reports whether r is an RFC 3986 unreserved character.
+func isPrefixChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune("-._~", r)
+}
+
 // NewServer creates a new reseed server instance with secure TLS configuration.
 // It sets up TLS 1.3-only connections, proper cipher suites, and middleware chain for
 // request processing. The prefix parameter customizes URL paths and trustProxy enables
 // reverse proxy support for deployment behind load balancers or CDNs.
 func NewServer(prefix string, trustProxy bool, samaddr string, requestRateLimit, webRateLimit, globalRateLimit int) *Server {
-	config := &tls.Config{
+	server, err := NewServerWithConfig(ServerConfig{
+		Prefix:           prefix,
+		TrustProxy:       trustProxy,
+		SAMAddr:          samaddr,
+		RequestRateLimit: requestRateLimit,
+		WebRateLimit:     webRateLimit,
+		GlobalRateLimit:  globalRateLimit,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return server
+}
+
+// ServerConfig holds everything NewServerWithConfig needs to build a Server.
+// Zero-valued dependencies are replaced by the defaults NewServer uses.
+type ServerConfig struct {
+	Prefix     string
+	TrustProxy bool
+	SAMAddr    string
+
+	// Rate limits in requests per hour for su3 downloads, web pages and all clients combined
+	RequestRateLimit int
+	WebRateLimit     int
+	GlobalRateLimit  int
+
+	// TLSConfig replaces the default TLS 1.3-only configuration
+	TLSConfig *tls.Config
+	// RequestRateStore, WebRateStore and GlobalRateStore replace the default
+	// in-memory stores backing each rate limiter
+	RequestRateStore throttled.Store
+	WebRateStore     throttled.Store
+	GlobalRateStore  throttled.Store
+	// RateLimitStoreSize bounds the default per-IP stores; zero means
+	// DefaultRateLimitStoreSize. Stores supplied above are left as they are.
+	RateLimitStoreSize int
+
+	// Reseeder serves the bundles; it may also be assigned after construction
+	Reseeder *ReseederImpl
+	// Health receives transport state; nil means DefaultHealth
+	Health *Health
+	// TrustedProxies sets Server.TrustedProxies
+	TrustedProxies []*net.IPNet
+	// GeoIP and GeoPartitions set Server.GeoIP and Server.GeoPartitions;
+	// without GeoIP neither is used
+	GeoIP         *GeoIP
+	GeoPartitions *GeoPartitions
+	// Ephemeral sets Server.Ephemeral
+	Ephemeral bool
+}
+
+// defaultTLSConfig returns the TLS 1.3-only configuration used unless
+// ServerConfig.TLSConfig overrides it.
+func defaultTLSConfig() *tls.Config {
+	return &tls.Config{
 		MinVersion:               tls.VersionTLS13,
 		PreferServerCipherSuites: true,
 		CipherSuites: []uint16{
@@ -85,9 +255,29 @@ func NewServer(prefix string, trustProxy bool, samaddr string, requestRateLimit,
 		},
 		CurvePreferences: []tls.CurveID{tls.CurveP384, tls.CurveP521}, // default CurveP256 removed
 	}
+}
+
+// NewServerWithConfig creates a reseed server from cfg. Unlike NewServer it
+// returns construction errors instead of exiting, and lets tests and embedding
+// programs supply their own TLS configuration, rate limit stores and Reseeder.
+func NewServerWithConfig(cfg ServerConfig) (*Server, error) {
+	config := cfg.TLSConfig
+	if config == nil {
+		config = defaultTLSConfig()
+	}
 	h := &http.Server{TLSConfig: config}
 
-	server := Server{Server: h, Reseeder: nil, RequestRateLimit: requestRateLimit, WebRateLimit: webRateLimit, GlobalRateLimit: globalRateLimit}
+	health := cfg.Health
+	if health == nil {
+		health = DefaultHealth
+	}
+	prefix := cfg.Prefix
+
+	server := Server{Server: h, Reseeder: cfg.Reseeder, RequestRateLimit: cfg.RequestRateLimit, WebRateLimit: cfg.WebRateLimit, GlobalRateLimit: cfg.GlobalRateLimit, Health: health, TrustedProxies: cfg.TrustedProxies, Ephemeral: cfg.Ephemeral, samAddr: cfg.SAMAddr, stopping: make(chan struct{})}
+	if cfg.GeoIP != nil {
+		server.GeoIP = cfg.GeoIP
+		server.GeoPartitions = cfg.GeoPartitions
+	}
 
 	/*
 		Disable this for now, I was working on it before the CPU exhaustion fixes
@@ -105,10 +295,14 @@ func NewServer(prefix string, trustProxy bool, samaddr string, requestRateLimit,
 				lgr.WithError(err).Warn("Failed to create Garlic instance for I2P. will try again without embedded SAM bridge")
 			}
 	*/
+	storeSize := cfg.RateLimitStoreSize
+	if storeSize == 0 {
+		storeSize = DefaultRateLimitStoreSize
+	}
 	var err error
-	server.requestRateStore, err = memstore.New(65536)
+	server.requestRateStore, err = rateStore(cfg.RequestRateStore, storeSize)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	server.requestRateQuota = throttled.RateQuota{
 		MaxRate:  throttled.PerHour(server.RequestRateLimit),
@@ -116,15 +310,16 @@ func NewServer(prefix string, trustProxy bool, samaddr string, requestRateLimit,
 	}
 	server.requestRateLimiter, err = throttled.NewGCRARateLimiter(server.requestRateStore, server.requestRateQuota)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	throttleSu3Handler := throttled.HTTPRateLimiter{
-		RateLimiter: server.requestRateLimiter,
-		VaryBy:      &throttled.VaryBy{RemoteAddr: true},
+		DeniedHandler: countingDeniedHandler(&metrics.su3RateLimited),
+		RateLimiter:   server.requestRateLimiter,
+		VaryBy:        &throttled.VaryBy{RemoteAddr: true},
 	}
-	server.webRequestRateStore, err = memstore.New(65536)
+	server.webRequestRateStore, err = rateStore(cfg.WebRateStore, storeSize)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	server.webRequestRateQuota = throttled.RateQuota{
 		MaxRate:  throttled.PerHour(server.WebRateLimit),
@@ -132,16 +327,17 @@ func NewServer(prefix string, trustProxy bool, samaddr string, requestRateLimit,
 	}
 	server.webRequestRateLimiter, err = throttled.NewGCRARateLimiter(server.webRequestRateStore, server.webRequestRateQuota)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	throttleWebHandler := throttled.HTTPRateLimiter{
-		RateLimiter: server.webRequestRateLimiter,
-		VaryBy:      &throttled.VaryBy{RemoteAddr: true},
+		DeniedHandler: countingDeniedHandler(&metrics.webRateLimited),
+		RateLimiter:   server.webRequestRateLimiter,
+		VaryBy:        &throttled.VaryBy{RemoteAddr: true},
 	}
 
-	server.globalRateStore, err = memstore.New(65536)
+	server.globalRateStore, err = rateStore(cfg.GlobalRateStore, globalRateStoreSize)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	server.globalRateQuota = throttled.RateQuota{
 		MaxRate:  throttled.PerHour(server.GlobalRateLimit),
@@ -149,17 +345,15 @@ func NewServer(prefix string, trustProxy bool, samaddr string, requestRateLimit,
 	}
 	server.globalRateLimiter, err = throttled.NewGCRARateLimiter(server.globalRateStore, server.globalRateQuota)
 	if err != nil {
-		log.Fatal(err)
-	}
-	throttledGlobalHandler := throttled.HTTPRateLimiter{
-		RateLimiter: server.globalRateLimiter,
-		VaryBy:      &throttled.VaryBy{Method: true},
+		return nil, err
 	}
 	middlewareChain := alice.New()
-	if trustProxy {
-		middlewareChain = middlewareChain.Append(proxiedMiddleware)
+	if cfg.TrustProxy {
+		middlewareChain = middlewareChain.Append(server.proxiedMiddleware)
 	}
 
+	su3Limit := server.allowlisted(su3RateLimit(throttleSu3Handler, throttleWebHandler))
+
 	errorHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 		if _, err := w.Write(nil); nil != err {
@@ -168,11 +362,25 @@ func NewServer(prefix string, trustProxy bool, samaddr string, requestRateLimit,
 	})
 
 	mux := http.NewServeMux()
-	mux.Handle("/", middlewareChain.Append(disableKeepAliveMiddleware, loggingMiddleware, throttledGlobalHandler.RateLimit, throttleWebHandler.RateLimit, server.browsingMiddleware).Then(errorHandler))
-	mux.Handle(prefix+"/i2pseeds.su3", middlewareChain.Append(disableKeepAliveMiddleware, loggingMiddleware, verifyMiddleware, throttledGlobalHandler.RateLimit, throttleSu3Handler.RateLimit).Then(http.HandlerFunc(server.reseedHandler)))
-	server.Handler = mux
+	mux.Handle("/readyz", middlewareChain.Then(http.HandlerFunc(server.readyzHandler)))
+	mux.Handle("/status.json", middlewareChain.Append(disableKeepAliveMiddleware, server.loggingMiddleware, server.globalRateLimitMiddleware, throttleWebHandler.RateLimit).Then(http.HandlerFunc(server.statusHandler)))
+	mux.Handle("/", middlewareChain.Append(disableKeepAliveMiddleware, server.loggingMiddleware, server.globalRateLimitMiddleware, throttleWebHandler.RateLimit, server.browsingMiddleware).Then(errorHandler))
+	mux.Handle(prefix+"/i2pseeds.su3", middlewareChain.Append(disableKeepAliveMiddleware, server.loggingMiddleware, server.verifyMiddleware, server.memoryGuardMiddleware, server.globalRateLimitMiddleware, su3Limit).Then(http.HandlerFunc(server.reseedHandler)))
+	mux.Handle(prefix+"/i2pseeds.su3.sha256", middlewareChain.Append(disableKeepAliveMiddleware, server.loggingMiddleware, server.verifyMiddleware, server.globalRateLimitMiddleware, throttleWebHandler.RateLimit).Then(http.HandlerFunc(server.checksumHandler)))
+	mux.Handle(prefix+"/"+signerCertName, middlewareChain.Append(disableKeepAliveMiddleware, server.loggingMiddleware, server.globalRateLimitMiddleware, throttleWebHandler.RateLimit).Then(http.HandlerFunc(server.signerCertHandler)))
+	bundleHandler := middlewareChain.Append(disableKeepAliveMiddleware, server.loggingMiddleware, server.verifyMiddleware, server.memoryGuardMiddleware, server.globalRateLimitMiddleware, su3Limit).Then(server.bundleHandler(prefix))
+	bundleIndexHandler := middlewareChain.Append(disableKeepAliveMiddleware, server.loggingMiddleware, server.verifyMiddleware, server.globalRateLimitMiddleware, throttleWebHandler.RateLimit).Then(server.bundleIndexHandler(prefix))
+	server.Handler = server.slowRequestMiddleware(server.bundleRouter(prefix, mux, bundleHandler, bundleIndexHandler))
+
+	return &server, nil
+}
 
-	return &server
+// rateStore returns store, or a new in-memory store tracking up to size keys if it is nil.
+func rateStore(store throttled.Store, size int) (throttled.Store, error) {
+	if store != nil {
+		return store, nil
+	}
+	return memstore.New(size)
 }
 
 func calculateBurst(rate, percent, minimum int) int {
@@ -204,17 +412,28 @@ func calculateBurst(rate, percent, minimum int) int {
 // other security-sensitive contexts. Uses crypto/rand for entropy source.
 func SecureRandomAlphaString() string {
 	// Fixed 16-character length for consistent token generation
-	length := 16
-	result := make([]byte, length)
-	// Buffer size calculation for efficient random byte usage
-	bufferSize := int(float64(length) * 1.3)
-	for i, j, randomBytes := 0, 0, []byte{}; i < length; j++ {
-		// Refresh random bytes buffer when needed for efficiency
+	return SecureRandomAlphaStringN(16)
+}
+
+// SecureRandomAlphaStringN generates a cryptographically secure random string of n
+// letters, or an empty string when n is not positive. Each character is drawn from
+// the low letterIdxBits of a crypto/rand byte, and values past the end of letterBytes
+// are rejected rather than folded back, so every letter is equally likely.
+func SecureRandomAlphaStringN(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	result := make([]byte, n)
+	// 52 of the 64 masked values are letters, so a character takes about 1.23
+	// bytes on average; draw a little more than that per refill
+	bufferSize := int(float64(n)*1.3) + 1
+	for i, j, randomBytes := 0, 0, []byte{}; i < n; j++ {
+		// Refresh random bytes buffer once every byte of it has been used
 		if j%bufferSize == 0 {
 			randomBytes = SecureRandomBytes(bufferSize)
 		}
 		// Filter random bytes to only include valid letter indices
-		if idx := int(randomBytes[j%length] & letterIdxMask); idx < len(letterBytes) {
+		if idx := int(randomBytes[j%bufferSize] & letterIdxMask); idx < len(letterBytes) {
 			result[i] = letterBytes[idx]
 			i++
 		}
@@ -241,6 +460,10 @@ func SecureRandomBytes(length int) []byte {
 func (srv *Server) Shutdown(ctx context.Context) error {
 	var firstErr error
 
+	if srv.stopping != nil {
+		srv.stopOnce.Do(func() { close(srv.stopping) })
+	}
+
 	if srv.embeddedRouter != nil && srv.embeddedRouter.Running() {
 		if err := srv.embeddedRouter.Stop(ctx); err != nil {
 			lgr.WithError(err).Warn("Error stopping embedded SAM bridge")
@@ -248,6 +471,7 @@ func (srv *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	srv.i2pMu.Lock()
 	if srv.Garlic != nil {
 		if err := srv.Garlic.Close(); err != nil {
 			lgr.WithError(err).Warn("Error closing I2P Garlic tunnel")
@@ -255,7 +479,9 @@ func (srv *Server) Shutdown(ctx context.Context) error {
 				firstErr = err
 			}
 		}
+		srv.Garlic = nil
 	}
+	srv.i2pMu.Unlock()
 
 	if srv.Onion != nil {
 		if err := srv.Onion.Close(); err != nil {
@@ -355,27 +581,67 @@ func (srv *Server) checkAcceptableUnsafe(val string) bool {
 }
 
 func (srv *Server) reseedHandler(w http.ResponseWriter, r *http.Request) {
-	var peer Peer
-	if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
-		peer = Peer(ip)
-	} else {
-		peer = Peer(r.RemoteAddr)
-	}
+	peer := requestPeer(r)
 
-	su3Bytes, err := srv.Reseeder.PeerSu3Bytes(peer)
+	part, parts := srv.geoPartition(peer)
+	su3Bytes, err := srv.Reseeder.peerSu3In(peer, part, parts)
 	if nil != err {
 		lgr.WithError(err).WithField("peer", peer).Errorf("Error serving su3 %s", err)
 		http.Error(w, "500 Unable to serve su3", http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Disposition", "attachment; filename=i2pseeds.su3")
+	if srv.oversized(su3Bytes) {
+		http.Error(w, "503 Reseed file unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	srv.setFreshnessHeaders(w, time.Now())
+	if notModified(w, r, srv.Reseeder.su3SHA256(su3Bytes)) {
+		return
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename="+srv.downloadName(-1))
 	w.Header().Set("Content-Type", "application/octet-stream")
 	w.Header().Set("Content-Length", strconv.FormatInt(int64(len(su3Bytes)), 10))
+	if r.Method == http.MethodHead {
+		return
+	}
+	srv.Reseeder.countServed()
 
 	io.Copy(w, bytes.NewReader(su3Bytes))
 }
 
+// oversized reports whether su3Bytes is larger than MaxSu3Size. The first
+// oversized bundle of each served generation is logged as an error; later
+// requests are only refused.
+func (srv *Server) oversized(su3Bytes []byte) bool {
+	if srv.MaxSu3Size <= 0 || int64(len(su3Bytes)) <= srv.MaxSu3Size {
+		return false
+	}
+	mark := srv.Reseeder.served.Load() + 1
+	if srv.oversizedLogged.Swap(mark) == mark {
+		return true
+	}
+	lgr.WithField("size", len(su3Bytes)).WithField("max_su3_size", srv.MaxSu3Size).
+		Error("Refusing to serve an su3 bundle larger than the maximum size; check NumRi")
+	return true
+}
+
+// su3RateLimit applies the su3 download limit to GET requests. HEAD requests
+// carry no bundle, so availability probes are held to the web page limit
+// instead and do not use up a client's downloads.
+func su3RateLimit(download, head throttled.HTTPRateLimiter) alice.Constructor {
+	return func(next http.Handler) http.Handler {
+		downloadLimited, headLimited := download.RateLimit(next), head.RateLimit(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				headLimited.ServeHTTP(w, r)
+				return
+			}
+			downloadLimited.ServeHTTP(w, r)
+		})
+	}
+}
+
 func disableKeepAliveMiddleware(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Connection", "close")
@@ -385,8 +651,26 @@ func disableKeepAliveMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
-func loggingMiddleware(next http.Handler) http.Handler {
-	return handlers.CombinedLoggingHandler(os.Stdout, next)
+// globalRateLimitMiddleware enforces the server-wide request quota. Unlike the
+// per-IP limiters it does not emit X-RateLimit-* headers, so the headers a client
+// sees always describe its own quota; only Retry-After is set when it denies.
+func (srv *Server) globalRateLimitMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		limited, result, err := srv.globalRateLimiter.RateLimit(r.Method, 1)
+		if err != nil {
+			lgr.WithError(err).Error("Global rate limiter failed")
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if limited {
+			metrics.globalRateLimited.Add(1)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			http.Error(w, "limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
 }
 
 func (srv *Server) browsingMiddleware(next http.Handler) http.Handler {
@@ -395,7 +679,7 @@ func (srv *Server) browsingMiddleware(next http.Handler) http.Handler {
 			srv.reseedHandler(w, r)
 			return
 		}
-		if I2pUserAgent != r.UserAgent() {
+		if !srv.allowedUserAgent(r.UserAgent()) {
 			srv.HandleARealBrowser(w, r)
 			return
 		}
@@ -404,32 +688,20 @@ func (srv *Server) browsingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
-func verifyMiddleware(next http.Handler) http.Handler {
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		if I2pUserAgent != r.UserAgent() {
-			http.Error(w, "403 Forbidden", http.StatusForbidden)
-			return
-		}
-
-		next.ServeHTTP(w, r)
+// allowedUserAgent reports whether ua is one of AllowedUserAgents, or is
+// I2pUserAgent when none are configured.
+func (srv *Server) allowedUserAgent(ua string) bool {
+	if len(srv.AllowedUserAgents) == 0 {
+		return ua == I2pUserAgent
 	}
-	return http.HandlerFunc(fn)
+	return slices.Contains(srv.AllowedUserAgents, ua)
 }
 
-func proxiedMiddleware(next http.Handler) http.Handler {
+func (srv *Server) verifyMiddleware(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		if prior, ok := r.Header["X-Forwarded-For"]; ok && len(prior) > 0 {
-			// X-Forwarded-For can contain comma-separated IPs: "client, proxy1, proxy2"
-			// We want the leftmost (original client) IP address
-			ips := strings.Split(prior[0], ",")
-			if len(ips) > 0 {
-				clientIP := strings.TrimSpace(ips[0])
-				// Validate that it's a valid IP address before using it
-				if net.ParseIP(clientIP) != nil {
-					r.RemoteAddr = clientIP
-				}
-				// If invalid, leave r.RemoteAddr unchanged (use original value)
-			}
+		if !srv.allowedUserAgent(r.UserAgent()) {
+			http.Error(w, "403 Forbidden", http.StatusForbidden)
+			return
 		}
 
 		next.ServeHTTP(w, r)
@@ -0,0 +1,244 @@
+package reseed
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/throttled/throttled/v2/store/memstore"
+)
+
+// TestNewServer_RateLimitStoreSize verifies that the per-IP rate limit stores are
+// bounded by ServerConfig.RateLimitStoreSize and evict the least recently seen client.
+func TestNewServer_RateLimitStoreSize(t *testing.T) {
+	srv, err := NewServerWithConfig(ServerConfig{RequestRateLimit: 4, WebRateLimit: 40, GlobalRateLimit: 2000, RateLimitStoreSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		key := fmt.Sprintf("10.0.0.%d", i)
+		if _, err := srv.requestRateStore.SetIfNotExistsWithTTL(key, 1, time.Hour); err != nil {
+			t.Fatalf("SetIfNotExistsWithTTL(%s) failed: %v", key, err)
+		}
+	}
+
+	if v, _, _ := srv.requestRateStore.GetWithTime("10.0.0.0"); v != -1 {
+		t.Errorf("Expected oldest client to be evicted, got value %d", v)
+	}
+	if v, _, _ := srv.requestRateStore.GetWithTime("10.0.0.2"); v != 1 {
+		t.Errorf("Expected newest client to be retained, got value %d", v)
+	}
+}
+
+// TestNewServer_RateLimitHeaders verifies that the reseed and web paths expose a
+// single set of per-client quota headers and a Retry-After once the limit is hit.
+func TestNewServer_RateLimitHeaders(t *testing.T) {
+	srv := NewServer("", false, "", 4, 40, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+
+	// throttled reports the limit as the burst size plus one
+	tests := []struct {
+		name  string
+		path  string
+		limit string
+	}{
+		{"reseed path", "/i2pseeds.su3", "2"},
+		{"web path", "/", "7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				req := httptest.NewRequest("GET", tt.path, nil)
+				req.Header.Set("User-Agent", I2pUserAgent)
+				req.RemoteAddr = "192.0.2.1:1234"
+				w := httptest.NewRecorder()
+				srv.Handler.ServeHTTP(w, req)
+
+				if got := w.Header().Values("X-RateLimit-Limit"); len(got) != 1 || got[0] != tt.limit {
+					t.Fatalf("Expected a single X-RateLimit-Limit of %s, got %v", tt.limit, got)
+				}
+				if w.Header().Get("X-RateLimit-Remaining") == "" {
+					t.Fatal("Expected X-RateLimit-Remaining header")
+				}
+				if w.Code == http.StatusTooManyRequests {
+					if w.Header().Get("Retry-After") == "" {
+						t.Error("Expected Retry-After header on a rate limited response")
+					}
+					return
+				}
+			}
+			t.Error("Expected requests to be rate limited")
+		})
+	}
+}
+
+// TestNewServerWithConfig verifies that injected dependencies are used instead
+// of the defaults NewServer builds.
+func TestNewServerWithConfig(t *testing.T) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	store, err := memstore.New(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	health := NewHealth()
+
+	srv, err := NewServerWithConfig(ServerConfig{
+		Prefix:           "/netdb",
+		RequestRateLimit: 4,
+		WebRateLimit:     40,
+		GlobalRateLimit:  2000,
+		TLSConfig:        tlsConfig,
+		RequestRateStore: store,
+		Reseeder:         reseeder,
+		Health:           health,
+	})
+	if err != nil {
+		t.Fatalf("NewServerWithConfig() error = %v", err)
+	}
+	if srv.TLSConfig != tlsConfig {
+		t.Error("Expected the injected TLS config")
+	}
+	if srv.Reseeder != reseeder || srv.Health != health {
+		t.Error("Expected the injected Reseeder and Health")
+	}
+
+	req := httptest.NewRequest("GET", "/netdb/i2pseeds.su3", nil)
+	req.Header.Set("User-Agent", I2pUserAgent)
+	req.RemoteAddr = "192.0.2.1:1234"
+	srv.Handler.ServeHTTP(httptest.NewRecorder(), req)
+	// throttled keys the store by the client IP followed by its newline separator
+	if v, _, _ := store.GetWithTime("192.0.2.1\n"); v == -1 {
+		t.Error("Expected the su3 rate limit to be tracked in the injected store")
+	}
+
+	// Defaults fill in everything left unset
+	srv, err = NewServerWithConfig(ServerConfig{RequestRateLimit: 4, WebRateLimit: 40, GlobalRateLimit: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.TLSConfig.MinVersion != tls.VersionTLS13 || srv.Health != DefaultHealth || srv.webRequestRateStore == nil {
+		t.Errorf("Expected the NewServer defaults, got TLS %x, health %p", srv.TLSConfig.MinVersion, srv.Health)
+	}
+}
+
+func TestNormalizePrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"/", "", false},
+		{"netdb", "/netdb", false},
+		{"/netdb/", "/netdb", false},
+		{"//reseed/v1//", "/reseed/v1", false},
+		{"/a-b_c.d~e", "/a-b_c.d~e", false},
+		{"/re seed", "", true},
+		{"/netdb/{id}", "", true},
+		{"/netdb?x=1", "", true},
+		{"/netdb%2F", "", true},
+		{"/a//b", "", true},
+		{"/../netdb", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePrefix(tt.prefix)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizePrefix(%q) = %q, %v; want %q, wantErr %v", tt.prefix, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestReseedHandler_Head verifies that HEAD reports the bundle's headers without
+// sending it or using up the client's su3 downloads.
+func TestReseedHandler_Head(t *testing.T) {
+	srv := NewServer("", false, "", 1, 40, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle-data")})
+	srv.Reseeder.rebuilding.Store(true)
+
+	request := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/i2pseeds.su3", nil)
+		req.Header.Set("User-Agent", I2pUserAgent)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 5; i++ {
+		w := request(http.MethodHead)
+		if w.Code != http.StatusOK {
+			t.Fatalf("HEAD %d: expected 200, got %d", i, w.Code)
+		}
+		if got := w.Header().Get("Content-Length"); got != "11" {
+			t.Errorf("Expected Content-Length 11, got %q", got)
+		}
+		if w.Header().Get("Content-Disposition") == "" {
+			t.Error("Expected Content-Disposition on HEAD")
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Expected no body for HEAD, got %q", w.Body.String())
+		}
+	}
+	if got := srv.Reseeder.servedDuringRebuild.Load(); got != 0 {
+		t.Errorf("Expected HEAD requests not to count as served, got %d", got)
+	}
+
+	if w := request(http.MethodGet); w.Code != http.StatusOK || w.Body.String() != "bundle-data" {
+		t.Errorf("Expected the download to still be allowed after HEAD probes, got %d %q", w.Code, w.Body.String())
+	}
+
+	// HEAD still requires the reseed User-Agent
+	req := httptest.NewRequest(http.MethodHead, "/i2pseeds.su3", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for HEAD with a non-reseed User-Agent, got %d", w.Code)
+	}
+}
+
+func TestReseedHandler_MaxSu3Size(t *testing.T) {
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle-data")})
+	srv.MultiBundle = true
+	srv.Checksum = true
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("User-Agent", I2pUserAgent)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	srv.MaxSu3Size = 11
+	for _, path := range []string{"/i2pseeds.su3", "/i2pseeds-0.su3"} {
+		if w := get(path); w.Code != http.StatusOK || w.Body.String() != "bundle-data" {
+			t.Errorf("%s: expected a bundle of exactly MaxSu3Size to be served, got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+	if w := get("/i2pseeds.su3.sha256"); w.Code != http.StatusOK {
+		t.Errorf("expected the checksum of a bundle within MaxSu3Size, got %d %q", w.Code, w.Body.String())
+	}
+	srv.MaxSu3Size = 10
+	for _, path := range []string{"/i2pseeds.su3", "/i2pseeds-0.su3", "/i2pseeds.su3.sha256"} {
+		if w := get(path); w.Code != http.StatusServiceUnavailable || w.Body.String() == "bundle-data" {
+			t.Errorf("%s: expected an oversized bundle to be refused with 503, got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+	if got := srv.oversizedLogged.Load(); got != 1 {
+		t.Errorf("expected the oversized bundle to be logged once for the unpublished set, got mark %d", got)
+	}
+	srv.Reseeder.publish([][]byte{[]byte("rebuilt-bundle")}, nil)
+	get("/i2pseeds.su3")
+	if got := srv.oversizedLogged.Load(); got != 2 {
+		t.Errorf("expected a rebuilt oversized set to be logged again, got mark %d", got)
+	}
+}
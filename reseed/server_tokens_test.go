@@ -1,6 +1,7 @@
 package reseed
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -207,3 +208,36 @@ func TestTokenCleanupMethods(t *testing.T) {
 		}
 	})
 }
+
+func TestSecureRandomAlphaStringN(t *testing.T) {
+	for _, n := range []int{-1, 0, 1, 16, 100} {
+		got := SecureRandomAlphaStringN(n)
+		if want := max(n, 0); len(got) != want {
+			t.Errorf("SecureRandomAlphaStringN(%d) has length %d, want %d", n, len(got), want)
+		}
+		if strings.Trim(got, letterBytes) != "" {
+			t.Errorf("SecureRandomAlphaStringN(%d) = %q, want only letters", n, got)
+		}
+	}
+	if len(SecureRandomAlphaString()) != 16 {
+		t.Error("Expected SecureRandomAlphaString to stay 16 characters long")
+	}
+
+	// Every letter should turn up about equally often; with 52000 draws the
+	// expected count is 1000, and a bias toward reused bytes or folded
+	// indices would push some letters far from it
+	counts := make(map[rune]int)
+	for i := 0; i < 1000; i++ {
+		for _, c := range SecureRandomAlphaStringN(52) {
+			counts[c]++
+		}
+	}
+	if len(counts) != len(letterBytes) {
+		t.Fatalf("Expected all %d letters to appear, got %d", len(letterBytes), len(counts))
+	}
+	for c, count := range counts {
+		if count < 800 || count > 1200 {
+			t.Errorf("Letter %q appeared %d times, expected about 1000", c, count)
+		}
+	}
+}
@@ -1,17 +1,25 @@
 package reseed
 
 import (
+	"context"
+	"crypto"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash/crc32"
+	"maps"
+	"math"
 	rand2 "math/rand"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -20,14 +28,67 @@ import (
 	"i2pgit.org/go-i2p/reseed-tools/su3"
 )
 
-// routerInfo holds metadata and content for an individual I2P router information file.
+// RouterInfo holds metadata and content for an individual I2P router information file.
 // Contains the router filename, modification time, raw data, and parsed RouterInfo structure
 // used for reseed bundle generation and network database management operations.
-type routerInfo struct {
+// Data and RI are left nil when the netDb is read with LazyData; the file is then
+// read again from Path when a bundle is built.
+type RouterInfo struct {
 	Name    string
 	ModTime time.Time
 	Data    []byte
 	RI      *router_info.RouterInfo
+	// Path is the file the RouterInfo was read from, empty if it did not come from disk
+	Path string
+	// Ident is the router identity hash, used to spot one router stored under several filenames
+	Ident string
+	// Transports lists the lowercase transport styles the router advertises (ntcp2, ssu2)
+	Transports []string
+	// Floodfill reports whether the router advertises the floodfill capability
+	Floodfill bool
+}
+
+// content returns the raw RouterInfo bytes, reading them from Path if they
+// were not kept in memory.
+func (ri RouterInfo) content() ([]byte, error) {
+	if ri.Data != nil || ri.Path == "" {
+		return ri.Data, nil
+	}
+	data, err := os.ReadFile(ri.Path)
+	if err != nil {
+		return nil, fmt.Errorf("re-reading %s: %w", ri.Name, err)
+	}
+	return data, nil
+}
+
+// identityKey returns the key used to tell routers apart within a bundle,
+// falling back to the filename when the identity hash is unknown.
+func (ri RouterInfo) identityKey() string {
+	if ri.Ident != "" {
+		return ri.Ident
+	}
+	return ri.Name
+}
+
+// supportsTransport reports whether the router advertises the given transport style.
+func (ri RouterInfo) supportsTransport(transport string) bool {
+	return slices.Contains(ri.Transports, transport)
+}
+
+// routerTransports returns the distinct transport styles advertised by ri, lowercased.
+func routerTransports(ri *router_info.RouterInfo) []string {
+	var transports []string
+	for _, addr := range ri.RouterAddresses() {
+		style, err := addr.TransportStyle().DataSafe()
+		if err != nil {
+			continue
+		}
+		style = strings.ToLower(style)
+		if !slices.Contains(transports, style) {
+			transports = append(transports, style)
+		}
+	}
+	return transports
 }
 
 // Peer represents a unique identifier for an I2P peer requesting reseed data.
@@ -57,19 +118,128 @@ type ReseederImpl struct {
 	netdb *LocalNetDbImpl
 	// su3s stores pre-built SU3 files for efficient serving using atomic operations
 	su3s atomic.Value // stores [][]byte
+	// sums stores the digests of the bundles in su3s (su3Sums)
+	sums atomic.Value
+	// built stores when the bundle set in su3s was built (time.Time)
+	built atomic.Value
+	// served is the generation of the bundle set in su3s, 0 for one swapped
+	// in without publish
+	served atomic.Uint64
 
-	// SigningKey contains the RSA private key for SU3 file cryptographic signing
-	SigningKey *rsa.PrivateKey
+	// SigningKey contains the private key for SU3 file cryptographic signing:
+	// RSA, ECDSA or Ed25519, which sets the bundles' signature type
+	SigningKey crypto.Signer
 	// SignerID contains the identity string used in SU3 signature verification
 	SignerID []byte
+	// RSAPSS signs bundles with SigTypeRSAPSSWithSHA512 instead of the
+	// PKCS#1 v1.5 SigTypeRSAWithSHA512. I2P routers cannot verify PSS
+	// bundles, so it is only for deployments whose policy requires it.
+	RSAPSS bool
+	// FloodfillFirst places floodfill routers ahead of the others inside each
+	// bundle's zip, for clients that process RouterInfos in order. Which
+	// routers are selected is unchanged.
+	FloodfillFirst bool
 	// NumRi specifies the number of router infos to include in each SU3 file
 	NumRi int
+	// Deterministic makes a rebuild from the same netDb contents produce the
+	// same bundles, in the same order and with the same version, so they can
+	// be cached for long periods or served from a CDN. Selection is seeded
+	// from DeterministicSeed and the routers in the pool, and bundles are
+	// versioned with the newest routerInfo's modification time. Bytes are
+	// only identical with a deterministic signature scheme: RSA PKCS#1 v1.5
+	// or Ed25519, not ECDSA or RSAPSS.
+	Deterministic bool
+	// DeterministicSeed is mixed into the selection seed of a Deterministic
+	// rebuild; changing it reshuffles the bundles without a netDb change
+	DeterministicSeed []byte
+	// MinRouterInfoFraction is the share of valid routerInfos, freshest first,
+	// that a rebuild draws bundles from; the rest are the ones most likely to
+	// describe routers that have gone away. Zero means
+	// DefaultRouterInfoFraction.
+	MinRouterInfoFraction float64
 	// RebuildInterval determines how often to refresh the SU3 file cache
 	RebuildInterval time.Duration
+	// BundleTTL is how long clients are told a served bundle stays fresh,
+	// counted from when it was built; zero uses RebuildInterval
+	BundleTTL time.Duration
+	// StartupWait, when positive, holds the initial rebuild back until the
+	// netDb has enough usable RouterInfos for a bundle or StartupWait
+	// elapses, for netDbs still being filled by a share download
+	StartupWait time.Duration
 	// NumSu3 specifies the number of pre-built SU3 files to maintain
 	NumSu3 int
 	// rebuildMu prevents concurrent rebuild operations that would cause goroutine accumulation
 	rebuildMu sync.Mutex
+	// rebuildRequests holds at most one pending RequestRebuild for the rebuild loop
+	rebuildRequests chan struct{}
+	// quit is the channel Start returned, closed once by Stop
+	quit     chan bool
+	stopOnce sync.Once
+	// loopDone is closed when the rebuild loop started by Start has returned
+	loopDone chan struct{}
+
+	// AuditLog, when non-empty, is the path of a JSON-lines file that receives the
+	// RouterInfo filenames selected for every bundle index after each rebuild
+	AuditLog string
+	// selection stores the RouterInfo filenames of the current bundle set ([][]string)
+	selection atomic.Value
+
+	// HistorySize is how many earlier bundle sets are kept besides the current
+	// one, so a bad rebuild can be rolled back with PinGeneration. Zero keeps
+	// only the current set.
+	HistorySize int
+	// historyMu protects history, generation and pinned
+	historyMu sync.Mutex
+	// history holds the retained bundle sets, newest last
+	history    []*bundleSet
+	generation uint64
+	// pinned, when set, is served instead of the newest bundle set
+	pinned *bundleSet
+
+	// VerifyCert, when set, is used to check every bundle signature immediately
+	// after signing so that an unverifiable bundle is never served
+	VerifyCert *x509.Certificate
+
+	// SignerCert, when set, is the certificate for SigningKey and is served at
+	// <prefix>/reseed-cert.pem so operators can install it in their routers
+	SignerCert *x509.Certificate
+
+	// ClockCheck, when set with Required, makes rebuilds keep the current
+	// bundles instead of signing new ones while the system clock is skewed
+	ClockCheck *ClockCheck
+
+	// MemoryGuard, when set, makes the servers shed reseed requests while the
+	// heap is above its limit
+	MemoryGuard *MemoryGuard
+
+	// Selector chooses the RouterInfos for each bundle; RandomSelector is used when nil
+	Selector BundleSelector
+
+	// rebuilding is set while a rebuild is assembling a new bundle set
+	rebuilding atomic.Bool
+	// servedDuringRebuild counts bundles served while rebuilding is set
+	servedDuringRebuild atomic.Uint64
+	// lastRebuild stores the *RebuildStats of the most recent completed rebuild
+	lastRebuild atomic.Value
+}
+
+// builtSu3 pairs a signed SU3 file with the names of the RouterInfos it contains,
+// so the selection for each bundle index can be recorded once the set is assembled.
+type builtSu3 struct {
+	file  *su3.File
+	names []string
+}
+
+// bundleAudit is a single bundle entry in a rebuild audit record.
+type bundleAudit struct {
+	Index       int      `json:"index"`
+	RouterInfos []string `json:"routerInfos"`
+}
+
+// rebuildAudit is the JSON-lines record appended to the audit log after each rebuild.
+type rebuildAudit struct {
+	Time    time.Time     `json:"time"`
+	Bundles []bundleAudit `json:"bundles"`
 }
 
 // NewReseeder creates a new reseed service instance with default configuration.
@@ -78,100 +248,318 @@ type ReseederImpl struct {
 // freshness with server performance.
 func NewReseeder(netdb *LocalNetDbImpl) *ReseederImpl {
 	rs := &ReseederImpl{
-		netdb:           netdb,
-		NumRi:           61,
-		RebuildInterval: 90 * time.Hour,
+		netdb:                 netdb,
+		NumRi:                 61,
+		MinRouterInfoFraction: DefaultRouterInfoFraction,
+		RebuildInterval:       90 * time.Hour,
+		rebuildRequests:       make(chan struct{}, 1),
 	}
 	// Initialize with empty slice to prevent nil panics
 	rs.su3s.Store([][]byte{})
+	rs.selection.Store([][]string{})
 	return rs
 }
 
 // Start begins the reseed service, performing an initial SU3 cache build and
 // starting a background goroutine that periodically rebuilds the cache at
-// RebuildInterval. Returns a channel that can be closed to stop the rebuild loop.
+// RebuildInterval. Returns a channel that can be closed to stop the rebuild loop;
+// Stop closes it too and waits for the loop to exit, so use one or the other.
+// With StartupWait set, Start returns at once and the initial build happens in
+// the background once the netDb is ready.
 func (rs *ReseederImpl) Start() chan bool {
 	// No need for atomic swapper - atomic.Value handles concurrency
 
+	rs.warnSmallNumRi()
+	rs.warnNondeterministicSigner()
+
 	// init the cache
-	err := rs.rebuild()
-	if nil != err {
-		lgr.WithError(err).Error("Error during initial rebuild")
+	if rs.StartupWait <= 0 {
+		rs.initialRebuild()
 	}
 
 	ticker := time.NewTicker(rs.RebuildInterval)
 	quit := make(chan bool)
+	done := make(chan struct{})
+	rs.quit, rs.loopDone = quit, done
 	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				err := rs.rebuild()
-				if nil != err {
-					lgr.WithError(err).Error("Error during periodic rebuild")
-				}
-			case <-quit:
-				ticker.Stop()
+		defer close(done)
+		defer ticker.Stop()
+		if rs.StartupWait > 0 {
+			if !rs.waitForRouterInfos(quit) {
 				return
 			}
+			rs.initialRebuild()
 		}
+		rs.rebuildLoop(ticker.C, quit, rs.rebuild)
 	}()
 
 	return quit
 }
 
+// Stop ends the rebuild loop started by Start, closing the channel Start
+// returned, and waits for a rebuild in progress to finish or ctx to be done.
+// A rebuild still scanning the netDb is abandoned rather than waited for.
+// Bundles built so far keep being served. Calling Stop more than once, or
+// without Start, is safe.
+func (rs *ReseederImpl) Stop(ctx context.Context) error {
+	if rs.quit == nil {
+		return nil
+	}
+	rs.stopOnce.Do(func() { close(rs.quit) })
+	select {
+	case <-rs.loopDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopContext returns a context that is cancelled once Stop is called, for
+// interrupting a rebuild. The caller must call the returned cancel function.
+func (rs *ReseederImpl) stopContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	quit := rs.quit
+	select {
+	case <-quit:
+		// Already stopped: cancel now rather than racing the watcher below
+		cancel()
+		return ctx, cancel
+	default:
+	}
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// initialRebuild builds the first SU3 cache, logging rather than returning a failure.
+func (rs *ReseederImpl) initialRebuild() {
+	if err := rs.rebuild(); err != nil {
+		lgr.WithError(err).Error("Error during initial rebuild")
+	}
+}
+
+// startupPollInterval is how often waitForRouterInfos checks the netDb.
+var startupPollInterval = 5 * time.Second
+
+// waitForRouterInfos polls the netDb until it has enough usable RouterInfos
+// for a bundle or StartupWait elapses. It reports false if quit was closed
+// first, in which case no rebuild should follow.
+func (rs *ReseederImpl) waitForRouterInfos(quit chan bool) bool {
+	deadline := time.NewTimer(rs.StartupWait)
+	defer deadline.Stop()
+	poll := time.NewTicker(startupPollInterval)
+	defer poll.Stop()
+
+	lgr.WithField("startup_wait", rs.StartupWait).WithField("required", rs.NumRi).Info("Waiting for the netDb to fill before the initial rebuild")
+	for {
+		rs.rebuildMu.Lock()
+		netdb := rs.netdb
+		rs.rebuildMu.Unlock()
+		check, err := netdb.Check(rs.NumRi, rs.MinRouterInfoFraction)
+		if err == nil && check.OK() {
+			lgr.WithField("usable", check.Usable).Info("netDb is ready, starting the initial rebuild")
+			return true
+		}
+		lgr.WithError(err).WithField("usable", check.Usable).WithField("required", rs.NumRi).Debug("netDb not ready yet")
+
+		select {
+		case <-poll.C:
+		case <-deadline.C:
+			lgr.WithField("usable", check.Usable).WithField("required", rs.NumRi).Warn("Startup wait elapsed before the netDb filled, rebuilding anyway")
+			return true
+		case <-quit:
+			return false
+		}
+	}
+}
+
+// RequestRebuild asks the rebuild loop started by Start to rebuild the SU3
+// cache as soon as it is idle, for example after the netDb has been updated.
+// Requests made while a rebuild is already pending are coalesced into it, so
+// a burst of updates costs one rebuild rather than one each.
+func (rs *ReseederImpl) RequestRebuild() {
+	select {
+	case rs.rebuildRequests <- struct{}{}:
+	default:
+	}
+}
+
+// rebuildLoop runs rebuild on every tick and every RequestRebuild until quit
+// is closed. Running both from one goroutine keeps rebuilds from overlapping.
+func (rs *ReseederImpl) rebuildLoop(tick <-chan time.Time, quit chan bool, rebuild func() error) {
+	for {
+		select {
+		case <-tick:
+			if err := rebuild(); err != nil {
+				lgr.WithError(err).Error("Error during periodic rebuild")
+			}
+		case <-rs.rebuildRequests:
+			if err := rebuild(); err != nil {
+				lgr.WithError(err).Error("Error during requested rebuild")
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
 func (rs *ReseederImpl) rebuild() error {
 	// Prevent concurrent rebuilds which cause goroutine accumulation and CPU exhaustion
 	rs.rebuildMu.Lock()
 	defer rs.rebuildMu.Unlock()
+	return rs.rebuildFrom(rs.netdb)
+}
+
+// NetDbPath returns the directory the SU3 cache is currently built from.
+func (rs *ReseederImpl) NetDbPath() string {
+	rs.rebuildMu.Lock()
+	defer rs.rebuildMu.Unlock()
+	return rs.netdb.Path
+}
 
+// SwitchNetDb rebuilds the SU3 cache from the netDb at path, using the same
+// age, version and loading settings as the current one. Only if that rebuild
+// succeeds are its bundles swapped in and path kept as the source of every
+// later rebuild; otherwise the current bundles and netDb stay in place. The
+// bundles already being served are never interrupted either way.
+func (rs *ReseederImpl) SwitchNetDb(path string) (*RebuildStats, error) {
+	rs.rebuildMu.Lock()
+	defer rs.rebuildMu.Unlock()
+
+	staged := *rs.netdb
+	staged.Path = path
+	if err := rs.rebuildFrom(&staged); err != nil {
+		return nil, err
+	}
+	lgr.WithField("previous", rs.netdb.Path).WithField("netdb", path).Info("Switched to new netDb")
+	rs.netdb = &staged
+	return rs.LastRebuild(), nil
+}
+
+// rebuildFrom builds and swaps in a new SU3 cache from netdb. The caller must hold rebuildMu.
+func (rs *ReseederImpl) rebuildFrom(netdb *LocalNetDbImpl) (err error) {
 	lgr.WithField("operation", "rebuild").Debug("Rebuilding su3 cache...")
+	defer func() {
+		if err != nil {
+			metrics.rebuildFailures.Add(1)
+		} else {
+			metrics.rebuilds.Add(1)
+		}
+	}()
+	start := time.Now()
+	rs.servedDuringRebuild.Store(0)
+	rs.rebuilding.Store(true)
+	defer rs.rebuilding.Store(false)
+
+	// keep the current bundles rather than sign new ones with a bad clock
+	if err := rs.ClockCheck.signingAllowed(); err != nil {
+		return err
+	}
 
-	// get all RIs from netdb provider
-	ris, err := rs.netdb.RouterInfos()
+	// get all RIs from netdb provider, giving up if Stop is called meanwhile
+	ctx, cancel := rs.stopContext()
+	defer cancel()
+	ris, scan, err := netdb.scanRouterInfos(ctx)
 	if nil != err {
-		return fmt.Errorf("unable to get routerInfos: %s", err)
+		return fmt.Errorf("unable to get routerInfos: %w", err)
 	}
+	valid := len(ris)
 
-	// Use only 75% of routerInfos. Shuffle first to avoid deterministic
-	// exclusion of the same routers every rebuild (filepath.Walk returns
-	// files in lexicographic order, so without shuffling the first 25% by
-	// sorted filename are always dropped).
-	// Use crypto/rand for secure seeding to avoid global mutex contention
+	// Use only the freshest MinRouterInfoFraction of routerInfos, so the ones
+	// left out are always those most likely to describe routers that have
+	// gone away, whatever order the walk returned them in
+	ris = keepFreshest(ris, rs.MinRouterInfoFraction)
+	// Use crypto/rand for secure seeding to avoid global mutex contention,
+	// unless the bundles must come out the same from the same pool
 	rng := newSecureRand()
-	rng.Shuffle(len(ris), func(i, j int) { ris[i], ris[j] = ris[j], ris[i] })
-	ris = ris[len(ris)/4:]
+	built := time.Now()
+	if rs.Deterministic {
+		rng = deterministicRand(ris, rs.DeterministicSeed)
+		built = newestModTime(ris)
+	}
 
 	// fail if we don't have enough RIs to make a single reseed file
 	if rs.NumRi > len(ris) {
 		return fmt.Errorf("not enough routerInfos - have: %d, need: %d", len(ris), rs.NumRi)
 	}
+	rs.warnThinPool(len(ris))
 
 	// build a pipeline ris -> seeds -> su3
 	// Pass thread-local RNG to avoid global mutex contention on math/rand
 	seedsChan := rs.seedsProducer(ris, rng)
-	// fan-in multiple builders
-	su3Chan := fanIn(rs.su3Builder(seedsChan), rs.su3Builder(seedsChan), rs.su3Builder(seedsChan))
+	// fan-in multiple builders, all stamping their bundles with the same
+	// version so the set is consistent however the work is spread
+	su3Chan := fanIn(rs.su3Builder(seedsChan, built), rs.su3Builder(seedsChan, built), rs.su3Builder(seedsChan, built))
 
 	// read from su3 chan and append to su3s slice
 	var newSu3s [][]byte
+	var newSelection [][]string
 	for gs := range su3Chan {
-		data, err := gs.MarshalBinary()
+		data, err := gs.file.MarshalBinary()
 		if nil != err {
 			return fmt.Errorf("error marshaling gs: %s", err)
 		}
 
 		newSu3s = append(newSu3s, data)
+		newSelection = append(newSelection, gs.names)
+	}
+	if rs.Deterministic {
+		sortBundles(newSu3s, newSelection)
 	}
 
 	// use this new set of su3s
-	rs.su3s.Store(newSu3s)
+	swapStart := time.Now()
+	rs.publish(newSu3s, newSelection)
+	swapped := time.Now()
+	stats := &RebuildStats{
+		Time:                swapped,
+		RebuildSeconds:      swapStart.Sub(start).Seconds(),
+		SwapSeconds:         swapped.Sub(swapStart).Seconds(),
+		ServedDuringRebuild: rs.servedDuringRebuild.Load(),
+		Bundles:             len(newSu3s),
+		Scanned:             scan.scanned,
+		Skipped:             scan.skipped,
+		Valid:               valid,
+		Discarded:           valid - len(ris),
+	}
+	stats.summarizeBundles(newSu3s, newSelection)
+	rs.lastRebuild.Store(stats)
+	lgr.WithField("operation", "rebuild").
+		WithField("rebuild_duration", swapStart.Sub(start)).
+		WithField("swap_duration", swapped.Sub(swapStart)).
+		WithField("served_during_rebuild", stats.ServedDuringRebuild).
+		WithField("bundles", stats.Bundles).
+		Info("Swapped su3 cache")
+
+	if rs.AuditLog != "" {
+		if err := writeAuditLog(rs.AuditLog, newSelection); err != nil {
+			lgr.WithError(err).WithField("audit_log", rs.AuditLog).Error("Failed to write rebuild audit log")
+		}
+	}
 
+	lgr.WithField("operation", "rebuild").
+		WithField("scanned", stats.Scanned).
+		WithField("skipped_unreadable", stats.Skipped.Unreadable).
+		WithField("skipped_too_old", stats.Skipped.TooOld).
+		WithField("skipped_version", stats.Skipped.Version).
+		WithField("skipped_capabilities", stats.Skipped.Capabilities).
+		WithField("valid", stats.Valid).
+		WithField("discarded", stats.Discarded).
+		WithField("unique_routers", stats.UniqueRouters).
+		WithField("bundles", stats.Bundles).
+		WithField("bundle_bytes", stats.BundleBytes).
+		Info("Rebuild summary")
 	lgr.WithField("operation", "rebuild").Debug("Done rebuilding.")
 
 	return nil
 }
 
-func (rs *ReseederImpl) seedsProducer(ris []routerInfo, rng *rand2.Rand) <-chan []routerInfo {
+func (rs *ReseederImpl) seedsProducer(ris []RouterInfo, rng *rand2.Rand) <-chan []RouterInfo {
 	lenRis := len(ris)
 
 	// if NumSu3 is not specified, then we determine the "best" number based on the number of RIs
@@ -195,25 +583,19 @@ func (rs *ReseederImpl) seedsProducer(ris []routerInfo, rng *rand2.Rand) <-chan
 
 	lgr.WithField("su3_count", numSu3s).WithField("routerinfos_per_su3", rs.NumRi).WithField("total_routerinfos", lenRis).Debug("Building su3 files")
 
-	out := make(chan []routerInfo)
+	out := make(chan []RouterInfo)
 
 	go func() {
-		// Pre-allocate index array; reused across iterations to reduce allocation.
-		// Partial Fisher-Yates shuffle selects only NumRi elements per iteration,
-		// reducing random number calls from O(n) to O(NumRi) per SU3 file.
-		indices := make([]int, lenRis)
-		for i := 0; i < numSu3s; i++ {
-			// Reset index array for uniform selection
-			for k := range indices {
-				indices[k] = k
-			}
-			// Partial Fisher-Yates: shuffle only first NumRi positions
-			seeds := make([]routerInfo, rs.NumRi)
-			for z := 0; z < rs.NumRi; z++ {
-				// Use thread-local RNG to avoid global mutex contention
-				j := z + rng.Intn(lenRis-z)
-				indices[z], indices[j] = indices[j], indices[z]
-				seeds[z] = ris[indices[z]]
+		selections := rs.bundleSelector(rng).SelectBundles(ris, numSu3s, rs.NumRi)
+		var selected []RouterInfo
+		for _, seeds := range selections {
+			selected = append(selected, seeds...)
+		}
+		lgr.WithField("pool_transports", formatDistribution(transportDistribution(ris))).WithField("bundle_transports", formatDistribution(transportDistribution(selected))).Info("Router transport distribution for this rebuild")
+
+		for _, seeds := range selections {
+			if len(seeds) == 0 {
+				continue
 			}
 			out <- seeds
 		}
@@ -223,6 +605,20 @@ func (rs *ReseederImpl) seedsProducer(ris []routerInfo, rng *rand2.Rand) <-chan
 	return out
 }
 
+// bundleSelector returns the configured Selector, or a RandomSelector drawing
+// from rng when none is set. A TransportSelector without its own Rand also
+// draws from rng, so Deterministic rebuilds stay reproducible with it.
+func (rs *ReseederImpl) bundleSelector(rng *rand2.Rand) BundleSelector {
+	if ts, ok := rs.Selector.(TransportSelector); ok && ts.Rand == nil {
+		ts.Rand = rng
+		return ts
+	}
+	if rs.Selector != nil {
+		return rs.Selector
+	}
+	return RandomSelector{Rand: rng}
+}
+
 // newSecureRand creates a new thread-local random number generator seeded with
 // cryptographically secure randomness. This avoids contention on the global
 // math/rand mutex which causes CPU exhaustion when multiple rebuild goroutines
@@ -239,17 +635,21 @@ func newSecureRand() *rand2.Rand {
 	return rand2.New(rand2.NewSource(seed))
 }
 
-func (rs *ReseederImpl) su3Builder(in <-chan []routerInfo) <-chan *su3.File {
-	out := make(chan *su3.File)
+func (rs *ReseederImpl) su3Builder(in <-chan []RouterInfo, built time.Time) <-chan *builtSu3 {
+	out := make(chan *builtSu3)
 	go func() {
 		for seeds := range in {
-			gs, err := rs.createSu3(seeds)
+			gs, err := rs.createSu3(seeds, built)
 			if nil != err {
 				lgr.WithError(err).Error("Error creating su3 file")
 				continue
 			}
 
-			out <- gs
+			names := make([]string, len(seeds))
+			for i, seed := range seeds {
+				names[i] = seed.Name
+			}
+			out <- &builtSu3{file: gs, names: names}
 		}
 		close(out)
 	}()
@@ -260,26 +660,100 @@ func (rs *ReseederImpl) su3Builder(in <-chan []routerInfo) <-chan *su3.File {
 // the peer's hash. This ensures the same peer consistently receives the same
 // reseed bundle within a rebuild cycle.
 func (rs *ReseederImpl) PeerSu3Bytes(peer Peer) ([]byte, error) {
-	m := rs.su3s.Load().([][]byte)
+	su3Bytes, err := rs.peerSu3(peer)
+	if err == nil {
+		rs.countServed()
+	}
+	return su3Bytes, err
+}
+
+// peerSu3 looks up the bundle PeerSu3Bytes would serve peer without counting it
+// as served, for answering HEAD requests.
+func (rs *ReseederImpl) peerSu3(peer Peer) ([]byte, error) {
+	return rs.peerSu3In(peer, 0, 1)
+}
+
+// peerSu3In is peerSu3 restricted to partition part of parts of the bundle set,
+// see GeoPartitions.
+func (rs *ReseederImpl) peerSu3In(peer Peer, part, parts int) ([]byte, error) {
+	m, _ := rs.su3s.Load().([][]byte)
+	m = partitionBundles(m, part, parts)
 
 	if len(m) == 0 {
 		return nil, errors.New("502: Internal service error, no reseed file available")
 	}
 
-	// Additional safety: ensure index is valid (defense in depth)
-	index := int(peer.Hash()) % len(m)
+	// Hash is a CRC-32, which is negative as an int where int is 32 bits, so
+	// take the remainder unsigned to always land inside m
+	index := uint(peer.Hash()) % uint(len(m))
+	return m[index], nil
+}
+
+// Su3Bytes returns the pre-built SU3 file at index within the current bundle set,
+// as served at the numbered bundle URLs.
+func (rs *ReseederImpl) Su3Bytes(index int) ([]byte, error) {
+	su3Bytes, err := rs.su3At(index)
+	if err == nil {
+		rs.countServed()
+	}
+	return su3Bytes, err
+}
+
+// su3At looks up the bundle at index without counting it as served.
+func (rs *ReseederImpl) su3At(index int) ([]byte, error) {
+	m, _ := rs.su3s.Load().([][]byte)
 	if index < 0 || index >= len(m) {
 		return nil, errors.New("404: Reseed file not found")
 	}
-
 	return m[index], nil
 }
 
-func (rs *ReseederImpl) createSu3(seeds []routerInfo) (*su3.File, error) {
+// BundleCount returns the number of SU3 bundles currently available for serving.
+func (rs *ReseederImpl) BundleCount() int {
+	m, _ := rs.su3s.Load().([][]byte)
+	return len(m)
+}
+
+// LastBundleSelection returns the RouterInfo filenames included in each bundle of
+// the current SU3 set, indexed the same way as the bundles served by PeerSu3Bytes.
+func (rs *ReseederImpl) LastBundleSelection() [][]string {
+	selection, _ := rs.selection.Load().([][]string)
+	return selection
+}
+
+// writeAuditLog appends one JSON record describing the bundle selection of a
+// rebuild to the file at path, creating it if necessary.
+func writeAuditLog(path string, selection [][]string) error {
+	record := rebuildAudit{Time: time.Now().UTC(), Bundles: make([]bundleAudit, len(selection))}
+	for i, names := range selection {
+		record.Bundles[i] = bundleAudit{Index: i, RouterInfos: names}
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// createSu3 zips and signs seeds into a reseed su3 versioned with built, the
+// time of the rebuild it belongs to.
+func (rs *ReseederImpl) createSu3(seeds []RouterInfo, built time.Time) (*su3.File, error) {
 	su3File := su3.New()
+	su3File.Version = su3.VersionAt(built)
 	su3File.FileType = su3.FileTypeZIP
 	su3File.ContentType = su3.ContentTypeReseed
 
+	if rs.FloodfillFirst {
+		seeds = floodfillsFirst(seeds)
+	}
 	zipped, err := zipSeeds(seeds)
 	if nil != err {
 		return nil, err
@@ -287,16 +761,41 @@ func (rs *ReseederImpl) createSu3(seeds []routerInfo) (*su3.File, error) {
 	su3File.Content = zipped
 
 	su3File.SignerID = rs.SignerID
-	if err := su3File.Sign(rs.SigningKey); err != nil {
+	if rs.RSAPSS {
+		su3File.SignatureType = su3.SigTypeRSAPSSWithSHA512
+	}
+	if err := su3File.SignWith(rs.SigningKey); err != nil {
 		return nil, fmt.Errorf("error signing su3 file: %w", err)
 	}
+	if rs.VerifyCert != nil {
+		if err := su3File.VerifySignature(rs.VerifyCert); err != nil {
+			return nil, fmt.Errorf("su3 file failed signature verification after signing: %w", err)
+		}
+	}
 
 	return su3File, nil
 }
 
+// floodfillsFirst returns a copy of seeds with the floodfill routers moved to
+// the front, keeping the random selection order within each group.
+func floodfillsFirst(seeds []RouterInfo) []RouterInfo {
+	ordered := make([]RouterInfo, 0, len(seeds))
+	for _, seed := range seeds {
+		if seed.Floodfill {
+			ordered = append(ordered, seed)
+		}
+	}
+	for _, seed := range seeds {
+		if !seed.Floodfill {
+			ordered = append(ordered, seed)
+		}
+	}
+	return ordered
+}
+
 /*type NetDbProvider interface {
 	// Get all router infos
-	RouterInfos() ([]routerInfo, error)
+	RouterInfos() ([]RouterInfo, error)
 }*/
 
 // LocalNetDbImpl provides access to the local I2P router information database.
@@ -308,6 +807,32 @@ type LocalNetDbImpl struct {
 	Path string
 	// MaxRouterInfoAge defines the maximum age for including router info in reseeds
 	MaxRouterInfoAge time.Duration
+	// LazyData keeps only each RouterInfo's name, identity and transports in
+	// memory after parsing it; the file is read again for every bundle it is
+	// placed in. This bounds the resident set to one file at a time during a
+	// scan at the cost of roughly NumRi*NumSu3 extra reads per rebuild, which
+	// the page cache usually absorbs. A file removed or replaced between the
+	// scan and the build fails that bundle or carries the newer RouterInfo.
+	LazyData bool
+	// MinRouterVersion, when set, additionally rejects RouterInfos advertising
+	// an older router.version, on top of the built-in GoodVersion check
+	MinRouterVersion string
+	// MaxFiles, when positive, stops a scan after this many routerInfo files,
+	// bounding the memory a huge netDb can take. The walk visits files in
+	// lexical order, so the files left out are always the same ones; an
+	// archive is read in the order of its entries instead.
+	MaxFiles int
+}
+
+// scanFull reports whether a scan that has collected count routerInfo files
+// has reached MaxFiles.
+func (db *LocalNetDbImpl) scanFull(count int) bool {
+	return db.MaxFiles > 0 && count >= db.MaxFiles
+}
+
+// warnScanCapped logs that a scan stopped at MaxFiles.
+func (db *LocalNetDbImpl) warnScanCapped() {
+	lgr.WithField("netdb", db.Path).WithField("max_files", db.MaxFiles).Warn("netDb has more routerInfo files than the scan limit, ignoring the rest")
 }
 
 // NewLocalNetDb creates a new local router database instance with specified parameters.
@@ -320,13 +845,41 @@ func NewLocalNetDb(path string, maxAge time.Duration) *LocalNetDbImpl {
 	}
 }
 
-// routerInfoRegex matches valid I2P routerInfo filenames. Compiled once at
+// routerInfoRegex matches valid I2P RouterInfo filenames. Compiled once at
 // package level for performance and correctness (avoids discarding compile error).
 var routerInfoRegex = regexp.MustCompile(`^routerInfo-[A-Za-z0-9-=~]+\.dat$`)
 
-func (db *LocalNetDbImpl) RouterInfos() (routerInfos []routerInfo, err error) {
+func (db *LocalNetDbImpl) RouterInfos() (routerInfos []RouterInfo, err error) {
+	return db.RouterInfosContext(context.Background())
+}
+
+// RouterInfosContext is RouterInfos, abandoning the scan with ctx's error once
+// ctx is done.
+func (db *LocalNetDbImpl) RouterInfosContext(ctx context.Context) (routerInfos []RouterInfo, err error) {
+	routerInfos, _, err = db.scanRouterInfos(ctx)
+	return routerInfos, err
+}
+
+// netDbScan counts what scanRouterInfos found before and during filtering.
+type netDbScan struct {
+	// scanned is the number of routerInfo files found
+	scanned int
+	// skipped breaks down the files the filters left out
+	skipped RouterInfoSkips
+}
+
+// scanRouterInfos reads the netDb like RouterInfosContext, additionally returning
+// how many routerInfo files were found and why those left out were skipped.
+func (db *LocalNetDbImpl) scanRouterInfos(ctx context.Context) (routerInfos []RouterInfo, scan netDbScan, err error) {
+	if isNetDbArchive(db.Path) {
+		return db.scanArchive(ctx)
+	}
 	files := make(map[string]os.FileInfo)
+	capped := false
 	walkpath := func(path string, f os.FileInfo, walkErr error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		// Per filepath.Walk contract, f may be nil when walkErr is non-nil
 		if walkErr != nil {
 			// If the root path is inaccessible, stop the walk entirely
@@ -337,60 +890,201 @@ func (db *LocalNetDbImpl) RouterInfos() (routerInfos []routerInfo, err error) {
 			return nil // continue walking other entries
 		}
 		if routerInfoRegex.MatchString(f.Name()) {
+			if db.scanFull(len(files)) {
+				capped = true
+				return filepath.SkipAll
+			}
 			files[path] = f
 		}
 		return nil
 	}
 
 	if walkErr := filepath.Walk(db.Path, walkpath); walkErr != nil {
-		return nil, fmt.Errorf("error walking netDb path %q: %w", db.Path, walkErr)
+		return nil, netDbScan{}, fmt.Errorf("error walking netDb path %q: %w", db.Path, walkErr)
+	}
+	if capped {
+		db.warnScanCapped()
 	}
+	scan.scanned = len(files)
 
-	for path, file := range files {
-		riBytes, err := os.ReadFile(path)
-		if nil != err {
-			lgr.WithError(err).WithField("path", path).Error("Error reading RouterInfo file")
-			continue
-		}
+	routerInfos, scan.skipped, err = db.loadRouterInfos(ctx, files, runtime.GOMAXPROCS(0))
+	return routerInfos, scan, err
+}
 
-		// ignore outdate routerInfos
-		age := time.Since(file.ModTime())
-		if age > db.MaxRouterInfoAge {
-			continue
-		}
-		riStruct, remainder, err := router_info.ReadRouterInfo(riBytes)
-		if err != nil {
-			lgr.WithError(err).WithField("path", path).Error("RouterInfo Parsing Error")
-			lgr.WithField("path", path).WithField("remainder", remainder).Debug("Leftover Data(for debugging)")
-			continue
+// loadRouterInfos reads, parses and filters files on a pool of workers. The
+// results are in path order whatever the number of workers, so a given netDb
+// always yields the same slice.
+func (db *LocalNetDbImpl) loadRouterInfos(ctx context.Context, files map[string]os.FileInfo, workers int) ([]RouterInfo, RouterInfoSkips, error) {
+	paths := slices.Sorted(maps.Keys(files))
+	results := make([]RouterInfo, len(paths))
+	reasons := make([]skipReason, len(paths))
+
+	// Each worker claims the next unread index, so no result needs a lock
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range max(1, min(workers, len(paths))) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(paths) || ctx.Err() != nil {
+					return
+				}
+				results[i], reasons[i] = db.loadRouterInfo(paths[i], files[paths[i]])
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, RouterInfoSkips{}, err
+	}
+
+	var routerInfos []RouterInfo
+	var skipped RouterInfoSkips
+	for i, ri := range results {
+		if reasons[i] == notSkipped {
+			routerInfos = append(routerInfos, ri)
+		} else {
+			skipped.count(reasons[i])
 		}
+	}
+	return routerInfos, skipped, nil
+}
 
-		// skip crappy routerInfos (temporarily bypass GoodVersion check)
-		// TEMPORARY: Accept all reachable routers regardless of version
-		gv, err := riStruct.GoodVersion()
-		if err != nil {
-			lgr.WithError(err).WithField("path", path).Error("RouterInfo GoodVersion Error")
+// loadRouterInfo reads and parses the routerInfo file at path, reporting why
+// it was skipped if it is unreadable, too old, unparseable or not useful in a
+// bundle.
+func (db *LocalNetDbImpl) loadRouterInfo(path string, file os.FileInfo) (RouterInfo, skipReason) {
+	riBytes, err := os.ReadFile(path)
+	if nil != err {
+		lgr.WithError(err).WithField("path", path).Error("Error reading RouterInfo file")
+		return RouterInfo{}, skipUnreadable
+	}
+
+	// ignore outdate routerInfos
+	age := time.Since(file.ModTime())
+	if age > db.MaxRouterInfoAge {
+		return RouterInfo{}, skipTooOld
+	}
+	ri, reason := db.parseRouterInfo(path, riBytes, !db.LazyData)
+	if reason == notSkipped {
+		ri.Name, ri.ModTime, ri.Path = file.Name(), file.ModTime(), path
+	}
+	return ri, reason
+}
+
+// parseRouterInfo parses riBytes, read from path, and applies the version and
+// capability filters, reporting why it was skipped if it fails them. The name,
+// modification time and path of the result are left to the caller; its Data
+// and RI are only set with keepData.
+func (db *LocalNetDbImpl) parseRouterInfo(path string, riBytes []byte, keepData bool) (RouterInfo, skipReason) {
+	riStruct, remainder, err := router_info.ReadRouterInfo(riBytes)
+	if err != nil {
+		lgr.WithError(err).WithField("path", path).Error("RouterInfo Parsing Error")
+		lgr.WithField("path", path).WithField("remainder", remainder).Debug("Leftover Data(for debugging)")
+		return RouterInfo{}, skipUnreadable
+	}
+
+	// skip routers outside the supported 0.9.x range; GoodVersion reports
+	// false with an error saying why, which is routine for old routers
+	if gv, err := riStruct.GoodVersion(); !gv {
+		lgr.WithError(err).WithField("path", path).WithField("version", riStruct.RouterVersion()).Debug("Skipped RouterInfo with unsupported version")
+		return RouterInfo{}, skipVersion
+	}
+	if db.MinRouterVersion != "" && !routerVersionAtLeast(riStruct.RouterVersion(), db.MinRouterVersion) {
+		lgr.WithField("path", path).WithField("version", riStruct.RouterVersion()).WithField("min_version", db.MinRouterVersion).Debug("Skipped RouterInfo below minimum version")
+		return RouterInfo{}, skipVersion
+	}
+	if riStruct.Reachable() && riStruct.UnCongested() {
+		var ident string
+		if hash, err := riStruct.IdentHash(); err == nil {
+			ident = string(hash[:])
 		}
-		if riStruct.Reachable() && riStruct.UnCongested() && gv {
-			routerInfos = append(routerInfos, routerInfo{
-				Name:    file.Name(),
-				ModTime: file.ModTime(),
-				Data:    riBytes,
-				RI:      &riStruct,
-			})
-		} else {
-			lgr.WithField("path", path).WithField("capabilities", riStruct.RouterCapabilities()).WithField("version", riStruct.RouterVersion()).Debug("Skipped less-useful RouterInfo")
+		ri := RouterInfo{
+			Ident:      ident,
+			Transports: routerTransports(&riStruct),
+			Floodfill:  riStruct.IsFloodfill(),
 		}
+		if keepData {
+			ri.Data = riBytes
+			ri.RI = &riStruct
+		}
+		return ri, notSkipped
 	}
+	lgr.WithField("path", path).WithField("capabilities", riStruct.RouterCapabilities()).WithField("version", riStruct.RouterVersion()).Debug("Skipped less-useful RouterInfo")
+	return RouterInfo{}, skipCapabilities
+}
 
-	return routerInfos, err
+// DefaultRouterInfoFraction is the share of valid routerInfos a rebuild keeps
+// when MinRouterInfoFraction is unset.
+const DefaultRouterInfoFraction = 0.75
+
+// rebuildDiscard returns how many of valid routerInfos a rebuild leaves out
+// when it keeps fraction of them, rounding in favour of keeping. A fraction
+// outside (0, 1] means DefaultRouterInfoFraction.
+func rebuildDiscard(valid int, fraction float64) int {
+	if fraction <= 0 || fraction > 1 {
+		fraction = DefaultRouterInfoFraction
+	}
+	// the epsilon stops 1-fraction landing just under a whole number of
+	// routers, which would keep one more than asked for
+	return int(math.Floor(float64(valid)*(1-fraction) + 1e-9))
+}
+
+// keepFreshest sorts ris newest first and drops the rebuildDiscard oldest.
+// Routers with the same modification time are ordered by name, so the same
+// netDb always yields the same pool.
+func keepFreshest(ris []RouterInfo, fraction float64) []RouterInfo {
+	slices.SortFunc(ris, func(a, b RouterInfo) int {
+		if c := b.ModTime.Compare(a.ModTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return ris[:len(ris)-rebuildDiscard(len(ris), fraction)]
+}
+
+// NetDbCheck reports whether a netDb would yield a servable bundle, using the
+// same filters as a rebuild.
+type NetDbCheck struct {
+	// Scanned is the number of routerInfo files found
+	Scanned int
+	// Valid is the number left after age, parsing and quality filtering
+	Valid int
+	// Usable is the number left after the freshest-fraction slice a rebuild
+	// applies
+	Usable int
+	// Required is the number of routerInfos one bundle needs
+	Required int
+}
+
+// OK reports whether enough routerInfos are usable for at least one bundle.
+func (check NetDbCheck) OK() bool {
+	return check.Usable >= check.Required
+}
+
+// Check runs the netDb through the filters and minimum checks of a rebuild
+// that keeps fraction of the valid routerInfos, without building anything,
+// for monitoring that a bundle could be served.
+func (db *LocalNetDbImpl) Check(numRi int, fraction float64) (NetDbCheck, error) {
+	ris, scan, err := db.scanRouterInfos(context.Background())
+	if err != nil {
+		return NetDbCheck{}, err
+	}
+	return NetDbCheck{
+		Scanned:  scan.scanned,
+		Valid:    len(ris),
+		Usable:   len(ris) - rebuildDiscard(len(ris), fraction),
+		Required: numRi,
+	}, nil
 }
 
 // fanIn multiplexes multiple SU3 file channels into a single output channel.
 // This function implements the fan-in concurrency pattern to efficiently merge
 // multiple concurrent SU3 file generation streams for balanced load distribution.
-func fanIn(inputs ...<-chan *su3.File) <-chan *su3.File {
-	out := make(chan *su3.File, len(inputs))
+func fanIn(inputs ...<-chan *builtSu3) <-chan *builtSu3 {
+	out := make(chan *builtSu3, len(inputs))
 
 	var wg sync.WaitGroup
 	wg.Add(len(inputs))
@@ -402,7 +1096,7 @@ func fanIn(inputs ...<-chan *su3.File) <-chan *su3.File {
 
 	// fan-in all the inputs to a single output
 	for _, input := range inputs {
-		go func(in <-chan *su3.File) {
+		go func(in <-chan *builtSu3) {
 			defer wg.Done()
 			for n := range in {
 				out <- n
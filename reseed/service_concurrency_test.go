@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -24,8 +25,8 @@ func TestRebuildConcurrency(t *testing.T) {
 
 	// Create some dummy routerInfo files
 	for i := 0; i < 100; i++ {
-		filename := filepath.Join(tmpDir, "routerInfo-test"+string(rune(i))+".dat")
-		// Write minimal valid routerInfo data (simplified for test)
+		filename := filepath.Join(tmpDir, "routerInfo-test"+strconv.Itoa(i)+".dat")
+		// Write minimal valid RouterInfo data (simplified for test)
 		dummyData := make([]byte, 256)
 		if _, err := rand.Read(dummyData); err != nil {
 			t.Fatalf("Failed to generate test data: %v", err)
@@ -69,7 +70,7 @@ func TestRebuildConcurrency(t *testing.T) {
 			// This should block if another rebuild is in progress
 			err := reseeder.rebuild()
 			if err != nil {
-				// Expected to fail due to invalid routerInfo data in test
+				// Expected to fail due to invalid RouterInfo data in test
 				t.Logf("Rebuild %d failed (expected): %v", id, err)
 			}
 
@@ -168,7 +169,7 @@ func BenchmarkRebuildWithMutex(b *testing.B) {
 	defer os.RemoveAll(tmpDir)
 
 	for i := 0; i < 50; i++ {
-		filename := filepath.Join(tmpDir, "routerInfo-bench"+string(rune(i))+".dat")
+		filename := filepath.Join(tmpDir, "routerInfo-bench"+strconv.Itoa(i)+".dat")
 		dummyData := make([]byte, 256)
 		rand.Read(dummyData)
 		ioutil.WriteFile(filename, dummyData, 0o644)
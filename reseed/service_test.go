@@ -1,14 +1,21 @@
 package reseed
 
 import (
+	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
 	mrand "math/rand"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -235,6 +242,18 @@ func TestSU3BoundsCheckingFix(t *testing.T) {
 		t.Error("Result not found in expected su3 cache")
 	}
 
+	// A reseeder that has never stored a bundle set must not panic either
+	if _, err := (&ReseederImpl{}).PeerSu3Bytes(peer); err == nil {
+		t.Error("Expected an error from a reseeder with no bundle set, got nil")
+	}
+
+	// Every peer maps to a bundle, whatever sign its hash has as an int
+	for i := 0; i < 1000; i++ {
+		if _, err := reseeder.PeerSu3Bytes(Peer(fmt.Sprint("peer-", i))); err != nil {
+			t.Fatalf("Peer %d: unexpected error %v", i, err)
+		}
+	}
+
 	t.Log("Bounds checking fix verified - proper access to su3 cache")
 }
 
@@ -312,10 +331,10 @@ func TestCreateSu3_SignErrorPropagation(t *testing.T) {
 		reseeder.SigningKey = key
 		reseeder.SignerID = []byte("test@mail.i2p")
 
-		seeds := []routerInfo{
+		seeds := []RouterInfo{
 			{Name: "routerInfo-test.dat", Data: []byte("test data"), ModTime: time.Now()},
 		}
-		su3File, err := reseeder.createSu3(seeds)
+		su3File, err := reseeder.createSu3(seeds, time.Now())
 		if err != nil {
 			t.Fatalf("Unexpected error with valid key: %v", err)
 		}
@@ -428,6 +447,124 @@ func TestNewReseeder_DefaultNumRi(t *testing.T) {
 	}
 }
 
+// TestRequestRebuild_Coalesces verifies that rebuild requests made while one
+// is pending collapse into a single rebuild, and that ticks and requests never
+// run rebuilds concurrently.
+func TestRequestRebuild_Coalesces(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	for i := 0; i < 5; i++ {
+		reseeder.RequestRebuild()
+	}
+
+	var running, overlapped atomic.Bool
+	rebuilds := make(chan struct{}, 10)
+	rebuild := func() error {
+		if running.Swap(true) {
+			overlapped.Store(true)
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Store(false)
+		rebuilds <- struct{}{}
+		return nil
+	}
+	tick := make(chan time.Time)
+	quit := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		reseeder.rebuildLoop(tick, quit, rebuild)
+		close(done)
+	}()
+
+	<-rebuilds
+	tick <- time.Now()
+	<-rebuilds
+	reseeder.RequestRebuild()
+	<-rebuilds
+	close(quit)
+	<-done
+
+	if got := len(rebuilds); got != 0 {
+		t.Errorf("Expected one rebuild each for the coalesced requests, the tick and the last request, got %d extra", got)
+	}
+	if overlapped.Load() {
+		t.Error("Rebuilds overlapped")
+	}
+}
+
+// TestWaitForRouterInfos verifies that the startup wait gives up on an empty
+// netDb once StartupWait elapses, and stops early when the reseeder is stopped.
+func TestWaitForRouterInfos(t *testing.T) {
+	defer func(interval time.Duration) { startupPollInterval = interval }(startupPollInterval)
+	startupPollInterval = 5 * time.Millisecond
+
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.StartupWait = 50 * time.Millisecond
+	start := time.Now()
+	if !reseeder.waitForRouterInfos(make(chan bool)) {
+		t.Error("Expected the wait to end in a rebuild once StartupWait elapsed")
+	}
+	if elapsed := time.Since(start); elapsed < reseeder.StartupWait {
+		t.Errorf("Expected to wait at least %v for an empty netDb, waited %v", reseeder.StartupWait, elapsed)
+	}
+
+	reseeder.StartupWait = time.Hour
+	quit := make(chan bool)
+	close(quit)
+	if reseeder.waitForRouterInfos(quit) {
+		t.Error("Expected a stopped reseeder to skip the initial rebuild")
+	}
+}
+
+// TestReseeder_Stop verifies that Stop ends the rebuild loop and can be
+// called again, or without Start, safely.
+func TestReseeder_Stop(t *testing.T) {
+	if err := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour)).Stop(context.Background()); err != nil {
+		t.Errorf("Stop() without Start error: %v", err)
+	}
+
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.RebuildInterval = time.Hour
+	reseeder.StartupWait = time.Hour
+	quit := reseeder.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := reseeder.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	select {
+	case <-quit:
+	default:
+		t.Error("Expected Stop to close the channel Start returned")
+	}
+	if err := reseeder.Stop(ctx); err != nil {
+		t.Errorf("Second Stop() error: %v", err)
+	}
+}
+
+func TestReseeder_StopContext(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.quit, reseeder.loopDone = make(chan bool), make(chan struct{})
+	close(reseeder.loopDone)
+
+	ctx, cancel := reseeder.stopContext()
+	defer cancel()
+	if ctx.Err() != nil {
+		t.Fatal("Expected the rebuild context to stay open until Stop")
+	}
+	if err := reseeder.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("Expected Stop to cancel the rebuild context")
+	}
+	if err := reseeder.rebuild(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a rebuild after Stop to be abandoned, got %v", err)
+	}
+}
+
 // TestSeedsProducer_ProducesCorrectCount verifies seedsProducer emits the
 // expected number of seed batches with the correct number of router infos each.
 func TestSeedsProducer_ProducesCorrectCount(t *testing.T) {
@@ -443,13 +580,13 @@ func TestSeedsProducer_ProducesCorrectCount(t *testing.T) {
 	reseeder.NumSu3 = 10
 
 	// Create mock router infos
-	ris := make([]routerInfo, 100)
+	ris := make([]RouterInfo, 100)
 	for i := range ris {
-		ris[i] = routerInfo{Name: fmt.Sprintf("routerInfo-%d.dat", i), Data: []byte("data"), ModTime: time.Now()}
+		ris[i] = RouterInfo{Name: fmt.Sprintf("routerInfo-%d.dat", i), Data: []byte("data"), ModTime: time.Now()}
 	}
 
 	ch := reseeder.seedsProducer(ris, mrand.New(mrand.NewSource(time.Now().UnixNano())))
-	var batches [][]routerInfo
+	var batches [][]RouterInfo
 	for batch := range ch {
 		batches = append(batches, batch)
 	}
@@ -478,9 +615,9 @@ func TestSeedsProducer_NoDuplicatesWithinBatch(t *testing.T) {
 	reseeder.NumRi = 20
 	reseeder.NumSu3 = 50
 
-	ris := make([]routerInfo, 200)
+	ris := make([]RouterInfo, 200)
 	for i := range ris {
-		ris[i] = routerInfo{Name: fmt.Sprintf("routerInfo-%04d.dat", i), Data: []byte("data"), ModTime: time.Now()}
+		ris[i] = RouterInfo{Name: fmt.Sprintf("routerInfo-%04d.dat", i), Data: []byte("data"), ModTime: time.Now()}
 	}
 
 	ch := reseeder.seedsProducer(ris, mrand.New(mrand.NewSource(time.Now().UnixNano())))
@@ -495,6 +632,53 @@ func TestSeedsProducer_NoDuplicatesWithinBatch(t *testing.T) {
 	}
 }
 
+// TestSeedsProducer_NoDuplicateIdentitiesWithinBatch verifies that a router
+// stored under several filenames appears at most once in each batch.
+func TestSeedsProducer_NoDuplicateIdentitiesWithinBatch(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.NumRi = 10
+	reseeder.NumSu3 = 50
+
+	// 30 files describing only 15 distinct routers
+	ris := make([]RouterInfo, 30)
+	for i := range ris {
+		ris[i] = RouterInfo{Name: fmt.Sprintf("routerInfo-%04d.dat", i), Ident: fmt.Sprintf("ident-%d", i/2), Data: []byte("data"), ModTime: time.Now()}
+	}
+
+	ch := reseeder.seedsProducer(ris, mrand.New(mrand.NewSource(time.Now().UnixNano())))
+	for batch := range ch {
+		if len(batch) != reseeder.NumRi {
+			t.Errorf("Expected %d router infos, got %d", reseeder.NumRi, len(batch))
+		}
+		seen := make(map[string]bool, len(batch))
+		for _, ri := range batch {
+			if seen[ri.Ident] {
+				t.Fatalf("Router identity %q appears twice in batch", ri.Ident)
+			}
+			seen[ri.Ident] = true
+		}
+	}
+}
+
+// TestSeedsProducer_FewerIdentitiesThanNumRi verifies that a bundle is capped at
+// the number of distinct routers available rather than padded with duplicates.
+func TestSeedsProducer_FewerIdentitiesThanNumRi(t *testing.T) {
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.NumRi = 10
+	reseeder.NumSu3 = 5
+
+	ris := make([]RouterInfo, 12)
+	for i := range ris {
+		ris[i] = RouterInfo{Name: fmt.Sprintf("routerInfo-%04d.dat", i), Ident: fmt.Sprintf("ident-%d", i%4), Data: []byte("data"), ModTime: time.Now()}
+	}
+
+	for batch := range reseeder.seedsProducer(ris, mrand.New(mrand.NewSource(1))) {
+		if len(batch) != 4 {
+			t.Errorf("Expected 4 distinct router infos, got %d", len(batch))
+		}
+	}
+}
+
 // TestSeedsProducer_UniformDistribution verifies that the partial Fisher-Yates
 // shuffle produces a roughly uniform distribution across all routers, not
 // systematically favoring or excluding any subset.
@@ -511,9 +695,9 @@ func TestSeedsProducer_UniformDistribution(t *testing.T) {
 	reseeder.NumSu3 = 500
 
 	const numRouters = 50
-	ris := make([]routerInfo, numRouters)
+	ris := make([]RouterInfo, numRouters)
 	for i := range ris {
-		ris[i] = routerInfo{Name: fmt.Sprintf("routerInfo-%04d.dat", i), Data: []byte("data"), ModTime: time.Now()}
+		ris[i] = RouterInfo{Name: fmt.Sprintf("routerInfo-%04d.dat", i), Data: []byte("data"), ModTime: time.Now()}
 	}
 
 	// Count how many times each router appears across all batches
@@ -540,88 +724,27 @@ func TestSeedsProducer_UniformDistribution(t *testing.T) {
 	}
 }
 
-// TestRebuild_ShufflesBeforeSlicing verifies that rebuild() randomizes router
-// exclusion instead of deterministically dropping the first 25% by filename.
-func TestRebuild_ShufflesBeforeSlicing(t *testing.T) {
-	// We test this indirectly: if the same set of routers were always excluded
-	// (deterministic), two consecutive rebuild calls would drop the same routers.
-	// With shuffling, the excluded set should differ between calls.
-
-	tempDir, err := os.MkdirTemp("", "netdb_shuffle")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	// Create enough router info files with predictable names
-	const numRouters = 40
+// TestKeepFreshest verifies that the rebuild's 75% slice always drops the
+// least recently modified routers, whatever order the netDb walk returned.
+func TestKeepFreshest(t *testing.T) {
 	now := time.Now()
-	for i := 0; i < numRouters; i++ {
-		name := fmt.Sprintf("routerInfo-AAAA%04d.dat", i)
-		fpath := filepath.Join(tempDir, name)
-		if err := os.WriteFile(fpath, []byte("dummy"), 0o644); err != nil {
-			t.Fatalf("Failed to create file: %v", err)
-		}
-		if err := os.Chtimes(fpath, now, now); err != nil {
-			t.Fatalf("Failed to set mtime: %v", err)
-		}
+	var ris []RouterInfo
+	for i := 0; i < 8; i++ {
+		ris = append(ris, RouterInfo{Name: fmt.Sprintf("routerInfo-%d.dat", i), ModTime: now.Add(-time.Duration(i) * time.Hour)})
 	}
+	// Two routers share the oldest kept time; the name decides between them
+	ris[5].ModTime = ris[4].ModTime
+	mrand.New(mrand.NewSource(1)).Shuffle(len(ris), func(i, j int) { ris[i], ris[j] = ris[j], ris[i] })
 
-	netdb := NewLocalNetDb(tempDir, 72*time.Hour)
-
-	// Collect two sets of router names returned by RouterInfos() + shuffle+slice
-	// to verify they differ (the shuffle makes the excluded set non-deterministic).
-	getIncludedNames := func() map[string]bool {
-		ris, err := netdb.RouterInfos()
-		if err != nil {
-			// RouterInfos may fail to parse dummy data, but the files will
-			// still be walked. For this test, we care about the walk order.
-			t.Logf("RouterInfos returned error (expected with dummy data): %v", err)
-		}
-		// Simulate what rebuild() does: shuffle then drop first 25%
-		mrand.Shuffle(len(ris), func(i, j int) { ris[i], ris[j] = ris[j], ris[i] })
-		if len(ris) > 0 {
-			ris = ris[len(ris)/4:]
-		}
-		names := make(map[string]bool, len(ris))
-		for _, ri := range ris {
-			names[ri.Name] = true
-		}
-		return names
+	kept := keepFreshest(ris, DefaultRouterInfoFraction)
+	if len(kept) != 6 {
+		t.Fatalf("Expected 6 of 8 routers kept, got %d", len(kept))
 	}
-
-	set1 := getIncludedNames()
-	set2 := getIncludedNames()
-
-	// If both sets are empty (because dummy data fails parsing), skip
-	if len(set1) == 0 && len(set2) == 0 {
-		t.Skip("RouterInfos returned empty sets (dummy data not parseable); shuffle test not applicable")
-	}
-
-	// With shuffling, the two sets should differ at least sometimes.
-	// Run multiple trials to reduce flakiness.
-	allIdentical := true
-	for trial := 0; trial < 10; trial++ {
-		s1 := getIncludedNames()
-		s2 := getIncludedNames()
-		if len(s1) != len(s2) {
-			allIdentical = false
-			break
-		}
-		for name := range s1 {
-			if !s2[name] {
-				allIdentical = false
-				break
-			}
-		}
-		if !allIdentical {
-			break
+	for i, ri := range kept {
+		if want := fmt.Sprintf("routerInfo-%d.dat", i); ri.Name != want {
+			t.Errorf("Position %d: expected %s, got %s", i, want, ri.Name)
 		}
 	}
-
-	if allIdentical && len(set1) > 0 {
-		t.Error("Shuffle did not produce different excluded sets across 10 trials; selection may still be deterministic")
-	}
 }
 
 // TestSeedsProducer_AutomaticSu3Count verifies that the automatic SU3 count
@@ -652,9 +775,9 @@ func TestSeedsProducer_AutomaticSu3Count(t *testing.T) {
 			reseeder.NumRi = 5  // small to avoid needing real data
 			reseeder.NumSu3 = 0 // auto mode
 
-			ris := make([]routerInfo, tc.numRouters)
+			ris := make([]RouterInfo, tc.numRouters)
 			for i := range ris {
-				ris[i] = routerInfo{Name: fmt.Sprintf("ri-%d.dat", i), Data: []byte("d"), ModTime: time.Now()}
+				ris[i] = RouterInfo{Name: fmt.Sprintf("ri-%d.dat", i), Data: []byte("d"), ModTime: time.Now()}
 			}
 
 			ch := reseeder.seedsProducer(ris, mrand.New(mrand.NewSource(time.Now().UnixNano())))
@@ -668,3 +791,237 @@ func TestSeedsProducer_AutomaticSu3Count(t *testing.T) {
 		})
 	}
 }
+
+// TestSu3Builder_RecordsSelection verifies that each built bundle carries the
+// names of the RouterInfos it was assembled from.
+func TestSu3Builder_RecordsSelection(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.SigningKey = key
+	reseeder.SignerID = []byte("test@mail.i2p")
+
+	in := make(chan []RouterInfo, 1)
+	in <- []RouterInfo{
+		{Name: "routerInfo-a.dat", Data: []byte("a"), ModTime: time.Now()},
+		{Name: "routerInfo-b.dat", Data: []byte("b"), ModTime: time.Now()},
+	}
+	close(in)
+
+	var built []*builtSu3
+	for b := range reseeder.su3Builder(in, time.Now()) {
+		built = append(built, b)
+	}
+	if len(built) != 1 {
+		t.Fatalf("Expected 1 bundle, got %d", len(built))
+	}
+	if got := built[0].names; len(got) != 2 || got[0] != "routerInfo-a.dat" || got[1] != "routerInfo-b.dat" {
+		t.Errorf("Unexpected bundle selection: %v", got)
+	}
+	if len(reseeder.LastBundleSelection()) != 0 {
+		t.Error("Expected empty selection before the first rebuild")
+	}
+}
+
+// TestCreateSu3_Ed25519 verifies that an Ed25519 signing key produces
+// Ed25519ph bundles that verify against its certificate.
+func TestCreateSu3_Ed25519(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	certDER, err := su3.NewEd25519SigningCertificate("test@mail.i2p", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.SigningKey = key
+	reseeder.SignerID = []byte("test@mail.i2p")
+	reseeder.VerifyCert = cert
+
+	su3File, err := reseeder.createSu3([]RouterInfo{{Name: "routerInfo-a.dat", Data: []byte("a"), ModTime: time.Now()}}, time.Now())
+	if err != nil {
+		t.Fatalf("createSu3() error: %v", err)
+	}
+	if su3File.SignatureType != su3.SigTypeEdDSASHA512Ed25519ph {
+		t.Errorf("Expected signature type %d, got %d", su3.SigTypeEdDSASHA512Ed25519ph, su3File.SignatureType)
+	}
+}
+
+// TestCreateSu3_FloodfillFirst verifies that FloodfillFirst moves floodfills to
+// the front of the zip without reordering routers within either group.
+func TestCreateSu3_FloodfillFirst(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.SigningKey = key
+	reseeder.SignerID = []byte("test@mail.i2p")
+
+	var seeds []RouterInfo
+	for i, ff := range []bool{false, true, false, true, false} {
+		seeds = append(seeds, RouterInfo{Name: fmt.Sprintf("routerInfo-%d.dat", i), Data: []byte{byte(i)}, ModTime: time.Now(), Floodfill: ff})
+	}
+
+	zipOrder := func() []string {
+		t.Helper()
+		su3File, err := reseeder.createSu3(seeds, time.Now())
+		if err != nil {
+			t.Fatalf("createSu3() error: %v", err)
+		}
+		ris, err := uzipSeeds(su3File.Content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, ri := range ris {
+			names = append(names, ri.Name)
+		}
+		return names
+	}
+
+	if got := strings.Join(zipOrder(), ","); got != "routerInfo-0.dat,routerInfo-1.dat,routerInfo-2.dat,routerInfo-3.dat,routerInfo-4.dat" {
+		t.Errorf("Expected the selection order by default, got %s", got)
+	}
+	reseeder.FloodfillFirst = true
+	if got := strings.Join(zipOrder(), ","); got != "routerInfo-1.dat,routerInfo-3.dat,routerInfo-0.dat,routerInfo-2.dat,routerInfo-4.dat" {
+		t.Errorf("Expected floodfills first, got %s", got)
+	}
+	if seeds[0].Name != "routerInfo-0.dat" {
+		t.Error("Expected the caller's selection to be left unchanged")
+	}
+}
+
+// TestSu3Builder_SharedVersion verifies that every bundle of a rebuild carries
+// the rebuild's time as its version, whichever builder signed it.
+func TestSu3Builder_SharedVersion(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.SigningKey = key
+	reseeder.SignerID = []byte("test@mail.i2p")
+
+	in := make(chan []RouterInfo, 6)
+	for i := 0; i < 6; i++ {
+		in <- []RouterInfo{{Name: fmt.Sprintf("routerInfo-%d.dat", i), Data: []byte{byte(i)}, ModTime: time.Now()}}
+	}
+	close(in)
+
+	built := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	count := 0
+	for b := range fanIn(reseeder.su3Builder(in, built), reseeder.su3Builder(in, built)) {
+		count++
+		if got := string(b.file.Version); got != "1735689600" {
+			t.Errorf("Expected version 1735689600, got %s", got)
+		}
+	}
+	if count != 6 {
+		t.Errorf("Expected 6 bundles, got %d", count)
+	}
+}
+
+// TestWriteAuditLog_AppendsJSONLines verifies that each call appends a single
+// JSON record containing the per-bundle RouterInfo selection.
+func TestWriteAuditLog_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	selection := [][]string{{"routerInfo-a.dat"}, {"routerInfo-b.dat", "routerInfo-c.dat"}}
+
+	for i := 0; i < 2; i++ {
+		if err := writeAuditLog(path, selection); err != nil {
+			t.Fatalf("writeAuditLog failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d", len(lines))
+	}
+
+	var record rebuildAudit
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("Failed to decode audit record: %v", err)
+	}
+	if len(record.Bundles) != 2 || record.Bundles[1].Index != 1 || len(record.Bundles[1].RouterInfos) != 2 {
+		t.Errorf("Unexpected audit record: %+v", record)
+	}
+}
+
+// TestCreateSu3_VerifyOnBuild verifies that createSu3 rejects a bundle whose
+// signature does not verify against the configured certificate.
+func TestCreateSu3_VerifyOnBuild(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	certDer, err := su3.NewSigningCertificate("test@mail.i2p", key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(certDer)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	reseeder := NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	reseeder.SignerID = []byte("test@mail.i2p")
+	reseeder.VerifyCert = cert
+	seeds := []RouterInfo{{Name: "routerInfo-test.dat", Data: []byte("test data"), ModTime: time.Now()}}
+
+	reseeder.SigningKey = key
+	if _, err := reseeder.createSu3(seeds, time.Now()); err != nil {
+		t.Errorf("Expected matching key to pass verification, got: %v", err)
+	}
+
+	reseeder.SigningKey = otherKey
+	if _, err := reseeder.createSu3(seeds, time.Now()); err == nil {
+		t.Error("Expected verification failure when signing key does not match certificate")
+	}
+}
+
+func TestNetDbCheck_OK(t *testing.T) {
+	// 80 valid routerInfos leave 60 after the 75% slice a rebuild applies
+	if got := 80 - rebuildDiscard(80, DefaultRouterInfoFraction); got != 60 {
+		t.Fatalf("Expected 60 usable routerInfos, got %d", got)
+	}
+	// fractions that do not divide evenly round in favour of keeping
+	for _, tc := range []struct {
+		valid    int
+		fraction float64
+		discard  int
+	}{{10, 0.9, 1}, {10, 0.7, 3}, {7, 0.5, 3}, {5, 1, 0}, {8, 0, 2}} {
+		if got := rebuildDiscard(tc.valid, tc.fraction); got != tc.discard {
+			t.Errorf("rebuildDiscard(%d, %v) = %d, want %d", tc.valid, tc.fraction, got, tc.discard)
+		}
+	}
+	if !(NetDbCheck{Usable: 60, Required: 60}).OK() {
+		t.Error("Expected exactly enough routerInfos to pass")
+	}
+	if (NetDbCheck{Usable: 59, Required: 60}).OK() {
+		t.Error("Expected too few routerInfos to fail")
+	}
+
+	check, err := NewLocalNetDb(t.TempDir(), 72*time.Hour).Check(1, DefaultRouterInfoFraction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.OK() || check.Required != 1 {
+		t.Errorf("Expected an empty netDb to fail, got %+v", check)
+	}
+}
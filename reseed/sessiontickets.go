@@ -0,0 +1,111 @@
+package reseed
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// sessionTicketKeyHistory is how many generated session ticket keys are kept.
+// The newest encrypts new tickets; the older ones still decrypt tickets issued
+// during the previous rotation intervals.
+const sessionTicketKeyHistory = 3
+
+// LoadSessionTicketKeys reads TLS session ticket keys from path, one
+// hex-encoded 32-byte key per line. The first key encrypts new tickets and the
+// rest only decrypt, so a set of load-balanced servers reading the same file
+// can rotate by prepending a new key. Blank lines and lines starting with '#'
+// are ignored.
+func LoadSessionTicketKeys(path string) ([][32]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys [][32]byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		raw, err := hex.DecodeString(text)
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("%s:%d: session ticket keys must be 64 hex characters", path, line)
+		}
+		var key [32]byte
+		copy(key[:], raw)
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no session ticket keys", path)
+	}
+	return keys, nil
+}
+
+// startSessionTicketRotation installs the configured session ticket keys on the
+// server's TLS config. With SessionTicketKeyFile the file is loaded now and, if
+// SessionTicketRotation is set, re-read at that interval. Without a file but
+// with an interval, a fresh random key is generated at every interval instead.
+// With neither, Go's automatic ticket keys are left in place.
+func (srv *Server) startSessionTicketRotation() error {
+	interval := srv.SessionTicketRotation
+	if srv.SessionTicketKeyFile == "" && interval <= 0 {
+		return nil
+	}
+
+	var next func(current [][32]byte) ([][32]byte, error)
+	if srv.SessionTicketKeyFile != "" {
+		next = func([][32]byte) ([][32]byte, error) { return LoadSessionTicketKeys(srv.SessionTicketKeyFile) }
+	} else {
+		next = rotateGeneratedKey
+	}
+
+	keys, err := next(nil)
+	if err != nil {
+		return err
+	}
+	srv.TLSConfig.SetSessionTicketKeys(keys)
+	if interval <= 0 {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-srv.stopping:
+				return
+			case <-ticker.C:
+				rotated, err := next(keys)
+				if err != nil {
+					lgr.WithError(err).Error("Failed to rotate TLS session ticket keys, keeping the current ones")
+					continue
+				}
+				keys = rotated
+				srv.TLSConfig.SetSessionTicketKeys(keys)
+				lgr.WithField("keys", len(keys)).Debug("Rotated TLS session ticket keys")
+			}
+		}
+	}()
+	return nil
+}
+
+// rotateGeneratedKey puts a new random key in front of current, dropping keys
+// beyond sessionTicketKeyHistory.
+func rotateGeneratedKey(current [][32]byte) ([][32]byte, error) {
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, err
+	}
+	keys := append([][32]byte{key}, current...)
+	if len(keys) > sessionTicketKeyHistory {
+		keys = keys[:sessionTicketKeyHistory]
+	}
+	return keys, nil
+}
@@ -0,0 +1,75 @@
+package reseed
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTicketKeys(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tickets.keys")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSessionTicketKeys(t *testing.T) {
+	first, second := strings.Repeat("ab", 32), strings.Repeat("01", 32)
+	keys, err := LoadSessionTicketKeys(writeTicketKeys(t, "# newest first", first, "", second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0][0] != 0xab || keys[1][0] != 0x01 {
+		t.Errorf("Expected both keys in file order, got %x", keys)
+	}
+
+	for name, lines := range map[string][]string{
+		"short key":  {"abcd"},
+		"not hex":    {strings.Repeat("zz", 32)},
+		"no keys":    {"# only a comment"},
+		"empty file": {},
+	} {
+		if _, err := LoadSessionTicketKeys(writeTicketKeys(t, lines...)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRotateGeneratedKey(t *testing.T) {
+	var keys [][32]byte
+	for i := 0; i < sessionTicketKeyHistory+2; i++ {
+		rotated, err := rotateGeneratedKey(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) > 0 && rotated[1] != keys[0] {
+			t.Fatal("Expected the previous key to move behind the new one")
+		}
+		keys = rotated
+	}
+	if len(keys) != sessionTicketKeyHistory {
+		t.Errorf("Expected %d keys to be kept, got %d", sessionTicketKeyHistory, len(keys))
+	}
+}
+
+func TestStartSessionTicketRotation(t *testing.T) {
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.TLSConfig = &tls.Config{}
+	if err := srv.startSessionTicketRotation(); err != nil {
+		t.Errorf("Expected no error without configuration, got %v", err)
+	}
+
+	srv.SessionTicketKeyFile = writeTicketKeys(t, "not a key")
+	if err := srv.startSessionTicketRotation(); err == nil {
+		t.Error("Expected an invalid key file to be reported")
+	}
+
+	srv.SessionTicketKeyFile = writeTicketKeys(t, strings.Repeat("ab", 32))
+	if err := srv.startSessionTicketRotation(); err != nil {
+		t.Errorf("Expected a valid key file to be installed, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package reseed
+
+import (
+	"encoding/pem"
+	"net/http"
+)
+
+// signerCertName is the file name the signer certificate is served under,
+// next to the su3 bundle.
+const signerCertName = "reseed-cert.pem"
+
+// signerCertHandler serves the certificate of the key that signs the reseed
+// bundles, so operators can fetch it and install it in routers that do not
+// ship with it. It answers 404 unless ReseederImpl.SignerCert is set.
+func (srv *Server) signerCertHandler(w http.ResponseWriter, r *http.Request) {
+	if srv.Reseeder == nil || srv.Reseeder.SignerCert == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.Header().Set("Content-Disposition", "attachment; filename="+signerCertName)
+	w.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Reseeder.SignerCert.Raw}))
+}
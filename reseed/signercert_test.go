@@ -0,0 +1,56 @@
+package reseed
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"i2pgit.org/go-i2p/reseed-tools/su3"
+)
+
+func TestSignerCertHandler(t *testing.T) {
+	srv := NewServer("/netdb", false, "", 4, 40, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/netdb/reseed-cert.pem", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404 without a signer certificate, got %d", w.Code)
+	}
+
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certDer, err := su3.NewSigningCertificate("you@mail.i2p", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Reseeder.SignerCert, err = x509.ParseCertificate(certDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := get()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-pem-file" {
+		t.Errorf("Unexpected Content-Type %q", ct)
+	}
+	block, _ := pem.Decode(w.Body.Bytes())
+	if block == nil || block.Type != "CERTIFICATE" || string(block.Bytes) != string(certDer) {
+		t.Errorf("Expected the signer certificate as PEM, got %q", w.Body.String())
+	}
+}
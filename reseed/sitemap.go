@@ -0,0 +1,99 @@
+package reseed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// sitemapURLSet is the root element of /sitemap.xml.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Xhtml   string       `xml:"xmlns:xhtml,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is a single homepage translation together with its alternates.
+type sitemapURL struct {
+	Loc        string             `xml:"loc"`
+	Alternates []sitemapAlternate `xml:"xhtml:link"`
+}
+
+// sitemapAlternate links a sitemap entry to one of its translations.
+type sitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// languageURL returns the absolute URL of the homepage translation for lang,
+// or of the language-negotiated homepage when lang is empty.
+func (srv *Server) languageURL(lang string) string {
+	base := strings.TrimSuffix(srv.CanonicalURL, "/") + "/"
+	if lang == "" {
+		return base
+	}
+	return base + "?lang=" + lang
+}
+
+// languageAlternates lists every active translation of the homepage,
+// followed by the x-default entry for clients matching none of them.
+func (srv *Server) languageAlternates() []sitemapAlternate {
+	alternates := make([]sitemapAlternate, 0, len(activeLanguages)+1)
+	for _, tag := range activeLanguages {
+		alternates = append(alternates, sitemapAlternate{Rel: "alternate", Hreflang: tag.String(), Href: srv.languageURL(tag.String())})
+	}
+	return append(alternates, sitemapAlternate{Rel: "alternate", Hreflang: "x-default", Href: srv.languageURL("")})
+}
+
+// supportedLanguageParam returns the ?lang= value of r if it names one of the
+// active languages, or "" otherwise.
+func supportedLanguageParam(r *http.Request) string {
+	lang := r.URL.Query().Get("lang")
+	for _, tag := range activeLanguages {
+		if tag.String() == lang {
+			return lang
+		}
+	}
+	return ""
+}
+
+// pageHeader returns the document header for the homepage. When CanonicalURL is
+// set it carries a canonical link for the requested translation and hreflang
+// links to every other one, so search engines index each language separately.
+func (srv *Server) pageHeader(r *http.Request) []byte {
+	if srv.CanonicalURL == "" {
+		return header
+	}
+	var links strings.Builder
+	links.WriteString(`    <link rel="canonical" href="` + html.EscapeString(srv.languageURL(supportedLanguageParam(r))) + `">` + "\n")
+	for _, alt := range srv.languageAlternates() {
+		links.WriteString(`    <link rel="alternate" hreflang="` + alt.Hreflang + `" href="` + html.EscapeString(alt.Href) + `">` + "\n")
+	}
+	return bytes.Replace(header, []byte("  </head>"), []byte(links.String()+"  </head>"), 1)
+}
+
+// handleSitemapRequest serves /sitemap.xml listing every homepage translation.
+func (srv *Server) handleSitemapRequest(w http.ResponseWriter) {
+	alternates := srv.languageAlternates()
+	urlset := sitemapURLSet{
+		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		Xhtml: "http://www.w3.org/1999/xhtml",
+	}
+	for _, alt := range alternates[:len(alternates)-1] {
+		urlset.URLs = append(urlset.URLs, sitemapURL{Loc: alt.Href, Alternates: alternates})
+	}
+
+	out, err := xml.MarshalIndent(urlset, "", "  ")
+	if err != nil {
+		lgr.WithError(err).Error("Error encoding sitemap")
+		http.Error(w, "500 Unable to build sitemap", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+}
@@ -0,0 +1,77 @@
+package reseed
+
+import (
+	"net/http"
+	"time"
+)
+
+// slowRequest describes a request that took longer than SlowRequestThreshold.
+type slowRequest struct {
+	route     string
+	transport string
+	method    string
+	status    int
+	duration  time.Duration
+}
+
+// logSlowRequest emits the warning for a slow request. It is a variable so tests
+// can capture what would be logged.
+var logSlowRequest = func(req slowRequest) {
+	lgr.WithField("route", req.route).
+		WithField("transport", req.transport).
+		WithField("method", req.method).
+		WithField("status", req.status).
+		WithField("duration", req.duration).
+		Warn("Slow request")
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if sr.status == 0 {
+		sr.status = code
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// slowRequestMiddleware times every request and logs the ones that take longer
+// than SlowRequestThreshold, so disk or CPU contention, for example during a
+// rebuild, shows up without enabling debug logging. A zero threshold disables it.
+func (srv *Server) slowRequestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if srv.SlowRequestThreshold <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		duration := time.Since(start)
+		if duration <= srv.SlowRequestThreshold {
+			return
+		}
+
+		// The mux records the pattern it matched; numbered bundles bypass it
+		route := r.Pattern
+		if route == "" {
+			route = r.URL.Path
+		}
+		transport, _ := srv.transport.Load().(string)
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		logSlowRequest(slowRequest{route: route, transport: transport, method: r.Method, status: status, duration: duration})
+	})
+}
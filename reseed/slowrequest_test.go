@@ -0,0 +1,48 @@
+package reseed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSlowRequestMiddleware(t *testing.T) {
+	var logged []slowRequest
+	orig := logSlowRequest
+	logSlowRequest = func(req slowRequest) { logged = append(logged, req) }
+	t.Cleanup(func() { logSlowRequest = orig })
+
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.transport.Store(TransportHTTPS)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusTeapot)
+	})
+	mux.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {})
+	handler := srv.slowRequestMiddleware(mux)
+
+	serve := func(path string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	serve("/slow")
+	if len(logged) != 0 {
+		t.Fatalf("Expected nothing logged without a threshold, got %+v", logged)
+	}
+
+	srv.SlowRequestThreshold = 10 * time.Millisecond
+	serve("/fast")
+	serve("/slow")
+	if len(logged) != 1 {
+		t.Fatalf("Expected only the slow request to be logged, got %+v", logged)
+	}
+	got := logged[0]
+	if got.route != "/slow" || got.transport != TransportHTTPS || got.method != "GET" || got.status != http.StatusTeapot {
+		t.Errorf("Unexpected slow request entry %+v", got)
+	}
+	if got.duration < 20*time.Millisecond {
+		t.Errorf("Expected the measured duration, got %v", got.duration)
+	}
+}
@@ -0,0 +1,49 @@
+package reseed
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ServerStatus is the summary of the reseed service served at /status.json,
+// for dashboards that would otherwise have to scrape the homepage.
+type ServerStatus struct {
+	Version string `json:"version"`
+	// Bundles is the number of SU3 files currently cached
+	Bundles int `json:"bundles"`
+	// LastRebuild is when the current bundle set started being served, absent before the first rebuild
+	LastRebuild *time.Time `json:"last_rebuild,omitempty"`
+	// RouterInfos is the number of routerInfos that passed filtering at the last rebuild
+	RouterInfos int `json:"router_infos"`
+	// RebuildIntervalSeconds is how often the SU3 cache is rebuilt
+	RebuildIntervalSeconds float64 `json:"rebuild_interval_seconds"`
+	// BundleTTLSeconds is how long a bundle is meant to be used after it was built
+	BundleTTLSeconds float64 `json:"bundle_ttl_seconds"`
+}
+
+// Status returns the current ServerStatus of srv.
+func (srv *Server) Status() ServerStatus {
+	status := ServerStatus{Version: Version}
+	if srv.Reseeder == nil {
+		return status
+	}
+	status.Bundles = srv.Reseeder.BundleCount()
+	status.RebuildIntervalSeconds = srv.Reseeder.RebuildInterval.Seconds()
+	status.BundleTTLSeconds = srv.Reseeder.bundleTTL().Seconds()
+	if stats := srv.Reseeder.LastRebuild(); stats != nil {
+		last := stats.Time
+		status.LastRebuild = &last
+		status.RouterInfos = stats.Valid
+	}
+	return status
+}
+
+// statusHandler serves Status as JSON to any client, whatever its User-Agent.
+func (srv *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(srv.Status()); err != nil {
+		lgr.WithError(err).Error("Error writing status response")
+	}
+}
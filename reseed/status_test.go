@@ -0,0 +1,54 @@
+package reseed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStatusJSON(t *testing.T) {
+	srv := newReadyServer(t)
+	built := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv.Reseeder.su3s.Store([][]byte{[]byte("a"), []byte("b")})
+	srv.Reseeder.lastRebuild.Store(&RebuildStats{Time: built, Valid: 250, Bundles: 2})
+	srv.Reseeder.RebuildInterval = 90 * time.Minute
+
+	req := httptest.NewRequest("GET", "/status.json", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Expected a JSON 200 for a browser User-Agent, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var status ServerStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode status %q: %v", w.Body.String(), err)
+	}
+	if status.Version != Version || status.Bundles != 2 || status.RouterInfos != 250 || status.RebuildIntervalSeconds != 5400 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if status.LastRebuild == nil || !status.LastRebuild.Equal(built) {
+		t.Errorf("Expected last rebuild %v, got %v", built, status.LastRebuild)
+	}
+}
+
+func TestStatus_BeforeFirstRebuild(t *testing.T) {
+	srv := NewServer("", false, "", 4, 40, 2000)
+	if status := srv.Status(); status.Version != Version || status.Bundles != 0 || status.LastRebuild != nil {
+		t.Errorf("Expected an empty status without a reseeder, got %+v", status)
+	}
+
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	w := httptest.NewRecorder()
+	srv.statusHandler(w, httptest.NewRequest("GET", "/status.json", nil))
+	var raw map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["last_rebuild"]; ok || raw["bundles"] != float64(0) {
+		t.Errorf("Expected no last_rebuild and 0 bundles before the first rebuild, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,98 @@
+package reseed
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+)
+
+// minTLSKeyBits is the floor, in RSA-equivalent bits, under Server.MinKeyBits
+// for TLS certificates: keys weaker than 2048-bit RSA are refused even when
+// the check is disabled.
+const minTLSKeyBits = 2048
+
+// curveIDs maps the elliptic curves usable for TLS signatures to their CurveID.
+var curveIDs = map[elliptic.Curve]tls.CurveID{
+	elliptic.P256(): tls.CurveP256,
+	elliptic.P384(): tls.CurveP384,
+	elliptic.P521(): tls.CurveP521,
+}
+
+// CheckTLSCertificate applies the checks ListenAndServeTLS makes before it
+// serves cert: CheckCertificateCompatibility against config, or the default
+// TLS configuration when config is nil, and CheckKeySize against minKeyBits,
+// raised to minTLSKeyBits.
+func CheckTLSCertificate(cert *x509.Certificate, config *tls.Config, minKeyBits int) error {
+	if config == nil {
+		config = defaultTLSConfig()
+	}
+	if err := CheckCertificateCompatibility(cert, config); err != nil {
+		return err
+	}
+	if err := CheckKeySize(cert.PublicKey, max(minKeyBits, minTLSKeyBits)); err != nil {
+		return fmt.Errorf("TLS certificate %w", err)
+	}
+	return nil
+}
+
+// CheckCertificateCompatibility reports whether cert can be served with config.
+// Key types that cannot complete a handshake (DSA, P-224, or no TLS 1.2 cipher
+// suite for the key when TLS 1.2 is allowed) are returned as an error. Key
+// sizes are left to CheckKeySize. An ECDSA curve outside
+// config.CurvePreferences still works, since TLS 1.3 signatures do not depend
+// on the key exchange groups, and is only logged.
+func CheckCertificateCompatibility(cert *x509.Certificate, config *tls.Config) error {
+	var suiteKind string
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		suiteKind = "_RSA_"
+	case *ecdsa.PublicKey:
+		id, ok := curveIDs[pub.Curve]
+		if !ok {
+			return fmt.Errorf("TLS certificate uses ECDSA curve %s, which TLS cannot sign with; use P-384 or P-521", pub.Curve.Params().Name)
+		}
+		if len(config.CurvePreferences) > 0 && !containsCurve(config.CurvePreferences, id) {
+			lgr.WithField("curve", pub.Curve.Params().Name).WithField("preferred_curves", curveNames(config.CurvePreferences)).Warn("TLS certificate curve is not among the configured curve preferences; consider a P-384 certificate")
+		}
+		suiteKind = "_ECDSA_"
+	case ed25519.PublicKey:
+		// Ed25519 has no TLS 1.2 cipher suites of its own, it signs with the ECDSA ones
+		suiteKind = "_ECDSA_"
+	default:
+		return fmt.Errorf("TLS certificate uses unsupported key type %T; use an ECDSA P-384 or RSA key", cert.PublicKey)
+	}
+
+	// TLS 1.3 suites are independent of the certificate key, and CipherSuites
+	// only restricts TLS 1.2 and below
+	if config.MinVersion >= tls.VersionTLS13 || len(config.CipherSuites) == 0 {
+		return nil
+	}
+	for _, id := range config.CipherSuites {
+		if strings.Contains(tls.CipherSuiteName(id), suiteKind) {
+			return nil
+		}
+	}
+	return fmt.Errorf("TLS 1.2 is enabled but none of the configured cipher suites can be used with a %T certificate", cert.PublicKey)
+}
+
+func containsCurve(curves []tls.CurveID, id tls.CurveID) bool {
+	for _, c := range curves {
+		if c == id {
+			return true
+		}
+	}
+	return false
+}
+
+func curveNames(curves []tls.CurveID) string {
+	names := make([]string, len(curves))
+	for i, c := range curves {
+		names[i] = c.String()
+	}
+	return strings.Join(names, ", ")
+}
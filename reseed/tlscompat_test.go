@@ -0,0 +1,103 @@
+package reseed
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckCertificateCompatibility(t *testing.T) {
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p224, _ := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	rsa2048, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsa1024, _ := rsa.GenerateKey(rand.Reader, 1024)
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	serverConfig := NewServer("", false, "", 4, 40, 2000).TLSConfig
+	tls12ECDSAOnly := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384},
+	}
+
+	tests := []struct {
+		name    string
+		pub     crypto.PublicKey
+		config  *tls.Config
+		wantErr string
+	}{
+		{"P-384 with server config", &p384.PublicKey, serverConfig, ""},
+		{"P-256 outside curve preferences", &p256.PublicKey, serverConfig, ""},
+		{"RSA 2048 with TLS 1.3", &rsa2048.PublicKey, serverConfig, ""},
+		{"Ed25519", edPub, serverConfig, ""},
+		{"P-224", &p224.PublicKey, serverConfig, "P-224"},
+		{"short RSA key, left to CheckKeySize", &rsa1024.PublicKey, serverConfig, ""},
+		{"RSA without a TLS 1.2 RSA suite", &rsa2048.PublicKey, tls12ECDSAOnly, "cipher suites"},
+		{"ECDSA with a TLS 1.2 ECDSA suite", &p384.PublicKey, tls12ECDSAOnly, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCertificateCompatibility(&x509.Certificate{PublicKey: tt.pub}, tt.config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected certificate to be accepted, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestListenAndServeTLS_RejectsIncompatibleCertificate verifies the check runs at
+// startup, before the server starts listening.
+func TestListenAndServeTLS_RejectsIncompatibleCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600)
+
+	srv := NewServer("", false, "", 4, 40, 2000)
+	srv.Health = NewHealth()
+	srv.Addr = "127.0.0.1:0"
+	err = srv.ListenAndServeTLS(certFile, keyFile)
+	if err == nil || !strings.Contains(err.Error(), "P-224") {
+		t.Fatalf("Expected a P-224 compatibility error, got: %v", err)
+	}
+	if srv.ServerListener != nil {
+		t.Error("Server should not listen with an incompatible certificate")
+	}
+}
@@ -0,0 +1,34 @@
+package reseed
+
+import (
+	"net"
+
+	"github.com/go-i2p/onramp"
+)
+
+// Tunnel is the part of an onramp Garlic (I2P) or Onion (Tor) the server uses
+// to listen for connections, so tests can stand in for live I2P and Tor.
+type Tunnel interface {
+	Listen(args ...string) (net.Listener, error)
+	ListenTLS(args ...string) (net.Listener, error)
+	Close() error
+}
+
+var (
+	// newGarlic creates the SAM session behind the I2P transports.
+	newGarlic = func(tunName, samAddr string, options []string) (Tunnel, error) {
+		garlic, err := onramp.NewGarlic(tunName, samAddr, options)
+		if err != nil {
+			return nil, err
+		}
+		return garlic, nil
+	}
+	// newOnion creates the Tor instance behind the onion transports.
+	newOnion = func(name string) (Tunnel, error) {
+		onion, err := onramp.NewOnion(name)
+		if err != nil {
+			return nil, err
+		}
+		return onion, nil
+	}
+)
@@ -0,0 +1,234 @@
+package reseed
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/i2pkeys"
+)
+
+// fakeTunnel stands in for an onramp Garlic or Onion, listening on loopback TCP.
+type fakeTunnel struct {
+	addr      net.Addr // reported by the listener instead of the TCP address, if set
+	listenErr error
+
+	mu       sync.Mutex
+	tls      bool
+	closed   bool
+	listener net.Listener
+}
+
+func (f *fakeTunnel) Listen(args ...string) (net.Listener, error) { return f.listen(false) }
+
+func (f *fakeTunnel) ListenTLS(args ...string) (net.Listener, error) { return f.listen(true) }
+
+func (f *fakeTunnel) listen(tls bool) (net.Listener, error) {
+	if f.listenErr != nil {
+		return nil, f.listenErr
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.tls = tls
+	f.listener = ln
+	f.mu.Unlock()
+	return fakeAddrListener{Listener: ln, addr: f.addr}, nil
+}
+
+func (f *fakeTunnel) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+// dialAddr waits until srv reports transport as serving and returns the
+// loopback address of the tunnel's listener.
+func (f *fakeTunnel) dialAddr(t *testing.T, srv *Server, transport string) string {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := srv.Health.Status().Transports[transport]; ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the server to serve %s on the tunnel", transport)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.listener.Addr().String()
+}
+
+func (f *fakeTunnel) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// fakeAddrListener reports addr, when set, as its address.
+type fakeAddrListener struct {
+	net.Listener
+	addr net.Addr
+}
+
+func (l fakeAddrListener) Addr() net.Addr {
+	if l.addr != nil {
+		return l.addr
+	}
+	return l.Listener.Addr()
+}
+
+// stubTunnels makes newGarlic and newOnion return the given tunnels, or err.
+func stubTunnels(t *testing.T, garlic, onion Tunnel, err error) {
+	t.Helper()
+	origGarlic, origOnion := newGarlic, newOnion
+	newGarlic = func(tunName, samAddr string, options []string) (Tunnel, error) { return garlic, err }
+	newOnion = func(name string) (Tunnel, error) { return onion, err }
+	t.Cleanup(func() { newGarlic, newOnion = origGarlic, origOnion })
+}
+
+// serveAndStop checks that srv answers HTTP on addr, then shuts it down and
+// returns what the listen call returned.
+func serveAndStop(t *testing.T, srv *Server, addr string, result <-chan error) error {
+	t.Helper()
+	resp, err := http.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("Expected the server to answer on the tunnel listener: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error: %v", err)
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("Expected the listen call to return after shutdown")
+		return nil
+	}
+}
+
+func TestListenAndServeOnion_FakeTunnel(t *testing.T) {
+	for _, useTLS := range []bool{false, true} {
+		onion := &fakeTunnel{}
+		stubTunnels(t, nil, onion, nil)
+		srv := NewServer("", false, "", 100, 100, 2000)
+		srv.Health = NewHealth()
+
+		result := make(chan error, 1)
+		go func() {
+			if useTLS {
+				result <- srv.ListenAndServeOnionTLS(nil, nil, "", "")
+			} else {
+				result <- srv.ListenAndServeOnion(nil, nil)
+			}
+		}()
+		addr := onion.dialAddr(t, srv, TransportOnion)
+		if onion.tls != useTLS {
+			t.Errorf("TLS %v: expected the matching Onion listen call, got tls=%v", useTLS, onion.tls)
+		}
+		if got := srv.Address(); !strings.Contains(got, "onion:"+addr) {
+			t.Errorf("TLS %v: expected Address() to report the onion listener %s, got %s", useTLS, addr, got)
+		}
+		if ts := srv.transportStatus(TransportOnion); !ts.Up || ts.Address != addr {
+			t.Errorf("TLS %v: expected onion transport up at %s, got %+v", useTLS, addr, ts)
+		}
+
+		if err := serveAndStop(t, srv, addr, result); !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("TLS %v: expected http.ErrServerClosed, got %v", useTLS, err)
+		}
+		if !onion.isClosed() {
+			t.Errorf("TLS %v: expected Shutdown to close the Tor instance", useTLS)
+		}
+	}
+}
+
+func TestListenAndServeOnion_Errors(t *testing.T) {
+	startErr := errors.New("tor failed to start")
+	stubTunnels(t, nil, nil, startErr)
+	srv := NewServer("", false, "", 100, 100, 2000)
+	if err := srv.ListenAndServeOnion(nil, nil); !errors.Is(err, startErr) {
+		t.Errorf("Expected the Tor start error, got %v", err)
+	}
+	if err := srv.ListenAndServeOnionTLS(nil, nil, "", ""); !errors.Is(err, startErr) {
+		t.Errorf("Expected the Tor start error over TLS, got %v", err)
+	}
+
+	listenErr := errors.New("onion service not published")
+	onion := &fakeTunnel{}
+	onion.listenErr = listenErr
+	stubTunnels(t, nil, onion, nil)
+	if err := srv.ListenAndServeOnion(nil, nil); !errors.Is(err, listenErr) {
+		t.Errorf("Expected the listen error, got %v", err)
+	}
+}
+
+func TestListenAndServeI2P_FakeTunnel(t *testing.T) {
+	for _, useTLS := range []bool{false, true} {
+		garlic := &fakeTunnel{}
+		garlic.addr = i2pkeys.FiveHundredAs()
+		stubTunnels(t, garlic, nil, nil)
+		srv := NewServer("", false, "", 100, 100, 2000)
+		srv.Health = NewHealth()
+
+		samaddr := unreachableSAM(t)
+		result := make(chan error, 1)
+		go func() {
+			if useTLS {
+				result <- srv.ListenAndServeI2PTLS(samaddr, i2pkeys.I2PKeys{}, "", "")
+			} else {
+				result <- srv.ListenAndServeI2P(samaddr, i2pkeys.I2PKeys{})
+			}
+		}()
+		addr := garlic.dialAddr(t, srv, TransportI2P)
+		if garlic.tls != useTLS {
+			t.Errorf("TLS %v: expected the matching Garlic listen call, got tls=%v", useTLS, garlic.tls)
+		}
+		srv.i2pMu.Lock()
+		ln := srv.I2PListener
+		srv.i2pMu.Unlock()
+		if got, want := listenerAddress(ln), i2pkeys.FiveHundredAs().Base32(); got != want {
+			t.Errorf("TLS %v: expected the I2P address %s, got %s", useTLS, want, got)
+		}
+
+		if err := serveAndStop(t, srv, addr, result); !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("TLS %v: expected http.ErrServerClosed, got %v", useTLS, err)
+		}
+		if !garlic.isClosed() {
+			t.Errorf("TLS %v: expected Shutdown to close the SAM session", useTLS)
+		}
+	}
+}
+
+func TestServeI2PSession_Errors(t *testing.T) {
+	samErr := errors.New("SAM bridge refused the session")
+	stubTunnels(t, nil, nil, samErr)
+	srv := NewServer("", false, "", 100, 100, 2000)
+	listen := func(g Tunnel) (net.Listener, error) { return g.Listen() }
+	if err := srv.serveI2PSession(unreachableSAM(t), "i2p-http", listen); !errors.Is(err, samErr) {
+		t.Errorf("Expected the session error, got %v", err)
+	}
+
+	// A failed listen drops the session so the next attempt starts afresh
+	listenErr := errors.New("tunnel build failed")
+	garlic := &fakeTunnel{}
+	garlic.listenErr = listenErr
+	stubTunnels(t, garlic, nil, nil)
+	if err := srv.serveI2PSession(unreachableSAM(t), "i2p-http", listen); !errors.Is(err, listenErr) {
+		t.Errorf("Expected the listen error, got %v", err)
+	}
+	if !garlic.isClosed() || srv.Garlic != nil {
+		t.Error("Expected the failed session to be closed and forgotten")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 // Test for Bug #6: User Agent String Mismatch with I2P Compatibility
@@ -17,7 +18,7 @@ func TestUserAgentCompatibility(t *testing.T) {
 	})
 
 	// Wrap with our verification middleware
-	handler := verifyMiddleware(testHandler)
+	handler := (&Server{}).verifyMiddleware(testHandler)
 
 	testCases := []struct {
 		name           string
@@ -136,3 +137,35 @@ func TestStrictUserAgentValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestAllowedUserAgents(t *testing.T) {
+	srv := NewServer("", false, "", 100, 100, 2000)
+	srv.Reseeder = NewReseeder(NewLocalNetDb(t.TempDir(), 72*time.Hour))
+	srv.Reseeder.su3s.Store([][]byte{[]byte("bundle")})
+
+	get := func(userAgent string) int {
+		req := httptest.NewRequest("GET", "/i2pseeds.su3", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if get(I2pUserAgent) != http.StatusOK || get("i2pd/2.50") != http.StatusForbidden {
+		t.Error("Expected only I2pUserAgent to be served by default")
+	}
+
+	srv.AllowedUserAgents = []string{I2pUserAgent, "i2pd/2.50"}
+	if get(I2pUserAgent) != http.StatusOK || get("i2pd/2.50") != http.StatusOK {
+		t.Error("Expected every configured User-Agent to be served")
+	}
+	if get("i2pd/2.51") != http.StatusForbidden {
+		t.Error("Expected an unlisted User-Agent to be refused")
+	}
+
+	srv.AllowedUserAgents = []string{"i2pd/2.50"}
+	if get(I2pUserAgent) != http.StatusForbidden {
+		t.Error("Expected I2pUserAgent to be refused once it is left out of the list")
+	}
+}
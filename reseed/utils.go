@@ -4,11 +4,12 @@ import (
 	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/x509"
-	"crypto/x509/pkix"
 	"math/big"
 	"net"
 	"strings"
 	"time"
+
+	"i2pgit.org/go-i2p/reseed-tools/su3"
 )
 
 // SignerFilename generates a certificate filename from a signer ID string.
@@ -25,10 +26,22 @@ func NewTLSCertificate(host string, priv *ecdsa.PrivateKey) ([]byte, error) {
 	return NewTLSCertificateAltNames(priv, host)
 }
 
+// NewTLSCertificateWithSubject is NewTLSCertificate with the given organization,
+// unit and locality in the certificate subject.
+func NewTLSCertificateWithSubject(host string, priv *ecdsa.PrivateKey, subject su3.CertificateSubject) ([]byte, error) {
+	return newTLSCertificate(priv, subject, host)
+}
+
 // NewTLSCertificateAltNames creates a new TLS certificate supporting multiple hostnames.
 // Generates a 5-year validity certificate with specified hostnames as Subject Alternative Names
 // for flexible deployment across multiple domains. Uses ECDSA private key for modern cryptography.
 func NewTLSCertificateAltNames(priv *ecdsa.PrivateKey, hosts ...string) ([]byte, error) {
+	return newTLSCertificate(priv, su3.CertificateSubject{}, hosts...)
+}
+
+// newTLSCertificate is NewTLSCertificateAltNames with subject filling the
+// certificate subject around the first host.
+func newTLSCertificate(priv *ecdsa.PrivateKey, subject su3.CertificateSubject, hosts ...string) ([]byte, error) {
 	notBefore := time.Now()
 	notAfter := notBefore.Add(5 * 365 * 24 * time.Hour)
 	host := ""
@@ -44,15 +57,8 @@ func NewTLSCertificateAltNames(priv *ecdsa.PrivateKey, hosts ...string) ([]byte,
 	}
 
 	template := x509.Certificate{
-		SerialNumber: serialNumber,
-		Subject: pkix.Name{
-			Organization:       []string{"I2P Anonymous Network"},
-			OrganizationalUnit: []string{"I2P"},
-			Locality:           []string{"XX"},
-			StreetAddress:      []string{"XX"},
-			Country:            []string{"XX"},
-			CommonName:         host,
-		},
+		SerialNumber:       serialNumber,
+		Subject:            subject.Name(host),
 		NotBefore:          notBefore,
 		NotAfter:           notAfter,
 		SignatureAlgorithm: x509.ECDSAWithSHA512,
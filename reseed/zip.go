@@ -6,7 +6,7 @@ import (
 	"io"
 )
 
-func zipSeeds(seeds []routerInfo) ([]byte, error) {
+func zipSeeds(seeds []RouterInfo) ([]byte, error) {
 	// Create a buffer to write our archive to.
 	buf := new(bytes.Buffer)
 
@@ -23,7 +23,12 @@ func zipSeeds(seeds []routerInfo) ([]byte, error) {
 			return nil, err
 		}
 
-		_, err = zipFile.Write(file.Data)
+		data, err := file.content()
+		if err != nil {
+			lgr.WithError(err).WithField("file_name", file.Name).Error("Failed to read RouterInfo for zip")
+			return nil, err
+		}
+		_, err = zipFile.Write(data)
 		if err != nil {
 			lgr.WithError(err).WithField("file_name", file.Name).Error("Failed to write file data to zip")
 			return nil, err
@@ -38,7 +43,7 @@ func zipSeeds(seeds []routerInfo) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func uzipSeeds(c []byte) ([]routerInfo, error) {
+func uzipSeeds(c []byte) ([]RouterInfo, error) {
 	input := bytes.NewReader(c)
 	zipReader, err := zip.NewReader(input, int64(len(c)))
 	if nil != err {
@@ -46,7 +51,7 @@ func uzipSeeds(c []byte) ([]routerInfo, error) {
 		return nil, err
 	}
 
-	var seeds []routerInfo
+	var seeds []RouterInfo
 	for _, f := range zipReader.File {
 		rc, err := f.Open()
 		if err != nil {
@@ -60,7 +65,7 @@ func uzipSeeds(c []byte) ([]routerInfo, error) {
 			return nil, err
 		}
 
-		seeds = append(seeds, routerInfo{Name: f.Name, Data: data})
+		seeds = append(seeds, RouterInfo{Name: f.Name, Data: data})
 	}
 
 	return seeds, nil
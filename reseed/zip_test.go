@@ -3,6 +3,8 @@ package reseed
 import (
 	"archive/zip"
 	"bytes"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -11,7 +13,7 @@ import (
 func TestZipSeeds_Success(t *testing.T) {
 	// Test with valid router info data
 	testTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
-	seeds := []routerInfo{
+	seeds := []RouterInfo{
 		{
 			Name:    "routerInfo-test1.dat",
 			ModTime: testTime,
@@ -90,7 +92,7 @@ func TestZipSeeds_Success(t *testing.T) {
 
 func TestZipSeeds_EmptyInput(t *testing.T) {
 	// Test with empty slice
-	seeds := []routerInfo{}
+	seeds := []RouterInfo{}
 
 	zipData, err := zipSeeds(seeds)
 	if err != nil {
@@ -112,7 +114,7 @@ func TestZipSeeds_EmptyInput(t *testing.T) {
 func TestZipSeeds_SingleFile(t *testing.T) {
 	// Test with single router info
 	testTime := time.Date(2023, 6, 15, 10, 30, 0, 0, time.UTC)
-	seeds := []routerInfo{
+	seeds := []RouterInfo{
 		{
 			Name:    "single-router.dat",
 			ModTime: testTime,
@@ -144,7 +146,7 @@ func TestZipSeeds_SingleFile(t *testing.T) {
 func TestUzipSeeds_Success(t *testing.T) {
 	// First create a zip file using zipSeeds
 	testTime := time.Date(2024, 2, 14, 8, 45, 0, 0, time.UTC)
-	originalSeeds := []routerInfo{
+	originalSeeds := []RouterInfo{
 		{
 			Name:    "router1.dat",
 			ModTime: testTime,
@@ -173,7 +175,7 @@ func TestUzipSeeds_Success(t *testing.T) {
 	}
 
 	// Create a map for easier comparison
-	seedMap := make(map[string]routerInfo)
+	seedMap := make(map[string]RouterInfo)
 	for _, seed := range unzippedSeeds {
 		seedMap[seed.Name] = seed
 	}
@@ -199,7 +201,7 @@ func TestUzipSeeds_Success(t *testing.T) {
 
 func TestUzipSeeds_EmptyZip(t *testing.T) {
 	// Create an empty zip file
-	emptySeeds := []routerInfo{}
+	emptySeeds := []RouterInfo{}
 	zipData, err := zipSeeds(emptySeeds)
 	if err != nil {
 		t.Fatalf("Setup failed: zipSeeds() error = %v", err)
@@ -247,11 +249,11 @@ func TestZipUnzipRoundTrip(t *testing.T) {
 	// Test round-trip: zip -> unzip -> compare
 	tests := []struct {
 		name  string
-		seeds []routerInfo
+		seeds []RouterInfo
 	}{
 		{
 			name: "MultipleFiles",
-			seeds: []routerInfo{
+			seeds: []RouterInfo{
 				{Name: "file1.dat", ModTime: time.Now(), Data: []byte("data1")},
 				{Name: "file2.dat", ModTime: time.Now(), Data: []byte("data2")},
 				{Name: "file3.dat", ModTime: time.Now(), Data: []byte("data3")},
@@ -259,17 +261,17 @@ func TestZipUnzipRoundTrip(t *testing.T) {
 		},
 		{
 			name: "SingleFile",
-			seeds: []routerInfo{
+			seeds: []RouterInfo{
 				{Name: "single.dat", ModTime: time.Now(), Data: []byte("single data")},
 			},
 		},
 		{
 			name:  "Empty",
-			seeds: []routerInfo{},
+			seeds: []RouterInfo{},
 		},
 		{
 			name: "LargeData",
-			seeds: []routerInfo{
+			seeds: []RouterInfo{
 				{Name: "large.dat", ModTime: time.Now(), Data: bytes.Repeat([]byte("x"), 10000)},
 			},
 		},
@@ -321,7 +323,7 @@ func TestZipSeeds_BinaryData(t *testing.T) {
 		binaryData[i] = byte(i)
 	}
 
-	seeds := []routerInfo{
+	seeds := []RouterInfo{
 		{
 			Name:    "binary.dat",
 			ModTime: time.Now(),
@@ -350,7 +352,7 @@ func TestZipSeeds_BinaryData(t *testing.T) {
 
 func TestZipSeeds_SpecialCharactersInFilename(t *testing.T) {
 	// Test with filenames containing special characters
-	seeds := []routerInfo{
+	seeds := []RouterInfo{
 		{
 			Name:    "file-with-dashes.dat",
 			ModTime: time.Now(),
@@ -390,3 +392,30 @@ func TestZipSeeds_SpecialCharactersInFilename(t *testing.T) {
 		t.Error("File with underscores not found")
 	}
 }
+
+func TestZipSeeds_LazyData(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "routerInfo-lazy.dat")
+	if err := os.WriteFile(path, []byte("lazy router info data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	seeds := []RouterInfo{{Name: "routerInfo-lazy.dat", ModTime: time.Now(), Path: path}}
+
+	zipData, err := zipSeeds(seeds)
+	if err != nil {
+		t.Fatalf("zipSeeds() error = %v, want nil", err)
+	}
+	unzipped, err := uzipSeeds(zipData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unzipped) != 1 || string(unzipped[0].Data) != "lazy router info data" {
+		t.Errorf("Expected the file to be re-read into the zip, got %+v", unzipped)
+	}
+
+	// A file removed between the scan and the build fails the bundle
+	os.Remove(path)
+	if _, err := zipSeeds(seeds); err == nil {
+		t.Error("Expected an error for a RouterInfo file that no longer exists")
+	}
+}
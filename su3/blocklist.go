@@ -0,0 +1,33 @@
+package su3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+)
+
+// NewBlocklistFile creates an unsigned SU3 file carrying an I2P blocklist: the
+// text list is gzip-compressed (FileTypeTXTGZ) under ContentTypeBlocklist with a
+// Unix timestamp version. Each line of the list is an IP address, CIDR range or
+// router hash, optionally preceded by "comment:", and lines starting with # are
+// ignored by routers. The caller sets SignerID and signs the file.
+func NewBlocklistFile(list []byte) (*File, error) {
+	if len(bytes.TrimSpace(list)) == 0 {
+		return nil, fmt.Errorf("blocklist cannot be empty")
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(list); err != nil {
+		return nil, fmt.Errorf("failed to compress blocklist: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress blocklist: %w", err)
+	}
+
+	su3File := New()
+	su3File.FileType = FileTypeTXTGZ
+	su3File.ContentType = ContentTypeBlocklist
+	su3File.Content = buf.Bytes()
+	return su3File, nil
+}
@@ -0,0 +1,35 @@
+package su3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestNewBlocklistFile(t *testing.T) {
+	list := []byte("# test\n192.0.2.1\n198.51.100.0/24\n")
+	su3File, err := NewBlocklistFile(list)
+	if err != nil {
+		t.Fatalf("NewBlocklistFile() error: %v", err)
+	}
+	if su3File.ContentType != ContentTypeBlocklist || su3File.FileType != FileTypeTXTGZ {
+		t.Errorf("Unexpected content/file type %d/%d", su3File.ContentType, su3File.FileType)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(su3File.Content))
+	if err != nil {
+		t.Fatalf("Content is not gzip: %v", err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, list) {
+		t.Errorf("Decompressed content = %q, want %q", got, list)
+	}
+
+	if _, err := NewBlocklistFile([]byte(" \n")); err == nil {
+		t.Error("Expected an error for an empty blocklist")
+	}
+}
@@ -38,11 +38,24 @@ const (
 	// Router Identities and Destinations but is NOT defined for SU3 files.
 	// The SU3 specification skips type 7 and uses type 8 for EdDSA.
 
+	// SigTypeEdDSASHA512Ed25519 represents pure EdDSA signature (RFC 8032 Ed25519)
+	// over the unhashed file body. Since the SU3 specification does not define
+	// it, files of this type are only verified, never signed; Ed25519 keys sign
+	// with SigTypeEdDSASHA512Ed25519ph. Signature length is always 64 bytes.
+	SigTypeEdDSASHA512Ed25519 = uint16(7)
+
 	// SigTypeEdDSASHA512Ed25519ph represents EdDSA signature with SHA-512 prehash
 	// using Ed25519ph (RFC 8032). The data is hashed with SHA-512 before signing.
 	// Per I2P SU3 spec, this is type code 0x0008. Signature length is always 64 bytes.
 	SigTypeEdDSASHA512Ed25519ph = uint16(8)
 
+	// SigTypeRSAPSSWithSHA512 represents RSA signature algorithm with SHA512 hash
+	// and PSS padding, for deployments whose policy rules out PKCS#1 v1.5. It is
+	// not part of the I2P SU3 specification and uses a code from the range the
+	// specification reserves for experimental types, so I2P routers will not
+	// accept files signed with it; SigTypeRSAWithSHA512 remains the default.
+	SigTypeRSAPSSWithSHA512 = uint16(65280)
+
 	// ContentTypeUnknown indicates SU3 file contains unspecified content type.
 	// Used when the content type cannot be determined or is not categorized.
 	ContentTypeUnknown = uint8(0)
@@ -32,8 +32,44 @@ type dsaSignature struct {
 // Note: This is a type definition (not a type alias), so ecdsaSignature has its own method set.
 type ecdsaSignature dsaSignature
 
+// The organization, unit and locality written into generated certificates
+// unless a CertificateSubject gives others.
+const (
+	DefaultCertificateOrganization       = "I2P Anonymous Network"
+	DefaultCertificateOrganizationalUnit = "I2P"
+	DefaultCertificateLocality           = "XX"
+)
+
+// CertificateSubject holds the subject fields of a generated certificate other
+// than its common name, for operators who sign under their own organizational
+// identity. Empty fields take the Default values.
+type CertificateSubject struct {
+	Organization       string
+	OrganizationalUnit string
+	Locality           string
+}
+
+// Name returns the subject for a generated certificate with the given common name.
+func (s CertificateSubject) Name(commonName string) pkix.Name {
+	orDefault := func(value, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+	return pkix.Name{
+		Organization:       []string{orDefault(s.Organization, DefaultCertificateOrganization)},
+		OrganizationalUnit: []string{orDefault(s.OrganizationalUnit, DefaultCertificateOrganizationalUnit)},
+		Locality:           []string{orDefault(s.Locality, DefaultCertificateLocality)},
+		StreetAddress:      []string{"XX"},
+		Country:            []string{"XX"},
+		CommonName:         commonName,
+	}
+}
+
 // checkSignature verifies a digital signature against signed data using the specified certificate.
-// It supports RSA, DSA, and ECDSA signature algorithms with various hash functions (SHA1, SHA256, SHA384, SHA512).
+// It supports RSA (PKCS#1 v1.5 and PSS), DSA, and ECDSA signature algorithms with various hash functions (SHA1, SHA256, SHA384, SHA512),
+// and pure Ed25519.
 // This function extends the standard x509 signature verification to support additional algorithms needed for SU3 files.
 func checkSignature(c *x509.Certificate, algo x509.SignatureAlgorithm, signed, signature []byte) (err error) {
 	if c == nil {
@@ -41,6 +77,11 @@ func checkSignature(c *x509.Certificate, algo x509.SignatureAlgorithm, signed, s
 		return errors.New("x509: certificate is nil")
 	}
 
+	// Pure Ed25519 verifies the message itself rather than a digest of it
+	if algo == x509.PureEd25519 {
+		return verifyEd25519Signature(c.PublicKey, signed, signature)
+	}
+
 	hashType, err := mapAlgorithmToHashType(algo)
 	if err != nil {
 		return err
@@ -50,10 +91,57 @@ func checkSignature(c *x509.Certificate, algo x509.SignatureAlgorithm, signed, s
 	if err != nil {
 		return err
 	}
+	return checkDigestSignature(c, algo, hashType, digest, signature)
+}
 
+// checkDigestSignature verifies signature against the digest, computed with
+// hashType, of the signed data. It is checkSignature for callers that hashed
+// the data themselves, such as Reader; pure Ed25519 signs the data rather than
+// a digest and cannot be checked this way.
+func checkDigestSignature(c *x509.Certificate, algo x509.SignatureAlgorithm, hashType crypto.Hash, digest, signature []byte) error {
+	if algo == x509.PureEd25519 {
+		return errors.New("ed25519: pure Ed25519 signatures cannot be checked against a digest")
+	}
+	if isRSAPSS(algo) {
+		return verifyRSAPSSSignature(c.PublicKey, hashType, digest, signature)
+	}
 	return verifySignatureByKeyType(c.PublicKey, digest, signature)
 }
 
+// rsaPSSOptions are the PSS parameters used to sign and verify
+// SigTypeRSAPSSWithSHA512 files: a salt as long as the hash.
+var rsaPSSOptions = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+
+// isRSAPSS reports whether algo is an RSA algorithm with PSS padding.
+func isRSAPSS(algo x509.SignatureAlgorithm) bool {
+	return algo == x509.SHA256WithRSAPSS || algo == x509.SHA384WithRSAPSS || algo == x509.SHA512WithRSAPSS
+}
+
+// verifyRSAPSSSignature verifies an RSA-PSS signature over digest, which was
+// computed with hashType, using rsaPSSOptions.
+func verifyRSAPSSSignature(publicKey crypto.PublicKey, hashType crypto.Hash, digest, signature []byte) error {
+	pub, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		lgr.WithField("public_key_type", fmt.Sprintf("%T", publicKey)).Error("RSA-PSS verification requires an RSA public key")
+		return x509.ErrUnsupportedAlgorithm
+	}
+	return rsa.VerifyPSS(pub, hashType, digest, signature, rsaPSSOptions)
+}
+
+// verifyEd25519Signature verifies a pure Ed25519 signature over the unhashed signed data.
+func verifyEd25519Signature(publicKey crypto.PublicKey, signed, signature []byte) error {
+	pub, ok := publicKey.(ed25519.PublicKey)
+	if !ok {
+		lgr.WithField("public_key_type", fmt.Sprintf("%T", publicKey)).Error("Ed25519 verification requires an Ed25519 public key")
+		return x509.ErrUnsupportedAlgorithm
+	}
+	if !ed25519.Verify(pub, signed, signature) {
+		lgr.Error("Ed25519 signature verification failed")
+		return errors.New("ed25519: signature verification failure")
+	}
+	return nil
+}
+
 // mapAlgorithmToHashType maps a signature algorithm to its corresponding hash function.
 // It returns the appropriate crypto.Hash type for the given x509.SignatureAlgorithm.
 func mapAlgorithmToHashType(algo x509.SignatureAlgorithm) (crypto.Hash, error) {
@@ -64,16 +152,16 @@ func mapAlgorithmToHashType(algo x509.SignatureAlgorithm) (crypto.Hash, error) {
 	switch algo {
 	case x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
 		hashType = crypto.SHA1
-	case x509.SHA256WithRSA, x509.DSAWithSHA256, x509.ECDSAWithSHA256:
+	case x509.SHA256WithRSA, x509.SHA256WithRSAPSS, x509.DSAWithSHA256, x509.ECDSAWithSHA256:
 		hashType = crypto.SHA256
-	case x509.SHA384WithRSA, x509.ECDSAWithSHA384:
+	case x509.SHA384WithRSA, x509.SHA384WithRSAPSS, x509.ECDSAWithSHA384:
 		hashType = crypto.SHA384
-	case x509.SHA512WithRSA, x509.ECDSAWithSHA512:
+	case x509.SHA512WithRSA, x509.SHA512WithRSAPSS, x509.ECDSAWithSHA512:
 		hashType = crypto.SHA512
 	case x509.PureEd25519:
-		// Ed25519ph is handled separately via verifyEd25519ph() in su3.go.
-		// If a caller reaches this via checkSignature with PureEd25519, use SHA-512
-		// as the prehash algorithm consistent with Ed25519ph requirements.
+		// checkSignature verifies PureEd25519 over the raw data and Ed25519ph is
+		// handled separately via verifyEd25519ph() in su3.go. SHA-512 is the hash
+		// Ed25519 uses internally.
 		hashType = crypto.SHA512
 	default:
 		lgr.WithField("algorithm", algo).Error("Unsupported signature algorithm")
@@ -172,6 +260,12 @@ func verifyECDSASignature(pub *ecdsa.PublicKey, digest, signature []byte) error
 // I2P reseed operations. The certificate is valid for 10 years and includes proper key usage
 // extensions for digital signatures.
 func NewSigningCertificate(signerID string, privateKey *rsa.PrivateKey) ([]byte, error) {
+	return NewSigningCertificateWithSubject(signerID, privateKey, CertificateSubject{})
+}
+
+// NewSigningCertificateWithSubject is NewSigningCertificate with the given
+// organization, unit and locality in the certificate subject.
+func NewSigningCertificateWithSubject(signerID string, privateKey *rsa.PrivateKey, subject CertificateSubject) ([]byte, error) {
 	if privateKey == nil {
 		return nil, fmt.Errorf("private key cannot be nil")
 	}
@@ -199,18 +293,11 @@ func NewSigningCertificate(signerID string, privateKey *rsa.PrivateKey) ([]byte,
 		IsCA:                  isCA,
 		SubjectKeyId:          subjectKeyId,
 		SerialNumber:          serialNumber,
-		Subject: pkix.Name{
-			Organization:       []string{"I2P Anonymous Network"},
-			OrganizationalUnit: []string{"I2P"},
-			Locality:           []string{"XX"},
-			StreetAddress:      []string{"XX"},
-			Country:            []string{"XX"},
-			CommonName:         signerID,
-		},
-		NotBefore:   time.Now(),
-		NotAfter:    time.Now().AddDate(10, 0, 0),
-		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Subject:               subject.Name(signerID),
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 	}
 
 	publicKey := &privateKey.PublicKey
@@ -255,18 +342,11 @@ func NewECDSASigningCertificate(signerID string, privateKey *ecdsa.PrivateKey) (
 		IsCA:                  isCA,
 		SubjectKeyId:          subjectKeyId,
 		SerialNumber:          serialNumber,
-		Subject: pkix.Name{
-			Organization:       []string{"I2P Anonymous Network"},
-			OrganizationalUnit: []string{"I2P"},
-			Locality:           []string{"XX"},
-			StreetAddress:      []string{"XX"},
-			Country:            []string{"XX"},
-			CommonName:         signerID,
-		},
-		NotBefore:   time.Now(),
-		NotAfter:    time.Now().AddDate(10, 0, 0),
-		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Subject:               CertificateSubject{}.Name(signerID),
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 	}
 
 	publicKey := &privateKey.PublicKey
@@ -300,6 +380,12 @@ func ECDSACurveForSignatureType(sigType uint16) elliptic.Curve {
 // for use in I2P reseed operations. The certificate is valid for 10 years and includes
 // proper key usage extensions for digital signatures.
 func NewEd25519SigningCertificate(signerID string, privateKey ed25519.PrivateKey) ([]byte, error) {
+	return NewEd25519SigningCertificateWithSubject(signerID, privateKey, CertificateSubject{})
+}
+
+// NewEd25519SigningCertificateWithSubject is NewEd25519SigningCertificate with
+// the given organization, unit and locality in the certificate subject.
+func NewEd25519SigningCertificateWithSubject(signerID string, privateKey ed25519.PrivateKey, subject CertificateSubject) ([]byte, error) {
 	if privateKey == nil {
 		return nil, fmt.Errorf("Ed25519 private key cannot be nil")
 	}
@@ -324,18 +410,11 @@ func NewEd25519SigningCertificate(signerID string, privateKey ed25519.PrivateKey
 		IsCA:                  isCA,
 		SubjectKeyId:          subjectKeyId,
 		SerialNumber:          serialNumber,
-		Subject: pkix.Name{
-			Organization:       []string{"I2P Anonymous Network"},
-			OrganizationalUnit: []string{"I2P"},
-			Locality:           []string{"XX"},
-			StreetAddress:      []string{"XX"},
-			Country:            []string{"XX"},
-			CommonName:         signerID,
-		},
-		NotBefore:   time.Now(),
-		NotAfter:    time.Now().AddDate(10, 0, 0),
-		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Subject:               subject.Name(signerID),
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 	}
 
 	publicKey := privateKey.Public()
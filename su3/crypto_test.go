@@ -649,3 +649,30 @@ func BenchmarkCheckSignature(b *testing.B) {
 		_ = checkSignature(cert, x509.SHA256WithRSA, testData, signature)
 	}
 }
+
+func TestCertificateSubject_Override(t *testing.T) {
+	if got := (CertificateSubject{}).Name("a@mail.i2p"); got.Organization[0] != "I2P Anonymous Network" || got.OrganizationalUnit[0] != "I2P" || got.Locality[0] != "XX" {
+		t.Errorf("Expected the I2P placeholder subject by default, got %v", got)
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	subject := CertificateSubject{Organization: "Example Org", OrganizationalUnit: "Reseed Ops", Locality: "Berlin"}
+	certDER, err := NewSigningCertificateWithSubject("ops@mail.i2p", privateKey, subject)
+	if err != nil {
+		t.Fatalf("NewSigningCertificate() error: %v", err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	got := cert.Subject
+	if got.Organization[0] != "Example Org" || got.OrganizationalUnit[0] != "Reseed Ops" || got.Locality[0] != "Berlin" {
+		t.Errorf("Expected the configured subject, got %v", got)
+	}
+	if got.CommonName != "ops@mail.i2p" {
+		t.Errorf("CommonName = %q, want ops@mail.i2p", got.CommonName)
+	}
+}
@@ -0,0 +1,108 @@
+package su3
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractContent writes the content of s into destDir, decoded according to
+// FileType. ZIP content, such as a reseed bundle or plugin, is unzipped into
+// destDir; XMLGZ and TXTGZ content is gunzipped to a single file; XML, HTML,
+// DMG and EXE content is written unchanged. Single files are named news.xml
+// for news and extracted.<ext> otherwise. Unknown file types return an error.
+func (s *File) ExtractContent(destDir string) error {
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return err
+	}
+	switch s.FileType {
+	case FileTypeZIP:
+		return extractZip(s.Content, destDir)
+	case FileTypeXMLGZ, FileTypeTXTGZ:
+		zr, err := gzip.NewReader(bytes.NewReader(s.Content))
+		if err != nil {
+			return fmt.Errorf("su3: invalid gzip content: %w", err)
+		}
+		defer zr.Close()
+		return writeExtracted(filepath.Join(destDir, s.extractedName()), zr)
+	case FileTypeXML, FileTypeHTML, FileTypeDMG, FileTypeEXE:
+		return writeExtracted(filepath.Join(destDir, s.extractedName()), bytes.NewReader(s.Content))
+	default:
+		return fmt.Errorf("su3: cannot extract content of file type %d", s.FileType)
+	}
+}
+
+// extractedName returns the name ExtractContent writes single-file content to,
+// without the .gz of compressed file types.
+func (s *File) extractedName() string {
+	if s.ContentType == ContentTypeNews {
+		return "news.xml"
+	}
+	return "extracted" + strings.TrimSuffix(FileTypeExtension(s.FileType), ".gz")
+}
+
+// extractZip unzips content into destDir, refusing entries that would land
+// outside it and stopping once maxContentLength bytes have been written.
+func extractZip(content []byte, destDir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return fmt.Errorf("su3: invalid zip content: %w", err)
+	}
+	var written int64
+	for _, f := range zr.File {
+		if !filepath.IsLocal(f.Name) {
+			return fmt.Errorf("su3: zip entry %q is outside the destination", f.Name)
+		}
+		path := filepath.Join(destDir, f.Name)
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("su3: zip entry %q: %w", f.Name, err)
+		}
+		n, err := writeFileLimited(path, rc, maxContentLength-written)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("su3: zip entry %q: %w", f.Name, err)
+		}
+		written += n
+	}
+	return nil
+}
+
+// writeExtracted writes r to path, bounded by maxContentLength.
+func writeExtracted(path string, r io.Reader) error {
+	if _, err := writeFileLimited(path, r, maxContentLength); err != nil {
+		return fmt.Errorf("su3: %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeFileLimited copies r to a new file at path, failing if r holds more
+// than limit bytes so compressed content cannot expand without bound.
+func writeFileLimited(path string, r io.Reader, limit int64) (int64, error) {
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, io.LimitReader(r, limit+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > limit {
+		err = fmt.Errorf("decompressed content exceeds %d bytes", maxContentLength)
+	}
+	return n, err
+}
@@ -0,0 +1,111 @@
+package su3
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func zipContent(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gzipContent(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(data))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFile_ExtractContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType uint8
+		fileType    uint8
+		content     []byte
+		want        map[string]string
+	}{
+		{"reseed zip", ContentTypeReseed, FileTypeZIP,
+			zipContent(t, map[string]string{"routerInfo-a.dat": "a", "routerInfo-b.dat": "b"}),
+			map[string]string{"routerInfo-a.dat": "a", "routerInfo-b.dat": "b"}},
+		{"plugin zip with directories", ContentTypePlugin, FileTypeZIP,
+			zipContent(t, map[string]string{"plugin.config": "name=test", "lib/test.jar": "jar"}),
+			map[string]string{"plugin.config": "name=test", "lib/test.jar": "jar"}},
+		{"news xml.gz", ContentTypeNews, FileTypeXMLGZ, gzipContent(t, "<feed/>"),
+			map[string]string{"news.xml": "<feed/>"}},
+		{"blocklist txt.gz", ContentTypeBlocklist, FileTypeTXTGZ, gzipContent(t, "192.0.2.1"),
+			map[string]string{"extracted.txt": "192.0.2.1"}},
+		{"router exe", ContentTypeRouter, FileTypeEXE, []byte("MZ"),
+			map[string]string{"extracted.exe": "MZ"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := New()
+			file.ContentType = tt.contentType
+			file.FileType = tt.fileType
+			file.Content = tt.content
+
+			dir := filepath.Join(t.TempDir(), "out")
+			if err := file.ExtractContent(dir); err != nil {
+				t.Fatalf("ExtractContent() error: %v", err)
+			}
+			entries, _ := os.ReadDir(dir)
+			for name, want := range tt.want {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil || string(got) != want {
+					t.Errorf("%s = %q (%v), want %q; directory holds %v", name, got, err, want, entries)
+				}
+			}
+		})
+	}
+}
+
+func TestFile_ExtractContent_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType uint8
+		content  []byte
+		wantErr  string
+	}{
+		{"unsupported file type", 99, []byte("data"), "file type 99"},
+		{"invalid zip", FileTypeZIP, []byte("not a zip"), "invalid zip"},
+		{"invalid gzip", FileTypeXMLGZ, []byte("not gzip"), "invalid gzip"},
+		{"zip slip", FileTypeZIP, zipContent(t, map[string]string{"../escape.dat": "x"}), "outside the destination"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := New()
+			file.FileType = tt.fileType
+			file.Content = tt.content
+			dir := filepath.Join(t.TempDir(), "out")
+			err := file.ExtractContent(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "..", "escape.dat")); err == nil {
+				t.Error("Expected nothing to be written outside the destination")
+			}
+		})
+	}
+}
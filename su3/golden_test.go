@@ -0,0 +1,193 @@
+package su3
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// goldenVector is an SU3 file whose encoding is fixed by the specification.
+// header is the 40-byte fixed header written out by hand, field by field:
+//
+//	magic "I2Psu3" | 00 | format | sig type (2) | sig length (2) | 00 | version length |
+//	00 | signer ID length | content length (8) | 00 | file type | 00 | content type | 12 zero bytes
+//
+// The rest of the file is the version (zero-padded to 16 bytes), the signer
+// ID, the content and the signature, in that order.
+type goldenVector struct {
+	name        string
+	sigType     uint16
+	sigLen      int
+	fileType    uint8
+	contentType uint8
+	version     string
+	content     string
+	header      string
+}
+
+const goldenSignerID = "test@mail.i2p"
+
+var goldenVectors = []goldenVector{
+	{"dsa", SigTypeDSA, 40, FileTypeZIP, ContentTypeReseed, "1700000000", "reseed bundle",
+		"493250737533 00 00 0000 0028 00 10 00 0d 000000000000000d 00 00 00 03 000000000000000000000000"},
+	{"ecdsa-p256", SigTypeECDSAWithSHA256, 72, FileTypeXMLGZ, ContentTypeNews, "1700000000", "news feed",
+		"493250737533 00 00 0001 0048 00 10 00 0d 0000000000000009 00 03 00 04 000000000000000000000000"},
+	{"ecdsa-p384", SigTypeECDSAWithSHA384, 104, FileTypeZIP, ContentTypeRouter, "1700000000", "router update",
+		"493250737533 00 00 0002 0068 00 10 00 0d 000000000000000d 00 00 00 01 000000000000000000000000"},
+	{"ecdsa-p521", SigTypeECDSAWithSHA512, 141, FileTypeZIP, ContentTypePlugin, "1700000000", "plugin",
+		"493250737533 00 00 0003 008d 00 10 00 0d 0000000000000006 00 00 00 02 000000000000000000000000"},
+	{"rsa-sha256", SigTypeRSAWithSHA256, 256, FileTypeZIP, ContentTypeReseed, "1700000000", "reseed bundle",
+		"493250737533 00 00 0004 0100 00 10 00 0d 000000000000000d 00 00 00 03 000000000000000000000000"},
+	{"rsa-sha384", SigTypeRSAWithSHA384, 384, FileTypeTXTGZ, ContentTypeBlocklist, "1700000000", "blocklist",
+		"493250737533 00 00 0005 0180 00 10 00 0d 0000000000000009 00 04 00 05 000000000000000000000000"},
+	{"rsa-sha512-long-version", SigTypeRSAWithSHA512, 512, FileTypeZIP, ContentTypeReseed, "1700000000-build-42", "reseed bundle",
+		"493250737533 00 00 0006 0200 00 13 00 0d 000000000000000d 00 00 00 03 000000000000000000000000"},
+	{"ed25519ph-html", SigTypeEdDSASHA512Ed25519ph, 64, FileTypeHTML, ContentTypeUnknown, "1700000000", "<p>hi</p>",
+		"493250737533 00 00 0008 0040 00 10 00 0d 0000000000000009 00 02 00 00 000000000000000000000000"},
+	{"ed25519ph", SigTypeEdDSASHA512Ed25519ph, 64, FileTypeZIP, ContentTypeReseed, "1700000000", "reseed bundle",
+		"493250737533 00 00 0008 0040 00 10 00 0d 000000000000000d 00 00 00 03 000000000000000000000000"},
+	{"rsa-pss-sha512", SigTypeRSAPSSWithSHA512, 512, FileTypeZIP, ContentTypeReseed, "1700000000", "reseed bundle",
+		"493250737533 00 00 ff00 0200 00 10 00 0d 000000000000000d 00 00 00 03 000000000000000000000000"},
+}
+
+// goldenSignature returns n recognisable stand-in signature bytes. The format
+// only fixes where the signature goes and how long it is, not its value.
+func goldenSignature(n int) []byte {
+	sig := make([]byte, n)
+	for i := range sig {
+		sig[i] = byte(i + 1)
+	}
+	return sig
+}
+
+// goldenVersion pads version to the 16 bytes SU3 requires at minimum.
+func goldenVersion(version string) []byte {
+	padded := []byte(version)
+	for len(padded) < minVersionLength {
+		padded = append(padded, 0)
+	}
+	return padded
+}
+
+// expectedBytes assembles the encoding of v from its hand-written parts.
+func (v goldenVector) expectedBytes(t *testing.T, sig []byte) []byte {
+	t.Helper()
+	header, err := hex.DecodeString(strings.ReplaceAll(v.header, " ", ""))
+	if err != nil || len(header) != 40 {
+		t.Fatalf("Bad golden header for %s: %d bytes, %v", v.name, len(header), err)
+	}
+	var b bytes.Buffer
+	b.Write(header)
+	b.Write(goldenVersion(v.version))
+	b.WriteString(goldenSignerID)
+	b.WriteString(v.content)
+	b.Write(sig)
+	return b.Bytes()
+}
+
+func (v goldenVector) file(sig []byte) *File {
+	return &File{
+		SignatureType: v.sigType,
+		FileType:      v.fileType,
+		ContentType:   v.contentType,
+		Version:       []byte(v.version),
+		SignerID:      []byte(goldenSignerID),
+		Content:       []byte(v.content),
+		Signature:     sig,
+	}
+}
+
+func TestGoldenVectors_MarshalBinary(t *testing.T) {
+	for _, v := range goldenVectors {
+		t.Run(v.name, func(t *testing.T) {
+			sig := goldenSignature(v.sigLen)
+			want := v.expectedBytes(t, sig)
+			file := v.file(sig)
+
+			got, err := file.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary() error: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("MarshalBinary() differs from the golden encoding\n got: %x\nwant: %x", got, want)
+			}
+			if body := file.BodyBytes(); !bytes.Equal(body, want[:len(want)-v.sigLen]) {
+				t.Errorf("BodyBytes() is not the golden encoding without its signature")
+			}
+			if string(file.Version) != v.version {
+				t.Errorf("Encoding changed the file's version to %q", file.Version)
+			}
+		})
+	}
+}
+
+func TestGoldenVectors_UnmarshalBinary(t *testing.T) {
+	for _, v := range goldenVectors {
+		t.Run(v.name, func(t *testing.T) {
+			sig := goldenSignature(v.sigLen)
+			data := v.expectedBytes(t, sig)
+
+			file := &File{}
+			if err := file.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary() error: %v", err)
+			}
+			if file.Format != 0 || file.SignatureType != v.sigType || file.FileType != v.fileType || file.ContentType != v.contentType {
+				t.Errorf("Unexpected header fields: format %d, sig type %d, file type %d, content type %d",
+					file.Format, file.SignatureType, file.FileType, file.ContentType)
+			}
+			if !bytes.Equal(file.Version, goldenVersion(v.version)) || string(file.SignerID) != goldenSignerID ||
+				string(file.Content) != v.content || !bytes.Equal(file.Signature, sig) {
+				t.Errorf("Unexpected fields: version %q, signer %q, content %q, %d signature bytes",
+					file.Version, file.SignerID, file.Content, len(file.Signature))
+			}
+
+			again, _ := file.MarshalBinary()
+			if !bytes.Equal(again, data) {
+				t.Error("Re-encoding the parsed file did not reproduce the golden bytes")
+			}
+		})
+	}
+}
+
+// TestGoldenVector_Ed25519Signed pins a whole file, signature included: Ed25519ph
+// signatures are deterministic, so signing with a fixed key must always give
+// the same bytes.
+func TestGoldenVector_Ed25519Signed(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+
+	file := New()
+	file.Version = []byte("1700000000")
+	file.SignerID = []byte(goldenSignerID)
+	file.FileType = FileTypeZIP
+	file.ContentType = ContentTypeReseed
+	file.Content = []byte("reseed bundle")
+	if err := file.SignWith(key); err != nil {
+		t.Fatalf("SignWith() error: %v", err)
+	}
+
+	sig, _ := hex.DecodeString("e3069519bdd3f23ce50b9901dce65d6343aecdd25f3170aceeb690422b26f011" +
+		"e5beb7eea08c4f0cdea8c3267d213e1a8f66f40cb1f1be93f04c04b6aeed720b")
+	v := goldenVector{"ed25519ph-signed", SigTypeEdDSASHA512Ed25519ph, 64, FileTypeZIP, ContentTypeReseed, "1700000000", "reseed bundle",
+		"493250737533 00 00 0008 0040 00 10 00 0d 000000000000000d 00 00 00 03 000000000000000000000000"}
+	want := v.expectedBytes(t, sig)
+	digest := sha512.Sum512(want[:len(want)-64])
+	if err := ed25519.VerifyWithOptions(key.Public().(ed25519.PublicKey), digest[:], sig, &ed25519.Options{Hash: crypto.SHA512}); err != nil {
+		t.Fatal("Golden signature does not verify over the golden body")
+	}
+
+	got, err := file.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Signed file differs from the golden encoding\n got: %x\nwant: %x", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package su3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// gzipMagic is the two-byte header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// NewNewsFile creates an unsigned SU3 file carrying an I2P news feed in the format
+// consumed by the router's news subsystem: gzip-compressed XML (FileTypeXMLGZ),
+// ContentTypeNews and a Unix timestamp version. The feed may be passed as plain
+// Atom XML or already gzip-compressed; plain XML is compressed before embedding.
+// The caller sets SignerID and signs the file, normally with the RSA-SHA512 key
+// whose certificate routers keep in certificates/news.
+func NewNewsFile(feed []byte) (*File, error) {
+	if len(feed) == 0 {
+		return nil, fmt.Errorf("news feed cannot be empty")
+	}
+
+	content := feed
+	if !bytes.HasPrefix(feed, gzipMagic) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(feed); err != nil {
+			return nil, fmt.Errorf("failed to compress news feed: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress news feed: %w", err)
+		}
+		content = buf.Bytes()
+	}
+
+	su3File := New()
+	su3File.FileType = FileTypeXMLGZ
+	su3File.ContentType = ContentTypeNews
+	su3File.Content = content
+	return su3File, nil
+}
+
+// NewsFeed returns the uncompressed XML news feed contained in a news SU3 file.
+// It returns an error if the file is not a news file or its payload is not XML
+// or gzip-compressed XML, the only file types routers accept for news.
+func (s *File) NewsFeed() ([]byte, error) {
+	if s.ContentType != ContentTypeNews {
+		return nil, fmt.Errorf("su3 content type %d is not news", s.ContentType)
+	}
+
+	switch s.FileType {
+	case FileTypeXML:
+		return s.Content, nil
+	case FileTypeXMLGZ:
+		zr, err := gzip.NewReader(bytes.NewReader(s.Content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip news payload: %w", err)
+		}
+		defer zr.Close()
+		// Bound decompression by the same limit applied to SU3 content
+		feed, err := io.ReadAll(io.LimitReader(zr, maxContentLength+1))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip news payload: %w", err)
+		}
+		if len(feed) > maxContentLength {
+			return nil, fmt.Errorf("decompressed news feed exceeds %d bytes", maxContentLength)
+		}
+		return feed, nil
+	default:
+		return nil, fmt.Errorf("su3 file type %d is not valid for news", s.FileType)
+	}
+}
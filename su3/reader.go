@@ -0,0 +1,177 @@
+package su3
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// Reader reads an SU3 file from a stream without holding its content in
+// memory, for plugin and router update files too large to load whole. The
+// header, version and signer ID are read by NewReader, the content is streamed
+// through Content, and the signature is read once the content has been.
+// Verification hashes the content as it streams past, so it only needs the
+// signature buffered.
+type Reader struct {
+	// File holds the header fields, version and signer ID. Its Content is
+	// always nil; Signature is set by ReadSignature.
+	File *File
+
+	src     io.Reader
+	content *contentReader
+	// hash accumulates the signed bytes; nil when the signature type cannot
+	// be checked against a digest
+	hash          hash.Hash
+	hashType      crypto.Hash
+	sigLength     uint16
+	signatureRead bool
+}
+
+// NewReader reads the header, version and signer ID of the SU3 file in r and
+// returns a Reader positioned at the start of its content.
+func NewReader(r io.Reader) (*Reader, error) {
+	sr := &Reader{File: &File{}, src: r}
+
+	// The header is signed with the rest, but the hash depends on the
+	// signature type it declares, so it is kept until the type is known
+	var header bytes.Buffer
+	h, err := sr.File.readHeader(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, err
+	}
+	signed := io.Discard
+	if hashType, ok := digestHash(sr.File.SignatureType); ok {
+		sr.hashType = hashType
+		sr.hash = hashType.New()
+		sr.hash.Write(header.Bytes())
+		signed = sr.hash
+	}
+	sr.sigLength = h.signatureLength
+
+	signedSrc := io.TeeReader(r, signed)
+	sr.File.Version = make([]byte, h.versionLength)
+	if _, err := io.ReadFull(signedSrc, sr.File.Version); err != nil {
+		return nil, fmt.Errorf("failed to read version: %w", err)
+	}
+	sr.File.SignerID = make([]byte, h.signerIDLength)
+	if _, err := io.ReadFull(signedSrc, sr.File.SignerID); err != nil {
+		return nil, fmt.Errorf("failed to read signer ID: %w", err)
+	}
+	sr.content = &contentReader{r: signedSrc, left: h.contentLength}
+	return sr, nil
+}
+
+// ContentLength returns the content length declared by the header.
+func (sr *Reader) ContentLength() uint64 {
+	return sr.content.total()
+}
+
+// Content returns the file's content as a stream. It reports
+// io.ErrUnexpectedEOF if the file ends before the declared content length.
+func (sr *Reader) Content() io.Reader {
+	return sr.content
+}
+
+// ReadSignature skips whatever content has not been read yet, then reads and
+// returns the signature, also setting File.Signature.
+func (sr *Reader) ReadSignature() ([]byte, error) {
+	if sr.signatureRead {
+		return sr.File.Signature, nil
+	}
+	if _, err := io.Copy(io.Discard, sr.content); err != nil {
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	signature := make([]byte, sr.sigLength)
+	if _, err := io.ReadFull(sr.src, signature); err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	sr.File.Signature = signature
+	sr.signatureRead = true
+	return signature, nil
+}
+
+// VerifySignature reads the signature, skipping any unread content, and checks
+// it against cert like File.VerifySignature. Pure Ed25519 signs the whole file
+// rather than a digest of it, so such files must be read with UnmarshalBinary
+// to be verified.
+func (sr *Reader) VerifySignature(cert *x509.Certificate) error {
+	if cert == nil {
+		return errors.New("x509: certificate is nil")
+	}
+	signature, err := sr.ReadSignature()
+	if err != nil {
+		return err
+	}
+	if sr.hash == nil {
+		if sr.File.SignatureType == SigTypeEdDSASHA512Ed25519 {
+			return errors.New("su3: pure Ed25519 files cannot be verified while streaming")
+		}
+		lgr.WithField("signature_type", sr.File.SignatureType).Error("Unknown signature type for SU3 verification")
+		return fmt.Errorf("unknown signature type: %d", sr.File.SignatureType)
+	}
+	digest := sr.hash.Sum(nil)
+
+	if sr.File.SignatureType == SigTypeEdDSASHA512Ed25519ph {
+		pubKey, ok := cert.PublicKey.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("Ed25519ph verification requires ed25519.PublicKey, got %T", cert.PublicKey)
+		}
+		return verifyEd25519phDigest(pubKey, digest, signature)
+	}
+	sigAlg, _ := signatureAlgorithm(sr.File.SignatureType)
+	if err := checkDigestSignature(cert, sigAlg, sr.hashType, digest, signature); err != nil {
+		lgr.WithError(err).WithField("signature_type", sr.File.SignatureType).Error("SU3 signature verification failed")
+		return err
+	}
+	return nil
+}
+
+// digestHash returns the hash whose digest of the signed data the signature
+// type signs, and false for types that do not sign a digest or are unknown.
+func digestHash(sigType uint16) (crypto.Hash, bool) {
+	if sigType == SigTypeEdDSASHA512Ed25519ph {
+		return crypto.SHA512, true
+	}
+	sigAlg, ok := signatureAlgorithm(sigType)
+	if !ok || sigAlg == x509.PureEd25519 {
+		return 0, false
+	}
+	hashType, err := mapAlgorithmToHashType(sigAlg)
+	if err != nil || !hashType.Available() {
+		return 0, false
+	}
+	return hashType, true
+}
+
+// contentReader reads exactly the declared content length from r, reporting a
+// stream that ends early as io.ErrUnexpectedEOF rather than a clean EOF.
+type contentReader struct {
+	r    io.Reader
+	left uint64
+	read uint64
+}
+
+func (cr *contentReader) Read(p []byte) (int, error) {
+	if cr.left == 0 {
+		return 0, io.EOF
+	}
+	if uint64(len(p)) > cr.left {
+		p = p[:cr.left]
+	}
+	n, err := cr.r.Read(p)
+	cr.left -= uint64(n)
+	cr.read += uint64(n)
+	if err == io.EOF && cr.left > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (cr *contentReader) total() uint64 {
+	return cr.left + cr.read
+}
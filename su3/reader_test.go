@@ -0,0 +1,153 @@
+package su3
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"io"
+	"testing"
+)
+
+// newRSATestFile returns a signed plugin SU3 carrying content, and the
+// certificate that verifies it.
+func newRSATestFile(t *testing.T, content []byte) ([]byte, *x509.Certificate) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	certDER, err := NewSigningCertificate("plugin@example.com", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := New()
+	file.FileType = FileTypeZIP
+	file.ContentType = ContentTypePlugin
+	file.SignerID = []byte("plugin@example.com")
+	file.Content = content
+	if err := file.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	data, err := file.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data, cert
+}
+
+func TestReader_StreamsAndVerifies(t *testing.T) {
+	content := bytes.Repeat([]byte("plugin payload "), 10000)
+	data, cert := newRSATestFile(t, content)
+
+	sr, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewReader() error: %v", err)
+	}
+	if sr.File.ContentType != ContentTypePlugin || string(sr.File.SignerID) != "plugin@example.com" {
+		t.Errorf("Unexpected header fields: %s", sr.File)
+	}
+	if sr.File.Content != nil {
+		t.Error("Expected the content not to be buffered in File")
+	}
+	if got := sr.ContentLength(); got != uint64(len(content)) {
+		t.Errorf("ContentLength() = %d, want %d", got, len(content))
+	}
+
+	streamed, err := io.ReadAll(sr.Content())
+	if err != nil || !bytes.Equal(streamed, content) {
+		t.Fatalf("Expected the content to stream unchanged, got %d bytes (%v)", len(streamed), err)
+	}
+	if err := sr.VerifySignature(cert); err != nil {
+		t.Errorf("Expected the streamed file to verify, got %v", err)
+	}
+	if len(sr.File.Signature) != 256 {
+		t.Errorf("Expected a 256-byte signature, got %d", len(sr.File.Signature))
+	}
+}
+
+func TestReader_VerifySkipsUnreadContent(t *testing.T) {
+	data, cert := newRSATestFile(t, []byte("payload that is never read"))
+	sr, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sr.VerifySignature(cert); err != nil {
+		t.Errorf("Expected verification to read past the content, got %v", err)
+	}
+}
+
+func TestReader_TamperedAndTruncated(t *testing.T) {
+	content := []byte("payload to tamper with")
+	data, cert := newRSATestFile(t, content)
+
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-256-1] ^= 0xff // last content byte
+	sr, err := NewReader(bytes.NewReader(tampered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sr.VerifySignature(cert); err == nil {
+		t.Error("Expected tampered content to fail verification")
+	}
+
+	truncated := data[:len(data)-256-5]
+	sr, err = NewReader(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(sr.Content()); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected io.ErrUnexpectedEOF for truncated content, got %v", err)
+	}
+
+	if _, err := NewReader(bytes.NewReader([]byte("BADMAG"))); err == nil {
+		t.Error("Expected bad magic bytes to be refused")
+	}
+}
+
+func TestReader_Ed25519(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certDER, err := NewEd25519SigningCertificate("ed@example.com", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		sigType    uint16
+		verifiable bool
+	}{
+		{SigTypeEdDSASHA512Ed25519ph, true},
+		{SigTypeEdDSASHA512Ed25519, false},
+	} {
+		file := New()
+		file.SignatureType = tt.sigType
+		file.Content = []byte("ed25519 payload")
+		if tt.sigType == SigTypeEdDSASHA512Ed25519 {
+			signPureEd25519(file, key)
+		} else if err := file.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := file.MarshalBinary()
+		sr, err := NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sr.VerifySignature(cert); (err == nil) != tt.verifiable {
+			t.Errorf("Type %d: expected verifiable=%v, got %v", tt.sigType, tt.verifiable, err)
+		}
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"crypto/x509"
 	"encoding/binary"
 	"fmt"
+	"io"
+	"slices"
 	"strconv"
 	"time"
 )
@@ -48,12 +50,18 @@ type File struct {
 	Signature []byte
 }
 
+// VersionAt returns the su3 version for a file built at t: the Unix time in
+// seconds, as decimal text.
+func VersionAt(t time.Time) []byte {
+	return []byte(strconv.FormatInt(t.Unix(), 10))
+}
+
 // New creates a new SU3 file with default settings and current timestamp.
 // The file is initialized with RSA-SHA512 signature type and a Unix timestamp version.
 // Additional fields must be set before signing and distribution.
 func New() *File {
 	return &File{
-		Version:       []byte(strconv.FormatInt(time.Now().Unix(), 10)),
+		Version:       VersionAt(time.Now()),
 		SignatureType: SigTypeRSAWithSHA512,
 	}
 }
@@ -102,7 +110,7 @@ func (s *File) Sign(privkey crypto.Signer) error {
 			return err
 		}
 		hashType = crypto.SHA384
-	case SigTypeRSAWithSHA512:
+	case SigTypeRSAWithSHA512, SigTypeRSAPSSWithSHA512:
 		if err := validateRSAKey(privkey); err != nil {
 			return err
 		}
@@ -114,6 +122,9 @@ func (s *File) Sign(privkey crypto.Signer) error {
 		// Ed25519ph uses SHA-512 prehash: we hash the data ourselves,
 		// then pass the 64-byte digest to Ed25519 Sign with Hash option.
 		hashType = crypto.SHA512
+	case SigTypeEdDSASHA512Ed25519:
+		// Type 7 is not defined for SU3 files, so routers would reject it
+		return fmt.Errorf("signature type %d is not defined for SU3 files, use %d for Ed25519 keys", s.SignatureType, SigTypeEdDSASHA512Ed25519ph)
 	default:
 		lgr.WithField("signature_type", s.SignatureType).Error("Unknown signature type for SU3 signing")
 		return fmt.Errorf("unknown signature type: %d", s.SignatureType)
@@ -141,6 +152,17 @@ func (s *File) Sign(privkey crypto.Signer) error {
 	// Dispatch signing based on key type
 	switch key := privkey.(type) {
 	case *rsa.PrivateKey:
+		if s.SignatureType == SigTypeRSAPSSWithSHA512 {
+			// PSS signs the digest with its hash identified, unlike the raw
+			// PKCS#1 v1.5 mode below, and a salt as long as the hash.
+			sig, err := rsa.SignPSS(rand.Reader, key, hashType, digest, rsaPSSOptions)
+			if err != nil {
+				lgr.WithError(err).Error("Failed to generate RSA-PSS signature for SU3 file")
+				return err
+			}
+			s.Signature = sig
+			break
+		}
 		// Generate RSA signature using PKCS#1 v1.5 padding scheme.
 		// We pass hash=0 to produce raw PKCS#1 v1.5 signatures without the
 		// DigestInfo ASN.1 prefix. This is intentional for I2P SU3 format
@@ -187,11 +209,44 @@ func (s *File) Sign(privkey crypto.Signer) error {
 	return nil
 }
 
-// validateRSAKey checks that privkey is an *rsa.PrivateKey.
+// SignWith signs the file with privkey, first choosing the signature type that
+// matches the key: the SHA-512 type of its curve for ECDSA, and Ed25519ph for
+// Ed25519, the only EdDSA type the SU3 specification defines. RSA keys keep the
+// SignatureType the file was given.
+func (s *File) SignWith(privkey crypto.Signer) error {
+	switch key := privkey.(type) {
+	case *ecdsa.PrivateKey:
+		switch key.Curve {
+		case elliptic.P256():
+			s.SignatureType = SigTypeECDSAWithSHA256
+		case elliptic.P384():
+			s.SignatureType = SigTypeECDSAWithSHA384
+		case elliptic.P521():
+			s.SignatureType = SigTypeECDSAWithSHA512
+		}
+	case ed25519.PrivateKey:
+		s.SignatureType = SigTypeEdDSASHA512Ed25519ph
+	}
+	return s.Sign(privkey)
+}
+
+// RSAKeySizes lists the RSA modulus sizes, in bits, that Sign accepts. These
+// are the sizes of the SU3 RSA signature types (2048 for RSA-SHA256, 3072 for
+// RSA-SHA384 and 4096 for RSA-SHA512); routers reject signatures of any other
+// length.
+var RSAKeySizes = []int{2048, 3072, 4096}
+
+// validateRSAKey checks that privkey is an *rsa.PrivateKey of one of the
+// RSAKeySizes.
 func validateRSAKey(privkey crypto.Signer) error {
-	if _, ok := privkey.(*rsa.PrivateKey); !ok {
+	key, ok := privkey.(*rsa.PrivateKey)
+	if !ok {
 		return fmt.Errorf("RSA signature type requires *rsa.PrivateKey, got %T", privkey)
 	}
+	bits := key.Size() * 8
+	if !slices.Contains(RSAKeySizes, bits) {
+		return fmt.Errorf("RSA signing key is %d bits, SU3 signatures require one of %v", bits, RSAKeySizes)
+	}
 	return nil
 }
 
@@ -298,13 +353,13 @@ func (s *File) BodyBytes() []byte {
 		} else {
 			signatureLength = uint16(384) // Default for 3072-bit RSA key
 		}
-	case SigTypeRSAWithSHA512:
+	case SigTypeRSAWithSHA512, SigTypeRSAPSSWithSHA512:
 		if len(s.Signature) > 0 {
 			signatureLength = uint16(len(s.Signature))
 		} else {
 			signatureLength = uint16(512) // Default for 4096-bit RSA key (standard)
 		}
-	case SigTypeEdDSASHA512Ed25519ph:
+	case SigTypeEdDSASHA512Ed25519, SigTypeEdDSASHA512Ed25519ph:
 		// Ed25519 signatures are always exactly 64 bytes per I2P spec and RFC 8032.
 		// No variable-length padding needed.
 		signatureLength = uint16(ed25519.SignatureSize)
@@ -361,86 +416,118 @@ func (s *File) MarshalBinary() ([]byte, error) {
 // (reseed bundles are typically <5MB).
 const maxContentLength = 100 * 1024 * 1024 // 100 MB
 
-// UnmarshalBinary deserializes binary data into a SU3 file structure.
-// This parses the SU3 file format and populates all fields including header metadata,
-// content, and signature. Returns an error if the data is malformed, truncated,
-// contains invalid magic bytes, or has content exceeding the maximum allowed size.
-func (s *File) UnmarshalBinary(data []byte) error {
-	var (
-		r = bytes.NewReader(data)
+// headerLengths holds the length fields of an SU3 file's fixed header.
+type headerLengths struct {
+	signatureLength uint16
+	versionLength   uint8
+	signerIDLength  uint8
+	contentLength   uint64
+}
 
+// readHeader reads the fixed header from r, checking the magic bytes, and sets
+// the file's Format, SignatureType, FileType and ContentType from it. The
+// version, signer ID, content and signature that follow are left unread.
+func (s *File) readHeader(r io.Reader) (headerLengths, error) {
+	var (
+		h       headerLengths
 		magic   = make([]byte, len(magicBytes))
 		skip    [1]byte
 		bigSkip [12]byte
-
-		signatureLength uint16
-		versionLength   uint8
-		signerIDLength  uint8
-		contentLength   uint64
 	)
-
 	// Read and validate magic bytes — all valid SU3 files must start with "I2Psu3"
 	if err := binary.Read(r, binary.BigEndian, &magic); err != nil {
-		return fmt.Errorf("failed to read magic bytes: %w", err)
+		return h, fmt.Errorf("failed to read magic bytes: %w", err)
 	}
 	if string(magic) != magicBytes {
-		return fmt.Errorf("invalid magic bytes: expected %q, got %q", magicBytes, string(magic))
+		return h, fmt.Errorf("invalid magic bytes: expected %q, got %q", magicBytes, string(magic))
 	}
 
 	// Read fixed-length header fields in big-endian format following SU3 specification
 	if err := binary.Read(r, binary.BigEndian, &skip); err != nil {
-		return fmt.Errorf("failed to read header: %w", err)
+		return h, fmt.Errorf("failed to read header: %w", err)
 	}
 	if err := binary.Read(r, binary.BigEndian, &s.Format); err != nil {
-		return fmt.Errorf("failed to read format: %w", err)
+		return h, fmt.Errorf("failed to read format: %w", err)
 	}
 	if err := binary.Read(r, binary.BigEndian, &s.SignatureType); err != nil {
-		return fmt.Errorf("failed to read signature type: %w", err)
+		return h, fmt.Errorf("failed to read signature type: %w", err)
 	}
-	if err := binary.Read(r, binary.BigEndian, &signatureLength); err != nil {
-		return fmt.Errorf("failed to read signature length: %w", err)
+	if err := binary.Read(r, binary.BigEndian, &h.signatureLength); err != nil {
+		return h, fmt.Errorf("failed to read signature length: %w", err)
 	}
 	if err := binary.Read(r, binary.BigEndian, &skip); err != nil {
-		return fmt.Errorf("failed to read header: %w", err)
+		return h, fmt.Errorf("failed to read header: %w", err)
 	}
-	if err := binary.Read(r, binary.BigEndian, &versionLength); err != nil {
-		return fmt.Errorf("failed to read version length: %w", err)
+	if err := binary.Read(r, binary.BigEndian, &h.versionLength); err != nil {
+		return h, fmt.Errorf("failed to read version length: %w", err)
 	}
 	if err := binary.Read(r, binary.BigEndian, &skip); err != nil {
-		return fmt.Errorf("failed to read header: %w", err)
+		return h, fmt.Errorf("failed to read header: %w", err)
 	}
-	if err := binary.Read(r, binary.BigEndian, &signerIDLength); err != nil {
-		return fmt.Errorf("failed to read signer ID length: %w", err)
+	if err := binary.Read(r, binary.BigEndian, &h.signerIDLength); err != nil {
+		return h, fmt.Errorf("failed to read signer ID length: %w", err)
 	}
-	if err := binary.Read(r, binary.BigEndian, &contentLength); err != nil {
-		return fmt.Errorf("failed to read content length: %w", err)
+	if err := binary.Read(r, binary.BigEndian, &h.contentLength); err != nil {
+		return h, fmt.Errorf("failed to read content length: %w", err)
 	}
 	if err := binary.Read(r, binary.BigEndian, &skip); err != nil {
-		return fmt.Errorf("failed to read header: %w", err)
+		return h, fmt.Errorf("failed to read header: %w", err)
 	}
 	if err := binary.Read(r, binary.BigEndian, &s.FileType); err != nil {
-		return fmt.Errorf("failed to read file type: %w", err)
+		return h, fmt.Errorf("failed to read file type: %w", err)
 	}
 	if err := binary.Read(r, binary.BigEndian, &skip); err != nil {
-		return fmt.Errorf("failed to read header: %w", err)
+		return h, fmt.Errorf("failed to read header: %w", err)
 	}
 	if err := binary.Read(r, binary.BigEndian, &s.ContentType); err != nil {
-		return fmt.Errorf("failed to read content type: %w", err)
+		return h, fmt.Errorf("failed to read content type: %w", err)
 	}
 	if err := binary.Read(r, binary.BigEndian, &bigSkip); err != nil {
-		return fmt.Errorf("failed to read header padding: %w", err)
+		return h, fmt.Errorf("failed to read header padding: %w", err)
+	}
+
+	return h, nil
+}
+
+// UnmarshalBinary deserializes binary data into a SU3 file structure.
+// This parses the SU3 file format and populates all fields including header metadata,
+// content, and signature. Returns an error if the data is malformed, truncated,
+// contains invalid magic bytes, has content exceeding the maximum allowed size, or
+// declares more version, signer ID, content or signature bytes than data holds.
+func (s *File) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	h, err := s.readHeader(r)
+	if err != nil {
+		return err
 	}
 
 	// Validate content length to prevent OOM from maliciously crafted SU3 files
-	if contentLength > maxContentLength {
-		return fmt.Errorf("content length %d exceeds maximum allowed %d bytes", contentLength, maxContentLength)
+	if h.contentLength > maxContentLength {
+		return fmt.Errorf("content length %d exceeds maximum allowed %d bytes", h.contentLength, maxContentLength)
+	}
+
+	// Check the header lengths against the data actually present before
+	// allocating, so a truncated file or a forged length fails here rather
+	// than after a large allocation
+	remaining := uint64(r.Len())
+	idLength := uint64(h.versionLength) + uint64(h.signerIDLength)
+	if idLength > remaining {
+		return fmt.Errorf("su3: version and signer ID lengths exceed data: %d bytes left after the header", remaining)
+	}
+	remaining -= idLength
+	if h.contentLength > remaining {
+		return fmt.Errorf("su3: content length %d exceeds data: %d bytes left", h.contentLength, remaining)
+	}
+	remaining -= h.contentLength
+	if uint64(h.signatureLength) > remaining {
+		return fmt.Errorf("su3: signature length %d exceeds data: %d bytes left", h.signatureLength, remaining)
 	}
 
 	// Allocate byte slices based on header length fields
-	s.Version = make([]byte, versionLength)
-	s.SignerID = make([]byte, signerIDLength)
-	s.Content = make([]byte, contentLength)
-	s.Signature = make([]byte, signatureLength)
+	s.Version = make([]byte, h.versionLength)
+	s.SignerID = make([]byte, h.signerIDLength)
+	s.Content = make([]byte, h.contentLength)
+	s.Signature = make([]byte, h.signatureLength)
 
 	// Read variable-length data fields in the order specified by SU3 format
 	if err := binary.Read(r, binary.BigEndian, &s.Version); err != nil {
@@ -464,30 +551,14 @@ func (s *File) UnmarshalBinary(data []byte) error {
 // certificate's public key. The signature algorithm is determined by the SignatureType field.
 // Returns an error if verification fails or the signature type is unsupported.
 func (s *File) VerifySignature(cert *x509.Certificate) error {
-	var sigAlg x509.SignatureAlgorithm
-	// Map SU3 signature types to standard x509 signature algorithms
-	// Each SU3 signature type corresponds to a specific combination of algorithm and hash
-	switch s.SignatureType {
-	case SigTypeDSA:
-		sigAlg = x509.DSAWithSHA1
-	case SigTypeECDSAWithSHA256:
-		sigAlg = x509.ECDSAWithSHA256
-	case SigTypeECDSAWithSHA384:
-		sigAlg = x509.ECDSAWithSHA384
-	case SigTypeECDSAWithSHA512:
-		sigAlg = x509.ECDSAWithSHA512
-	case SigTypeRSAWithSHA256:
-		sigAlg = x509.SHA256WithRSA
-	case SigTypeRSAWithSHA384:
-		sigAlg = x509.SHA384WithRSA
-	case SigTypeRSAWithSHA512:
-		sigAlg = x509.SHA512WithRSA
-	case SigTypeEdDSASHA512Ed25519ph:
+	if s.SignatureType == SigTypeEdDSASHA512Ed25519ph {
 		// Ed25519ph doesn't map to a standard x509.SignatureAlgorithm.
 		// Go's x509.PureEd25519 is for pure Ed25519, not Ed25519ph (prehash).
 		// We handle verification directly using crypto/ed25519.
 		return s.verifyEd25519ph(cert)
-	default:
+	}
+	sigAlg, ok := signatureAlgorithm(s.SignatureType)
+	if !ok {
 		lgr.WithField("signature_type", s.SignatureType).Error("Unknown signature type for SU3 verification")
 		return fmt.Errorf("unknown signature type: %d", s.SignatureType)
 	}
@@ -501,6 +572,34 @@ func (s *File) VerifySignature(cert *x509.Certificate) error {
 	return nil
 }
 
+// signatureAlgorithm maps an SU3 signature type to the x509 signature algorithm
+// that verifies it. Each SU3 signature type corresponds to a specific combination
+// of algorithm and hash. Ed25519ph has no x509 equivalent and reports false, as
+// do unknown types.
+func signatureAlgorithm(sigType uint16) (x509.SignatureAlgorithm, bool) {
+	switch sigType {
+	case SigTypeDSA:
+		return x509.DSAWithSHA1, true
+	case SigTypeECDSAWithSHA256:
+		return x509.ECDSAWithSHA256, true
+	case SigTypeECDSAWithSHA384:
+		return x509.ECDSAWithSHA384, true
+	case SigTypeECDSAWithSHA512:
+		return x509.ECDSAWithSHA512, true
+	case SigTypeRSAWithSHA256:
+		return x509.SHA256WithRSA, true
+	case SigTypeRSAWithSHA384:
+		return x509.SHA384WithRSA, true
+	case SigTypeRSAWithSHA512:
+		return x509.SHA512WithRSA, true
+	case SigTypeRSAPSSWithSHA512:
+		return x509.SHA512WithRSAPSS, true
+	case SigTypeEdDSASHA512Ed25519:
+		return x509.PureEd25519, true
+	}
+	return x509.UnknownSignatureAlgorithm, false
+}
+
 // verifyEd25519ph verifies an Ed25519ph (prehash) signature using the certificate's
 // public key. Ed25519ph is not a standard x509.SignatureAlgorithm in Go, so we
 // extract the Ed25519 public key from the certificate and verify directly.
@@ -519,9 +618,13 @@ func (s *File) verifyEd25519ph(cert *x509.Certificate) error {
 	// Ed25519ph: hash the body with SHA-512, then verify against the digest
 	h := crypto.SHA512.New()
 	h.Write(s.BodyBytes())
-	digest := h.Sum(nil)
+	return verifyEd25519phDigest(pubKey, h.Sum(nil), s.Signature)
+}
 
-	if err := ed25519.VerifyWithOptions(pubKey, digest, s.Signature, &ed25519.Options{Hash: crypto.SHA512}); err != nil {
+// verifyEd25519phDigest verifies an Ed25519ph signature over the SHA-512 digest
+// of the signed data.
+func verifyEd25519phDigest(pubKey ed25519.PublicKey, digest, signature []byte) error {
+	if err := ed25519.VerifyWithOptions(pubKey, digest, signature, &ed25519.Options{Hash: crypto.SHA512}); err != nil {
 		lgr.WithError(err).Error("Ed25519ph signature verification failed")
 		return fmt.Errorf("Ed25519ph verification failure: %w", err)
 	}
@@ -539,9 +642,9 @@ func (s *File) String() string {
 	// Display key fields with proper formatting and null-byte trimming
 	fmt.Fprintln(&b, "---------------------------")
 	fmt.Fprintf(&b, "Format: %q\n", s.Format)
-	fmt.Fprintf(&b, "SignatureType: %q\n", s.SignatureType)
-	fmt.Fprintf(&b, "FileType: %q\n", s.FileType)
-	fmt.Fprintf(&b, "ContentType: %q\n", s.ContentType)
+	fmt.Fprintf(&b, "SignatureType: %d\n", s.SignatureType)
+	fmt.Fprintf(&b, "FileType: %d (%s)\n", s.FileType, FileTypeName(s.FileType))
+	fmt.Fprintf(&b, "ContentType: %d (%s)\n", s.ContentType, ContentTypeName(s.ContentType))
 	fmt.Fprintf(&b, "Version: %q\n", bytes.Trim(s.Version, "\x00"))
 	fmt.Fprintf(&b, "SignerId: %q\n", s.SignerID)
 	fmt.Fprintf(&b, "---------------------------")
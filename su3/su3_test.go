@@ -11,6 +11,7 @@ import (
 	"crypto/x509"
 	"encoding/binary"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -591,8 +592,44 @@ func TestFile_UnmarshalBinary_TruncatedContent(t *testing.T) {
 	if err == nil {
 		t.Fatal("Expected error for truncated content, got nil")
 	}
-	if !strings.Contains(err.Error(), "failed to read content") {
-		t.Errorf("Expected 'failed to read content' error, got: %v", err)
+	if !strings.Contains(err.Error(), "su3: content length 1024 exceeds data") {
+		t.Errorf("Expected 'content length exceeds data' error, got: %v", err)
+	}
+}
+
+// TestFile_UnmarshalBinary_LengthsExceedData verifies that each header length
+// is checked against the bytes actually present before anything is allocated.
+func TestFile_UnmarshalBinary_LengthsExceedData(t *testing.T) {
+	file := New()
+	file.SignerID = []byte("test@example.com")
+	file.Content = []byte("content")
+	file.Signature = make([]byte, 512)
+	data, err := file.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&File{}).UnmarshalBinary(data); err != nil {
+		t.Fatalf("Expected the complete file to parse, got %v", err)
+	}
+
+	// The header is 40 bytes; the version follows it
+	for _, tt := range []struct {
+		name      string
+		data      []byte
+		errSubstr string
+	}{
+		{"missing signature bytes", data[:len(data)-1], "su3: signature length 512 exceeds data: 511 bytes left"},
+		{"header only", data[:40], "su3: version and signer ID lengths exceed data"},
+		{"forged content length", func() []byte {
+			forged := append([]byte(nil), data...)
+			binary.BigEndian.PutUint64(forged[16:24], 1<<20)
+			return forged
+		}(), "su3: content length 1048576 exceeds data"},
+	} {
+		err := (&File{}).UnmarshalBinary(tt.data)
+		if err == nil || !strings.Contains(err.Error(), tt.errSubstr) {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.errSubstr, err)
+		}
 	}
 }
 
@@ -781,6 +818,7 @@ func TestConstants(t *testing.T) {
 		"RSAWithSHA256":        4,
 		"RSAWithSHA384":        5,
 		"RSAWithSHA512":        6,
+		"EdDSASHA512Ed25519":   7,
 		"EdDSASHA512Ed25519ph": 8,
 	}
 
@@ -792,6 +830,7 @@ func TestConstants(t *testing.T) {
 		"RSAWithSHA256":        SigTypeRSAWithSHA256,
 		"RSAWithSHA384":        SigTypeRSAWithSHA384,
 		"RSAWithSHA512":        SigTypeRSAWithSHA512,
+		"EdDSASHA512Ed25519":   SigTypeEdDSASHA512Ed25519,
 		"EdDSASHA512Ed25519ph": SigTypeEdDSASHA512Ed25519ph,
 	}
 
@@ -903,6 +942,26 @@ func TestFile_Sign_RSAKeySize(t *testing.T) {
 	}
 }
 
+func TestFile_Sign_RejectsUnusualRSAKeySize(t *testing.T) {
+	for _, bits := range []int{1024, 2056} {
+		privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			t.Fatalf("Failed to generate %d-bit RSA key: %v", bits, err)
+		}
+
+		file := New()
+		file.Content = []byte("test content")
+		file.SignerID = []byte("test@example.com")
+		err = file.Sign(privateKey)
+		if err == nil || !strings.Contains(err.Error(), strconv.Itoa(bits)+" bits") {
+			t.Errorf("Expected a %d-bit key to be refused, got %v", bits, err)
+		}
+		if file.Signature != nil {
+			t.Errorf("Expected no signature from a %d-bit key", bits)
+		}
+	}
+}
+
 // Benchmark tests for performance validation
 func BenchmarkNew(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -1164,6 +1223,95 @@ func TestFile_Ed25519ph_RoundTrip(t *testing.T) {
 	}
 }
 
+// signPureEd25519 signs file with pure Ed25519 over its body, as another
+// implementation might; Sign refuses the type since SU3 does not define it.
+func signPureEd25519(file *File, key ed25519.PrivateKey) {
+	file.SignatureType = SigTypeEdDSASHA512Ed25519
+	file.Signature = ed25519.Sign(key, file.BodyBytes())
+}
+
+func TestFile_Ed25519_VerifyOnly(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	certDER, err := NewEd25519SigningCertificate("ed25519@example.com", edKey)
+	if err != nil {
+		t.Fatalf("Failed to create Ed25519 certificate: %v", err)
+	}
+	parsedCert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	originalFile := New()
+	originalFile.FileType = FileTypeZIP
+	originalFile.ContentType = ContentTypeReseed
+	originalFile.Content = []byte("Ed25519 round-trip test content")
+	originalFile.SignerID = []byte("ed25519@example.com")
+	originalFile.SignatureType = SigTypeEdDSASHA512Ed25519
+	if err := originalFile.Sign(edKey); err == nil {
+		t.Error("Expected Sign to refuse signature type 7, which SU3 does not define")
+	}
+	signPureEd25519(originalFile, edKey)
+
+	data, err := originalFile.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal file: %v", err)
+	}
+	newFile := &File{}
+	if err := newFile.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Failed to unmarshal file: %v", err)
+	}
+	if len(newFile.Signature) != ed25519.SignatureSize {
+		t.Errorf("Expected signature length %d after round-trip, got %d", ed25519.SignatureSize, len(newFile.Signature))
+	}
+	if err := newFile.VerifySignature(parsedCert); err != nil {
+		t.Fatalf("Failed to verify Ed25519 signature after round-trip: %v", err)
+	}
+
+	newFile.Content = []byte("tampered content")
+	if err := newFile.VerifySignature(parsedCert); err == nil {
+		t.Error("Expected verification to fail for tampered content")
+	}
+}
+
+func TestFile_SignWith_ChoosesType(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate ECDSA key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		key       crypto.Signer
+		sigType   uint16
+		wantType  uint16
+		wantSigSz int
+	}{
+		{"Ed25519", edKey, SigTypeRSAWithSHA512, SigTypeEdDSASHA512Ed25519ph, ed25519.SignatureSize},
+		{"Ed25519 type 7 replaced", edKey, SigTypeEdDSASHA512Ed25519, SigTypeEdDSASHA512Ed25519ph, ed25519.SignatureSize},
+		{"ECDSA P-384", ecKey, SigTypeRSAWithSHA512, SigTypeECDSAWithSHA384, 104},
+		{"RSA-PSS kept", rsaKey, SigTypeRSAPSSWithSHA512, SigTypeRSAPSSWithSHA512, 256},
+	} {
+		file := New()
+		file.SignatureType = tt.sigType
+		file.Content = []byte("SignWith test")
+		if err := file.SignWith(tt.key); err != nil {
+			t.Fatalf("%s: SignWith() error: %v", tt.name, err)
+		}
+		if file.SignatureType != tt.wantType || len(file.Signature) != tt.wantSigSz {
+			t.Errorf("%s: got type %d with %d-byte signature, want type %d with %d bytes",
+				tt.name, file.SignatureType, len(file.Signature), tt.wantType, tt.wantSigSz)
+		}
+	}
+}
+
 func TestFile_Ed25519ph_VerifySignature(t *testing.T) {
 	_, edKey, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
@@ -1344,3 +1492,69 @@ func TestConstants_Ed25519ph(t *testing.T) {
 		t.Errorf("Expected SigTypeEdDSASHA512Ed25519ph = 8 per I2P spec, got %d", SigTypeEdDSASHA512Ed25519ph)
 	}
 }
+
+func TestFile_RSAPSS_RoundTrip(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	certDER, err := NewSigningCertificate("pss@example.com", rsaKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	originalFile := New()
+	originalFile.SignatureType = SigTypeRSAPSSWithSHA512
+	originalFile.FileType = FileTypeZIP
+	originalFile.ContentType = ContentTypeReseed
+	originalFile.Content = []byte("RSA-PSS round-trip test content")
+	originalFile.SignerID = []byte("pss@example.com")
+	if err := originalFile.Sign(rsaKey); err != nil {
+		t.Fatalf("Failed to sign file: %v", err)
+	}
+
+	data, err := originalFile.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal file: %v", err)
+	}
+	newFile := &File{}
+	if err := newFile.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Failed to unmarshal file: %v", err)
+	}
+	if newFile.SignatureType != SigTypeRSAPSSWithSHA512 {
+		t.Errorf("SignatureType mismatch: expected %d, got %d", SigTypeRSAPSSWithSHA512, newFile.SignatureType)
+	}
+	if err := newFile.VerifySignature(cert); err != nil {
+		t.Fatalf("Failed to verify RSA-PSS signature after round-trip: %v", err)
+	}
+
+	// A PSS signature must not pass as PKCS#1 v1.5, nor the other way round
+	newFile.SignatureType = SigTypeRSAWithSHA512
+	if err := newFile.VerifySignature(cert); err == nil {
+		t.Error("Expected a PSS signature to fail PKCS#1 v1.5 verification")
+	}
+	pkcs1 := New()
+	pkcs1.Content = originalFile.Content
+	if err := pkcs1.Sign(rsaKey); err != nil {
+		t.Fatalf("Failed to sign file: %v", err)
+	}
+	pkcs1.SignatureType = SigTypeRSAPSSWithSHA512
+	if err := pkcs1.VerifySignature(cert); err == nil {
+		t.Error("Expected a PKCS#1 v1.5 signature to fail PSS verification")
+	}
+
+	// PSS needs an RSA key
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	pss := New()
+	pss.SignatureType = SigTypeRSAPSSWithSHA512
+	if err := pss.Sign(edKey); err == nil {
+		t.Error("Expected RSA-PSS signing with an Ed25519 key to fail")
+	}
+}
@@ -0,0 +1,93 @@
+package su3
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// contentTypeNames maps ContentType* constants to the names used by the I2P router.
+var contentTypeNames = map[uint8]string{
+	ContentTypeUnknown:   "unknown",
+	ContentTypeRouter:    "router",
+	ContentTypePlugin:    "plugin",
+	ContentTypeReseed:    "reseed",
+	ContentTypeNews:      "news",
+	ContentTypeBlocklist: "blocklist",
+}
+
+// fileTypeExtensions maps FileType* constants to the file extension of their payload.
+var fileTypeExtensions = map[uint8]string{
+	FileTypeZIP:   ".zip",
+	FileTypeXML:   ".xml",
+	FileTypeHTML:  ".html",
+	FileTypeXMLGZ: ".xml.gz",
+	FileTypeTXTGZ: ".txt.gz",
+	FileTypeDMG:   ".dmg",
+	FileTypeEXE:   ".exe",
+}
+
+// ContentTypeName returns a human-readable name for an SU3 content type,
+// or "type N" for values not defined by the specification.
+func ContentTypeName(contentType uint8) string {
+	if name, ok := contentTypeNames[contentType]; ok {
+		return name
+	}
+	return fmt.Sprintf("type %d", contentType)
+}
+
+// FileTypeName returns a human-readable name for an SU3 file type, such as
+// "zip" or "xml.gz", or "type N" for values not defined by the specification.
+func FileTypeName(fileType uint8) string {
+	if ext, ok := fileTypeExtensions[fileType]; ok {
+		return ext[1:]
+	}
+	return fmt.Sprintf("type %d", fileType)
+}
+
+// FileTypeExtension returns the file extension, including the leading dot, for
+// the payload of an SU3 file type. Unknown file types return ".bin".
+func FileTypeExtension(fileType uint8) string {
+	if ext, ok := fileTypeExtensions[fileType]; ok {
+		return ext
+	}
+	return ".bin"
+}
+
+// ParseContentType returns the content type named by name, one of the names
+// ContentTypeName returns, such as "news" or "plugin". Case is ignored.
+func ParseContentType(name string) (uint8, error) {
+	for contentType, n := range contentTypeNames {
+		if strings.EqualFold(name, n) {
+			return contentType, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown su3 content type %q, want one of %s", name, strings.Join(sortedNames(contentTypeNames, nil), ", "))
+}
+
+// ParseFileType returns the file type named by name, one of the names
+// FileTypeName returns, such as "zip" or "xml.gz". Case is ignored and the dot
+// is optional, so "XMLGZ" names FileTypeXMLGZ too.
+func ParseFileType(name string) (uint8, error) {
+	want := strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(name, ".")), ".", "")
+	for fileType, ext := range fileTypeExtensions {
+		if strings.ReplaceAll(ext, ".", "") == want {
+			return fileType, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown su3 file type %q, want one of %s", name, strings.Join(sortedNames(fileTypeExtensions, func(ext string) string { return ext[1:] }), ", "))
+}
+
+// sortedNames lists the names in m in type order, passed through format if set.
+func sortedNames(m map[uint8]string, format func(string) string) []string {
+	types := slices.Sorted(maps.Keys(m))
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = m[t]
+		if format != nil {
+			names[i] = format(names[i])
+		}
+	}
+	return names
+}
@@ -0,0 +1,78 @@
+package su3
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContentTypeName(t *testing.T) {
+	tests := map[uint8]string{
+		ContentTypeUnknown:   "unknown",
+		ContentTypeRouter:    "router",
+		ContentTypePlugin:    "plugin",
+		ContentTypeReseed:    "reseed",
+		ContentTypeNews:      "news",
+		ContentTypeBlocklist: "blocklist",
+		42:                   "type 42",
+	}
+	for contentType, want := range tests {
+		if got := ContentTypeName(contentType); got != want {
+			t.Errorf("ContentTypeName(%d) = %q, want %q", contentType, got, want)
+		}
+	}
+}
+
+func TestFileTypeNameAndExtension(t *testing.T) {
+	tests := []struct {
+		fileType uint8
+		name     string
+		ext      string
+	}{
+		{FileTypeZIP, "zip", ".zip"},
+		{FileTypeXML, "xml", ".xml"},
+		{FileTypeHTML, "html", ".html"},
+		{FileTypeXMLGZ, "xml.gz", ".xml.gz"},
+		{FileTypeTXTGZ, "txt.gz", ".txt.gz"},
+		{FileTypeDMG, "dmg", ".dmg"},
+		{FileTypeEXE, "exe", ".exe"},
+		{99, "type 99", ".bin"},
+	}
+	for _, tt := range tests {
+		if got := FileTypeName(tt.fileType); got != tt.name {
+			t.Errorf("FileTypeName(%d) = %q, want %q", tt.fileType, got, tt.name)
+		}
+		if got := FileTypeExtension(tt.fileType); got != tt.ext {
+			t.Errorf("FileTypeExtension(%d) = %q, want %q", tt.fileType, got, tt.ext)
+		}
+	}
+}
+
+func TestParseContentAndFileType(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want uint8
+	}{
+		{"news", ContentTypeNews}, {"Plugin", ContentTypePlugin}, {"BLOCKLIST", ContentTypeBlocklist},
+	} {
+		if got, err := ParseContentType(tt.name); err != nil || got != tt.want {
+			t.Errorf("ParseContentType(%q) = %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := ParseContentType("firmware"); err == nil || !strings.Contains(err.Error(), "unknown, router, plugin, reseed, news, blocklist") {
+		t.Errorf("Expected an unknown content type to list the valid names, got %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		want uint8
+	}{
+		{"zip", FileTypeZIP}, {"xml.gz", FileTypeXMLGZ}, {"XMLGZ", FileTypeXMLGZ}, {".txt.gz", FileTypeTXTGZ}, {"exe", FileTypeEXE},
+	} {
+		if got, err := ParseFileType(tt.name); err != nil || got != tt.want {
+			t.Errorf("ParseFileType(%q) = %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := ParseFileType("tar"); err == nil || !strings.Contains(err.Error(), "zip, xml, html, xml.gz") {
+		t.Errorf("Expected an unknown file type to list the valid names, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package su3
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMalformed is wrapped by the errors VerifyBytes returns when data is
+	// not a well-formed SU3 file.
+	ErrMalformed = errors.New("su3: malformed file")
+	// ErrBadSignature is wrapped by the errors VerifyBytes returns when the
+	// file parses but its signature does not verify against the certificate.
+	ErrBadSignature = errors.New("su3: signature verification failed")
+)
+
+// VerifyBytes parses data as an SU3 file and verifies its signature against
+// cert, for programs that hold the certificate themselves rather than in a
+// keystore directory. Errors wrap ErrMalformed or ErrBadSignature, so callers
+// can tell a damaged file from one signed by someone else with errors.Is.
+func VerifyBytes(data []byte, cert *x509.Certificate) error {
+	file := New()
+	if err := file.UnmarshalBinary(data); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	if err := file.VerifySignature(cert); err != nil {
+		return fmt.Errorf("%w: %w", ErrBadSignature, err)
+	}
+	return nil
+}
+
+// VerifyBytesWithPEM is VerifyBytes with the certificate given in PEM form, as
+// found in the certificates directory of an I2P router.
+func VerifyBytesWithPEM(data, pemCert []byte) error {
+	block, _ := pem.Decode(pemCert)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return fmt.Errorf("su3: no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("su3: invalid certificate: %w", err)
+	}
+	return VerifyBytes(data, cert)
+}
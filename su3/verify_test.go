@@ -0,0 +1,43 @@
+package su3
+
+import (
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func TestVerifyBytes(t *testing.T) {
+	data, cert := newRSATestFile(t, []byte("plugin payload"))
+	if err := VerifyBytes(data, cert); err != nil {
+		t.Fatalf("VerifyBytes() error: %v", err)
+	}
+
+	pemCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	if err := VerifyBytesWithPEM(data, pemCert); err != nil {
+		t.Fatalf("VerifyBytesWithPEM() error: %v", err)
+	}
+	if err := VerifyBytesWithPEM(data, []byte("not a certificate")); err == nil {
+		t.Error("Expected a missing PEM certificate to be refused")
+	}
+}
+
+func TestVerifyBytes_DistinguishesErrors(t *testing.T) {
+	data, cert := newRSATestFile(t, []byte("plugin payload"))
+	_, otherCert := newRSATestFile(t, []byte("other payload"))
+
+	err := VerifyBytes(data[:40], cert)
+	if !errors.Is(err, ErrMalformed) || errors.Is(err, ErrBadSignature) {
+		t.Errorf("Expected a truncated file to be ErrMalformed, got %v", err)
+	}
+
+	err = VerifyBytes(data, otherCert)
+	if !errors.Is(err, ErrBadSignature) || errors.Is(err, ErrMalformed) {
+		t.Errorf("Expected the wrong certificate to be ErrBadSignature, got %v", err)
+	}
+
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-256-1] ^= 0xff // last content byte
+	if err := VerifyBytes(tampered, cert); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Expected tampered content to be ErrBadSignature, got %v", err)
+	}
+}